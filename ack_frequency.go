@@ -0,0 +1,68 @@
+package quic
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
+	"github.com/quic-go/quic-go/internal/wire"
+)
+
+const (
+	// We want to receive at least this many ACKs per congestion window.
+	minAcksPerCongestionWindow = 8
+	// The maximum number of ack-eliciting packets we allow the peer to receive before sending an ACK.
+	maxAckElicitingThreshold = 63
+	// The reordering threshold we request.
+	// This matches the packet threshold used for loss detection (RFC 9002, section 6.1.1),
+	// so that the peer sends an ACK right away once a packet would be declared lost.
+	ackFrequencyReorderingThreshold = 3
+)
+
+// The ackFrequencyManager asks the peer to send fewer ACKs when the congestion window is large,
+// by sending ACK_FREQUENCY frames (draft-ietf-quic-ack-frequency).
+// It is only used if the peer advertised the min_ack_delay transport parameter.
+type ackFrequencyManager struct {
+	rttStats          *utils.RTTStats
+	peerMinAckDelay   time.Duration
+	peerMaxAckDelay   time.Duration
+	queueControlFrame func(wire.Frame)
+
+	nextSequenceNumber uint64
+	threshold          uint64 // the ack-eliciting threshold that was requested last
+}
+
+func newAckFrequencyManager(rttStats *utils.RTTStats, peerMinAckDelay, peerMaxAckDelay time.Duration, queueControlFrame func(wire.Frame)) *ackFrequencyManager {
+	return &ackFrequencyManager{
+		rttStats:          rttStats,
+		peerMinAckDelay:   peerMinAckDelay,
+		peerMaxAckDelay:   peerMaxAckDelay,
+		queueControlFrame: queueControlFrame,
+		threshold:         1, // the default value used by the peer
+	}
+}
+
+// Update is called after processing an ACK frame.
+// To avoid sending too many ACK_FREQUENCY frames, the requested threshold is always one less than a power of 2,
+// i.e. a new frame is only sent when the congestion window doubles or halves.
+func (m *ackFrequencyManager) Update(cwnd, maxDatagramSize protocol.ByteCount) {
+	packetsPerAck := uint64(2)
+	for packetsPerAck*2-1 <= maxAckElicitingThreshold && protocol.ByteCount(packetsPerAck*2*minAcksPerCongestionWindow)*maxDatagramSize <= cwnd {
+		packetsPerAck *= 2
+	}
+	threshold := packetsPerAck - 1
+	if threshold == m.threshold {
+		return
+	}
+	m.threshold = threshold
+	// The requested max_ack_delay is never larger than the max_ack_delay transport parameter.
+	// This way, we don't need to take it into account when calculating the PTO.
+	maxAckDelay := min(m.peerMaxAckDelay, max(m.peerMinAckDelay, m.rttStats.SmoothedRTT()/4))
+	m.queueControlFrame(&wire.AckFrequencyFrame{
+		SequenceNumber:        m.nextSequenceNumber,
+		AckElicitingThreshold: threshold,
+		RequestedMaxAckDelay:  maxAckDelay,
+		ReorderingThreshold:   ackFrequencyReorderingThreshold,
+	})
+	m.nextSequenceNumber++
+}
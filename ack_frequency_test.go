@@ -0,0 +1,85 @@
+package quic
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
+	"github.com/quic-go/quic-go/internal/wire"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ACK frequency manager", func() {
+	const maxDatagramSize = 1000
+
+	var (
+		manager  *ackFrequencyManager
+		rttStats *utils.RTTStats
+		frames   []*wire.AckFrequencyFrame
+	)
+
+	BeforeEach(func() {
+		frames = nil
+		rttStats = &utils.RTTStats{}
+		rttStats.UpdateRTT(40*time.Millisecond, 0, time.Now())
+		manager = newAckFrequencyManager(rttStats, time.Millisecond, 25*time.Millisecond, func(f wire.Frame) {
+			frames = append(frames, f.(*wire.AckFrequencyFrame))
+		})
+	})
+
+	It("doesn't request fewer ACKs for small congestion windows", func() {
+		manager.Update(15*maxDatagramSize, maxDatagramSize)
+		Expect(frames).To(BeEmpty())
+	})
+
+	It("requests fewer ACKs when the congestion window grows", func() {
+		manager.Update(32*maxDatagramSize, maxDatagramSize)
+		Expect(frames).To(Equal([]*wire.AckFrequencyFrame{{
+			SequenceNumber:        0,
+			AckElicitingThreshold: 3,
+			RequestedMaxAckDelay:  10 * time.Millisecond,
+			ReorderingThreshold:   ackFrequencyReorderingThreshold,
+		}}))
+		// no new frame if the threshold doesn't change
+		manager.Update(63*maxDatagramSize, maxDatagramSize)
+		Expect(frames).To(HaveLen(1))
+		manager.Update(64*maxDatagramSize, maxDatagramSize)
+		Expect(frames).To(HaveLen(2))
+		Expect(frames[1].SequenceNumber).To(BeEquivalentTo(1))
+		Expect(frames[1].AckElicitingThreshold).To(BeEquivalentTo(7))
+	})
+
+	It("requests more ACKs when the congestion window shrinks", func() {
+		manager.Update(128*maxDatagramSize, maxDatagramSize)
+		Expect(frames).To(HaveLen(1))
+		Expect(frames[0].AckElicitingThreshold).To(BeEquivalentTo(15))
+		manager.Update(20*maxDatagramSize, maxDatagramSize)
+		Expect(frames).To(HaveLen(2))
+		Expect(frames[1].AckElicitingThreshold).To(BeEquivalentTo(1))
+	})
+
+	It("limits the ack-eliciting threshold", func() {
+		manager.Update(protocol.MaxByteCount, maxDatagramSize)
+		Expect(frames).To(HaveLen(1))
+		Expect(frames[0].AckElicitingThreshold).To(BeEquivalentTo(maxAckElicitingThreshold))
+	})
+
+	It("doesn't request a max_ack_delay outside of the peer's limits", func() {
+		rttStats.UpdateRTT(time.Second, 0, time.Now())
+		manager.Update(32*maxDatagramSize, maxDatagramSize)
+		Expect(frames).To(HaveLen(1))
+		Expect(frames[0].RequestedMaxAckDelay).To(Equal(25 * time.Millisecond))
+
+		frames = nil
+		rttStats = &utils.RTTStats{}
+		rttStats.UpdateRTT(time.Millisecond, 0, time.Now())
+		manager = newAckFrequencyManager(rttStats, 2*time.Millisecond, 25*time.Millisecond, func(f wire.Frame) {
+			frames = append(frames, f.(*wire.AckFrequencyFrame))
+		})
+		manager.Update(32*maxDatagramSize, maxDatagramSize)
+		Expect(frames).To(HaveLen(1))
+		Expect(frames[0].RequestedMaxAckDelay).To(Equal(2 * time.Millisecond))
+	})
+})
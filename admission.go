@@ -0,0 +1,182 @@
+package quic
+
+import (
+	"net"
+	"net/netip"
+	"sync"
+	"time"
+)
+
+// An AdmissionDecision is the decision made by the Transport.AdmitConnection callback.
+type AdmissionDecision uint8
+
+const (
+	// AdmissionAccept accepts the connection attempt.
+	// It is still subject to the MaxUnvalidatedHandshakes and MaxHandshakes limits.
+	AdmissionAccept AdmissionDecision = iota
+	// AdmissionRetry requires the client to validate its address using a Retry packet.
+	// If the client's address was already validated, the connection attempt is accepted.
+	AdmissionRetry
+	// AdmissionDrop silently drops the Initial packet.
+	AdmissionDrop
+	// AdmissionRefuse rejects the connection attempt with a CONNECTION_REFUSED error.
+	AdmissionRefuse
+)
+
+func (d AdmissionDecision) String() string {
+	switch d {
+	case AdmissionAccept:
+		return "accept"
+	case AdmissionRetry:
+		return "retry"
+	case AdmissionDrop:
+		return "drop"
+	case AdmissionRefuse:
+		return "refuse"
+	default:
+		return "unknown admission decision"
+	}
+}
+
+// AdmissionInfo contains information about a new connection attempt.
+// It is passed to the Transport.AdmitConnection callback.
+type AdmissionInfo struct {
+	// RemoteAddr is the remote address of the Initial packet.
+	RemoteAddr net.Addr
+	// AddrValidated says if the client presented a valid token,
+	// i.e. if the remote address was validated.
+	AddrValidated bool
+	// IsRetryToken says if the token presented by the client is a Retry token,
+	// i.e. if this connection attempt is the continuation of an attempt that was answered with a Retry.
+	IsRetryToken bool
+	// Version is the QUIC version of the Initial packet.
+	Version Version
+	// DestConnectionID is the Destination Connection ID of the Initial packet.
+	DestConnectionID ConnectionID
+	// NumHandshakes is the number of handshakes currently in progress,
+	// including the handshakes with unvalidated addresses.
+	NumHandshakes int
+	// NumUnvalidatedHandshakes is the number of handshakes in progress with unvalidated addresses.
+	NumUnvalidatedHandshakes int
+	// TokenData is the application data embedded into the token presented by the client,
+	// see TokenPolicyConfig.NewTokenData and AddressToken.Data.
+	// It is nil if the client didn't present a valid token, or presented a Retry token.
+	TokenData []byte
+}
+
+// HandshakeRateLimit configures the rate limiting performed by NewHandshakeRateLimiter.
+type HandshakeRateLimit struct {
+	// PerIP is the maximum number of connection attempts from a single IP address per Interval.
+	// A connection attempt continued after a Retry is not counted a second time.
+	// If zero, connection attempts are not limited per IP address.
+	PerIP int
+	// PerSubnet is the maximum number of connection attempts from a single subnet per Interval.
+	// If zero, connection attempts are not limited per subnet.
+	PerSubnet int
+	// IPv4PrefixLen is the prefix length used to group IPv4 addresses into subnets.
+	// If unset, 24 is used.
+	IPv4PrefixLen int
+	// IPv6PrefixLen is the prefix length used to group IPv6 addresses into subnets.
+	// If unset, 48 is used.
+	IPv6PrefixLen int
+	// Interval is the length of the rate limiting window.
+	// If unset, 1 second is used.
+	Interval time.Duration
+}
+
+// NewHandshakeRateLimiter returns a callback for Transport.AdmitConnection,
+// that limits the rate of connection attempts per source IP address and per subnet.
+//
+// Since the source address of an Initial packet can be spoofed, clients exceeding the limit
+// are first asked to validate their address using a Retry.
+// Clients with a validated address exceeding the limit are refused.
+// Connection attempts from addresses that are not IP addresses are always accepted.
+func NewHandshakeRateLimiter(l HandshakeRateLimit) func(*AdmissionInfo) AdmissionDecision {
+	return newHandshakeRateLimiter(l, time.Now).admit
+}
+
+func newHandshakeRateLimiter(l HandshakeRateLimit, now func() time.Time) *handshakeRateLimiter {
+	r := &handshakeRateLimiter{
+		HandshakeRateLimit: l,
+		now:                now,
+		perIP:              make(map[netip.Addr]int),
+		perSubnet:          make(map[netip.Prefix]int),
+	}
+	if r.IPv4PrefixLen == 0 {
+		r.IPv4PrefixLen = 24
+	}
+	if r.IPv6PrefixLen == 0 {
+		r.IPv6PrefixLen = 48
+	}
+	if r.Interval == 0 {
+		r.Interval = time.Second
+	}
+	return r
+}
+
+type handshakeRateLimiter struct {
+	HandshakeRateLimit
+	now func() time.Time
+
+	mutex       sync.Mutex
+	windowStart time.Time
+	// The counters are reset at the beginning of every window.
+	// This bounds the memory used to the number of addresses seen within one window.
+	perIP     map[netip.Addr]int
+	perSubnet map[netip.Prefix]int
+}
+
+func (r *handshakeRateLimiter) admit(info *AdmissionInfo) AdmissionDecision {
+	addr, ok := ipFromNetAddr(info.RemoteAddr)
+	if !ok {
+		return AdmissionAccept
+	}
+	prefixLen := r.IPv6PrefixLen
+	if addr.Is4() {
+		prefixLen = r.IPv4PrefixLen
+	}
+	subnet, err := addr.Prefix(prefixLen)
+	if err != nil {
+		return AdmissionAccept
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if now := r.now(); now.Sub(r.windowStart) >= r.Interval {
+		r.windowStart = now
+		clear(r.perIP)
+		clear(r.perSubnet)
+	}
+	// The Initial packet that was answered with the Retry was already counted.
+	if !info.IsRetryToken {
+		r.perIP[addr]++
+		r.perSubnet[subnet]++
+	}
+	if (r.PerIP > 0 && r.perIP[addr] > r.PerIP) || (r.PerSubnet > 0 && r.perSubnet[subnet] > r.PerSubnet) {
+		if info.AddrValidated {
+			return AdmissionRefuse
+		}
+		return AdmissionRetry
+	}
+	return AdmissionAccept
+}
+
+func ipFromNetAddr(addr net.Addr) (netip.Addr, bool) {
+	var ip net.IP
+	switch a := addr.(type) {
+	case *net.UDPAddr:
+		ip = a.IP
+	case *net.TCPAddr:
+		ip = a.IP
+	case *net.IPAddr:
+		ip = a.IP
+	default:
+		return netip.Addr{}, false
+	}
+	ipAddr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return netip.Addr{}, false
+	}
+	return ipAddr.Unmap(), true
+}
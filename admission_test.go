@@ -0,0 +1,84 @@
+package quic
+
+import (
+	"net"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Handshake Rate Limiter", func() {
+	admissionInfo := func(ip string, validated bool) *AdmissionInfo {
+		return &AdmissionInfo{
+			RemoteAddr:    &net.UDPAddr{IP: net.ParseIP(ip), Port: 1234},
+			AddrValidated: validated,
+		}
+	}
+
+	It("limits connection attempts per IP address", func() {
+		admit := NewHandshakeRateLimiter(HandshakeRateLimit{PerIP: 2, Interval: time.Hour})
+		Expect(admit(admissionInfo("192.0.2.1", false))).To(Equal(AdmissionAccept))
+		Expect(admit(admissionInfo("192.0.2.1", false))).To(Equal(AdmissionAccept))
+		Expect(admit(admissionInfo("192.0.2.1", false))).To(Equal(AdmissionRetry))
+		Expect(admit(admissionInfo("192.0.2.1", true))).To(Equal(AdmissionRefuse))
+		// other IP addresses are not affected
+		Expect(admit(admissionInfo("192.0.2.2", false))).To(Equal(AdmissionAccept))
+	})
+
+	It("limits connection attempts per subnet", func() {
+		admit := NewHandshakeRateLimiter(HandshakeRateLimit{PerSubnet: 2, Interval: time.Hour})
+		Expect(admit(admissionInfo("192.0.2.1", false))).To(Equal(AdmissionAccept))
+		Expect(admit(admissionInfo("192.0.2.2", false))).To(Equal(AdmissionAccept))
+		Expect(admit(admissionInfo("192.0.2.3", true))).To(Equal(AdmissionRefuse))
+		Expect(admit(admissionInfo("192.0.3.1", false))).To(Equal(AdmissionAccept))
+		// IPv6 addresses are grouped into /48 subnets
+		Expect(admit(admissionInfo("2001:db8:1:1::1", false))).To(Equal(AdmissionAccept))
+		Expect(admit(admissionInfo("2001:db8:1:2::1", false))).To(Equal(AdmissionAccept))
+		Expect(admit(admissionInfo("2001:db8:1:3::1", false))).To(Equal(AdmissionRetry))
+		Expect(admit(admissionInfo("2001:db8:2::1", false))).To(Equal(AdmissionAccept))
+	})
+
+	It("uses the configured prefix lengths", func() {
+		admit := NewHandshakeRateLimiter(HandshakeRateLimit{PerSubnet: 1, IPv4PrefixLen: 32, Interval: time.Hour})
+		Expect(admit(admissionInfo("192.0.2.1", false))).To(Equal(AdmissionAccept))
+		Expect(admit(admissionInfo("192.0.2.2", false))).To(Equal(AdmissionAccept))
+		Expect(admit(admissionInfo("192.0.2.2", false))).To(Equal(AdmissionRetry))
+	})
+
+	It("treats IPv4-mapped IPv6 addresses as IPv4 addresses", func() {
+		admit := NewHandshakeRateLimiter(HandshakeRateLimit{PerIP: 1, Interval: time.Hour})
+		Expect(admit(admissionInfo("192.0.2.1", false))).To(Equal(AdmissionAccept))
+		Expect(admit(admissionInfo("::ffff:192.0.2.1", false))).To(Equal(AdmissionRetry))
+	})
+
+	It("doesn't count connection attempts continued after a Retry", func() {
+		admit := NewHandshakeRateLimiter(HandshakeRateLimit{PerIP: 2, PerSubnet: 2, Interval: time.Hour})
+		Expect(admit(admissionInfo("192.0.2.1", false))).To(Equal(AdmissionAccept))
+		// the server sent a Retry, e.g. because of the MaxUnvalidatedHandshakes limit
+		info := admissionInfo("192.0.2.1", true)
+		info.IsRetryToken = true
+		Expect(admit(info)).To(Equal(AdmissionAccept))
+		Expect(admit(admissionInfo("192.0.2.1", false))).To(Equal(AdmissionAccept))
+		Expect(admit(admissionInfo("192.0.2.1", false))).To(Equal(AdmissionRetry))
+		// the client whose connection attempt exceeded the limit still exceeds it after the Retry
+		Expect(admit(info)).To(Equal(AdmissionRefuse))
+	})
+
+	It("resets the counters after the interval", func() {
+		now := time.Now()
+		admit := newHandshakeRateLimiter(HandshakeRateLimit{PerIP: 1, Interval: time.Second}, func() time.Time { return now }).admit
+		Expect(admit(admissionInfo("192.0.2.1", false))).To(Equal(AdmissionAccept))
+		now = now.Add(time.Second - time.Nanosecond)
+		Expect(admit(admissionInfo("192.0.2.1", false))).To(Equal(AdmissionRetry))
+		now = now.Add(time.Nanosecond)
+		Expect(admit(admissionInfo("192.0.2.1", false))).To(Equal(AdmissionAccept))
+	})
+
+	It("accepts connection attempts from non-IP addresses", func() {
+		admit := NewHandshakeRateLimiter(HandshakeRateLimit{PerIP: 1, PerSubnet: 1})
+		info := &AdmissionInfo{RemoteAddr: &net.UnixAddr{Name: "foo", Net: "unixgram"}}
+		Expect(admit(info)).To(Equal(AdmissionAccept))
+		Expect(admit(info)).To(Equal(AdmissionAccept))
+	})
+})
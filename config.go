@@ -1,6 +1,7 @@
 package quic
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -39,12 +40,34 @@ func validateConfig(config *Config) error {
 	if config.MaxConnectionReceiveWindow > quicvarint.Max {
 		config.MaxConnectionReceiveWindow = quicvarint.Max
 	}
+	if config.MaxPathMTU > protocol.MaxPacketBufferSize {
+		config.MaxPathMTU = protocol.MaxPacketBufferSize
+	}
 	// check that all QUIC versions are actually supported
 	for _, v := range config.Versions {
 		if !protocol.IsValidVersion(v) {
 			return fmt.Errorf("invalid QUIC version: %s", v)
 		}
 	}
+	if config.DatagramSendQueuePolicy > DatagramQueueError {
+		return fmt.Errorf("invalid datagram send queue policy: %s", config.DatagramSendQueuePolicy)
+	}
+	switch config.DatagramReceiveQueuePolicy {
+	case DatagramQueueDefault, DatagramQueueDropNewest, DatagramQueueDropOldest:
+	default:
+		return fmt.Errorf("invalid datagram receive queue policy: %s", config.DatagramReceiveQueuePolicy)
+	}
+	if pa := config.PreferredAddress; pa != nil {
+		if !pa.IPv4.IsValid() && !pa.IPv6.IsValid() {
+			return errors.New("invalid preferred address: neither IPv4 nor IPv6 address set")
+		}
+		if pa.IPv4.IsValid() && !pa.IPv4.Addr().Is4() {
+			return fmt.Errorf("invalid preferred address: %s is not an IPv4 address", pa.IPv4)
+		}
+		if pa.IPv6.IsValid() && (!pa.IPv6.Addr().Is6() || pa.IPv6.Addr().Is4In6()) {
+			return fmt.Errorf("invalid preferred address: %s is not an IPv6 address", pa.IPv6)
+		}
+	}
 	return nil
 }
 
@@ -82,6 +105,26 @@ func populateConfig(config *Config) *Config {
 	if maxConnectionReceiveWindow == 0 {
 		maxConnectionReceiveWindow = protocol.DefaultMaxReceiveConnectionFlowControlWindow
 	}
+	maxPathMTU := config.MaxPathMTU
+	if maxPathMTU == 0 {
+		maxPathMTU = protocol.MaxPacketBufferSize
+	}
+	datagramSendQueueLen := config.DatagramSendQueueLen
+	if datagramSendQueueLen <= 0 {
+		datagramSendQueueLen = protocol.DefaultMaxDatagramSendQueueLen
+	}
+	datagramSendQueuePolicy := config.DatagramSendQueuePolicy
+	if datagramSendQueuePolicy == DatagramQueueDefault {
+		datagramSendQueuePolicy = DatagramQueueBlock
+	}
+	datagramReceiveQueueLen := config.DatagramReceiveQueueLen
+	if datagramReceiveQueueLen <= 0 {
+		datagramReceiveQueueLen = protocol.DefaultMaxDatagramReceiveQueueLen
+	}
+	datagramReceiveQueuePolicy := config.DatagramReceiveQueuePolicy
+	if datagramReceiveQueuePolicy == DatagramQueueDefault {
+		datagramReceiveQueuePolicy = DatagramQueueDropNewest
+	}
 	maxIncomingStreams := config.MaxIncomingStreams
 	if maxIncomingStreams == 0 {
 		maxIncomingStreams = protocol.DefaultMaxIncomingStreams
@@ -101,6 +144,9 @@ func populateConfig(config *Config) *Config {
 		HandshakeIdleTimeout:           handshakeIdleTimeout,
 		MaxIdleTimeout:                 idleTimeout,
 		KeepAlivePeriod:                config.KeepAlivePeriod,
+		ConnectionIDRotationInterval:   config.ConnectionIDRotationInterval,
+		ConnectionIDRotationPackets:    config.ConnectionIDRotationPackets,
+		ConnectionIDRotationIdlePeriod: config.ConnectionIDRotationIdlePeriod,
 		InitialStreamReceiveWindow:     initialStreamReceiveWindow,
 		MaxStreamReceiveWindow:         maxStreamReceiveWindow,
 		InitialConnectionReceiveWindow: initialConnectionReceiveWindow,
@@ -110,8 +156,20 @@ func populateConfig(config *Config) *Config {
 		MaxIncomingUniStreams:          maxIncomingUniStreams,
 		TokenStore:                     config.TokenStore,
 		EnableDatagrams:                config.EnableDatagrams,
+		DatagramSendQueueLen:           datagramSendQueueLen,
+		DatagramSendQueuePolicy:        datagramSendQueuePolicy,
+		DatagramReceiveQueueLen:        datagramReceiveQueueLen,
+		DatagramReceiveQueuePolicy:     datagramReceiveQueuePolicy,
+		DatagramMaxAge:                 config.DatagramMaxAge,
 		DisablePathMTUDiscovery:        config.DisablePathMTUDiscovery,
+		MaxPathMTU:                     maxPathMTU,
 		Allow0RTT:                      config.Allow0RTT,
+		PreferredAddress:               config.PreferredAddress,
+		AllowActiveMigration:           config.AllowActiveMigration,
+		CongestionController:           config.CongestionController,
+		EnableL4S:                      config.EnableL4S,
+		EnableAckFrequency:             config.EnableAckFrequency,
+		EnableResetStreamAt:            config.EnableResetStreamAt,
 		Tracer:                         config.Tracer,
 		Tracer_and_Balancer:            config.Tracer_and_Balancer,
 	}
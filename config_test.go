@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/netip"
 	"reflect"
 	"time"
 
@@ -50,6 +51,35 @@ var _ = Describe("Config", func() {
 			Expect(conf.MaxStreamReceiveWindow).To(BeEquivalentTo(uint64(quicvarint.Max)))
 			Expect(conf.MaxConnectionReceiveWindow).To(BeEquivalentTo(uint64(quicvarint.Max)))
 		})
+
+		It("clips too large values for the maximum Path MTU", func() {
+			conf := &Config{MaxPathMTU: 1500}
+			Expect(validateConfig(conf)).To(Succeed())
+			Expect(conf.MaxPathMTU).To(BeEquivalentTo(protocol.MaxPacketBufferSize))
+		})
+
+		It("validates the datagram queue policies", func() {
+			Expect(validateConfig(&Config{DatagramSendQueuePolicy: DatagramQueueError})).To(Succeed())
+			Expect(validateConfig(&Config{DatagramSendQueuePolicy: 42})).To(MatchError("invalid datagram send queue policy: unknown datagram queue policy: 42"))
+			Expect(validateConfig(&Config{DatagramReceiveQueuePolicy: DatagramQueueDropOldest})).To(Succeed())
+			Expect(validateConfig(&Config{DatagramReceiveQueuePolicy: DatagramQueueDefault})).To(Succeed())
+			Expect(validateConfig(&Config{DatagramReceiveQueuePolicy: DatagramQueueBlock})).To(MatchError("invalid datagram receive queue policy: block"))
+			Expect(validateConfig(&Config{DatagramReceiveQueuePolicy: DatagramQueueError})).To(MatchError("invalid datagram receive queue policy: error"))
+		})
+
+		It("validates the preferred address", func() {
+			Expect(validateConfig(&Config{PreferredAddress: &PreferredAddress{
+				IPv4: netip.MustParseAddrPort("192.0.2.1:443"),
+				IPv6: netip.MustParseAddrPort("[2001:db8::1]:443"),
+			}})).To(Succeed())
+			Expect(validateConfig(&Config{PreferredAddress: &PreferredAddress{}})).To(MatchError("invalid preferred address: neither IPv4 nor IPv6 address set"))
+			Expect(validateConfig(&Config{PreferredAddress: &PreferredAddress{
+				IPv4: netip.MustParseAddrPort("[2001:db8::1]:443"),
+			}})).To(MatchError("invalid preferred address: [2001:db8::1]:443 is not an IPv4 address"))
+			Expect(validateConfig(&Config{PreferredAddress: &PreferredAddress{
+				IPv6: netip.MustParseAddrPort("192.0.2.1:443"),
+			}})).To(MatchError("invalid preferred address: 192.0.2.1:443 is not an IPv6 address"))
+		})
 	})
 
 	configWithNonZeroNonFunctionFields := func() *Config {
@@ -65,7 +95,7 @@ var _ = Describe("Config", func() {
 			}
 
 			switch fn := typ.Field(i).Name; fn {
-			case "GetConfigForClient", "RequireAddressValidation", "GetLogWriter", "AllowConnectionWindowIncrease", "Tracer":
+			case "GetConfigForClient", "RequireAddressValidation", "GetLogWriter", "AllowConnectionWindowIncrease", "Tracer", "Tracer_and_Balancer", "CongestionController":
 				// Can't compare functions.
 			case "Versions":
 				f.Set(reflect.ValueOf([]Version{1, 2, 3}))
@@ -95,14 +125,42 @@ var _ = Describe("Config", func() {
 				f.Set(reflect.ValueOf(&StatelessResetKey{1, 2, 3, 4}))
 			case "KeepAlivePeriod":
 				f.Set(reflect.ValueOf(time.Second))
+			case "ConnectionIDRotationInterval":
+				f.Set(reflect.ValueOf(time.Minute))
+			case "ConnectionIDRotationPackets":
+				f.Set(reflect.ValueOf(uint32(5000)))
+			case "ConnectionIDRotationIdlePeriod":
+				f.Set(reflect.ValueOf(2 * time.Second))
 			case "EnableDatagrams":
 				f.Set(reflect.ValueOf(true))
+			case "DatagramSendQueueLen":
+				f.Set(reflect.ValueOf(13))
+			case "DatagramSendQueuePolicy":
+				f.Set(reflect.ValueOf(DatagramQueueError))
+			case "DatagramReceiveQueueLen":
+				f.Set(reflect.ValueOf(14))
+			case "DatagramReceiveQueuePolicy":
+				f.Set(reflect.ValueOf(DatagramQueueDropOldest))
+			case "DatagramMaxAge":
+				f.Set(reflect.ValueOf(time.Minute))
 			case "DisableVersionNegotiationPackets":
 				f.Set(reflect.ValueOf(true))
 			case "DisablePathMTUDiscovery":
 				f.Set(reflect.ValueOf(true))
+			case "MaxPathMTU":
+				f.Set(reflect.ValueOf(uint16(1400)))
 			case "Allow0RTT":
 				f.Set(reflect.ValueOf(true))
+			case "PreferredAddress":
+				f.Set(reflect.ValueOf(&PreferredAddress{IPv4: netip.MustParseAddrPort("127.0.0.1:1234")}))
+			case "AllowActiveMigration":
+				f.Set(reflect.ValueOf(true))
+			case "EnableL4S":
+				f.Set(reflect.ValueOf(true))
+			case "EnableAckFrequency":
+				f.Set(reflect.ValueOf(true))
+			case "EnableResetStreamAt":
+				f.Set(reflect.ValueOf(true))
 			default:
 				Fail(fmt.Sprintf("all fields must be accounted for, but saw unknown field %q", fn))
 			}
@@ -117,7 +175,7 @@ var _ = Describe("Config", func() {
 
 	Context("cloning", func() {
 		It("clones function fields", func() {
-			var calledAllowConnectionWindowIncrease, calledTracer bool
+			var calledAllowConnectionWindowIncrease, calledTracer, calledCongestionController bool
 			c1 := &Config{
 				GetConfigForClient:            func(info *ClientHelloInfo) (*Config, error) { return nil, errors.New("nope") },
 				AllowConnectionWindowIncrease: func(Connection, uint64) bool { calledAllowConnectionWindowIncrease = true; return true },
@@ -125,6 +183,10 @@ var _ = Describe("Config", func() {
 					calledTracer = true
 					return nil
 				},
+				CongestionController: func(*logging.RTTStats, logging.ByteCount, *logging.ConnectionTracer) CongestionController {
+					calledCongestionController = true
+					return nil
+				},
 			}
 			c2 := c1.Clone()
 			c2.AllowConnectionWindowIncrease(nil, 1234)
@@ -133,6 +195,8 @@ var _ = Describe("Config", func() {
 			Expect(err).To(MatchError("nope"))
 			c2.Tracer(context.Background(), logging.PerspectiveClient, protocol.ConnectionID{})
 			Expect(calledTracer).To(BeTrue())
+			c2.CongestionController(nil, 1200, nil)
+			Expect(calledCongestionController).To(BeTrue())
 		})
 
 		It("clones non-function fields", func() {
@@ -166,6 +230,11 @@ var _ = Describe("Config", func() {
 			Expect(c.MaxIncomingStreams).To(BeEquivalentTo(protocol.DefaultMaxIncomingStreams))
 			Expect(c.MaxIncomingUniStreams).To(BeEquivalentTo(protocol.DefaultMaxIncomingUniStreams))
 			Expect(c.DisablePathMTUDiscovery).To(BeFalse())
+			Expect(c.MaxPathMTU).To(BeEquivalentTo(protocol.MaxPacketBufferSize))
+			Expect(c.DatagramSendQueueLen).To(Equal(protocol.DefaultMaxDatagramSendQueueLen))
+			Expect(c.DatagramReceiveQueueLen).To(Equal(protocol.DefaultMaxDatagramReceiveQueueLen))
+			Expect(c.DatagramSendQueuePolicy).To(Equal(DatagramQueueBlock))
+			Expect(c.DatagramReceiveQueuePolicy).To(Equal(DatagramQueueDropNewest))
 			Expect(c.GetConfigForClient).To(BeNil())
 		})
 	})
@@ -0,0 +1,95 @@
+package quic
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/congestion"
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
+	"github.com/quic-go/quic-go/logging"
+)
+
+// A CongestionController performs congestion control and pacing for a connection.
+// It is only used from the connection's run loop, so it doesn't need to be safe for concurrent use.
+type CongestionController interface {
+	// TimeUntilSend returns the time when the next packet can be sent, according to the pacer.
+	TimeUntilSend(bytesInFlight logging.ByteCount) time.Time
+	// HasPacingBudget says if the pacer allows sending a packet at this moment.
+	HasPacingBudget(now time.Time) bool
+	// OnPacketSent is called for every packet sent.
+	// bytesInFlight is the number of bytes in flight before this packet was sent.
+	OnPacketSent(sentTime time.Time, bytesInFlight logging.ByteCount, packetNumber logging.PacketNumber, bytes logging.ByteCount, isRetransmittable bool)
+	// CanSend says if the congestion window allows sending another packet.
+	CanSend(bytesInFlight logging.ByteCount) bool
+	// MaybeExitSlowStart is called when a new RTT sample is available.
+	MaybeExitSlowStart()
+	// OnPacketAcked is called for every packet that is newly acknowledged.
+	OnPacketAcked(number logging.PacketNumber, ackedBytes logging.ByteCount, priorInFlight logging.ByteCount, eventTime time.Time)
+	// OnCongestionEvent is called when a packet is declared lost, or when the peer reports ECN-CE marks.
+	// Congestion controllers that implement an OnECNFeedback(largestAcked logging.PacketNumber, ackedBytes, ceMarkedBytes logging.ByteCount) method
+	// use L4S: packets are sent with ECT(1), and ECN-CE marks are reported using OnECNFeedback instead.
+	// Congestion controllers that implement an OnSpuriousLoss(logging.PacketNumber) method are notified
+	// when a packet that was declared lost is acknowledged, and can undo their response to the loss.
+	OnCongestionEvent(number logging.PacketNumber, lostBytes logging.ByteCount, priorInFlight logging.ByteCount)
+	// OnRetransmissionTimeout is called when the PTO fires.
+	OnRetransmissionTimeout(packetsRetransmitted bool)
+	// OnConnectionMigration is called when the connection switches to a new path.
+	OnConnectionMigration()
+	// SetMaxDatagramSize is called when the maximum packet size changes, e.g. as a result of Path MTU Discovery.
+	SetMaxDatagramSize(logging.ByteCount)
+	InSlowStart() bool
+	InRecovery() bool
+	GetCongestionWindow() logging.ByteCount
+}
+
+var _ congestion.SendAlgorithmWithDebugInfos = CongestionController(nil)
+
+// NewRenoCongestionController creates a congestion controller implementing NewReno, as described in RFC 9002.
+// This is the default congestion controller.
+func NewRenoCongestionController(rttStats *logging.RTTStats, initialMaxDatagramSize logging.ByteCount, tracer *logging.ConnectionTracer) CongestionController {
+	return congestion.NewRenoSender(rttStats, initialMaxDatagramSize, tracer)
+}
+
+// NewCubicCongestionController creates a congestion controller implementing CUBIC, as described in RFC 9438.
+func NewCubicCongestionController(rttStats *logging.RTTStats, initialMaxDatagramSize logging.ByteCount, tracer *logging.ConnectionTracer) CongestionController {
+	return congestion.NewCubicSender(congestion.DefaultClock{}, rttStats, initialMaxDatagramSize, false, false, tracer)
+}
+
+// NewRenoCongestionControllerWithHyStartPlusPlus creates a NewReno congestion controller
+// that uses HyStart++ (RFC 9406) instead of HyStart to exit slow start.
+func NewRenoCongestionControllerWithHyStartPlusPlus(rttStats *logging.RTTStats, initialMaxDatagramSize logging.ByteCount, tracer *logging.ConnectionTracer) CongestionController {
+	return congestion.NewCubicSender(congestion.DefaultClock{}, rttStats, initialMaxDatagramSize, true, true, tracer)
+}
+
+// NewCubicCongestionControllerWithHyStartPlusPlus creates a CUBIC congestion controller
+// that uses HyStart++ (RFC 9406) instead of HyStart to exit slow start.
+func NewCubicCongestionControllerWithHyStartPlusPlus(rttStats *logging.RTTStats, initialMaxDatagramSize logging.ByteCount, tracer *logging.ConnectionTracer) CongestionController {
+	return congestion.NewCubicSender(congestion.DefaultClock{}, rttStats, initialMaxDatagramSize, false, true, tracer)
+}
+
+// NewBBRCongestionController creates a congestion controller implementing BBR.
+// BBR builds a model of the path from the measured bandwidth and minimum RTT, and doesn't interpret every packet loss as a congestion signal.
+func NewBBRCongestionController(rttStats *logging.RTTStats, initialMaxDatagramSize logging.ByteCount, tracer *logging.ConnectionTracer) CongestionController {
+	return congestion.NewBBRSender(congestion.DefaultClock{}, rttStats, initialMaxDatagramSize, tracer)
+}
+
+// NewPragueCongestionController creates a scalable congestion controller for L4S (RFC 9330), following TCP Prague.
+// Packets are sent with ECT(1), and the congestion window is reduced in proportion to the fraction of CE-marked packets.
+// For this to be effective, the bottleneck needs to support L4S.
+func NewPragueCongestionController(rttStats *logging.RTTStats, initialMaxDatagramSize logging.ByteCount, tracer *logging.ConnectionTracer) CongestionController {
+	return congestion.NewPragueSender(rttStats, initialMaxDatagramSize, tracer)
+}
+
+func newSendAlgorithmFactory(conf *Config) congestion.SendAlgorithmFactory {
+	if conf.CongestionController == nil {
+		if conf.EnableL4S {
+			return func(rttStats *utils.RTTStats, initialMaxDatagramSize protocol.ByteCount, tracer *logging.ConnectionTracer) congestion.SendAlgorithmWithDebugInfos {
+				return congestion.NewPragueSender(rttStats, initialMaxDatagramSize, tracer)
+			}
+		}
+		return nil
+	}
+	return func(rttStats *utils.RTTStats, initialMaxDatagramSize protocol.ByteCount, tracer *logging.ConnectionTracer) congestion.SendAlgorithmWithDebugInfos {
+		return conf.CongestionController(rttStats, initialMaxDatagramSize, tracer)
+	}
+}
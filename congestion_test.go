@@ -0,0 +1,69 @@
+package quic
+
+import (
+	"github.com/quic-go/quic-go/internal/congestion"
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
+	"github.com/quic-go/quic-go/logging"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Congestion Controller", func() {
+	It("uses the default congestion controller if none is configured", func() {
+		Expect(newSendAlgorithmFactory(&Config{})).To(BeNil())
+	})
+
+	It("uses the Prague congestion controller if L4S is enabled", func() {
+		factory := newSendAlgorithmFactory(&Config{EnableL4S: true})
+		Expect(factory).ToNot(BeNil())
+		_, ok := factory(&utils.RTTStats{}, 1234, nil).(congestion.ScalableECNSendAlgorithm)
+		Expect(ok).To(BeTrue())
+		// a configured congestion controller takes precedence
+		factory = newSendAlgorithmFactory(&Config{EnableL4S: true, CongestionController: NewCubicCongestionController})
+		_, ok = factory(&utils.RTTStats{}, 1234, nil).(congestion.ScalableECNSendAlgorithm)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("uses the configured congestion controller", func() {
+		rttStats := &utils.RTTStats{}
+		tracer := &logging.ConnectionTracer{}
+		cc := NewCubicCongestionController(rttStats, 1234, nil)
+		var called bool
+		factory := newSendAlgorithmFactory(&Config{
+			CongestionController: func(r *logging.RTTStats, size logging.ByteCount, tr *logging.ConnectionTracer) CongestionController {
+				Expect(r).To(BeIdenticalTo(rttStats))
+				Expect(size).To(Equal(protocol.ByteCount(1234)))
+				Expect(tr).To(BeIdenticalTo(tracer))
+				called = true
+				return cc
+			},
+		})
+		Expect(factory).ToNot(BeNil())
+		Expect(factory(rttStats, 1234, tracer)).To(BeIdenticalTo(cc))
+		Expect(called).To(BeTrue())
+	})
+
+	for _, f := range []struct {
+		name       string
+		newControl func(*logging.RTTStats, logging.ByteCount, *logging.ConnectionTracer) CongestionController
+	}{
+		{name: "Reno", newControl: NewRenoCongestionController},
+		{name: "CUBIC", newControl: NewCubicCongestionController},
+		{name: "Reno with HyStart++", newControl: NewRenoCongestionControllerWithHyStartPlusPlus},
+		{name: "CUBIC with HyStart++", newControl: NewCubicCongestionControllerWithHyStartPlusPlus},
+		{name: "BBR", newControl: NewBBRCongestionController},
+		{name: "Prague", newControl: NewPragueCongestionController},
+	} {
+		newControl := f.newControl
+
+		It("creates a "+f.name+" congestion controller", func() {
+			cc := newControl(&utils.RTTStats{}, 1200, nil)
+			Expect(cc.InSlowStart()).To(BeTrue())
+			Expect(cc.InRecovery()).To(BeFalse())
+			Expect(cc.GetCongestionWindow()).To(Equal(32 * protocol.ByteCount(1200)))
+			Expect(cc.CanSend(0)).To(BeTrue())
+		})
+	}
+})
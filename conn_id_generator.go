@@ -2,6 +2,7 @@ package quic
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/qerr"
@@ -14,6 +15,20 @@ type connIDGenerator struct {
 
 	activeSrcConnIDs        map[uint64]protocol.ConnectionID
 	initialClientDestConnID *protocol.ConnectionID // nil for the client
+	// the connection ID sent in the preferred_address transport parameter, until it is added
+	preferredAddressConnID *protocol.ConnectionID
+
+	handshakeComplete bool
+	numActiveConnIDs  uint64 // the number of connection IDs the peer stores, as determined by SetMaxActiveConnIDs
+	// Connection IDs with sequence numbers smaller than retirePriorTo were retired by sending
+	// NEW_CONNECTION_ID frames with the Retire Prior To field set.
+	retirePriorTo uint64
+
+	// All issued connection IDs can be replaced periodically, or after sending a number of packets.
+	rotationInterval     time.Duration
+	rotationPackets      uint32
+	lastRotation         time.Time
+	packetsSinceRotation uint32
 
 	addConnectionID        func(protocol.ConnectionID)
 	getStatelessResetToken func(protocol.ConnectionID) protocol.StatelessResetToken
@@ -33,9 +48,13 @@ func newConnIDGenerator(
 	replaceWithClosed func([]protocol.ConnectionID, protocol.Perspective, []byte),
 	queueControlFrame func(wire.Frame),
 	generator ConnectionIDGenerator,
+	rotationInterval time.Duration, // if 0, connection IDs are not rotated periodically
+	rotationPackets uint32, // if 0, connection IDs are not rotated after sending a number of packets
 ) *connIDGenerator {
 	m := &connIDGenerator{
 		generator:              generator,
+		rotationInterval:       rotationInterval,
+		rotationPackets:        rotationPackets,
 		activeSrcConnIDs:       make(map[uint64]protocol.ConnectionID),
 		addConnectionID:        addConnectionID,
 		getStatelessResetToken: getStatelessResetToken,
@@ -57,9 +76,9 @@ func (m *connIDGenerator) SetMaxActiveConnIDs(limit uint64) error {
 	// connection IDs the peer will store. This limit includes the connection ID
 	// used during the handshake, and the one sent in the preferred_address
 	// transport parameter.
-	// We currently don't send the preferred_address transport parameter,
-	// so we can issue (limit - 1) connection IDs.
-	for i := uint64(len(m.activeSrcConnIDs)); i < min(limit, protocol.MaxIssuedConnectionIDs); i++ {
+	// Both of them are already contained in activeSrcConnIDs.
+	m.numActiveConnIDs = min(limit, protocol.MaxIssuedConnectionIDs)
+	for i := uint64(len(m.activeSrcConnIDs)); i < m.numActiveConnIDs; i++ {
 		if err := m.issueNewConnID(); err != nil {
 			return err
 		}
@@ -87,8 +106,9 @@ func (m *connIDGenerator) Retire(seq uint64, sentWithDestConnID protocol.Connect
 	}
 	m.retireConnectionID(connID)
 	delete(m.activeSrcConnIDs, seq)
-	// Don't issue a replacement for the initial connection ID.
-	if seq == 0 {
+	// Don't issue a replacement for the initial connection ID,
+	// and for connection IDs that were already replaced when we requested their retirement.
+	if seq == 0 || seq < m.retirePriorTo {
 		return nil
 	}
 	return m.issueNewConnID()
@@ -105,12 +125,84 @@ func (m *connIDGenerator) issueNewConnID() error {
 		SequenceNumber:      m.highestSeq + 1,
 		ConnectionID:        connID,
 		StatelessResetToken: m.getStatelessResetToken(connID),
+		RetirePriorTo:       m.retirePriorTo,
 	})
 	m.highestSeq++
 	return nil
 }
 
+func (m *connIDGenerator) SentPacket() {
+	m.packetsSinceRotation++
+}
+
+// MaybeRotate replaces all issued connection IDs with new ones,
+// if the rotation interval has passed, or enough packets were sent since the last rotation.
+// The peer is asked to retire the old connection IDs using the Retire Prior To field.
+func (m *connIDGenerator) MaybeRotate(now time.Time) error {
+	if !m.handshakeComplete || m.numActiveConnIDs == 0 || m.generator.ConnectionIDLen() == 0 {
+		return nil
+	}
+	if m.lastRotation.IsZero() {
+		m.lastRotation = now
+	}
+	if (m.rotationInterval == 0 || now.Sub(m.lastRotation) < m.rotationInterval) &&
+		(m.rotationPackets == 0 || m.packetsSinceRotation < m.rotationPackets) {
+		return nil
+	}
+	m.lastRotation = now
+	m.packetsSinceRotation = 0
+	m.retirePriorTo = m.highestSeq + 1
+	frames := make([]*wire.NewConnectionIDFrame, 0, m.numActiveConnIDs)
+	for i := uint64(0); i < m.numActiveConnIDs; i++ {
+		connID, err := m.generator.GenerateConnectionID()
+		if err != nil {
+			return err
+		}
+		m.highestSeq++
+		m.activeSrcConnIDs[m.highestSeq] = connID
+		m.addConnectionID(connID)
+		frames = append(frames, &wire.NewConnectionIDFrame{
+			SequenceNumber:      m.highestSeq,
+			ConnectionID:        connID,
+			StatelessResetToken: m.getStatelessResetToken(connID),
+			RetirePriorTo:       m.retirePriorTo,
+		})
+	}
+	// The framer sends the most recently queued control frame first.
+	// Queue the frames in reverse order, such that the peer receives the lowest sequence number first.
+	// Otherwise, it would switch to the highest sequence number, and immediately retire all the others.
+	for i := len(frames) - 1; i >= 0; i-- {
+		m.queueControlFrame(frames[i])
+	}
+	return nil
+}
+
+// GeneratePreferredAddressConnID generates the connection ID sent in the preferred_address transport parameter.
+// This connection ID uses sequence number 1, so it needs to be generated before any other connection ID is issued.
+// It is only added to the connection runner when AddPreferredAddressConnID is called.
+func (m *connIDGenerator) GeneratePreferredAddressConnID() (protocol.ConnectionID, protocol.StatelessResetToken, error) {
+	connID, err := m.generator.GenerateConnectionID()
+	if err != nil {
+		return protocol.ConnectionID{}, protocol.StatelessResetToken{}, err
+	}
+	m.highestSeq++
+	m.activeSrcConnIDs[m.highestSeq] = connID
+	m.preferredAddressConnID = &connID
+	return connID, m.getStatelessResetToken(connID), nil
+}
+
+// AddPreferredAddressConnID adds the connection ID sent in the preferred_address transport parameter.
+// The connection needs to be registered with the connection runner by then.
+func (m *connIDGenerator) AddPreferredAddressConnID() {
+	if m.preferredAddressConnID == nil {
+		return
+	}
+	m.addConnectionID(*m.preferredAddressConnID)
+	m.preferredAddressConnID = nil
+}
+
 func (m *connIDGenerator) SetHandshakeComplete() {
+	m.handshakeComplete = true
 	if m.initialClientDestConnID != nil {
 		m.retireConnectionID(*m.initialClientDestConnID)
 		m.initialClientDestConnID = nil
@@ -2,6 +2,7 @@ package quic
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/qerr"
@@ -28,12 +29,7 @@ var _ = Describe("Connection ID Generator", func() {
 		return protocol.StatelessResetToken{b, b, b, b, b, b, b, b, b, b, b, b, b, b, b, b}
 	}
 
-	BeforeEach(func() {
-		addedConnIDs = nil
-		retiredConnIDs = nil
-		removedConnIDs = nil
-		queuedFrames = nil
-		replacedWithClosed = nil
+	newGenerator := func(rotationInterval time.Duration, rotationPackets uint32) {
 		g = newConnIDGenerator(
 			initialConnID,
 			&initialClientDestConnID,
@@ -46,7 +42,18 @@ var _ = Describe("Connection ID Generator", func() {
 			},
 			func(f wire.Frame) { queuedFrames = append(queuedFrames, f) },
 			&protocol.DefaultConnectionIDGenerator{ConnLen: initialConnID.Len()},
+			rotationInterval,
+			rotationPackets,
 		)
+	}
+
+	BeforeEach(func() {
+		addedConnIDs = nil
+		retiredConnIDs = nil
+		removedConnIDs = nil
+		queuedFrames = nil
+		replacedWithClosed = nil
+		newGenerator(0, 0)
 	})
 
 	It("issues new connection IDs", func() {
@@ -67,6 +74,27 @@ var _ = Describe("Connection ID Generator", func() {
 		}
 	})
 
+	It("generates a connection ID for the preferred address", func() {
+		connID, token, err := g.GeneratePreferredAddressConnID()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(connID.Len()).To(Equal(7))
+		Expect(token).To(Equal(connIDToToken(connID)))
+		Expect(addedConnIDs).To(BeEmpty())
+		g.AddPreferredAddressConnID()
+		Expect(addedConnIDs).To(Equal([]protocol.ConnectionID{connID}))
+		g.AddPreferredAddressConnID()
+		Expect(addedConnIDs).To(HaveLen(1))
+		// The limit includes the connection ID sent in the preferred_address.
+		Expect(g.SetMaxActiveConnIDs(4)).To(Succeed())
+		Expect(addedConnIDs).To(HaveLen(3))
+		Expect(queuedFrames).To(HaveLen(2))
+		Expect(queuedFrames[0].(*wire.NewConnectionIDFrame).SequenceNumber).To(BeEquivalentTo(2))
+		Expect(queuedFrames[1].(*wire.NewConnectionIDFrame).SequenceNumber).To(BeEquivalentTo(3))
+		// the connection ID can be retired
+		Expect(g.Retire(1, protocol.ConnectionID{})).To(Succeed())
+		Expect(retiredConnIDs).To(Equal([]protocol.ConnectionID{connID}))
+	})
+
 	It("limits the number of connection IDs that it issues", func() {
 		Expect(g.SetMaxActiveConnIDs(9999999)).To(Succeed())
 		Expect(retiredConnIDs).To(BeEmpty())
@@ -135,6 +163,102 @@ var _ = Describe("Connection ID Generator", func() {
 		Expect(nf.ConnectionID.Len()).To(Equal(7))
 	})
 
+	Context("rotating connection IDs", func() {
+		retirePriorTo := func(frames []wire.Frame) []uint64 {
+			var seqs []uint64
+			for _, f := range frames {
+				seqs = append(seqs, f.(*wire.NewConnectionIDFrame).RetirePriorTo)
+			}
+			return seqs
+		}
+
+		It("doesn't rotate by default", func() {
+			g.SetHandshakeComplete()
+			Expect(g.SetMaxActiveConnIDs(4)).To(Succeed())
+			queuedFrames = nil
+			now := time.Now()
+			Expect(g.MaybeRotate(now)).To(Succeed())
+			for i := 0; i < 100000; i++ {
+				g.SentPacket()
+			}
+			Expect(g.MaybeRotate(now.Add(24 * time.Hour))).To(Succeed())
+			Expect(queuedFrames).To(BeEmpty())
+		})
+
+		It("doesn't rotate before the handshake completes", func() {
+			newGenerator(time.Second, 0)
+			Expect(g.SetMaxActiveConnIDs(4)).To(Succeed())
+			queuedFrames = nil
+			now := time.Now()
+			Expect(g.MaybeRotate(now)).To(Succeed())
+			Expect(g.MaybeRotate(now.Add(time.Hour))).To(Succeed())
+			Expect(queuedFrames).To(BeEmpty())
+		})
+
+		It("replaces all connection IDs periodically", func() {
+			newGenerator(time.Second, 0)
+			g.SetHandshakeComplete()
+			Expect(g.SetMaxActiveConnIDs(4)).To(Succeed())
+			Expect(retirePriorTo(queuedFrames)).To(Equal([]uint64{0, 0, 0}))
+			queuedFrames = nil
+			now := time.Now()
+			Expect(g.MaybeRotate(now)).To(Succeed())
+			Expect(g.MaybeRotate(now.Add(time.Second - time.Nanosecond))).To(Succeed())
+			Expect(queuedFrames).To(BeEmpty())
+			Expect(g.MaybeRotate(now.Add(time.Second))).To(Succeed())
+			Expect(queuedFrames).To(HaveLen(4))
+			// the frames are queued in reverse order, since the framer sends the last queued frame first
+			for i, f := range queuedFrames {
+				Expect(f.(*wire.NewConnectionIDFrame).SequenceNumber).To(BeEquivalentTo(7 - i))
+			}
+			Expect(retirePriorTo(queuedFrames)).To(Equal([]uint64{4, 4, 4, 4}))
+			queuedFrames = nil
+			// the next rotation happens one interval later
+			Expect(g.MaybeRotate(now.Add(2*time.Second - time.Nanosecond))).To(Succeed())
+			Expect(queuedFrames).To(BeEmpty())
+			Expect(g.MaybeRotate(now.Add(2 * time.Second))).To(Succeed())
+			Expect(retirePriorTo(queuedFrames)).To(Equal([]uint64{8, 8, 8, 8}))
+		})
+
+		It("replaces all connection IDs after sending a number of packets", func() {
+			newGenerator(0, 100)
+			g.SetHandshakeComplete()
+			Expect(g.SetMaxActiveConnIDs(4)).To(Succeed())
+			queuedFrames = nil
+			for i := 0; i < 99; i++ {
+				g.SentPacket()
+			}
+			Expect(g.MaybeRotate(time.Now())).To(Succeed())
+			Expect(queuedFrames).To(BeEmpty())
+			g.SentPacket()
+			Expect(g.MaybeRotate(time.Now())).To(Succeed())
+			Expect(retirePriorTo(queuedFrames)).To(Equal([]uint64{4, 4, 4, 4}))
+		})
+
+		It("doesn't issue replacements when the peer retires the old connection IDs", func() {
+			newGenerator(time.Second, 0)
+			g.SetHandshakeComplete()
+			Expect(g.SetMaxActiveConnIDs(4)).To(Succeed())
+			now := time.Now()
+			Expect(g.MaybeRotate(now)).To(Succeed())
+			Expect(g.MaybeRotate(now.Add(time.Second))).To(Succeed())
+			Expect(addedConnIDs).To(HaveLen(7))
+			queuedFrames = nil
+			retiredConnIDs = nil
+			for seq := uint64(1); seq < 4; seq++ {
+				Expect(g.Retire(seq, protocol.ConnectionID{})).To(Succeed())
+			}
+			Expect(retiredConnIDs).To(HaveLen(3))
+			Expect(queuedFrames).To(BeEmpty())
+			// connection IDs issued by the rotation are replaced as usual
+			Expect(g.Retire(4, protocol.ConnectionID{})).To(Succeed())
+			Expect(queuedFrames).To(HaveLen(1))
+			nf := queuedFrames[0].(*wire.NewConnectionIDFrame)
+			Expect(nf.SequenceNumber).To(BeEquivalentTo(8))
+			Expect(nf.RetirePriorTo).To(BeEquivalentTo(4))
+		})
+	})
+
 	It("retires the initial connection ID", func() {
 		Expect(g.Retire(0, protocol.ConnectionID{})).To(Succeed())
 		Expect(removedConnIDs).To(BeEmpty())
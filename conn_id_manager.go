@@ -2,6 +2,8 @@ package quic
 
 import (
 	"fmt"
+	"math"
+	"time"
 
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/qerr"
@@ -25,12 +27,23 @@ type connIDManager struct {
 	activeConnectionID        protocol.ConnectionID
 	activeStatelessResetToken *protocol.StatelessResetToken
 
+	// the connection ID used for probing a new path, see ReservePathConnID
+	pathConnID *newConnID
+
 	// We change the connection ID after sending on average
-	// protocol.PacketsPerConnectionID packets. The actual value is randomized
+	// avgPacketsPerConnectionID packets. The actual value is randomized
 	// hide the packet loss rate from on-path observers.
-	rand                   utils.Rand
-	packetsSinceLastChange uint32
-	packetsPerConnectionID uint32
+	rand                      utils.Rand
+	packetsSinceLastChange    uint32
+	packetsPerConnectionID    uint32
+	avgPacketsPerConnectionID uint32
+
+	// Additionally, the connection ID can be changed periodically, and after the connection was idle.
+	rotationInterval time.Duration
+	idlePeriod       time.Duration
+	lastChange       time.Time // zero until the first call to MaybeRotate after a change of the connection ID
+	lastSent         time.Time
+	rotate           bool // set when the connection ID should be changed before sending the next packet
 
 	addStatelessResetToken    func(protocol.StatelessResetToken)
 	removeStatelessResetToken func(protocol.StatelessResetToken)
@@ -39,12 +52,22 @@ type connIDManager struct {
 
 func newConnIDManager(
 	initialDestConnID protocol.ConnectionID,
+	packetsPerConnectionID uint32, // if 0, protocol.PacketsPerConnectionID is used
+	rotationInterval time.Duration, // if 0, the connection ID is not changed periodically
+	idlePeriod time.Duration, // if 0, the connection ID is not changed after idle periods
 	addStatelessResetToken func(protocol.StatelessResetToken),
 	removeStatelessResetToken func(protocol.StatelessResetToken),
 	queueControlFrame func(wire.Frame),
 ) *connIDManager {
+	if packetsPerConnectionID == 0 {
+		packetsPerConnectionID = protocol.PacketsPerConnectionID
+	}
+	packetsPerConnectionID = min(packetsPerConnectionID, math.MaxInt32)
 	return &connIDManager{
 		activeConnectionID:        initialDestConnID,
+		avgPacketsPerConnectionID: packetsPerConnectionID,
+		rotationInterval:          rotationInterval,
+		idlePeriod:                idlePeriod,
 		addStatelessResetToken:    addStatelessResetToken,
 		removeStatelessResetToken: removeStatelessResetToken,
 		queueControlFrame:         queueControlFrame,
@@ -154,15 +177,67 @@ func (h *connIDManager) updateConnectionID() {
 	h.activeSequenceNumber = front.SequenceNumber
 	h.activeConnectionID = front.ConnectionID
 	h.activeStatelessResetToken = &front.StatelessResetToken
-	h.packetsSinceLastChange = 0
-	h.packetsPerConnectionID = protocol.PacketsPerConnectionID/2 + uint32(h.rand.Int31n(protocol.PacketsPerConnectionID))
+	h.resetRotation()
 	h.addStatelessResetToken(*h.activeStatelessResetToken)
 }
 
+func (h *connIDManager) resetRotation() {
+	h.packetsSinceLastChange = 0
+	h.packetsPerConnectionID = h.avgPacketsPerConnectionID/2 + uint32(h.rand.Int31n(int32(h.avgPacketsPerConnectionID)))
+	h.lastChange = time.Time{}
+	h.rotate = false
+}
+
+// ReservePathConnID takes an unused connection ID out of the queue.
+// It is used for probing a new path, since a connection ID must not be used on more than one path.
+func (h *connIDManager) ReservePathConnID() (protocol.ConnectionID, bool) {
+	if h.pathConnID != nil {
+		panic("connIDManager BUG: path connection ID already reserved")
+	}
+	if h.queue.Len() == 0 {
+		return protocol.ConnectionID{}, false
+	}
+	front := h.queue.Remove(h.queue.Front())
+	h.pathConnID = &front
+	h.addStatelessResetToken(front.StatelessResetToken)
+	return front.ConnectionID, true
+}
+
+// SwitchToPathConnID makes the connection ID reserved by ReservePathConnID the active connection ID,
+// and retires the previously active connection ID.
+func (h *connIDManager) SwitchToPathConnID() {
+	h.queueControlFrame(&wire.RetireConnectionIDFrame{
+		SequenceNumber: h.activeSequenceNumber,
+	})
+	h.highestRetired = max(h.highestRetired, h.activeSequenceNumber)
+	if h.activeStatelessResetToken != nil {
+		h.removeStatelessResetToken(*h.activeStatelessResetToken)
+	}
+	h.activeSequenceNumber = h.pathConnID.SequenceNumber
+	h.activeConnectionID = h.pathConnID.ConnectionID
+	h.activeStatelessResetToken = &h.pathConnID.StatelessResetToken
+	h.pathConnID = nil
+	h.resetRotation()
+}
+
+// AbandonPathConnID retires the connection ID reserved by ReservePathConnID.
+// It is called when path validation failed.
+func (h *connIDManager) AbandonPathConnID() {
+	h.queueControlFrame(&wire.RetireConnectionIDFrame{
+		SequenceNumber: h.pathConnID.SequenceNumber,
+	})
+	h.highestRetired = max(h.highestRetired, h.pathConnID.SequenceNumber)
+	h.removeStatelessResetToken(h.pathConnID.StatelessResetToken)
+	h.pathConnID = nil
+}
+
 func (h *connIDManager) Close() {
 	if h.activeStatelessResetToken != nil {
 		h.removeStatelessResetToken(*h.activeStatelessResetToken)
 	}
+	if h.pathConnID != nil {
+		h.removeStatelessResetToken(h.pathConnID.StatelessResetToken)
+	}
 }
 
 // is called when the server performs a Retry
@@ -183,8 +258,27 @@ func (h *connIDManager) SetStatelessResetToken(token protocol.StatelessResetToke
 	h.addStatelessResetToken(token)
 }
 
-func (h *connIDManager) SentPacket() {
+func (h *connIDManager) SentPacket(now time.Time) {
 	h.packetsSinceLastChange++
+	h.lastSent = now
+}
+
+// MaybeRotate is called before sending packets.
+// It makes sure that a new connection ID is used if the rotation interval has passed since the last change,
+// or if no packet was sent for longer than the idle period.
+func (h *connIDManager) MaybeRotate(now time.Time) {
+	if !h.handshakeComplete {
+		return
+	}
+	if h.lastChange.IsZero() {
+		h.lastChange = now
+	}
+	if h.rotationInterval > 0 && now.Sub(h.lastChange) >= h.rotationInterval {
+		h.rotate = true
+	}
+	if h.idlePeriod > 0 && !h.lastSent.IsZero() && now.Sub(h.lastSent) >= h.idlePeriod {
+		h.rotate = true
+	}
 }
 
 func (h *connIDManager) shouldUpdateConnID() bool {
@@ -195,9 +289,13 @@ func (h *connIDManager) shouldUpdateConnID() bool {
 	if h.queue.Len() > 0 && h.activeSequenceNumber == 0 {
 		return true
 	}
+	// Change if the rotation interval passed, or after an idle period, as long as there's an unused connection ID.
+	if h.rotate && h.queue.Len() > 0 {
+		return true
+	}
 	// For later changes, only change if
 	// 1. The queue of connection IDs is filled more than 50%.
-	// 2. We sent at least PacketsPerConnectionID packets
+	// 2. We sent at least packetsPerConnectionID packets
 	return 2*h.queue.Len() >= protocol.MaxActiveConnectionIDs &&
 		h.packetsSinceLastChange >= h.packetsPerConnectionID
 }
@@ -1,6 +1,8 @@
 package quic
 
 import (
+	"time"
+
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/qerr"
 	"github.com/quic-go/quic-go/internal/wire"
@@ -18,18 +20,25 @@ var _ = Describe("Connection ID Manager", func() {
 	)
 	initialConnID := protocol.ParseConnectionID([]byte{0, 0, 0, 0})
 
-	BeforeEach(func() {
-		frameQueue = nil
-		tokenAdded = nil
-		removedTokens = nil
+	newManager := func(packetsPerConnID uint32, rotationInterval, idlePeriod time.Duration) {
 		m = newConnIDManager(
 			initialConnID,
+			packetsPerConnID,
+			rotationInterval,
+			idlePeriod,
 			func(token protocol.StatelessResetToken) { tokenAdded = &token },
 			func(token protocol.StatelessResetToken) { removedTokens = append(removedTokens, token) },
 			func(f wire.Frame,
 			) {
 				frameQueue = append(frameQueue, f)
 			})
+	}
+
+	BeforeEach(func() {
+		frameQueue = nil
+		tokenAdded = nil
+		removedTokens = nil
+		newManager(0, 0, 0)
 	})
 
 	get := func() (protocol.ConnectionID, protocol.StatelessResetToken) {
@@ -275,7 +284,7 @@ var _ = Describe("Connection ID Manager", func() {
 
 		var counter int
 		for i := 0; i < 50*protocol.PacketsPerConnectionID; i++ {
-			m.SentPacket()
+			m.SentPacket(time.Now())
 
 			connID := m.Get()
 			if connID != lastConnID {
@@ -294,6 +303,103 @@ var _ = Describe("Connection ID Manager", func() {
 		Expect(counter).To(BeNumerically("~", 50, 10))
 	})
 
+	It("uses a custom number of packets per connection ID", func() {
+		newManager(100, 0, 0)
+		var s uint8
+		for s = uint8(1); s < protocol.MaxActiveConnectionIDs; s++ {
+			Expect(m.Add(&wire.NewConnectionIDFrame{
+				SequenceNumber:      uint64(s),
+				ConnectionID:        protocol.ParseConnectionID([]byte{s, s, s, s}),
+				StatelessResetToken: protocol.StatelessResetToken{s, s, s, s, s, s, s, s, s, s, s, s, s, s, s, s},
+			})).To(Succeed())
+		}
+		m.SetHandshakeComplete()
+		lastConnID := m.Get()
+
+		var counter int
+		for i := 0; i < 50*100; i++ {
+			m.SentPacket(time.Now())
+			if connID := m.Get(); connID != lastConnID {
+				counter++
+				lastConnID = connID
+				Expect(m.Add(&wire.NewConnectionIDFrame{
+					SequenceNumber:      uint64(s),
+					ConnectionID:        protocol.ParseConnectionID([]byte{s, s, s, s}),
+					StatelessResetToken: protocol.StatelessResetToken{s, s, s, s, s, s, s, s, s, s, s, s, s, s, s, s},
+				})).To(Succeed())
+				s++
+			}
+		}
+		Expect(counter).To(BeNumerically("~", 50, 10))
+	})
+
+	Context("rotating connection IDs", func() {
+		addConnIDs := func(num uint8) {
+			for s := uint8(1); s <= num; s++ {
+				Expect(m.Add(&wire.NewConnectionIDFrame{
+					SequenceNumber:      uint64(s),
+					ConnectionID:        protocol.ParseConnectionID([]byte{s, s, s, s}),
+					StatelessResetToken: protocol.StatelessResetToken{s},
+				})).To(Succeed())
+			}
+		}
+
+		It("changes the connection ID periodically", func() {
+			newManager(0, time.Minute, 0)
+			addConnIDs(3)
+			now := time.Now()
+			m.MaybeRotate(now)
+			Expect(m.Get()).To(Equal(initialConnID))
+			m.SetHandshakeComplete()
+			Expect(m.Get()).To(Equal(protocol.ParseConnectionID([]byte{1, 1, 1, 1})))
+			m.MaybeRotate(now)
+			m.MaybeRotate(now.Add(time.Minute - time.Nanosecond))
+			Expect(m.Get()).To(Equal(protocol.ParseConnectionID([]byte{1, 1, 1, 1})))
+			m.MaybeRotate(now.Add(time.Minute))
+			Expect(m.Get()).To(Equal(protocol.ParseConnectionID([]byte{2, 2, 2, 2})))
+			Expect(m.Get()).To(Equal(protocol.ParseConnectionID([]byte{2, 2, 2, 2})))
+			// the interval restarts when the connection ID is changed
+			m.MaybeRotate(now.Add(time.Minute + time.Second))
+			m.MaybeRotate(now.Add(2*time.Minute + time.Second - time.Nanosecond))
+			Expect(m.Get()).To(Equal(protocol.ParseConnectionID([]byte{2, 2, 2, 2})))
+			m.MaybeRotate(now.Add(2*time.Minute + time.Second))
+			Expect(m.Get()).To(Equal(protocol.ParseConnectionID([]byte{3, 3, 3, 3})))
+		})
+
+		It("changes the connection ID after an idle period", func() {
+			newManager(0, 0, 10*time.Second)
+			addConnIDs(3)
+			m.SetHandshakeComplete()
+			Expect(m.Get()).To(Equal(protocol.ParseConnectionID([]byte{1, 1, 1, 1})))
+			now := time.Now()
+			m.SentPacket(now)
+			m.MaybeRotate(now.Add(5 * time.Second))
+			m.SentPacket(now.Add(5 * time.Second))
+			m.MaybeRotate(now.Add(10 * time.Second))
+			Expect(m.Get()).To(Equal(protocol.ParseConnectionID([]byte{1, 1, 1, 1})))
+			m.MaybeRotate(now.Add(15 * time.Second))
+			Expect(m.Get()).To(Equal(protocol.ParseConnectionID([]byte{2, 2, 2, 2})))
+		})
+
+		It("keeps the connection ID if there's no unused connection ID", func() {
+			newManager(0, time.Minute, 0)
+			addConnIDs(1)
+			m.SetHandshakeComplete()
+			Expect(m.Get()).To(Equal(protocol.ParseConnectionID([]byte{1, 1, 1, 1})))
+			now := time.Now()
+			m.MaybeRotate(now)
+			m.MaybeRotate(now.Add(time.Minute))
+			Expect(m.Get()).To(Equal(protocol.ParseConnectionID([]byte{1, 1, 1, 1})))
+			// the connection ID is changed as soon as a new connection ID is available
+			Expect(m.Add(&wire.NewConnectionIDFrame{
+				SequenceNumber:      2,
+				ConnectionID:        protocol.ParseConnectionID([]byte{2, 2, 2, 2}),
+				StatelessResetToken: protocol.StatelessResetToken{2},
+			})).To(Succeed())
+			Expect(m.Get()).To(Equal(protocol.ParseConnectionID([]byte{2, 2, 2, 2})))
+		})
+	})
+
 	It("retires delayed connection IDs that arrive after a higher connection ID was already retired", func() {
 		for s := uint8(10); s <= 10+protocol.MaxActiveConnectionIDs/2; s++ {
 			Expect(m.Add(&wire.NewConnectionIDFrame{
@@ -305,7 +411,7 @@ var _ = Describe("Connection ID Manager", func() {
 		m.SetHandshakeComplete()
 		Expect(m.Get()).To(Equal(protocol.ParseConnectionID([]byte{10, 10, 10, 10})))
 		for {
-			m.SentPacket()
+			m.SentPacket(time.Now())
 			if m.Get() == protocol.ParseConnectionID([]byte{11, 11, 11, 11}) {
 				break
 			}
@@ -335,7 +441,7 @@ var _ = Describe("Connection ID Manager", func() {
 		m.SetHandshakeComplete()
 		Expect(m.Get()).To(Equal(protocol.ParseConnectionID([]byte{1, 1, 1, 1})))
 		for i := 0; i < 2*protocol.PacketsPerConnectionID; i++ {
-			m.SentPacket()
+			m.SentPacket(time.Now())
 		}
 		Expect(m.Get()).To(Equal(protocol.ParseConnectionID([]byte{1, 1, 1, 1})))
 		Expect(m.Add(&wire.NewConnectionIDFrame{
@@ -347,6 +453,52 @@ var _ = Describe("Connection ID Manager", func() {
 		Expect(removedTokens[0]).To(Equal(protocol.StatelessResetToken{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1}))
 	})
 
+	Context("path migration", func() {
+		BeforeEach(func() {
+			Expect(m.Add(&wire.NewConnectionIDFrame{
+				SequenceNumber:      1,
+				ConnectionID:        protocol.ParseConnectionID([]byte{1, 2, 3, 4}),
+				StatelessResetToken: protocol.StatelessResetToken{1},
+			})).To(Succeed())
+			Expect(m.Add(&wire.NewConnectionIDFrame{
+				SequenceNumber:      2,
+				ConnectionID:        protocol.ParseConnectionID([]byte{5, 6, 7, 8}),
+				StatelessResetToken: protocol.StatelessResetToken{2},
+			})).To(Succeed())
+		})
+
+		It("reserves an unused connection ID and switches to it", func() {
+			connID, ok := m.ReservePathConnID()
+			Expect(ok).To(BeTrue())
+			Expect(connID).To(Equal(protocol.ParseConnectionID([]byte{1, 2, 3, 4})))
+			Expect(*tokenAdded).To(Equal(protocol.StatelessResetToken{1}))
+			// the reserved connection ID is not used on the current path
+			Expect(m.Get()).To(Equal(initialConnID))
+			m.SwitchToPathConnID()
+			Expect(m.Get()).To(Equal(protocol.ParseConnectionID([]byte{1, 2, 3, 4})))
+			Expect(frameQueue).To(Equal([]wire.Frame{&wire.RetireConnectionIDFrame{SequenceNumber: 0}}))
+		})
+
+		It("retires the reserved connection ID when path validation fails", func() {
+			_, ok := m.ReservePathConnID()
+			Expect(ok).To(BeTrue())
+			m.AbandonPathConnID()
+			Expect(frameQueue).To(Equal([]wire.Frame{&wire.RetireConnectionIDFrame{SequenceNumber: 1}}))
+			Expect(removedTokens).To(Equal([]protocol.StatelessResetToken{{1}}))
+			Expect(m.Get()).To(Equal(initialConnID))
+			connID, ok := m.ReservePathConnID()
+			Expect(ok).To(BeTrue())
+			Expect(connID).To(Equal(protocol.ParseConnectionID([]byte{5, 6, 7, 8})))
+		})
+
+		It("doesn't reserve a connection ID if there are no unused connection IDs", func() {
+			get()
+			get()
+			_, ok := m.ReservePathConnID()
+			Expect(ok).To(BeFalse())
+		})
+	})
+
 	It("removes the currently active stateless reset token when it is closed", func() {
 		m.Close()
 		Expect(removedTokens).To(BeEmpty())
@@ -3,11 +3,13 @@ package quic
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
 	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
 	"net"
+	"net/netip"
 	"reflect"
 	"sync"
 	"sync/atomic"
@@ -55,6 +57,7 @@ type streamManager interface {
 type cryptoStreamHandler interface {
 	StartHandshake() error
 	ChangeConnectionID(protocol.ConnectionID)
+	ChangeVersion(protocol.Version)
 	SetLargest1RTTAcked(protocol.PacketNumber) error
 	SetHandshakeConfirmed()
 	GetSessionTicket() ([]byte, error)
@@ -62,6 +65,7 @@ type cryptoStreamHandler interface {
 	DiscardInitialKeys()
 	io.Closer
 	ConnectionState() handshake.ConnectionState
+	NumKeyUpdates() uint64
 }
 
 type receivedPacket struct {
@@ -130,8 +134,12 @@ type connection struct {
 
 	perspective protocol.Perspective
 	version     protocol.Version
-	config      *Config
+	// The version used for the first Initial packet.
+	// It differs from version if a different version was selected using compatible version negotiation.
+	originalVersion protocol.Version
+	config          *Config
 
+	connMutex sync.Mutex // only needed when the connection migrates, see Migrate
 	conn      sendConn
 	sendQueue sender
 
@@ -148,13 +156,17 @@ type connection struct {
 	framer                framer
 	windowUpdateQueue     *windowUpdateQueue
 	connFlowController    flowcontrol.ConnectionFlowController
-	tokenStoreKey         string                    // only set for the client
-	tokenGenerator        *handshake.TokenGenerator // only set for the server
+	tokenStoreKey         string      // only set for the client
+	tokenPolicy           TokenPolicy // only set for the server
 
 	unpacker      unpacker
 	frameParser   wire.FrameParser
 	packer        packer
 	mtuDiscoverer mtuDiscoverer // initialized when the handshake completes
+	// set if we enabled the ACK frequency extension, and the peer supports it
+	ackFrequencyManager *ackFrequencyManager
+	// set if the peer supports receiving RESET_STREAM_AT frames, read by the streams
+	supportsResetStreamAt atomic.Bool
 
 	initialStream       cryptoStream
 	handshakeStream     cryptoStream
@@ -185,6 +197,9 @@ type connection struct {
 	receivedRetry       bool
 	versionNegotiated   bool
 	receivedFirstPacket bool
+	// Set on the client once a Handshake packet or CRYPTO data from the server was processed.
+	// After that, the server can't select a different version using compatible version negotiation anymore.
+	receivedServerHandshake bool
 
 	// the minimum of the max_idle_timeout values advertised by both endpoints
 	idleTimeout  time.Duration
@@ -206,9 +221,25 @@ type connection struct {
 
 	datagramQueue *datagramQueue
 
+	pathMigrations    chan *pathMigration
+	pathMigration     *pathMigration // the path that's currently being validated
+	pathConns         []rawConn      // conns passed to Migrate that are in use or being validated
+	sentPathChallenge bool
+
+	// only used by the server
+	largestRcvdAppDataPN  protocol.PacketNumber
+	peerAddressValidation *peerAddressValidation // set while a new client address is being validated
+
 	connStateMutex sync.Mutex
 	connState      ConnectionState
 
+	// statistics, only accessed from the run loop
+	packetsReceived         uint64
+	bytesReceived           uint64
+	flowControlBlockedSince time.Time // zero if not blocked by connection-level flow control
+	flowControlBlockedTime  time.Duration
+	statsRequests           chan chan ConnectionStats
+
 	logID  string
 	tracer *logging.ConnectionTracer
 	logger utils.Logger
@@ -234,7 +265,8 @@ var newConnection = func(
 	statelessResetToken protocol.StatelessResetToken,
 	conf *Config,
 	tlsConf *tls.Config,
-	tokenGenerator *handshake.TokenGenerator,
+	tokenPolicy TokenPolicy,
+	ticketKeys *handshake.SessionTicketKeyRing,
 	clientAddressValidated bool,
 	tracer *logging.ConnectionTracer,
 	balancer *streamtypebalancer.Balancer,
@@ -247,13 +279,14 @@ var newConnection = func(
 		config:              conf,
 		handshakeDestConnID: destConnID,
 		srcConnIDLen:        srcConnID.Len(),
-		tokenGenerator:      tokenGenerator,
+		tokenPolicy:         tokenPolicy,
 		oneRTTStream:        newCryptoStream(),
 		perspective:         protocol.PerspectiveServer,
 		tracer:              tracer,
 		Balancer:            balancer,
 		logger:              logger,
 		version:             v,
+		originalVersion:     v,
 	}
 	if origDestConnID.Len() > 0 {
 		s.logID = origDestConnID.String()
@@ -262,6 +295,9 @@ var newConnection = func(
 	}
 	s.connIDManager = newConnIDManager(
 		destConnID,
+		s.config.ConnectionIDRotationPackets,
+		s.config.ConnectionIDRotationInterval,
+		s.config.ConnectionIDRotationIdlePeriod,
 		func(token protocol.StatelessResetToken) { runner.AddResetToken(token, s) },
 		runner.RemoveResetToken,
 		s.queueControlFrame,
@@ -276,6 +312,8 @@ var newConnection = func(
 		runner.ReplaceWithClosed,
 		s.queueControlFrame,
 		connIDGenerator,
+		s.config.ConnectionIDRotationInterval,
+		s.config.ConnectionIDRotationPackets,
 	)
 	s.preSetup()
 	s.ctx, s.ctxCancel = context.WithCancelCause(context.WithValue(context.Background(), ConnectionTracingKey, tracingID))
@@ -286,10 +324,11 @@ var newConnection = func(
 		clientAddressValidated,
 		s.conn.capabilities().ECN,
 		s.perspective,
+		newSendAlgorithmFactory(s.config),
 		s.tracer,
 		s.logger,
 	)
-	s.mtuDiscoverer = newMTUDiscoverer(s.rttStats, getMaxPacketSize(s.conn.RemoteAddr()), s.sentPacketHandler.SetMaxDatagramSize)
+	s.mtuDiscoverer = newMTUDiscoverer(s.rttStats, getMaxPacketSize(s.conn.RemoteAddr()), s.sentPacketHandler.SetMaxDatagramSize, s.tracer)
 	params := &wire.TransportParameters{
 		InitialMaxStreamDataBidiLocal:   protocol.ByteCount(s.config.InitialStreamReceiveWindow),
 		InitialMaxStreamDataBidiRemote:  protocol.ByteCount(s.config.InitialStreamReceiveWindow),
@@ -300,7 +339,7 @@ var newConnection = func(
 		MaxUniStreamNum:                 protocol.StreamNum(s.config.MaxIncomingUniStreams),
 		MaxAckDelay:                     protocol.MaxAckDelayInclGranularity,
 		AckDelayExponent:                protocol.AckDelayExponent,
-		DisableActiveMigration:          true,
+		DisableActiveMigration:          !s.config.AllowActiveMigration,
 		StatelessResetToken:             &statelessResetToken,
 		OriginalDestinationConnectionID: origDestConnID,
 		// For interoperability with quic-go versions before May 2023, this value must be set to a value
@@ -311,12 +350,42 @@ var newConnection = func(
 		ActiveConnectionIDLimit:   protocol.MaxActiveConnectionIDs,
 		InitialSourceConnectionID: srcConnID,
 		RetrySourceConnectionID:   retrySrcConnID,
+		// The chosen version is updated by the crypto setup, if a different version is negotiated.
+		VersionInformation: &wire.VersionInformation{
+			ChosenVersion:     s.version,
+			AvailableVersions: s.config.Versions,
+		},
 	}
 	if s.config.EnableDatagrams {
 		params.MaxDatagramFrameSize = wire.MaxDatagramSize
 	} else {
 		params.MaxDatagramFrameSize = protocol.InvalidByteCount
 	}
+	if s.config.EnableAckFrequency {
+		minAckDelay := protocol.MinAckDelay
+		params.MinAckDelay = &minAckDelay
+	}
+	params.EnableResetStreamAt = s.config.EnableResetStreamAt
+	// The preferred_address transport parameter can't be sent when using zero-length connection IDs.
+	if pa := s.config.PreferredAddress; pa != nil && s.srcConnIDLen > 0 {
+		connID, token, err := s.connIDGenerator.GeneratePreferredAddressConnID()
+		if err != nil {
+			s.logger.Errorf("Failed to generate a connection ID for the preferred address: %s", err)
+		} else {
+			params.PreferredAddress = &wire.PreferredAddress{
+				IPv4:                netip.AddrPortFrom(netip.IPv4Unspecified(), 0),
+				IPv6:                netip.AddrPortFrom(netip.IPv6Unspecified(), 0),
+				ConnectionID:        connID,
+				StatelessResetToken: token,
+			}
+			if pa.IPv4.IsValid() {
+				params.PreferredAddress.IPv4 = pa.IPv4
+			}
+			if pa.IPv6.IsValid() {
+				params.PreferredAddress.IPv6 = pa.IPv6
+			}
+		}
+	}
 	if s.tracer != nil && s.tracer.SentTransportParameters != nil {
 		s.tracer.SentTransportParameters(params)
 	}
@@ -327,6 +396,7 @@ var newConnection = func(
 		params,
 		tlsConf,
 		conf.Allow0RTT,
+		ticketKeys,
 		s.rttStats,
 		tracer,
 		logger,
@@ -368,9 +438,13 @@ var newClientConnection = func(
 		tracer:              tracer,
 		versionNegotiated:   hasNegotiatedVersion,
 		version:             v,
+		originalVersion:     v,
 	}
 	s.connIDManager = newConnIDManager(
 		destConnID,
+		s.config.ConnectionIDRotationPackets,
+		s.config.ConnectionIDRotationInterval,
+		s.config.ConnectionIDRotationIdlePeriod,
 		func(token protocol.StatelessResetToken) { runner.AddResetToken(token, s) },
 		runner.RemoveResetToken,
 		s.queueControlFrame,
@@ -385,6 +459,8 @@ var newClientConnection = func(
 		runner.ReplaceWithClosed,
 		s.queueControlFrame,
 		connIDGenerator,
+		s.config.ConnectionIDRotationInterval,
+		s.config.ConnectionIDRotationPackets,
 	)
 	s.preSetup()
 	s.ctx, s.ctxCancel = context.WithCancelCause(context.WithValue(context.Background(), ConnectionTracingKey, tracingID))
@@ -395,10 +471,11 @@ var newClientConnection = func(
 		false, // has no effect
 		s.conn.capabilities().ECN,
 		s.perspective,
+		newSendAlgorithmFactory(s.config),
 		s.tracer,
 		s.logger,
 	)
-	s.mtuDiscoverer = newMTUDiscoverer(s.rttStats, getMaxPacketSize(s.conn.RemoteAddr()), s.sentPacketHandler.SetMaxDatagramSize)
+	s.mtuDiscoverer = newMTUDiscoverer(s.rttStats, getMaxPacketSize(s.conn.RemoteAddr()), s.sentPacketHandler.SetMaxDatagramSize, s.tracer)
 	oneRTTStream := newCryptoStream()
 	params := &wire.TransportParameters{
 		InitialMaxStreamDataBidiRemote: protocol.ByteCount(s.config.InitialStreamReceiveWindow),
@@ -418,12 +495,21 @@ var newClientConnection = func(
 		// See https://github.com/quic-go/quic-go/pull/3806.
 		ActiveConnectionIDLimit:   protocol.MaxActiveConnectionIDs,
 		InitialSourceConnectionID: srcConnID,
+		VersionInformation: &wire.VersionInformation{
+			ChosenVersion:     s.version,
+			AvailableVersions: s.config.Versions,
+		},
 	}
 	if s.config.EnableDatagrams {
 		params.MaxDatagramFrameSize = wire.MaxDatagramSize
 	} else {
 		params.MaxDatagramFrameSize = protocol.InvalidByteCount
 	}
+	if s.config.EnableAckFrequency {
+		minAckDelay := protocol.MinAckDelay
+		params.MinAckDelay = &minAckDelay
+	}
+	params.EnableResetStreamAt = s.config.EnableResetStreamAt
 	if s.tracer != nil && s.tracer.SentTransportParameters != nil {
 		s.tracer.SentTransportParameters(params)
 	}
@@ -460,6 +546,12 @@ func (s *connection) preSetup() {
 	s.sendQueue = newSendQueue(s.conn)
 	s.retransmissionQueue = newRetransmissionQueue()
 	s.frameParser = *wire.NewFrameParser(s.config.EnableDatagrams)
+	if s.config.EnableAckFrequency {
+		s.frameParser.SetSupportsAckFrequency()
+	}
+	if s.config.EnableResetStreamAt {
+		s.frameParser.SetSupportsResetStreamAt()
+	}
 	s.rttStats = &utils.RTTStats{}
 	s.connFlowController = flowcontrol.NewConnectionFlowController(
 		protocol.ByteCount(s.config.InitialConnectionReceiveWindow),
@@ -486,6 +578,9 @@ func (s *connection) preSetup() {
 	s.receivedPackets = make(chan receivedPacket, protocol.MaxConnUnprocessedPackets)
 	s.closeChan = make(chan closeError, 1)
 	s.sendingScheduled = make(chan struct{}, 1)
+	s.pathMigrations = make(chan *pathMigration)
+	s.statsRequests = make(chan chan ConnectionStats)
+	s.largestRcvdAppDataPN = protocol.InvalidPacketNumber
 	s.handshakeCtx, s.handshakeCtxCancel = context.WithCancel(context.Background())
 
 	now := time.Now()
@@ -493,7 +588,7 @@ func (s *connection) preSetup() {
 	s.creationTime = now
 
 	s.windowUpdateQueue = newWindowUpdateQueue(s.streamsMap, s.connFlowController, s.framer.QueueControlFrame)
-	s.datagramQueue = newDatagramQueue(s.scheduleSending, s.logger)
+	s.datagramQueue = newDatagramQueue(s.scheduleSending, s.config, s.tracer, s.logger)
 	s.connState.Version = s.version
 }
 
@@ -501,11 +596,19 @@ func (s *connection) preSetup() {
 func (s *connection) run() error {
 	var closeErr closeError
 	defer func() {
+		if !s.flowControlBlockedSince.IsZero() {
+			s.flowControlBlockedTime += time.Since(s.flowControlBlockedSince)
+			s.flowControlBlockedSince = time.Time{}
+		}
 		s.ctxCancel(closeErr.err)
 	}()
 
 	s.timer = *newTimer()
 
+	// The connection ID for the preferred address can only be added now,
+	// since the connection is not yet registered with the connection runner when it is generated.
+	s.connIDGenerator.AddPreferredAddressConnID()
+
 	if err := s.cryptoStreamHandler.StartHandshake(); err != nil {
 		return err
 	}
@@ -564,6 +667,11 @@ runLoop:
 				// We do all the interesting stuff after the switch statement, so
 				// nothing to see here.
 			case <-sendQueueAvailable:
+			case m := <-s.pathMigrations:
+				s.startPathMigration(m, time.Now())
+			case c := <-s.statsRequests:
+				c <- s.stats(time.Now())
+				continue
 			case firstPacket := <-s.receivedPackets:
 				wasProcessed := s.handlePacketImpl(firstPacket)
 				// Don't set timers and send packets if the packet made us close the connection.
@@ -629,12 +737,22 @@ runLoop:
 			}
 		}
 
+		if s.pathMigration != nil {
+			s.maybeSendPathProbe(now)
+		}
+		if s.peerAddressValidation != nil && !now.Before(s.peerAddressValidation.deadline) {
+			s.abandonPeerAddressValidation()
+		}
+
 		if s.sendQueue.WouldBlock() {
 			// The send queue is still busy sending out packets.
 			// Wait until there's space to enqueue new packets.
 			sendQueueAvailable = s.sendQueue.Available()
 			continue
 		}
+		if err := s.maybeRotateConnIDs(now); err != nil {
+			s.closeLocal(err)
+		}
 		if err := s.triggerSending(now); err != nil {
 			s.closeLocal(err)
 		}
@@ -648,6 +766,9 @@ runLoop:
 	s.cryptoStreamHandler.Close()
 	s.sendQueue.Close() // close the send queue before sending the CONNECTION_CLOSE
 	s.handleCloseError(&closeErr)
+	for _, c := range s.pathConns {
+		c.Close()
+	}
 	if s.tracer != nil && s.tracer.Close != nil {
 		if e := (&errCloseForRecreating{}); !errors.As(closeErr.err, &e) {
 			s.tracer.Close()
@@ -685,6 +806,52 @@ func (s *connection) ConnectionState() ConnectionState {
 	return s.connState
 }
 
+func (s *connection) Stats() ConnectionStats {
+	c := make(chan ConnectionStats, 1)
+	select {
+	case s.statsRequests <- c:
+	case <-s.ctx.Done():
+		// The run loop has exited, so it's safe to access the connection state.
+		return s.stats(time.Now())
+	}
+	select {
+	case stats := <-c:
+		return stats
+	case <-s.ctx.Done():
+		return s.stats(time.Now())
+	}
+}
+
+// stats must only be called from the run loop, or after the run loop has exited.
+func (s *connection) stats(now time.Time) ConnectionStats {
+	sent := s.sentPacketHandler.Stats()
+	blockedTime := s.flowControlBlockedTime
+	if !s.flowControlBlockedSince.IsZero() {
+		blockedTime += now.Sub(s.flowControlBlockedSince)
+	}
+	return ConnectionStats{
+		MinRTT:                 s.rttStats.MinRTT(),
+		LatestRTT:              s.rttStats.LatestRTT(),
+		SmoothedRTT:            s.rttStats.SmoothedRTT(),
+		MeanDeviation:          s.rttStats.MeanDeviation(),
+		CongestionWindow:       uint64(sent.CongestionWindow),
+		BytesInFlight:          uint64(sent.BytesInFlight),
+		PacketsSent:            sent.PacketsSent,
+		BytesSent:              sent.BytesSent,
+		PacketsReceived:        s.packetsReceived,
+		BytesReceived:          s.bytesReceived,
+		PacketsLost:            sent.PacketsLost,
+		BytesLost:              sent.BytesLost,
+		PacketsRetransmitted:   sent.PacketsRetransmitted,
+		BytesRetransmitted:     sent.BytesRetransmitted,
+		PTOCount:               sent.PTOCount,
+		MTU:                    uint64(s.mtuDiscoverer.CurrentSize()),
+		ECNState:               sent.ECNState,
+		KeyUpdates:             s.cryptoStreamHandler.NumKeyUpdates(),
+		FlowControlBlockedTime: blockedTime,
+	}
+}
+
 // Time when the connection should time out
 func (s *connection) nextIdleTimeoutTime() time.Time {
 	idleTimeout := max(s.idleTimeout, s.rttStats.PTO(true)*3)
@@ -701,6 +868,11 @@ func (s *connection) nextKeepAliveTime() time.Time {
 	return s.lastPacketReceivedTime.Add(keepAliveInterval)
 }
 
+func (s *connection) maybeRotateConnIDs(now time.Time) error {
+	s.connIDManager.MaybeRotate(now)
+	return s.connIDGenerator.MaybeRotate(now)
+}
+
 func (s *connection) maybeResetTimer() {
 	var deadline time.Time
 	if !s.handshakeComplete {
@@ -715,6 +887,12 @@ func (s *connection) maybeResetTimer() {
 			deadline = s.nextIdleTimeoutTime()
 		}
 	}
+	if s.pathMigration != nil {
+		deadline = utils.MinTime(deadline, utils.MinTime(s.pathMigration.nextProbe, s.pathMigration.deadline))
+	}
+	if s.peerAddressValidation != nil {
+		deadline = utils.MinTime(deadline, s.peerAddressValidation.deadline)
+	}
 
 	s.timer.SetTimer(
 		deadline,
@@ -763,11 +941,13 @@ func (s *connection) handleHandshakeComplete() error {
 			s.queueControlFrame(s.oneRTTStream.PopCryptoFrame(protocol.MaxPostHandshakeCryptoFrameSize))
 		}
 	}
-	token, err := s.tokenGenerator.NewToken(s.conn.RemoteAddr())
+	token, err := s.tokenPolicy.NewToken(s.conn.RemoteAddr())
 	if err != nil {
 		return err
 	}
-	s.queueControlFrame(&wire.NewTokenFrame{Token: token})
+	if len(token) > 0 {
+		s.queueControlFrame(&wire.NewTokenFrame{Token: token})
+	}
 	s.queueControlFrame(&wire.HandshakeDoneFrame{})
 	return nil
 }
@@ -786,7 +966,11 @@ func (s *connection) handleHandshakeConfirmed() error {
 		if maxPacketSize == 0 {
 			maxPacketSize = protocol.MaxByteCount
 		}
-		s.mtuDiscoverer.Start(min(maxPacketSize, protocol.MaxPacketBufferSize))
+		s.mtuDiscoverer.Start(min(maxPacketSize, protocol.ByteCount(s.config.MaxPathMTU)))
+		s.sentPacketHandler.SetPacketSizeObserver(s.mtuDiscoverer)
+	}
+	if s.perspective == protocol.PerspectiveClient && s.peerParams.PreferredAddress != nil {
+		s.migrateToPreferredAddress(time.Now())
 	}
 	return nil
 }
@@ -843,7 +1027,7 @@ func (s *connection) handlePacketImpl(rp receivedPacket) bool {
 			}
 			lastConnID = hdr.DestConnectionID
 
-			if hdr.Version != s.version {
+			if hdr.Version != s.version && !s.isCompatibleVersionUpgrade(hdr) && !s.isOriginalVersionInitial(hdr) {
 				if s.tracer != nil && s.tracer.DroppedPacket != nil {
 					s.tracer.DroppedPacket(logging.PacketTypeFromHeader(hdr), protocol.InvalidPacketNumber, protocol.ByteCount(len(data)), logging.PacketDropUnexpectedVersion)
 				}
@@ -865,6 +1049,8 @@ func (s *connection) handlePacketImpl(rp receivedPacket) bool {
 
 			if wasProcessed := s.handleLongHeaderPacket(p, hdr); wasProcessed {
 				processed = true
+				s.packetsReceived++
+				s.bytesReceived += uint64(len(packetData))
 			}
 			data = rest
 		} else {
@@ -872,6 +1058,10 @@ func (s *connection) handlePacketImpl(rp receivedPacket) bool {
 				p.buffer.Split()
 			}
 			processed = s.handleShortHeaderPacket(p, destConnID)
+			if processed {
+				s.packetsReceived++
+				s.bytesReceived += uint64(len(p.data))
+			}
 			break
 		}
 	}
@@ -925,10 +1115,24 @@ func (s *connection) handleShortHeaderPacket(p receivedPacket, destConnID protoc
 			)
 		}
 	}
-	if err := s.handleUnpackedShortHeaderPacket(destConnID, pn, data, p.ecn, p.rcvTime, log); err != nil {
+	isNonProbing, err := s.handleUnpackedShortHeaderPacket(destConnID, pn, data, p.ecn, p.rcvTime, log)
+	if err != nil {
 		s.closeLocal(err)
 		return false
 	}
+	// Only a non-probing packet with the highest packet number seen so far can cause the server to switch paths,
+	// see section 9.3 of RFC 9000.
+	isLargest := pn > s.largestRcvdAppDataPN
+	if isLargest {
+		s.largestRcvdAppDataPN = pn
+	}
+	if s.perspective == protocol.PerspectiveServer && isNonProbing && isLargest {
+		if p.remoteAddr != nil && !addrsEqual(p.remoteAddr, s.conn.RemoteAddr()) {
+			s.handlePeerAddressChange(p)
+		} else if p.info.addr.IsValid() && !addrsEqualIgnoringPort(s.conn.LocalAddr(), &net.UDPAddr{IP: p.info.addr.AsSlice()}) {
+			s.handleLocalAddressChange(p)
+		}
+	}
 	return true
 }
 
@@ -963,11 +1167,31 @@ func (s *connection) handleLongHeaderPacket(p receivedPacket, hdr *wire.Header)
 		return false
 	}
 
-	packet, err := s.unpacker.UnpackLongHeader(hdr, p.rcvTime, p.data, s.version)
+	changeVersion := hdr.Version != s.version && !s.isOriginalVersionInitial(hdr)
+	if changeVersion && !s.isCompatibleVersionUpgrade(hdr) {
+		if s.tracer != nil && s.tracer.DroppedPacket != nil {
+			s.tracer.DroppedPacket(logging.PacketTypeFromHeader(hdr), protocol.InvalidPacketNumber, p.Size(), logging.PacketDropUnexpectedVersion)
+		}
+		s.logger.Debugf("Dropping packet with version %x. Expected %x.", hdr.Version, s.version)
+		return false
+	}
+	if changeVersion {
+		// This is an Initial packet sent by the server after it selected a different version
+		// using compatible version negotiation (see isCompatibleVersionUpgrade).
+		// We only switch to that version if the packet can be decrypted.
+		s.cryptoStreamHandler.ChangeVersion(hdr.Version)
+	}
+	packet, err := s.unpacker.UnpackLongHeader(hdr, p.rcvTime, p.data, hdr.Version)
 	if err != nil {
+		if changeVersion {
+			s.cryptoStreamHandler.ChangeVersion(s.version)
+		}
 		wasQueued = s.handleUnpackError(err, p, logging.PacketTypeFromHeader(hdr))
 		return false
 	}
+	if changeVersion {
+		s.switchVersion(hdr.Version)
+	}
 
 	if s.logger.Debug() {
 		s.logger.Debugf("<- Reading packet %d (%d bytes) for connection %s, %s", packet.hdr.PacketNumber, p.Size(), hdr.DestConnectionID, packet.encryptionLevel)
@@ -989,6 +1213,58 @@ func (s *connection) handleLongHeaderPacket(p receivedPacket, hdr *wire.Header)
 	return true
 }
 
+// isCompatibleVersionUpgrade says if a packet using a different version than the one we're currently using
+// is an Initial packet sent by the server after it selected a different version using
+// compatible version negotiation (RFC 9368).
+// The server might acknowledge some of our Initial packets using the original version before it
+// receives our transport parameters, so the upgrade is accepted until we processed a Handshake packet
+// or CRYPTO data from the server, as long as the Initial keys are still available.
+// Switching versions re-derives the Initial keys, and would otherwise allow an attacker who has seen
+// the connection IDs to change the version (and thereby the key derivation) in the middle of the connection.
+func (s *connection) isCompatibleVersionUpgrade(hdr *wire.Header) bool {
+	return s.perspective == protocol.PerspectiveClient &&
+		s.version == s.originalVersion &&
+		!s.receivedServerHandshake &&
+		!s.droppedInitialKeys &&
+		!s.handshakeComplete &&
+		hdr.Type == protocol.PacketTypeInitial &&
+		protocol.IsSupportedVersion(s.config.Versions, hdr.Version) &&
+		protocol.IsCompatibleVersion(s.version, hdr.Version)
+}
+
+// isOriginalVersionInitial says if a packet is an Initial packet sent by the client using the original version,
+// after we (the server) selected a different version using compatible version negotiation (RFC 9368).
+// The client only learns about the new version from our Initial packets, and until then it
+// acknowledges and retransmits its Initial packets using the original version.
+// These packets are accepted until we processed the first Handshake packet, which drops the Initial keys.
+func (s *connection) isOriginalVersionInitial(hdr *wire.Header) bool {
+	return s.perspective == protocol.PerspectiveServer &&
+		s.version != s.originalVersion &&
+		hdr.Version == s.originalVersion &&
+		hdr.Type == protocol.PacketTypeInitial &&
+		!s.droppedInitialKeys
+}
+
+// switchVersion switches to the version selected using compatible version negotiation (RFC 9368).
+// The crypto setup has already switched to the new version.
+func (s *connection) switchVersion(v protocol.Version) {
+	s.logger.Infof("Switching to QUIC version %s using compatible version negotiation.", v)
+	if s.tracer != nil && s.tracer.NegotiatedVersion != nil {
+		var clientVersions, serverVersions []protocol.Version
+		switch s.perspective {
+		case protocol.PerspectiveClient:
+			clientVersions = s.config.Versions
+		case protocol.PerspectiveServer:
+			serverVersions = s.config.Versions
+		}
+		s.tracer.NegotiatedVersion(v, clientVersions, serverVersions)
+	}
+	s.version = v
+	s.connStateMutex.Lock()
+	s.connState.Version = v
+	s.connStateMutex.Unlock()
+}
+
 func (s *connection) handleUnpackError(err error, p receivedPacket, pt logging.PacketType) (wasQueued bool) {
 	switch err {
 	case handshake.ErrKeysDropped:
@@ -1191,6 +1467,9 @@ func (s *connection) handleUnpackedLongHeaderPacket(
 		}
 	}
 
+	if s.perspective == protocol.PerspectiveClient && packet.encryptionLevel == protocol.EncryptionHandshake {
+		s.receivedServerHandshake = true
+	}
 	if s.perspective == protocol.PerspectiveServer && packet.encryptionLevel == protocol.EncryptionHandshake &&
 		!s.droppedInitialKeys {
 		// On the server side, Initial keys are dropped as soon as the first Handshake packet is received.
@@ -1210,7 +1489,7 @@ func (s *connection) handleUnpackedLongHeaderPacket(
 			s.tracer.ReceivedLongHeaderPacket(packet.hdr, packetSize, ecn, frames)
 		}
 	}
-	isAckEliciting, err := s.handleFrames(packet.data, packet.hdr.DestConnectionID, packet.encryptionLevel, log)
+	isAckEliciting, _, err := s.handleFrames(packet.data, packet.hdr.DestConnectionID, packet.encryptionLevel, log)
 	if err != nil {
 		return err
 	}
@@ -1224,16 +1503,16 @@ func (s *connection) handleUnpackedShortHeaderPacket(
 	ecn protocol.ECN,
 	rcvTime time.Time,
 	log func([]logging.Frame),
-) error {
+) (isNonProbing bool, _ error) {
 	s.lastPacketReceivedTime = rcvTime
 	s.firstAckElicitingPacketAfterIdleSentTime = time.Time{}
 	s.keepAlivePingSent = false
 
-	isAckEliciting, err := s.handleFrames(data, destConnID, protocol.Encryption1RTT, log)
+	isAckEliciting, isNonProbing, err := s.handleFrames(data, destConnID, protocol.Encryption1RTT, log)
 	if err != nil {
-		return err
+		return false, err
 	}
-	return s.receivedPacketHandler.ReceivedPacket(pn, ecn, protocol.Encryption1RTT, rcvTime, isAckEliciting)
+	return isNonProbing, s.receivedPacketHandler.ReceivedPacket(pn, ecn, protocol.Encryption1RTT, rcvTime, isAckEliciting)
 }
 
 func (s *connection) handleFrames(
@@ -1241,7 +1520,7 @@ func (s *connection) handleFrames(
 	destConnID protocol.ConnectionID,
 	encLevel protocol.EncryptionLevel,
 	log func([]logging.Frame),
-) (isAckEliciting bool, _ error) {
+) (isAckEliciting, isNonProbing bool, _ error) {
 	// Only used for tracing.
 	// If we're not tracing, this slice will always remain empty.
 	var frames []logging.Frame
@@ -1253,7 +1532,7 @@ func (s *connection) handleFrames(
 	for len(data) > 0 {
 		l, frame, err := s.frameParser.ParseNext(data, encLevel, s.version)
 		if err != nil {
-			return false, err
+			return false, false, err
 		}
 		data = data[l:]
 		if frame == nil {
@@ -1262,6 +1541,9 @@ func (s *connection) handleFrames(
 		if ackhandler.IsFrameAckEliciting(frame) {
 			isAckEliciting = true
 		}
+		if !wire.IsProbingFrame(frame) {
+			isNonProbing = true
+		}
 		if log != nil {
 			frames = append(frames, logutils.ConvertFrame(frame))
 		}
@@ -1272,7 +1554,7 @@ func (s *connection) handleFrames(
 		}
 		if err := s.handleFrame(frame, encLevel, destConnID); err != nil {
 			if log == nil {
-				return false, err
+				return false, false, err
 			}
 			// If we're logging, we need to keep parsing (but not handling) all frames.
 			handleErr = err
@@ -1282,7 +1564,7 @@ func (s *connection) handleFrames(
 	if log != nil {
 		log(frames)
 		if handleErr != nil {
-			return false, handleErr
+			return false, false, handleErr
 		}
 	}
 
@@ -1292,7 +1574,7 @@ func (s *connection) handleFrames(
 	// and an ACK serialized after that CRYPTO frame. In this case, we still want to process the ACK frame.
 	if !handshakeWasComplete && s.handshakeComplete {
 		if err := s.handleHandshakeComplete(); err != nil {
-			return false, err
+			return false, false, err
 		}
 	}
 
@@ -1313,6 +1595,8 @@ func (s *connection) handleFrame(f wire.Frame, encLevel protocol.EncryptionLevel
 		s.handleConnectionCloseFrame(frame)
 	case *wire.ResetStreamFrame:
 		err = s.handleResetStreamFrame(frame)
+	case *wire.ResetStreamAtFrame:
+		err = s.handleResetStreamAtFrame(frame)
 	case *wire.MaxDataFrame:
 		s.handleMaxDataFrame(frame)
 	case *wire.MaxStreamDataFrame:
@@ -1328,8 +1612,7 @@ func (s *connection) handleFrame(f wire.Frame, encLevel protocol.EncryptionLevel
 	case *wire.PathChallengeFrame:
 		s.handlePathChallengeFrame(frame)
 	case *wire.PathResponseFrame:
-		// since we don't send PATH_CHALLENGEs, we don't expect PATH_RESPONSEs
-		err = errors.New("unexpected PATH_RESPONSE frame")
+		err = s.handlePathResponseFrame(frame)
 	case *wire.NewTokenFrame:
 		err = s.handleNewTokenFrame(frame)
 	case *wire.NewConnectionIDFrame:
@@ -1340,6 +1623,10 @@ func (s *connection) handleFrame(f wire.Frame, encLevel protocol.EncryptionLevel
 		err = s.handleHandshakeDoneFrame()
 	case *wire.DatagramFrame:
 		err = s.handleDatagramFrame(frame)
+	case *wire.AckFrequencyFrame:
+		err = s.handleAckFrequencyFrame(frame)
+	case *wire.ImmediateAckFrame:
+		s.receivedPacketHandler.ReceivedImmediateAck()
 	default:
 		err = fmt.Errorf("unexpected frame type: %s", reflect.ValueOf(&frame).Elem().Type().Name())
 	}
@@ -1377,6 +1664,9 @@ func (s *connection) handleConnectionCloseFrame(frame *wire.ConnectionCloseFrame
 }
 
 func (s *connection) handleCryptoFrame(frame *wire.CryptoFrame, encLevel protocol.EncryptionLevel) error {
+	if s.perspective == protocol.PerspectiveClient {
+		s.receivedServerHandshake = true
+	}
 	if err := s.cryptoStreamManager.HandleCryptoFrame(frame, encLevel); err != nil {
 		return err
 	}
@@ -1396,6 +1686,8 @@ func (s *connection) handleHandshakeEvents() error {
 			s.handshakeComplete = true
 		case handshake.EventReceivedTransportParameters:
 			err = s.handleTransportParameters(ev.TransportParameters)
+		case handshake.EventNegotiatedVersion:
+			s.switchVersion(ev.Version)
 		case handshake.EventRestoredTransportParameters:
 			s.restoreTransportParameters(ev.TransportParameters)
 			close(s.earlyConnReadyChan)
@@ -1431,6 +1723,10 @@ func (s *connection) handleStreamFrame(frame *wire.StreamFrame) error {
 
 func (s *connection) handleMaxDataFrame(frame *wire.MaxDataFrame) {
 	s.connFlowController.UpdateSendWindow(frame.MaximumData)
+	if !s.flowControlBlockedSince.IsZero() && s.connFlowController.SendWindowSize() > 0 {
+		s.flowControlBlockedTime += time.Since(s.flowControlBlockedSince)
+		s.flowControlBlockedSince = time.Time{}
+	}
 }
 
 func (s *connection) handleMaxStreamDataFrame(frame *wire.MaxStreamDataFrame) error {
@@ -1462,6 +1758,18 @@ func (s *connection) handleResetStreamFrame(frame *wire.ResetStreamFrame) error
 	return str.handleResetStreamFrame(frame)
 }
 
+func (s *connection) handleResetStreamAtFrame(frame *wire.ResetStreamAtFrame) error {
+	str, err := s.streamsMap.GetOrOpenReceiveStream(frame.StreamID)
+	if err != nil {
+		return err
+	}
+	if str == nil {
+		// stream is closed and already garbage collected
+		return nil
+	}
+	return str.handleResetStreamAtFrame(frame)
+}
+
 func (s *connection) handleStopSendingFrame(frame *wire.StopSendingFrame) error {
 	str, err := s.streamsMap.GetOrOpenSendStream(frame.StreamID)
 	if err != nil {
@@ -1479,6 +1787,39 @@ func (s *connection) handlePathChallengeFrame(frame *wire.PathChallengeFrame) {
 	s.queueControlFrame(&wire.PathResponseFrame{Data: frame.Data})
 }
 
+func (s *connection) handlePathResponseFrame(frame *wire.PathResponseFrame) error {
+	if v := s.peerAddressValidation; v != nil && frame.Data == v.challenge {
+		s.completePeerAddressValidation()
+		return nil
+	}
+	m := s.pathMigration
+	if m == nil || frame.Data != m.challenge {
+		if !s.sentPathChallenge {
+			return errors.New("unexpected PATH_RESPONSE frame")
+		}
+		// This is a response to a PATH_CHALLENGE sent on a path we already switched to or abandoned.
+		return nil
+	}
+	s.pathMigration = nil
+	s.logger.Debugf("Path validation succeeded. Migrating to %s -> %s.", m.sendConn.LocalAddr(), m.sendConn.RemoteAddr())
+	s.connIDManager.SwitchToPathConnID()
+	s.setConn(m.sendConn)
+	if m.conn != nil {
+		// Close the conn of the path we migrated away from, if it was passed to Migrate.
+		// This also stops the go routine reading from that conn.
+		for _, c := range s.pathConns {
+			if c != m.conn {
+				c.Close()
+			}
+		}
+		s.pathConns = []rawConn{m.conn}
+	}
+	s.sentPacketHandler.MigratedPath()
+	s.mtuDiscoverer.Reset()
+	m.done <- nil
+	return nil
+}
+
 func (s *connection) handleNewTokenFrame(frame *wire.NewTokenFrame) error {
 	if s.perspective == protocol.PerspectiveServer {
 		return &qerr.TransportError{
@@ -1521,6 +1862,10 @@ func (s *connection) handleAckFrame(frame *wire.AckFrame, encLevel protocol.Encr
 	if !acked1RTTPacket {
 		return nil
 	}
+	if s.ackFrequencyManager != nil {
+		cwnd := s.sentPacketHandler.GetCongestionWindow()
+		s.ackFrequencyManager.Update(cwnd, s.mtuDiscoverer.CurrentSize())
+	}
 	// On the client side: If the packet acknowledged a 1-RTT packet, this confirms the handshake.
 	// This is only possible if the ACK was sent in a 1-RTT packet.
 	// This is an optimization over simply waiting for a HANDSHAKE_DONE frame, see section 4.1.2 of RFC 9001.
@@ -1532,6 +1877,17 @@ func (s *connection) handleAckFrame(frame *wire.AckFrame, encLevel protocol.Encr
 	return s.cryptoStreamHandler.SetLargest1RTTAcked(frame.LargestAcked())
 }
 
+func (s *connection) handleAckFrequencyFrame(f *wire.AckFrequencyFrame) error {
+	if f.RequestedMaxAckDelay < protocol.MinAckDelay {
+		return &qerr.TransportError{
+			ErrorCode:    qerr.ProtocolViolation,
+			ErrorMessage: fmt.Sprintf("requested max_ack_delay (%s) smaller than min_ack_delay (%s)", f.RequestedMaxAckDelay, protocol.MinAckDelay),
+		}
+	}
+	s.receivedPacketHandler.ReceivedAckFrequencyFrame(f)
+	return nil
+}
+
 func (s *connection) handleDatagramFrame(f *wire.DatagramFrame) error {
 	if f.Length(s.version) > wire.MaxDatagramSize {
 		return &qerr.TransportError{
@@ -1703,6 +2059,14 @@ func (s *connection) handleTransportParameters(params *wire.TransportParameters)
 			ErrorMessage: err.Error(),
 		}
 	}
+	if s.perspective == protocol.PerspectiveClient {
+		if err := s.checkVersionInformation(params.VersionInformation); err != nil {
+			return &qerr.TransportError{
+				ErrorCode:    qerr.VersionNegotiationErrorCode,
+				ErrorMessage: err.Error(),
+			}
+		}
+	}
 
 	if s.perspective == protocol.PerspectiveClient && s.peerParams != nil && s.ConnectionState().Used0RTT && !params.ValidForUpdate(s.peerParams) {
 		return &qerr.TransportError{
@@ -1757,6 +2121,31 @@ func (s *connection) checkTransportParameters(params *wire.TransportParameters)
 	return nil
 }
 
+// checkVersionInformation validates the server's version_information transport parameter (RFC 9368).
+func (s *connection) checkVersionInformation(vi *wire.VersionInformation) error {
+	if vi == nil {
+		// Without the version_information, it's not possible to detect if the Version Negotiation packet was forged.
+		if s.versionNegotiated {
+			return errors.New("missing version_information after version negotiation")
+		}
+		if s.version != s.originalVersion {
+			return errors.New("missing version_information after switching versions")
+		}
+		return nil
+	}
+	if vi.ChosenVersion != s.version {
+		return fmt.Errorf("server's chosen version (%s) doesn't match the negotiated version (%s)", vi.ChosenVersion, s.version)
+	}
+	if s.versionNegotiated {
+		// Check that we would have selected the same version,
+		// if the Version Negotiation packet had listed the server's available versions.
+		if v, ok := protocol.ChooseSupportedVersion(s.config.Versions, vi.AvailableVersions); !ok || v != s.originalVersion {
+			return fmt.Errorf("version downgrade detected (used %s, server's available versions: %s)", s.originalVersion, vi.AvailableVersions)
+		}
+	}
+	return nil
+}
+
 func (s *connection) applyTransportParameters() {
 	params := s.peerParams
 	// Our local idle timeout will always be > 0.
@@ -1770,11 +2159,13 @@ func (s *connection) applyTransportParameters() {
 	if params.StatelessResetToken != nil {
 		s.connIDManager.SetStatelessResetToken(*params.StatelessResetToken)
 	}
-	// We don't support connection migration yet, so we don't have any use for the preferred_address.
 	if params.PreferredAddress != nil {
-		// Retire the connection ID.
 		s.connIDManager.AddFromPreferredAddress(params.PreferredAddress.ConnectionID, params.PreferredAddress.StatelessResetToken)
 	}
+	s.supportsResetStreamAt.Store(params.EnableResetStreamAt)
+	if s.config.EnableAckFrequency && params.MinAckDelay != nil {
+		s.ackFrequencyManager = newAckFrequencyManager(s.rttStats, *params.MinAckDelay, params.MaxAckDelay, s.queueControlFrame)
+	}
 }
 
 func (s *connection) triggerSending(now time.Time) error {
@@ -1856,6 +2247,9 @@ func (s *connection) sendPackets(now time.Time) error {
 
 	if isBlocked, offset := s.connFlowController.IsNewlyBlocked(); isBlocked {
 		s.framer.QueueControlFrame(&wire.DataBlockedFrame{MaximumData: offset})
+		if s.flowControlBlockedSince.IsZero() {
+			s.flowControlBlockedSince = now
+		}
 	}
 	s.windowUpdateQueue.QueueAll()
 	if cf := s.cryptoStreamManager.GetPostHandshakeData(protocol.MaxPostHandshakeCryptoFrameSize); cf != nil {
@@ -2015,6 +2409,10 @@ func (s *connection) maybeSendAckOnlyPacket(now time.Time) error {
 }
 
 func (s *connection) sendProbePacket(encLevel protocol.EncryptionLevel, now time.Time) error {
+	// Ask the peer to acknowledge the probe packet right away.
+	if encLevel == protocol.Encryption1RTT && s.ackFrequencyManager != nil {
+		s.framer.QueueControlFrame(&wire.ImmediateAckFrame{})
+	}
 	// Queue probe packets until we actually send out a packet,
 	// or until there are no more packets to queue.
 	var packet *coalescedPacket
@@ -2069,7 +2467,8 @@ func (s *connection) registerPackedShortHeaderPacket(p shortHeaderPacket, ecn pr
 		largestAcked = p.Ack.LargestAcked()
 	}
 	s.sentPacketHandler.SentPacket(now, p.PacketNumber, largestAcked, p.StreamFrames, p.Frames, protocol.Encryption1RTT, ecn, p.Length, p.IsPathMTUProbePacket)
-	s.connIDManager.SentPacket()
+	s.connIDManager.SentPacket(now)
+	s.connIDGenerator.SentPacket()
 }
 
 func (s *connection) sendPackedCoalescedPacket(packet *coalescedPacket, ecn protocol.ECN, now time.Time) error {
@@ -2102,7 +2501,8 @@ func (s *connection) sendPackedCoalescedPacket(packet *coalescedPacket, ecn prot
 		}
 		s.sentPacketHandler.SentPacket(now, p.PacketNumber, largestAcked, p.StreamFrames, p.Frames, protocol.Encryption1RTT, ecn, p.Length, p.IsPathMTUProbePacket)
 	}
-	s.connIDManager.SentPacket()
+	s.connIDManager.SentPacket(now)
+	s.connIDGenerator.SentPacket()
 	s.sendQueue.Send(packet.buffer, 0, ecn)
 	return nil
 }
@@ -2353,7 +2753,21 @@ func (s *connection) onStreamCompleted(id protocol.StreamID) {
 	}
 }
 
+func (s *connection) peerSupportsResetStreamAt() bool {
+	return s.supportsResetStreamAt.Load()
+}
+
+func (s *connection) onStreamDataExpired(id protocol.StreamID, offset, length protocol.ByteCount) {
+	if s.tracer != nil && s.tracer.ExpiredStreamData != nil {
+		s.tracer.ExpiredStreamData(id, offset, length)
+	}
+}
+
 func (s *connection) SendDatagram(p []byte) error {
+	return s.SendDatagramWithCallback(p, nil)
+}
+
+func (s *connection) SendDatagramWithCallback(p []byte, cb func(DatagramState)) error {
 	if !s.supportsDatagrams() {
 		return errors.New("datagram support disabled")
 	}
@@ -2366,7 +2780,7 @@ func (s *connection) SendDatagram(p []byte) error {
 	}
 	f.Data = make([]byte, len(p))
 	copy(f.Data, p)
-	return s.datagramQueue.Add(f)
+	return s.datagramQueue.AddWithCallback(f, cb)
 }
 
 func (s *connection) ReceiveDatagram(ctx context.Context) ([]byte, error) {
@@ -2376,14 +2790,294 @@ func (s *connection) ReceiveDatagram(ctx context.Context) ([]byte, error) {
 	return s.datagramQueue.Receive(ctx)
 }
 
+func (s *connection) ReceiveDatagrams(ctx context.Context, bufs [][]byte) (int, error) {
+	if !s.config.EnableDatagrams {
+		return 0, errors.New("datagram support disabled")
+	}
+	return s.datagramQueue.ReceiveBatch(ctx, bufs)
+}
+
 func (s *connection) LocalAddr() net.Addr {
+	s.connMutex.Lock()
+	defer s.connMutex.Unlock()
 	return s.conn.LocalAddr()
 }
 
 func (s *connection) RemoteAddr() net.Addr {
+	s.connMutex.Lock()
+	defer s.connMutex.Unlock()
 	return s.conn.RemoteAddr()
 }
 
+// A pathMigration is a new path that's being validated.
+type pathMigration struct {
+	conn      rawConn // nil when migrating to the preferred address, which uses the current conn
+	sendConn  sendConn
+	connID    protocol.ConnectionID
+	challenge [8]byte
+
+	nextProbe time.Time
+	deadline  time.Time
+
+	toPreferredAddress bool
+	done               chan error // receives the result of path validation
+}
+
+func (s *connection) Migrate(conn net.PacketConn) error {
+	if s.perspective == protocol.PerspectiveServer {
+		conn.Close()
+		return errors.New("only clients can migrate a connection")
+	}
+	c, err := wrapConn(conn)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	m := &pathMigration{conn: c, done: make(chan error, 1)}
+	select {
+	case s.pathMigrations <- m:
+	case <-s.ctx.Done():
+		conn.Close()
+		return context.Cause(s.ctx)
+	}
+	select {
+	case err := <-m.done:
+		return err
+	case <-s.ctx.Done():
+		return context.Cause(s.ctx)
+	}
+}
+
+func (s *connection) startPathMigration(m *pathMigration, now time.Time) {
+	var err error
+	switch {
+	case s.pathMigration != nil:
+		err = errors.New("connection migration already in progress")
+	case !s.handshakeConfirmed:
+		err = errors.New("can't migrate the connection before the handshake is confirmed")
+	// The disable_active_migration transport parameter doesn't apply to the preferred address,
+	// see section 18.2 of RFC 9000.
+	case s.peerParams.DisableActiveMigration && !m.toPreferredAddress:
+		err = errors.New("peer disabled active connection migration")
+	}
+	if err == nil {
+		var ok bool
+		m.connID, ok = s.connIDManager.ReservePathConnID()
+		if !ok {
+			err = errors.New("no unused connection ID available for connection migration")
+		}
+	}
+	if err != nil {
+		if m.conn != nil {
+			m.conn.Close()
+		}
+		m.done <- err
+		return
+	}
+	if m.conn != nil {
+		s.pathConns = append(s.pathConns, m.conn)
+		m.sendConn = newSendConn(m.conn, s.conn.RemoteAddr(), packetInfo{}, s.logger)
+		go s.readFromPath(m.conn)
+	}
+	_, _ = rand.Read(m.challenge[:])
+	m.nextProbe = now
+	m.deadline = now.Add(max(3*s.rttStats.PTO(true), protocol.MinPathValidationTimeout))
+	s.pathMigration = m
+}
+
+// migrateToPreferredAddress starts migrating the connection to the server's preferred address,
+// see section 9.6 of RFC 9000.
+// The new path uses the same local address as the current path.
+func (s *connection) migrateToPreferredAddress(now time.Time) {
+	remoteAddr, ok := s.conn.RemoteAddr().(*net.UDPAddr)
+	if !ok {
+		return
+	}
+	pa := s.peerParams.PreferredAddress
+	addr := pa.IPv6
+	if remoteAddr.IP.To4() != nil {
+		addr = pa.IPv4
+	}
+	if !addr.IsValid() || addr.Addr().IsUnspecified() || addr.Port() == 0 {
+		s.logger.Debugf("Server didn't send a preferred address for the address family of %s", remoteAddr)
+		return
+	}
+	s.logger.Debugf("Migrating to the server's preferred address %s", addr)
+	s.startPathMigration(&pathMigration{
+		sendConn:           s.conn.withRemoteAddr(net.UDPAddrFromAddrPort(addr), packetInfo{}),
+		toPreferredAddress: true,
+		done:               make(chan error, 1),
+	}, now)
+}
+
+// maybeSendPathProbe sends a PATH_CHALLENGE on the path that's being validated.
+// The PATH_CHALLENGE is retransmitted every PTO, until the path validation deadline is reached.
+func (s *connection) maybeSendPathProbe(now time.Time) {
+	m := s.pathMigration
+	if now.Before(m.nextProbe) {
+		return
+	}
+	if !now.Before(m.deadline) {
+		s.abandonPathMigration(errors.New("path validation timed out"))
+		return
+	}
+	p, buf, err := s.packer.PackPathProbePacket(m.connID, ackhandler.Frame{Frame: &wire.PathChallengeFrame{Data: m.challenge}}, s.version)
+	if err != nil {
+		s.abandonPathMigration(err)
+		return
+	}
+	s.logShortHeaderPacket(p.DestConnID, p.Ack, p.Frames, p.StreamFrames, p.PacketNumber, p.PacketNumberLen, p.KeyPhase, protocol.ECNNon, buf.Len(), false)
+	s.sentPacketHandler.SentPathProbePacket(now, p.PacketNumber, p.Frames, p.Length)
+	err = m.sendConn.Write(buf.Data, 0, protocol.ECNNon)
+	buf.Release()
+	if err != nil {
+		s.abandonPathMigration(err)
+		return
+	}
+	s.sentPathChallenge = true
+	m.nextProbe = now.Add(s.rttStats.PTO(true))
+}
+
+func (s *connection) abandonPathMigration(err error) {
+	m := s.pathMigration
+	s.pathMigration = nil
+	s.logger.Debugf("Path validation for %s -> %s failed: %s", m.sendConn.LocalAddr(), m.sendConn.RemoteAddr(), err)
+	s.connIDManager.AbandonPathConnID()
+	if m.conn != nil {
+		for i, c := range s.pathConns {
+			if c == m.conn {
+				s.pathConns = append(s.pathConns[:i], s.pathConns[i+1:]...)
+				break
+			}
+		}
+		m.conn.Close()
+	}
+	m.done <- err
+}
+
+// A peerAddressValidation is the validation of a new client address, after the client's address changed.
+type peerAddressValidation struct {
+	// the conn for the last validated client address
+	validatedConn sendConn
+	challenge     [8]byte
+	deadline      time.Time
+}
+
+// handlePeerAddressChange is called by the server when a non-probing packet
+// is received from a new client address, for example after a NAT rebinding.
+// The server immediately starts sending to the new address, and validates it with a PATH_CHALLENGE.
+// Until the address is validated, the amplification limit applies.
+func (s *connection) handlePeerAddressChange(p receivedPacket) {
+	// The client is not allowed to migrate before the handshake is confirmed.
+	if !s.handshakeConfirmed {
+		return
+	}
+	oldConn := s.conn
+	validatedConn := oldConn
+	if s.peerAddressValidation != nil {
+		validatedConn = s.peerAddressValidation.validatedConn
+	}
+	s.setConn(oldConn.withRemoteAddr(p.remoteAddr, p.info))
+	if s.tracer != nil && s.tracer.UpdatedPeerAddress != nil {
+		s.tracer.UpdatedPeerAddress(oldConn.RemoteAddr(), p.remoteAddr)
+	}
+	// The client returned to the last validated address.
+	if addrsEqual(p.remoteAddr, validatedConn.RemoteAddr()) {
+		s.logger.Debugf("Client returned to %s", p.remoteAddr)
+		s.peerAddressValidation = nil
+		s.sentPacketHandler.PeerAddressValidated()
+		return
+	}
+	s.logger.Debugf("Client address changed from %s to %s. Validating the new address.", oldConn.RemoteAddr(), p.remoteAddr)
+	v := &peerAddressValidation{
+		validatedConn: validatedConn,
+		deadline:      p.rcvTime.Add(max(3*s.rttStats.PTO(true), protocol.MinPathValidationTimeout)),
+	}
+	_, _ = rand.Read(v.challenge[:])
+	s.peerAddressValidation = v
+	s.sentPacketHandler.PeerAddressChanged(p.Size())
+	s.queueControlFrame(&wire.PathChallengeFrame{Data: v.challenge})
+	s.sentPathChallenge = true
+}
+
+func (s *connection) completePeerAddressValidation() {
+	v := s.peerAddressValidation
+	s.peerAddressValidation = nil
+	s.logger.Debugf("Validated new client address %s", s.conn.RemoteAddr())
+	s.sentPacketHandler.PeerAddressValidated()
+	// If only the port changed, this was most likely a NAT rebinding.
+	// There's no need to reset the congestion controller in that case, see section 9.4 of RFC 9000.
+	if !addrsEqualIgnoringPort(v.validatedConn.RemoteAddr(), s.conn.RemoteAddr()) {
+		s.sentPacketHandler.MigratedPath()
+		s.mtuDiscoverer.Reset()
+	}
+}
+
+// abandonPeerAddressValidation is called when the new client address could not be validated.
+// The server then goes back to sending to the last validated address.
+func (s *connection) abandonPeerAddressValidation() {
+	v := s.peerAddressValidation
+	s.peerAddressValidation = nil
+	s.logger.Debugf("Validation of client address %s failed. Returning to %s.", s.conn.RemoteAddr(), v.validatedConn.RemoteAddr())
+	if s.tracer != nil && s.tracer.UpdatedPeerAddress != nil {
+		s.tracer.UpdatedPeerAddress(s.conn.RemoteAddr(), v.validatedConn.RemoteAddr())
+	}
+	s.setConn(v.validatedConn)
+	s.sentPacketHandler.PeerAddressValidated()
+}
+
+// handleLocalAddressChange is called by the server when a non-probing packet
+// is received on a different local address, e.g. after the client migrated to the preferred address.
+// Since the client validated the new path, the server can start using it right away.
+func (s *connection) handleLocalAddressChange(p receivedPacket) {
+	if !s.handshakeConfirmed {
+		return
+	}
+	s.logger.Debugf("Client started using local address %s", p.info.addr)
+	s.setConn(s.conn.withRemoteAddr(s.conn.RemoteAddr(), p.info))
+}
+
+func (s *connection) setConn(c sendConn) {
+	s.sendQueue.SetConn(c)
+	s.connMutex.Lock()
+	s.conn = c
+	s.connMutex.Unlock()
+}
+
+func addrsEqual(a, b net.Addr) bool {
+	if ua, ok := a.(*net.UDPAddr); ok {
+		if ub, ok := b.(*net.UDPAddr); ok {
+			return ua.IP.Equal(ub.IP) && ua.Port == ub.Port && ua.Zone == ub.Zone
+		}
+	}
+	return a.String() == b.String()
+}
+
+func addrsEqualIgnoringPort(a, b net.Addr) bool {
+	if ua, ok := a.(*net.UDPAddr); ok {
+		if ub, ok := b.(*net.UDPAddr); ok {
+			return ua.IP.Equal(ub.IP)
+		}
+	}
+	return a.String() == b.String()
+}
+
+// readFromPath reads packets from a conn passed to Migrate.
+// It returns once the conn is closed.
+func (s *connection) readFromPath(conn rawConn) {
+	for {
+		p, err := conn.ReadPacket()
+		if err != nil {
+			// Windows returns an error when receiving a UDP datagram that doesn't fit into the provided buffer.
+			if isRecvMsgSizeErr(err) {
+				continue
+			}
+			return
+		}
+		s.handlePacket(p)
+	}
+}
+
 func (s *connection) getPerspective() protocol.Perspective {
 	return s.perspective
 }
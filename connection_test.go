@@ -101,7 +101,7 @@ var _ = Describe("Connection", func() {
 		mconn.EXPECT().capabilities().DoAndReturn(func() connCapabilities { return capabilities }).AnyTimes()
 		mconn.EXPECT().RemoteAddr().Return(remoteAddr).AnyTimes()
 		mconn.EXPECT().LocalAddr().Return(localAddr).AnyTimes()
-		tokenGenerator := handshake.NewTokenGenerator([32]byte{0xa, 0xb, 0xc})
+		tokenPolicy := newTokenPolicy(handshake.NewTokenGenerator([32]byte{0xa, 0xb, 0xc}), 0, time.Minute, nil)
 		var tr *logging.ConnectionTracer
 		tr, tracer = mocklogging.NewMockConnectionTracer(mockCtrl)
 		tracer.EXPECT().NegotiatedVersion(gomock.Any(), gomock.Any(), gomock.Any()).MaxTimes(1)
@@ -120,9 +120,11 @@ var _ = Describe("Connection", func() {
 			protocol.StatelessResetToken{},
 			populateConfig(&Config{DisablePathMTUDiscovery: true}),
 			&tls.Config{},
-			tokenGenerator,
+			tokenPolicy,
+			nil,
 			false,
 			tr,
+			nil,
 			1234,
 			utils.DefaultLogger,
 			protocol.Version1,
@@ -185,6 +187,46 @@ var _ = Describe("Connection", func() {
 				err := conn.handleAckFrame(f, protocol.EncryptionHandshake)
 				Expect(err).ToNot(HaveOccurred())
 			})
+
+			It("asks the peer to send fewer ACKs when the congestion window is large", func() {
+				conn.ackFrequencyManager = newAckFrequencyManager(conn.rttStats, time.Millisecond, 25*time.Millisecond, conn.queueControlFrame)
+				f := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 2, Largest: 3}}}
+				sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+				sph.EXPECT().ReceivedAck(f, protocol.Encryption1RTT, gomock.Any()).Return(true, nil)
+				sph.EXPECT().GetCongestionWindow().Return(protocol.ByteCount(1000 * 1500))
+				conn.sentPacketHandler = sph
+				cryptoSetup.EXPECT().SetLargest1RTTAcked(protocol.PacketNumber(3))
+				Expect(conn.handleAckFrame(f, protocol.Encryption1RTT)).To(Succeed())
+				frames, _ := conn.framer.AppendControlFrames(nil, 1000, protocol.Version1)
+				Expect(frames).To(HaveLen(1))
+				Expect(frames[0].Frame).To(BeAssignableToTypeOf(&wire.AckFrequencyFrame{}))
+				Expect(frames[0].Frame.(*wire.AckFrequencyFrame).AckElicitingThreshold).To(BeNumerically(">", 1))
+			})
+		})
+
+		Context("handling ACK frequency frames", func() {
+			It("passes ACK_FREQUENCY frames to the ReceivedPacketHandler", func() {
+				f := &wire.AckFrequencyFrame{SequenceNumber: 1, AckElicitingThreshold: 10, RequestedMaxAckDelay: 5 * time.Millisecond}
+				rph := mockackhandler.NewMockReceivedPacketHandler(mockCtrl)
+				rph.EXPECT().ReceivedAckFrequencyFrame(f)
+				conn.receivedPacketHandler = rph
+				Expect(conn.handleFrame(f, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+			})
+
+			It("rejects ACK_FREQUENCY frames that request a max_ack_delay smaller than the min_ack_delay", func() {
+				f := &wire.AckFrequencyFrame{SequenceNumber: 1, AckElicitingThreshold: 10, RequestedMaxAckDelay: protocol.MinAckDelay / 2}
+				Expect(conn.handleFrame(f, protocol.Encryption1RTT, protocol.ConnectionID{})).To(MatchError(&qerr.TransportError{
+					ErrorCode:    qerr.ProtocolViolation,
+					ErrorMessage: "requested max_ack_delay (500µs) smaller than min_ack_delay (1ms)",
+				}))
+			})
+
+			It("passes IMMEDIATE_ACK frames to the ReceivedPacketHandler", func() {
+				rph := mockackhandler.NewMockReceivedPacketHandler(mockCtrl)
+				rph.EXPECT().ReceivedImmediateAck()
+				conn.receivedPacketHandler = rph
+				Expect(conn.handleFrame(&wire.ImmediateAckFrame{}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+			})
 		})
 
 		Context("handling RESET_STREAM frames", func() {
@@ -223,6 +265,29 @@ var _ = Describe("Connection", func() {
 			})
 		})
 
+		Context("handling RESET_STREAM_AT frames", func() {
+			It("passes the frame to the stream", func() {
+				f := &wire.ResetStreamAtFrame{
+					StreamID:     555,
+					ErrorCode:    42,
+					FinalSize:    0x1337,
+					ReliableSize: 0x42,
+				}
+				str := NewMockReceiveStreamI(mockCtrl)
+				streamManager.EXPECT().GetOrOpenReceiveStream(protocol.StreamID(555)).Return(str, nil)
+				str.EXPECT().handleResetStreamAtFrame(f)
+				Expect(conn.handleFrame(f, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+			})
+
+			It("ignores RESET_STREAM_AT frames for closed streams", func() {
+				streamManager.EXPECT().GetOrOpenReceiveStream(protocol.StreamID(3)).Return(nil, nil)
+				Expect(conn.handleFrame(&wire.ResetStreamAtFrame{
+					StreamID:  3,
+					ErrorCode: 42,
+				}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+			})
+		})
+
 		Context("handling MAX_DATA and MAX_STREAM_DATA frames", func() {
 			var connFC *mocks.MockConnectionFlowController
 
@@ -248,6 +313,19 @@ var _ = Describe("Connection", func() {
 				conn.handleMaxDataFrame(&wire.MaxDataFrame{MaximumData: offset})
 			})
 
+			It("tracks the time sending was blocked by connection-level flow control", func() {
+				conn.flowControlBlockedSince = time.Now().Add(-time.Second)
+				connFC.EXPECT().UpdateSendWindow(protocol.ByteCount(100))
+				connFC.EXPECT().SendWindowSize().Return(protocol.ByteCount(0))
+				conn.handleMaxDataFrame(&wire.MaxDataFrame{MaximumData: 100})
+				Expect(conn.flowControlBlockedTime).To(BeZero())
+				connFC.EXPECT().UpdateSendWindow(protocol.ByteCount(200))
+				connFC.EXPECT().SendWindowSize().Return(protocol.ByteCount(100))
+				conn.handleMaxDataFrame(&wire.MaxDataFrame{MaximumData: 200})
+				Expect(conn.flowControlBlockedSince).To(BeZero())
+				Expect(conn.flowControlBlockedTime).To(BeNumerically("~", time.Second, 100*time.Millisecond))
+			})
+
 			It("ignores MAX_STREAM_DATA frames for a closed stream", func() {
 				streamManager.EXPECT().GetOrOpenSendStream(protocol.StreamID(10)).Return(nil, nil)
 				Expect(conn.handleFrame(&wire.MaxStreamDataFrame{
@@ -470,6 +548,32 @@ var _ = Describe("Connection", func() {
 			Expect(conn.Context().Done()).To(BeClosed())
 		})
 
+		It("returns statistics, also after the connection was closed", func() {
+			// the RTT stats are owned by the run loop, so they need to be set before starting it
+			conn.rttStats.UpdateRTT(50*time.Millisecond, 0, time.Now())
+			mtu := conn.mtuDiscoverer.CurrentSize()
+			runConn()
+			cryptoSetup.EXPECT().NumKeyUpdates().Return(3).Times(2)
+			stats := conn.Stats()
+			Expect(stats.SmoothedRTT).To(Equal(50 * time.Millisecond))
+			Expect(stats.MinRTT).To(Equal(50 * time.Millisecond))
+			Expect(stats.LatestRTT).To(Equal(50 * time.Millisecond))
+			Expect(stats.MTU).To(BeEquivalentTo(mtu))
+			Expect(stats.CongestionWindow).ToNot(BeZero())
+			Expect(stats.KeyUpdates).To(BeEquivalentTo(3))
+
+			streamManager.EXPECT().CloseWithError(gomock.Any())
+			expectReplaceWithClosed()
+			cryptoSetup.EXPECT().Close()
+			packer.EXPECT().PackApplicationClose(gomock.Any(), gomock.Any(), conn.version).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
+			tracer.EXPECT().ClosedConnection(gomock.Any())
+			tracer.EXPECT().Close()
+			conn.CloseWithError(0, "")
+			Eventually(areConnsRunning).Should(BeFalse())
+			Expect(conn.Stats()).To(Equal(stats))
+		})
+
 		It("only closes once", func() {
 			runConn()
 			streamManager.EXPECT().CloseWithError(gomock.Any())
@@ -575,7 +679,7 @@ var _ = Describe("Connection", func() {
 			// don't EXPECT any calls to packer.PackPacket()
 			conn.handlePacket(receivedPacket{
 				rcvTime:    time.Now(),
-				remoteAddr: &net.UDPAddr{},
+				remoteAddr: remoteAddr,
 				buffer:     getPacketBuffer(),
 				data:       b,
 			})
@@ -727,6 +831,47 @@ var _ = Describe("Connection", func() {
 			Expect(conn.handlePacketImpl(p)).To(BeFalse())
 		})
 
+		It("accepts Initial packets using the original version after a compatible version upgrade", func() {
+			hdr := &wire.ExtendedHeader{
+				Header: wire.Header{
+					Type:             protocol.PacketTypeInitial,
+					DestConnectionID: srcConnID,
+					Version:          protocol.Version1,
+					Length:           1,
+				},
+				PacketNumber:    1,
+				PacketNumberLen: protocol.PacketNumberLen1,
+			}
+			unpacker.EXPECT().UnpackLongHeader(gomock.Any(), gomock.Any(), gomock.Any(), protocol.Version1).Return(&unpackedPacket{
+				encryptionLevel: protocol.EncryptionInitial,
+				hdr:             hdr,
+				data:            []byte{0}, // one PADDING frame
+			}, nil)
+			p := getLongHeaderPacket(hdr, nil)
+			conn.version = protocol.Version2
+			tracer.EXPECT().StartedConnection(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			tracer.EXPECT().ReceivedLongHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), []logging.Frame{})
+			Expect(conn.handlePacketImpl(p)).To(BeTrue())
+			Expect(conn.version).To(Equal(protocol.Version2))
+		})
+
+		It("drops Initial packets using the original version after dropping the Initial keys", func() {
+			p := getLongHeaderPacket(&wire.ExtendedHeader{
+				Header: wire.Header{
+					Type:             protocol.PacketTypeInitial,
+					DestConnectionID: srcConnID,
+					Version:          protocol.Version1,
+					Length:           1,
+				},
+				PacketNumber:    1,
+				PacketNumberLen: protocol.PacketNumberLen1,
+			}, nil)
+			conn.version = protocol.Version2
+			conn.droppedInitialKeys = true
+			tracer.EXPECT().DroppedPacket(logging.PacketTypeInitial, protocol.InvalidPacketNumber, p.Size(), logging.PacketDropUnexpectedVersion)
+			Expect(conn.handlePacketImpl(p)).To(BeFalse())
+		})
+
 		It("informs the ReceivedPacketHandler about non-ack-eliciting packets", func() {
 			hdr := &wire.ExtendedHeader{
 				Header: wire.Header{
@@ -1031,12 +1176,46 @@ var _ = Describe("Connection", func() {
 		})
 
 		Context("updating the remote address", func() {
-			It("doesn't support connection migration", func() {
+			It("doesn't update the remote address before the handshake is confirmed", func() {
 				unpacker.EXPECT().UnpackShortHeader(gomock.Any(), gomock.Any()).Return(protocol.PacketNumber(10), protocol.PacketNumberLen2, protocol.KeyPhaseZero, []byte{0} /* one PADDING frame */, nil)
 				packet := getShortHeaderPacket(srcConnID, 0x42, nil)
 				packet.remoteAddr = &net.IPAddr{IP: net.IPv4(192, 168, 0, 100)}
 				tracer.EXPECT().ReceivedShortHeaderPacket(gomock.Any(), protocol.ByteCount(len(packet.data)), gomock.Any(), gomock.Any())
 				Expect(conn.handlePacketImpl(packet)).To(BeTrue())
+				Expect(conn.conn).To(BeIdenticalTo(mconn))
+			})
+
+			It("switches to the new address when receiving a non-probing packet", func() {
+				conn.handshakeConfirmed = true
+				newAddr := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 100), Port: 1337}
+				b, err := (&wire.PingFrame{}).Append(nil, conn.version)
+				Expect(err).ToNot(HaveOccurred())
+				unpacker.EXPECT().UnpackShortHeader(gomock.Any(), gomock.Any()).Return(protocol.PacketNumber(10), protocol.PacketNumberLen2, protocol.KeyPhaseZero, b, nil)
+				packet := getShortHeaderPacket(srcConnID, 0x42, nil)
+				packet.remoteAddr = newAddr
+				tracer.EXPECT().ReceivedShortHeaderPacket(gomock.Any(), protocol.ByteCount(len(packet.data)), gomock.Any(), gomock.Any())
+				newConn := NewMockSendConn(mockCtrl)
+				newConn.EXPECT().RemoteAddr().Return(newAddr).AnyTimes()
+				mconn.EXPECT().withRemoteAddr(newAddr, gomock.Any()).Return(newConn)
+				sendQueue := NewMockSender(mockCtrl)
+				sendQueue.EXPECT().SetConn(newConn)
+				conn.sendQueue = sendQueue
+				tracer.EXPECT().UpdatedPeerAddress(remoteAddr, newAddr)
+				Expect(conn.handlePacketImpl(packet)).To(BeTrue())
+				Expect(conn.conn).To(BeIdenticalTo(newConn))
+				Expect(conn.peerAddressValidation).ToNot(BeNil())
+			})
+
+			It("doesn't switch to the new address when receiving a probing packet", func() {
+				conn.handshakeConfirmed = true
+				b, err := (&wire.PathChallengeFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}}).Append(nil, conn.version)
+				Expect(err).ToNot(HaveOccurred())
+				unpacker.EXPECT().UnpackShortHeader(gomock.Any(), gomock.Any()).Return(protocol.PacketNumber(10), protocol.PacketNumberLen2, protocol.KeyPhaseZero, b, nil)
+				packet := getShortHeaderPacket(srcConnID, 0x42, nil)
+				packet.remoteAddr = &net.UDPAddr{IP: net.IPv4(192, 168, 0, 100), Port: 1337}
+				tracer.EXPECT().ReceivedShortHeaderPacket(gomock.Any(), protocol.ByteCount(len(packet.data)), gomock.Any(), gomock.Any())
+				Expect(conn.handlePacketImpl(packet)).To(BeTrue())
+				Expect(conn.conn).To(BeIdenticalTo(mconn))
 			})
 		})
 
@@ -1302,6 +1481,7 @@ var _ = Describe("Connection", func() {
 
 			Context(fmt.Sprintf("sending %s probe packets", encLevel), func() {
 				var sendMode ackhandler.SendMode
+				var packetType protocol.PacketType
 				var getFrame func(protocol.ByteCount, protocol.Version) wire.Frame
 
 				BeforeEach(func() {
@@ -1309,9 +1489,11 @@ var _ = Describe("Connection", func() {
 					switch encLevel {
 					case protocol.EncryptionInitial:
 						sendMode = ackhandler.SendPTOInitial
+						packetType = protocol.PacketTypeInitial
 						getFrame = conn.retransmissionQueue.GetInitialFrame
 					case protocol.EncryptionHandshake:
 						sendMode = ackhandler.SendPTOHandshake
+						packetType = protocol.PacketTypeHandshake
 						getFrame = conn.retransmissionQueue.GetHandshakeFrame
 					case protocol.Encryption1RTT:
 						sendMode = ackhandler.SendPTOAppData
@@ -1327,6 +1509,9 @@ var _ = Describe("Connection", func() {
 					sph.EXPECT().QueueProbePacket(encLevel)
 					sph.EXPECT().ECNMode(gomock.Any())
 					p := getCoalescedPacket(123, enc != protocol.Encryption1RTT)
+					if enc != protocol.Encryption1RTT {
+						p.longHdrPackets[0].header.Type = packetType
+					}
 					packer.EXPECT().MaybePackProbePacket(encLevel, gomock.Any(), conn.version).Return(p, nil)
 					sph.EXPECT().SentPacket(gomock.Any(), protocol.PacketNumber(123), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 					conn.sentPacketHandler = sph
@@ -1350,6 +1535,9 @@ var _ = Describe("Connection", func() {
 					sph.EXPECT().ECNMode(gomock.Any()).Return(protocol.ECT0)
 					sph.EXPECT().QueueProbePacket(encLevel).Return(false)
 					p := getCoalescedPacket(123, enc != protocol.Encryption1RTT)
+					if enc != protocol.Encryption1RTT {
+						p.longHdrPackets[0].header.Type = packetType
+					}
 					packer.EXPECT().MaybePackProbePacket(encLevel, gomock.Any(), conn.version).Return(p, nil)
 					sph.EXPECT().SentPacket(gomock.Any(), protocol.PacketNumber(123), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 					runConn()
@@ -1368,6 +1556,29 @@ var _ = Describe("Connection", func() {
 				})
 			})
 		}
+
+		It("sends an IMMEDIATE_ACK with 1-RTT probe packets, if the peer supports the ACK frequency extension", func() {
+			conn.ackFrequencyManager = newAckFrequencyManager(conn.rttStats, time.Millisecond, 25*time.Millisecond, conn.queueControlFrame)
+			sph.EXPECT().GetLossDetectionTimeout().AnyTimes()
+			sph.EXPECT().TimeUntilSend().AnyTimes()
+			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendPTOAppData)
+			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendNone)
+			sph.EXPECT().QueueProbePacket(protocol.Encryption1RTT)
+			sph.EXPECT().ECNMode(gomock.Any())
+			p := getCoalescedPacket(123, false)
+			packer.EXPECT().MaybePackProbePacket(protocol.Encryption1RTT, gomock.Any(), conn.version).Return(p, nil)
+			sph.EXPECT().SentPacket(gomock.Any(), protocol.PacketNumber(123), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			runConn()
+			sent := make(chan struct{})
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).Do(func(*packetBuffer, uint16, protocol.ECN) { close(sent) })
+			tracer.EXPECT().SentShortHeaderPacket(gomock.Any(), p.shortHdrPacket.Length, gomock.Any(), gomock.Any(), gomock.Any())
+			conn.scheduleSending()
+			Eventually(sent).Should(BeClosed())
+			// We're using a mock packet packer in this test.
+			// We therefore need to test separately that the IMMEDIATE_ACK was actually queued.
+			frames, _ := conn.framer.AppendControlFrames(nil, 1000, protocol.Version1)
+			Expect(frames).To(Equal([]ackhandler.Frame{{Frame: &wire.ImmediateAckFrame{}}}))
+		})
 	})
 
 	Context("packet pacing", func() {
@@ -2104,6 +2315,91 @@ var _ = Describe("Connection", func() {
 			conn.handleTransportParameters(params)
 			Expect(conn.earlyConnReady()).To(BeClosed())
 		})
+
+		It("remembers if the client supports RESET_STREAM_AT", func() {
+			params := &wire.TransportParameters{
+				ActiveConnectionIDLimit:   3,
+				InitialSourceConnectionID: destConnID,
+				EnableResetStreamAt:       true,
+			}
+			streamManager.EXPECT().UpdateLimits(params)
+			packer.EXPECT().PackCoalescedPacket(false, gomock.Any(), conn.version).MaxTimes(3)
+			tracer.EXPECT().ReceivedTransportParameters(params)
+			Expect(conn.peerSupportsResetStreamAt()).To(BeFalse())
+			conn.handleTransportParameters(params)
+			Expect(conn.peerSupportsResetStreamAt()).To(BeTrue())
+		})
+
+		It("sends the preferred address", func() {
+			tr, tracer := mocklogging.NewMockConnectionTracer(mockCtrl)
+			var params *wire.TransportParameters
+			tracer.EXPECT().SentTransportParameters(gomock.Any()).Do(func(p *wire.TransportParameters) { params = p })
+			tracer.EXPECT().UpdatedKeyFromTLS(gomock.Any(), gomock.Any()).AnyTimes()
+			tracer.EXPECT().UpdatedCongestionState(gomock.Any())
+			connRunner.EXPECT().GetStatelessResetToken(gomock.Any()).Return(protocol.StatelessResetToken{42})
+			c := newConnection(
+				mconn,
+				connRunner,
+				protocol.ConnectionID{},
+				nil,
+				clientDestConnID,
+				destConnID,
+				srcConnID,
+				&protocol.DefaultConnectionIDGenerator{ConnLen: srcConnID.Len()},
+				protocol.StatelessResetToken{},
+				populateConfig(&Config{PreferredAddress: &PreferredAddress{IPv4: netip.MustParseAddrPort("192.0.2.1:443")}}),
+				&tls.Config{},
+				newTokenPolicy(handshake.NewTokenGenerator([32]byte{}), 0, time.Minute, nil),
+				nil,
+				false,
+				tr,
+				nil,
+				1234,
+				utils.DefaultLogger,
+				protocol.Version1,
+			).(*connection)
+			Expect(params.PreferredAddress).ToNot(BeNil())
+			Expect(params.PreferredAddress.IPv4).To(Equal(netip.MustParseAddrPort("192.0.2.1:443")))
+			Expect(params.PreferredAddress.IPv6).To(Equal(netip.AddrPortFrom(netip.IPv6Unspecified(), 0)))
+			Expect(params.PreferredAddress.StatelessResetToken).To(Equal(protocol.StatelessResetToken{42}))
+			connID := params.PreferredAddress.ConnectionID
+			Expect(connID.Len()).To(Equal(srcConnID.Len()))
+			// the connection ID is added when the connection is run
+			connRunner.EXPECT().Add(connID, c)
+			c.connIDGenerator.AddPreferredAddressConnID()
+		})
+
+		for _, allow := range []bool{false, true} {
+			It(fmt.Sprintf("sends the disable_active_migration transport parameter (active migration allowed: %t)", allow), func() {
+				tr, tracer := mocklogging.NewMockConnectionTracer(mockCtrl)
+				var params *wire.TransportParameters
+				tracer.EXPECT().SentTransportParameters(gomock.Any()).Do(func(p *wire.TransportParameters) { params = p })
+				tracer.EXPECT().UpdatedKeyFromTLS(gomock.Any(), gomock.Any()).AnyTimes()
+				tracer.EXPECT().UpdatedCongestionState(gomock.Any())
+				newConnection(
+					mconn,
+					connRunner,
+					protocol.ConnectionID{},
+					nil,
+					clientDestConnID,
+					destConnID,
+					srcConnID,
+					&protocol.DefaultConnectionIDGenerator{},
+					protocol.StatelessResetToken{},
+					populateConfig(&Config{AllowActiveMigration: allow}),
+					&tls.Config{},
+					newTokenPolicy(handshake.NewTokenGenerator([32]byte{}), 0, time.Minute, nil),
+					nil,
+					false,
+					tr,
+					nil,
+					1234,
+					utils.DefaultLogger,
+					protocol.Version1,
+				)
+				Expect(params.DisableActiveMigration).To(Equal(!allow))
+			})
+		}
 	})
 
 	Context("keep-alives", func() {
@@ -2477,6 +2773,164 @@ var _ = Describe("Connection", func() {
 		})
 	})
 
+	Context("peer address changes", func() {
+		var (
+			sph       *mockackhandler.MockSentPacketHandler
+			sendQueue *MockSender
+		)
+
+		BeforeEach(func() {
+			sph = mockackhandler.NewMockSentPacketHandler(mockCtrl)
+			conn.sentPacketHandler = sph
+			sendQueue = NewMockSender(mockCtrl)
+			conn.sendQueue = sendQueue
+			conn.handshakeConfirmed = true
+		})
+
+		changeAddr := func(addr *net.UDPAddr) (*MockSendConn, [8]byte) {
+			newConn := NewMockSendConn(mockCtrl)
+			newConn.EXPECT().RemoteAddr().Return(addr).AnyTimes()
+			mconn.EXPECT().withRemoteAddr(addr, gomock.Any()).Return(newConn)
+			sendQueue.EXPECT().SetConn(newConn)
+			tracer.EXPECT().UpdatedPeerAddress(remoteAddr, addr)
+			sph.EXPECT().PeerAddressChanged(protocol.ByteCount(6))
+			conn.handlePeerAddressChange(receivedPacket{remoteAddr: addr, data: []byte("foobar"), rcvTime: time.Now()})
+			Expect(conn.conn).To(BeIdenticalTo(newConn))
+			frames, _ := conn.framer.AppendControlFrames(nil, 1000, protocol.Version1)
+			Expect(frames).To(HaveLen(1))
+			Expect(frames[0].Frame).To(BeAssignableToTypeOf(&wire.PathChallengeFrame{}))
+			return newConn, frames[0].Frame.(*wire.PathChallengeFrame).Data
+		}
+
+		It("validates the new address after a NAT rebinding", func() {
+			_, challenge := changeAddr(&net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 4242})
+			// only the port changed, so the congestion controller is not reset
+			sph.EXPECT().PeerAddressValidated()
+			Expect(conn.handleFrame(&wire.PathResponseFrame{Data: challenge}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+			Expect(conn.peerAddressValidation).To(BeNil())
+		})
+
+		It("resets the congestion controller and the MTU when the client migrated to a new IP", func() {
+			mtuDiscoverer := NewMockMTUDiscoverer(mockCtrl)
+			conn.mtuDiscoverer = mtuDiscoverer
+			_, challenge := changeAddr(&net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1337})
+			sph.EXPECT().PeerAddressValidated()
+			sph.EXPECT().MigratedPath()
+			mtuDiscoverer.EXPECT().Reset()
+			Expect(conn.handleFrame(&wire.PathResponseFrame{Data: challenge}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+		})
+
+		It("returns to the old address if validation fails", func() {
+			newAddr := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1337}
+			changeAddr(newAddr)
+			tracer.EXPECT().UpdatedPeerAddress(newAddr, remoteAddr)
+			sendQueue.EXPECT().SetConn(mconn)
+			sph.EXPECT().PeerAddressValidated()
+			conn.abandonPeerAddressValidation()
+			Expect(conn.conn).To(BeIdenticalTo(mconn))
+			Expect(conn.peerAddressValidation).To(BeNil())
+		})
+
+		It("doesn't validate the address again when the client returns to the validated address", func() {
+			newConn, _ := changeAddr(&net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1337})
+			oldConn := NewMockSendConn(mockCtrl)
+			newConn.EXPECT().withRemoteAddr(remoteAddr, gomock.Any()).Return(oldConn)
+			oldConn.EXPECT().RemoteAddr().Return(remoteAddr).AnyTimes()
+			sendQueue.EXPECT().SetConn(oldConn)
+			tracer.EXPECT().UpdatedPeerAddress(gomock.Any(), remoteAddr)
+			sph.EXPECT().PeerAddressValidated()
+			conn.handlePeerAddressChange(receivedPacket{remoteAddr: remoteAddr, data: []byte("foobar"), rcvTime: time.Now()})
+			Expect(conn.peerAddressValidation).To(BeNil())
+			frames, _ := conn.framer.AppendControlFrames(nil, 1000, protocol.Version1)
+			Expect(frames).To(BeEmpty())
+		})
+
+		It("switches to the new local address when the client migrated to the preferred address", func() {
+			info := packetInfo{addr: netip.MustParseAddr("192.0.2.1")}
+			newConn := NewMockSendConn(mockCtrl)
+			mconn.EXPECT().withRemoteAddr(remoteAddr, info).Return(newConn)
+			sendQueue.EXPECT().SetConn(newConn)
+			conn.handleLocalAddressChange(receivedPacket{remoteAddr: remoteAddr, info: info})
+			Expect(conn.conn).To(BeIdenticalTo(newConn))
+			Expect(conn.peerAddressValidation).To(BeNil())
+		})
+
+		It("ignores address changes before the handshake is confirmed", func() {
+			conn.handshakeConfirmed = false
+			conn.handlePeerAddressChange(receivedPacket{remoteAddr: &net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1337}})
+			Expect(conn.conn).To(BeIdenticalTo(mconn))
+		})
+	})
+
+	Context("rotating connection IDs", func() {
+		It("issues new connection IDs when the rotation interval has passed", func() {
+			conn.connIDGenerator = newConnIDGenerator(
+				srcConnID,
+				&clientDestConnID,
+				func(connID protocol.ConnectionID) { connRunner.Add(connID, conn) },
+				connRunner.GetStatelessResetToken,
+				connRunner.Remove,
+				connRunner.Retire,
+				connRunner.ReplaceWithClosed,
+				conn.queueControlFrame,
+				&protocol.DefaultConnectionIDGenerator{ConnLen: 8},
+				time.Minute,
+				0,
+			)
+			connRunner.EXPECT().Add(gomock.Any(), conn).Times(3 + 4)
+			connRunner.EXPECT().GetStatelessResetToken(gomock.Any()).Times(3 + 4)
+			Expect(conn.connIDGenerator.SetMaxActiveConnIDs(4)).To(Succeed())
+			frames, _ := conn.framer.AppendControlFrames(nil, protocol.MaxByteCount, protocol.Version1)
+			Expect(frames).To(HaveLen(3))
+			connRunner.EXPECT().Retire(clientDestConnID)
+			conn.connIDGenerator.SetHandshakeComplete()
+
+			now := time.Now()
+			Expect(conn.maybeRotateConnIDs(now)).To(Succeed())
+			frames, _ = conn.framer.AppendControlFrames(nil, protocol.MaxByteCount, protocol.Version1)
+			Expect(frames).To(BeEmpty())
+			Expect(conn.maybeRotateConnIDs(now.Add(time.Minute))).To(Succeed())
+			frames, _ = conn.framer.AppendControlFrames(nil, protocol.MaxByteCount, protocol.Version1)
+			Expect(frames).To(HaveLen(4))
+			for _, f := range frames {
+				Expect(f.Frame).To(BeAssignableToTypeOf(&wire.NewConnectionIDFrame{}))
+				ncid := f.Frame.(*wire.NewConnectionIDFrame)
+				Expect(ncid.SequenceNumber).To(BeNumerically(">=", 4))
+				Expect(ncid.RetirePriorTo).To(BeEquivalentTo(4))
+			}
+		})
+
+		It("switches to a new connection ID of the peer when the rotation interval has passed", func() {
+			conn.connIDManager = newConnIDManager(
+				destConnID,
+				0,
+				time.Minute,
+				0,
+				func(token protocol.StatelessResetToken) { connRunner.AddResetToken(token, conn) },
+				connRunner.RemoveResetToken,
+				conn.queueControlFrame,
+			)
+			connID1 := protocol.ParseConnectionID([]byte{1, 1, 1, 1})
+			connID2 := protocol.ParseConnectionID([]byte{2, 2, 2, 2})
+			Expect(conn.connIDManager.Add(&wire.NewConnectionIDFrame{SequenceNumber: 1, ConnectionID: connID1, StatelessResetToken: protocol.StatelessResetToken{1}})).To(Succeed())
+			Expect(conn.connIDManager.Add(&wire.NewConnectionIDFrame{SequenceNumber: 2, ConnectionID: connID2, StatelessResetToken: protocol.StatelessResetToken{2}})).To(Succeed())
+			conn.connIDManager.SetHandshakeComplete()
+			// the first connection ID is changed as soon as the handshake completes
+			connRunner.EXPECT().AddResetToken(protocol.StatelessResetToken{1}, conn)
+			Expect(conn.connIDManager.Get()).To(Equal(connID1))
+
+			now := time.Now()
+			Expect(conn.maybeRotateConnIDs(now)).To(Succeed())
+			Expect(conn.connIDManager.Get()).To(Equal(connID1))
+			Expect(conn.maybeRotateConnIDs(now.Add(time.Minute))).To(Succeed())
+			connRunner.EXPECT().RemoveResetToken(protocol.StatelessResetToken{1})
+			connRunner.EXPECT().AddResetToken(protocol.StatelessResetToken{2}, conn)
+			Expect(conn.connIDManager.Get()).To(Equal(connID2))
+			frames, _ := conn.framer.AppendControlFrames(nil, protocol.MaxByteCount, protocol.Version1)
+			Expect(frames).To(ContainElement(ackhandler.Frame{Frame: &wire.RetireConnectionIDFrame{SequenceNumber: 1}}))
+		})
+	})
+
 	It("returns the local address", func() {
 		Expect(conn.LocalAddr()).To(Equal(localAddr))
 	})
@@ -2625,11 +3079,148 @@ var _ = Describe("Client Connection", func() {
 			Type:             protocol.PacketTypeHandshake,
 			DestConnectionID: srcConnID,
 			SrcConnectionID:  destConnID,
+			Version:          conn.version,
+		}
+		tracer.EXPECT().ReceivedLongHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+		Expect(conn.handleLongHeaderPacket(receivedPacket{buffer: getPacketBuffer()}, hdr)).To(BeTrue())
+	})
+
+	It("switches to a compatible version selected by the server", func() {
+		unpacker := NewMockUnpacker(mockCtrl)
+		conn.unpacker = unpacker
+		hdr := &wire.Header{
+			Type:             protocol.PacketTypeInitial,
+			DestConnectionID: srcConnID,
+			SrcConnectionID:  destConnID,
+			Version:          protocol.Version2,
 		}
+		gomock.InOrder(
+			cryptoSetup.EXPECT().ChangeVersion(protocol.Version2),
+			unpacker.EXPECT().UnpackLongHeader(hdr, gomock.Any(), gomock.Any(), protocol.Version2).Return(&unpackedPacket{
+				hdr:             &wire.ExtendedHeader{Header: *hdr},
+				data:            []byte{0}, // one PADDING frame
+				encryptionLevel: protocol.EncryptionInitial,
+			}, nil),
+		)
+		tracer.EXPECT().NegotiatedVersion(protocol.Version2, gomock.Any(), gomock.Any())
 		tracer.EXPECT().ReceivedLongHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 		Expect(conn.handleLongHeaderPacket(receivedPacket{buffer: getPacketBuffer()}, hdr)).To(BeTrue())
+		Expect(conn.version).To(Equal(protocol.Version2))
+	})
+
+	It("switches to a compatible version after receiving an Initial packet using the original version", func() {
+		unpacker := NewMockUnpacker(mockCtrl)
+		conn.unpacker = unpacker
+		// The server acknowledges our first Initial packet before it receives our transport parameters.
+		hdr1 := &wire.Header{
+			Type:             protocol.PacketTypeInitial,
+			DestConnectionID: srcConnID,
+			SrcConnectionID:  destConnID,
+			Version:          protocol.Version1,
+		}
+		unpacker.EXPECT().UnpackLongHeader(hdr1, gomock.Any(), gomock.Any(), protocol.Version1).Return(&unpackedPacket{
+			hdr:             &wire.ExtendedHeader{Header: *hdr1},
+			data:            []byte{0}, // one PADDING frame
+			encryptionLevel: protocol.EncryptionInitial,
+		}, nil)
+		tracer.EXPECT().ReceivedLongHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+		Expect(conn.handleLongHeaderPacket(receivedPacket{buffer: getPacketBuffer()}, hdr1)).To(BeTrue())
+
+		hdr2 := &wire.Header{
+			Type:             protocol.PacketTypeInitial,
+			DestConnectionID: srcConnID,
+			SrcConnectionID:  destConnID,
+			Version:          protocol.Version2,
+		}
+		gomock.InOrder(
+			cryptoSetup.EXPECT().ChangeVersion(protocol.Version2),
+			unpacker.EXPECT().UnpackLongHeader(hdr2, gomock.Any(), gomock.Any(), protocol.Version2).Return(&unpackedPacket{
+				hdr:             &wire.ExtendedHeader{Header: *hdr2, PacketNumber: 1},
+				data:            []byte{0}, // one PADDING frame
+				encryptionLevel: protocol.EncryptionInitial,
+			}, nil),
+		)
+		tracer.EXPECT().NegotiatedVersion(protocol.Version2, gomock.Any(), gomock.Any())
+		tracer.EXPECT().ReceivedLongHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+		Expect(conn.handleLongHeaderPacket(receivedPacket{buffer: getPacketBuffer()}, hdr2)).To(BeTrue())
+		Expect(conn.version).To(Equal(protocol.Version2))
+	})
+
+	It("doesn't switch versions for packets other than Initial packets", func() {
+		unpacker := NewMockUnpacker(mockCtrl)
+		conn.unpacker = unpacker
+		hdr := &wire.Header{
+			Type:             protocol.PacketTypeHandshake,
+			DestConnectionID: srcConnID,
+			SrcConnectionID:  destConnID,
+			Version:          protocol.Version2,
+		}
+		p := receivedPacket{data: make([]byte, 100), buffer: getPacketBuffer()}
+		tracer.EXPECT().DroppedPacket(logging.PacketTypeHandshake, protocol.InvalidPacketNumber, p.Size(), logging.PacketDropUnexpectedVersion)
+		Expect(conn.handleLongHeaderPacket(p, hdr)).To(BeFalse())
+		Expect(conn.version).To(Equal(protocol.Version1))
+	})
+
+	It("doesn't switch versions twice", func() {
+		unpacker := NewMockUnpacker(mockCtrl)
+		conn.unpacker = unpacker
+		conn.version = protocol.Version2
+		hdr := &wire.Header{
+			Type:             protocol.PacketTypeInitial,
+			DestConnectionID: srcConnID,
+			SrcConnectionID:  destConnID,
+			Version:          protocol.Version1,
+		}
+		p := receivedPacket{data: make([]byte, 100), buffer: getPacketBuffer()}
+		tracer.EXPECT().DroppedPacket(logging.PacketTypeInitial, protocol.InvalidPacketNumber, p.Size(), logging.PacketDropUnexpectedVersion)
+		Expect(conn.handleLongHeaderPacket(p, hdr)).To(BeFalse())
+		Expect(conn.version).To(Equal(protocol.Version2))
 	})
 
+	for _, state := range []string{"after receiving a Handshake packet", "after receiving CRYPTO data", "after dropping the Initial keys", "after completing the handshake"} {
+		s := state
+
+		It(fmt.Sprintf("doesn't switch versions for a late Initial packet, %s", s), func() {
+			unpacker := NewMockUnpacker(mockCtrl)
+			conn.unpacker = unpacker
+			switch s {
+			case "after receiving a Handshake packet":
+				hdr := &wire.Header{
+					Type:             protocol.PacketTypeHandshake,
+					DestConnectionID: srcConnID,
+					SrcConnectionID:  destConnID,
+					Version:          protocol.Version1,
+				}
+				unpacker.EXPECT().UnpackLongHeader(hdr, gomock.Any(), gomock.Any(), protocol.Version1).Return(&unpackedPacket{
+					hdr:             &wire.ExtendedHeader{Header: *hdr},
+					data:            []byte{0}, // one PADDING frame
+					encryptionLevel: protocol.EncryptionHandshake,
+				}, nil)
+				tracer.EXPECT().ReceivedLongHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+				Expect(conn.handleLongHeaderPacket(receivedPacket{buffer: getPacketBuffer()}, hdr)).To(BeTrue())
+			case "after receiving CRYPTO data":
+				// the data is buffered, since it's not contiguous with the data received so far
+				cryptoSetup.EXPECT().NextEvent().Return(handshake.Event{Kind: handshake.EventNoEvent})
+				Expect(conn.handleCryptoFrame(&wire.CryptoFrame{Offset: 10, Data: []byte("foo")}, protocol.EncryptionInitial)).To(Succeed())
+			case "after dropping the Initial keys":
+				conn.droppedInitialKeys = true
+			case "after completing the handshake":
+				conn.handshakeComplete = true
+			}
+			hdr := &wire.Header{
+				Type:             protocol.PacketTypeInitial,
+				DestConnectionID: srcConnID,
+				SrcConnectionID:  destConnID,
+				Version:          protocol.Version2,
+			}
+			p := receivedPacket{data: make([]byte, 100), buffer: getPacketBuffer()}
+			// neither the crypto setup nor the unpacker is called
+			tracer.EXPECT().DroppedPacket(logging.PacketTypeInitial, protocol.InvalidPacketNumber, p.Size(), logging.PacketDropUnexpectedVersion)
+			Expect(conn.handleLongHeaderPacket(p, hdr)).To(BeFalse())
+			Expect(conn.version).To(Equal(protocol.Version1))
+		})
+	}
+
 	It("handles HANDSHAKE_DONE frames", func() {
 		conn.peerParams = &wire.TransportParameters{}
 		sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
@@ -2676,6 +3267,176 @@ var _ = Describe("Client Connection", func() {
 		Eventually(areConnsRunning).Should(BeFalse())
 	})
 
+	Context("connection migration", func() {
+		var (
+			sph       *mockackhandler.MockSentPacketHandler
+			sendQueue *MockSender
+		)
+		pathConnID := protocol.ParseConnectionID([]byte{1, 3, 3, 7})
+
+		JustBeforeEach(func() {
+			sph = mockackhandler.NewMockSentPacketHandler(mockCtrl)
+			conn.sentPacketHandler = sph
+			sendQueue = NewMockSender(mockCtrl)
+			conn.sendQueue = sendQueue
+			conn.handshakeConfirmed = true
+			conn.peerParams = &wire.TransportParameters{}
+			Expect(conn.handleNewConnectionIDFrame(&wire.NewConnectionIDFrame{
+				SequenceNumber:      1,
+				ConnectionID:        pathConnID,
+				StatelessResetToken: protocol.StatelessResetToken{1},
+			})).To(Succeed())
+		})
+
+		newPathMigration := func() *pathMigration {
+			c, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+			Expect(err).ToNot(HaveOccurred())
+			DeferCleanup(func() { c.Close() })
+			rc, err := wrapConn(c)
+			Expect(err).ToNot(HaveOccurred())
+			return &pathMigration{conn: rc, done: make(chan error, 1)}
+		}
+
+		It("migrates to a new path after validating it", func() {
+			m := newPathMigration()
+			connRunner.EXPECT().AddResetToken(protocol.StatelessResetToken{1}, gomock.Any())
+			conn.startPathMigration(m, time.Now())
+			Expect(conn.pathMigration).To(Equal(m))
+			pathConn := NewMockSendConn(mockCtrl)
+			pathConn.EXPECT().LocalAddr().Return(&net.UDPAddr{}).AnyTimes()
+			pathConn.EXPECT().RemoteAddr().Return(&net.UDPAddr{}).AnyTimes()
+			m.sendConn = pathConn
+
+			var challenge [8]byte
+			packer.EXPECT().PackPathProbePacket(pathConnID, gomock.Any(), conn.version).DoAndReturn(func(_ protocol.ConnectionID, f ackhandler.Frame, _ protocol.Version) (shortHeaderPacket, *packetBuffer, error) {
+				challenge = f.Frame.(*wire.PathChallengeFrame).Data
+				return shortHeaderPacket{PacketNumber: 10, DestConnID: pathConnID, Frames: []ackhandler.Frame{f}, Length: 1200}, getPacketBuffer(), nil
+			})
+			tracer.EXPECT().SentShortHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			sph.EXPECT().SentPathProbePacket(gomock.Any(), protocol.PacketNumber(10), gomock.Any(), protocol.ByteCount(1200))
+			pathConn.EXPECT().Write(gomock.Any(), uint16(0), protocol.ECNNon)
+			conn.maybeSendPathProbe(time.Now())
+			Expect(m.done).ToNot(Receive())
+
+			mtuDiscoverer := NewMockMTUDiscoverer(mockCtrl)
+			conn.mtuDiscoverer = mtuDiscoverer
+			sendQueue.EXPECT().SetConn(pathConn)
+			sph.EXPECT().MigratedPath()
+			mtuDiscoverer.EXPECT().Reset()
+			Expect(conn.handleFrame(&wire.PathResponseFrame{Data: challenge}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+			Expect(m.done).To(Receive(BeNil()))
+			Expect(conn.pathMigration).To(BeNil())
+			Expect(conn.conn).To(Equal(pathConn))
+			Expect(conn.connIDManager.Get()).To(Equal(pathConnID))
+			// PATH_RESPONSEs for previous PATH_CHALLENGEs are ignored
+			Expect(conn.handleFrame(&wire.PathResponseFrame{Data: challenge}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+		})
+
+		It("closes the conn of the previous path after migrating", func() {
+			// the connection previously migrated to a conn passed to Migrate
+			previous := newPathMigration()
+			conn.pathConns = []rawConn{previous.conn}
+
+			m := newPathMigration()
+			connRunner.EXPECT().AddResetToken(protocol.StatelessResetToken{1}, gomock.Any())
+			conn.startPathMigration(m, time.Now())
+			Expect(conn.pathConns).To(Equal([]rawConn{previous.conn, m.conn}))
+			pathConn := NewMockSendConn(mockCtrl)
+			pathConn.EXPECT().LocalAddr().Return(&net.UDPAddr{}).AnyTimes()
+			pathConn.EXPECT().RemoteAddr().Return(&net.UDPAddr{}).AnyTimes()
+			m.sendConn = pathConn
+
+			sendQueue.EXPECT().SetConn(pathConn)
+			sph.EXPECT().MigratedPath()
+			Expect(conn.handleFrame(&wire.PathResponseFrame{Data: m.challenge}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+			Expect(m.done).To(Receive(BeNil()))
+			Expect(conn.pathConns).To(Equal([]rawConn{m.conn}))
+			_, err := previous.conn.ReadPacket()
+			Expect(err).To(MatchError(net.ErrClosed))
+		})
+
+		It("abandons the path if path validation times out", func() {
+			m := newPathMigration()
+			connRunner.EXPECT().AddResetToken(protocol.StatelessResetToken{1}, gomock.Any())
+			conn.startPathMigration(m, time.Now())
+			m.deadline = time.Now().Add(-time.Second)
+			connRunner.EXPECT().RemoveResetToken(protocol.StatelessResetToken{1})
+			conn.maybeSendPathProbe(time.Now())
+			Expect(m.done).To(Receive(MatchError("path validation timed out")))
+			Expect(conn.pathMigration).To(BeNil())
+			Expect(conn.pathConns).To(BeEmpty())
+			_, err := m.conn.ReadPacket()
+			Expect(err).To(MatchError(net.ErrClosed))
+			Expect(conn.connIDManager.Get()).To(Equal(destConnID))
+			frames, _ := conn.framer.AppendControlFrames(nil, 1000, protocol.Version1)
+			Expect(frames).To(Equal([]ackhandler.Frame{{Frame: &wire.RetireConnectionIDFrame{SequenceNumber: 1}}}))
+		})
+
+		It("doesn't migrate if the peer disabled active migration", func() {
+			conn.peerParams.DisableActiveMigration = true
+			m := newPathMigration()
+			conn.startPathMigration(m, time.Now())
+			Expect(m.done).To(Receive(MatchError("peer disabled active connection migration")))
+			Expect(conn.pathMigration).To(BeNil())
+		})
+
+		It("doesn't migrate before the handshake is confirmed", func() {
+			conn.handshakeConfirmed = false
+			m := newPathMigration()
+			conn.startPathMigration(m, time.Now())
+			Expect(m.done).To(Receive(MatchError("can't migrate the connection before the handshake is confirmed")))
+		})
+	})
+
+	Context("migrating to the preferred address", func() {
+		preferredConnID := protocol.ParseConnectionID([]byte{1, 3, 3, 7})
+
+		handshakeDone := func() {
+			sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+			conn.sentPacketHandler = sph
+			tracer.EXPECT().DroppedEncryptionLevel(protocol.EncryptionHandshake)
+			sph.EXPECT().DropPackets(protocol.EncryptionHandshake)
+			sph.EXPECT().SetHandshakeConfirmed()
+			cryptoSetup.EXPECT().SetHandshakeConfirmed()
+			Expect(conn.handleHandshakeDoneFrame()).To(Succeed())
+		}
+
+		setPreferredAddress := func(ipv4, ipv6 netip.AddrPort) {
+			conn.peerParams = &wire.TransportParameters{
+				// doesn't apply to the preferred address
+				DisableActiveMigration: true,
+				PreferredAddress: &wire.PreferredAddress{
+					IPv4:                ipv4,
+					IPv6:                ipv6,
+					ConnectionID:        preferredConnID,
+					StatelessResetToken: protocol.StatelessResetToken{1},
+				},
+			}
+			Expect(conn.connIDManager.AddFromPreferredAddress(preferredConnID, protocol.StatelessResetToken{1})).To(Succeed())
+		}
+
+		It("starts path validation when the handshake is confirmed", func() {
+			preferredAddr := netip.MustParseAddrPort("[2001:db8::1]:443")
+			setPreferredAddress(netip.AddrPortFrom(netip.IPv4Unspecified(), 0), preferredAddr)
+			pathConn := NewMockSendConn(mockCtrl)
+			mconn.EXPECT().withRemoteAddr(net.UDPAddrFromAddrPort(preferredAddr), packetInfo{}).Return(pathConn)
+			connRunner.EXPECT().AddResetToken(protocol.StatelessResetToken{1}, gomock.Any())
+			handshakeDone()
+			m := conn.pathMigration
+			Expect(m).ToNot(BeNil())
+			Expect(m.conn).To(BeNil())
+			Expect(m.sendConn).To(BeIdenticalTo(pathConn))
+			Expect(m.connID).To(Equal(preferredConnID))
+		})
+
+		It("doesn't migrate if there's no preferred address for the address family", func() {
+			setPreferredAddress(netip.MustParseAddrPort("192.0.2.1:443"), netip.AddrPortFrom(netip.IPv6Unspecified(), 0))
+			handshakeDone()
+			Expect(conn.pathMigration).To(BeNil())
+			Expect(conn.connIDManager.Get()).To(Equal(destConnID))
+		})
+	})
+
 	Context("handling tokens", func() {
 		var mockTokenStore *MockTokenStore
 
@@ -2926,14 +3687,16 @@ var _ = Describe("Client Connection", func() {
 			tracer.EXPECT().ReceivedTransportParameters(params).Do(func(*wire.TransportParameters) { close(processed) })
 			paramsChan <- params
 			Eventually(processed).Should(BeClosed())
+			// The connection ID manager and the framer are owned by the run loop.
+			// Wait for the run loop to return before accessing them.
+			expectClose(true, false)
+			conn.CloseWithError(0, "")
+			Eventually(errChan).Should(BeClosed())
 			// make sure the connection ID is not retired
 			cf, _ := conn.framer.AppendControlFrames(nil, protocol.MaxByteCount, protocol.Version1)
 			Expect(cf).To(BeEmpty())
 			connRunner.EXPECT().AddResetToken(protocol.StatelessResetToken{16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1}, conn)
 			Expect(conn.connIDManager.Get()).To(Equal(protocol.ParseConnectionID([]byte{1, 2, 3, 4})))
-			// shut down
-			connRunner.EXPECT().RemoveResetToken(protocol.StatelessResetToken{16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1})
-			expectClose(true, false)
 		})
 
 		It("uses the minimum of the peers' idle timeouts", func() {
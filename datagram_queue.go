@@ -3,57 +3,131 @@ package quic
 import (
 	"context"
 	"sync"
+	"time"
 
+	"github.com/quic-go/quic-go/internal/ackhandler"
+	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/internal/utils/ringbuffer"
 	"github.com/quic-go/quic-go/internal/wire"
+	"github.com/quic-go/quic-go/logging"
 )
 
-const (
-	maxDatagramSendQueueLen = 32
-	maxDatagramRcvQueueLen  = 128
-)
+// datagramAckHandler reports the fate of a DATAGRAM frame to the callback passed to SendDatagramWithCallback.
+type datagramAckHandler func(DatagramState)
+
+var _ ackhandler.FrameHandler = datagramAckHandler(nil)
+
+func (h datagramAckHandler) OnAcked(wire.Frame) { h(DatagramAcked) }
+func (h datagramAckHandler) OnLost(wire.Frame)  { h(DatagramLost) }
+
+type queuedDatagram struct {
+	frame    *wire.DatagramFrame
+	callback func(DatagramState) // nil if the application is not interested in the fate of the datagram
+	queuedAt time.Time
+}
+
+// A receivedDatagram holds the payload of a received DATAGRAM frame,
+// until it is read by the application.
+type receivedDatagram struct {
+	data []byte
+}
+
+var receivedDatagramPool = sync.Pool{
+	New: func() any { return &receivedDatagram{} },
+}
+
+func getReceivedDatagram() *receivedDatagram {
+	return receivedDatagramPool.Get().(*receivedDatagram)
+}
+
+func putReceivedDatagram(d *receivedDatagram) {
+	d.data = d.data[:0]
+	receivedDatagramPool.Put(d)
+}
 
 type datagramQueue struct {
 	sendMx    sync.Mutex
-	sendQueue ringbuffer.RingBuffer[*wire.DatagramFrame]
-	sent      chan struct{} // used to notify Add that a datagram was dequeued
+	sendQueue ringbuffer.RingBuffer[queuedDatagram]
+	// The datagram returned by Peek is removed from the sendQueue, so that it can't be dropped
+	// by the DatagramQueueDropOldest policy while it is being packed.
+	// It still counts towards the queue length.
+	peeked          queuedDatagram // peeked.frame is nil if there's no peeked datagram
+	sent            chan struct{}  // used to notify Add that a datagram was dequeued
+	maxSendQueueLen int
+	sendPolicy      DatagramQueuePolicy
+	maxAge          time.Duration
 
-	rcvMx    sync.Mutex
-	rcvQueue [][]byte
-	rcvd     chan struct{} // used to notify Receive that a new datagram was received
+	rcvQueue  *ringbuffer.SPMC[receivedDatagram] // written to by the run loop, read from by the application
+	rcvd      chan struct{}                      // used to notify Receive that a new datagram was received
+	rcvPolicy DatagramQueuePolicy
 
 	closeErr error
 	closed   chan struct{}
 
 	hasData func()
 
+	tracer *logging.ConnectionTracer
 	logger utils.Logger
 }
 
-func newDatagramQueue(hasData func(), logger utils.Logger) *datagramQueue {
+func newDatagramQueue(hasData func(), config *Config, tracer *logging.ConnectionTracer, logger utils.Logger) *datagramQueue {
 	return &datagramQueue{
-		hasData: hasData,
-		rcvd:    make(chan struct{}, 1),
-		sent:    make(chan struct{}, 1),
-		closed:  make(chan struct{}),
-		logger:  logger,
+		hasData:         hasData,
+		rcvd:            make(chan struct{}, 1),
+		sent:            make(chan struct{}, 1),
+		closed:          make(chan struct{}),
+		maxSendQueueLen: config.DatagramSendQueueLen,
+		sendPolicy:      config.DatagramSendQueuePolicy,
+		maxAge:          config.DatagramMaxAge,
+		rcvQueue:        ringbuffer.NewSPMC[receivedDatagram](config.DatagramReceiveQueueLen),
+		rcvPolicy:       config.DatagramReceiveQueuePolicy,
+		tracer:          tracer,
+		logger:          logger,
 	}
 }
 
 // Add queues a new DATAGRAM frame for sending.
-// Up to 32 DATAGRAM frames will be queued.
-// Once that limit is reached, Add blocks until the queue size has reduced.
+// What happens once the send queue is full depends on the DatagramQueuePolicy:
+// By default, Add blocks until the queue size has reduced.
 func (h *datagramQueue) Add(f *wire.DatagramFrame) error {
+	return h.AddWithCallback(f, nil)
+}
+
+// AddWithCallback is like Add, but the callback is informed about
+// the acknowledgement or loss of the DATAGRAM frame, or if it is dropped before being sent.
+func (h *datagramQueue) AddWithCallback(f *wire.DatagramFrame, cb func(DatagramState)) error {
 	h.sendMx.Lock()
 
 	for {
-		if h.sendQueue.Len() < maxDatagramSendQueueLen {
-			h.sendQueue.PushBack(f)
+		if h.sendQueueLen() < h.maxSendQueueLen {
+			h.sendQueue.PushBack(queuedDatagram{frame: f, callback: cb, queuedAt: time.Now()})
 			h.sendMx.Unlock()
 			h.hasData()
 			return nil
 		}
+		switch h.sendPolicy {
+		case DatagramQueueError:
+			h.sendMx.Unlock()
+			return ErrDatagramQueueFull
+		case DatagramQueueDropNewest:
+			h.sendMx.Unlock()
+			h.dropped(queuedDatagram{frame: f, callback: cb}, logging.DatagramDropSendQueueFull)
+			return nil
+		case DatagramQueueDropOldest:
+			// The only queued datagram is currently being packed.
+			if h.sendQueue.Empty() {
+				h.sendMx.Unlock()
+				h.dropped(queuedDatagram{frame: f, callback: cb}, logging.DatagramDropSendQueueFull)
+				return nil
+			}
+			d := h.sendQueue.PopFront()
+			h.sendQueue.PushBack(queuedDatagram{frame: f, callback: cb, queuedAt: time.Now()})
+			h.sendMx.Unlock()
+			h.dropped(d, logging.DatagramDropSendQueueFull)
+			h.hasData()
+			return nil
+		}
 		select {
 		case <-h.sent: // drain the queue so we don't loop immediately
 		default:
@@ -68,21 +142,95 @@ func (h *datagramQueue) Add(f *wire.DatagramFrame) error {
 	}
 }
 
+func (h *datagramQueue) sendQueueLen() int {
+	if h.peeked.frame != nil {
+		return h.sendQueue.Len() + 1
+	}
+	return h.sendQueue.Len()
+}
+
 // Peek gets the next DATAGRAM frame for sending.
+// DATAGRAM frames that have been queued for longer than the maximum age are dropped.
 // If actually sent out, Pop needs to be called before the next call to Peek.
+// Until Pop or Drop is called, the frame is not dropped when new DATAGRAM frames are added.
 func (h *datagramQueue) Peek() *wire.DatagramFrame {
+	var expired []queuedDatagram
+	h.sendMx.Lock()
+	if h.maxAge > 0 {
+		now := time.Now()
+		if h.peeked.frame != nil && now.Sub(h.peeked.queuedAt) > h.maxAge {
+			expired = append(expired, h.peeked)
+			h.peeked = queuedDatagram{}
+		}
+		for !h.sendQueue.Empty() && now.Sub(h.sendQueue.PeekFront().queuedAt) > h.maxAge {
+			expired = append(expired, h.sendQueue.PopFront())
+		}
+		if len(expired) > 0 {
+			h.signalSent()
+		}
+	}
+	if h.peeked.frame == nil && !h.sendQueue.Empty() {
+		h.peeked = h.sendQueue.PopFront()
+	}
+	f := h.peeked.frame
+	h.sendMx.Unlock()
+
+	for _, d := range expired {
+		h.dropped(d, logging.DatagramDropExpired)
+	}
+	return f
+}
+
+// Pop removes the DATAGRAM frame returned by Peek after it was sent out.
+// It returns the handler that needs to be informed about the acknowledgement or loss of the frame,
+// or nil if no callback was set.
+func (h *datagramQueue) Pop() ackhandler.FrameHandler {
 	h.sendMx.Lock()
 	defer h.sendMx.Unlock()
-	if h.sendQueue.Empty() {
+	d := h.popPeeked()
+	h.signalSent()
+	if d.callback == nil {
 		return nil
 	}
-	return h.sendQueue.PeekFront()
+	return datagramAckHandler(d.callback)
 }
 
-func (h *datagramQueue) Pop() {
+// Drop removes the DATAGRAM frame returned by Peek without sending it,
+// because it doesn't fit into a packet.
+func (h *datagramQueue) Drop() {
 	h.sendMx.Lock()
-	defer h.sendMx.Unlock()
-	_ = h.sendQueue.PopFront()
+	d := h.popPeeked()
+	h.signalSent()
+	h.sendMx.Unlock()
+
+	h.dropped(d, logging.DatagramDropTooLarge)
+}
+
+// popPeeked removes the DATAGRAM frame returned by Peek from the queue.
+func (h *datagramQueue) popPeeked() queuedDatagram {
+	if h.peeked.frame == nil {
+		return h.sendQueue.PopFront()
+	}
+	d := h.peeked
+	h.peeked = queuedDatagram{}
+	return d
+}
+
+// dropped reports that a DATAGRAM frame was dropped before being sent.
+// The callback might call into the datagramQueue, so it must be called without holding the mutex.
+func (h *datagramQueue) dropped(d queuedDatagram, reason logging.DatagramDropReason) {
+	if h.logger.Debug() {
+		h.logger.Debugf("Dropping DATAGRAM frame (%d bytes payload)", len(d.frame.Data))
+	}
+	if h.tracer != nil && h.tracer.DroppedDatagram != nil {
+		h.tracer.DroppedDatagram(protocol.ByteCount(len(d.frame.Data)), reason)
+	}
+	if d.callback != nil {
+		d.callback(DatagramDropped)
+	}
+}
+
+func (h *datagramQueue) signalSent() {
 	select {
 	case h.sent <- struct{}{}:
 	default:
@@ -90,48 +238,126 @@ func (h *datagramQueue) Pop() {
 }
 
 // HandleDatagramFrame handles a received DATAGRAM frame.
+// It must only be called from the run loop.
+// If the receive queue is full, either this or the oldest queued DATAGRAM frame is dropped.
 func (h *datagramQueue) HandleDatagramFrame(f *wire.DatagramFrame) {
-	data := make([]byte, len(f.Data))
-	copy(data, f.Data)
-	var queued bool
-	h.rcvMx.Lock()
-	if len(h.rcvQueue) < maxDatagramRcvQueueLen {
-		h.rcvQueue = append(h.rcvQueue, data)
-		queued = true
-		select {
-		case h.rcvd <- struct{}{}:
-		default:
+	d := getReceivedDatagram()
+	d.data = append(d.data, f.Data...)
+	if !h.rcvQueue.Push(d) {
+		dropped := d
+		if h.rcvPolicy == DatagramQueueDropOldest {
+			// The application might have dequeued datagrams in the meantime,
+			// in which case Pop returns nil, and nothing needs to be dropped.
+			dropped = h.rcvQueue.Pop()
+			h.rcvQueue.Push(d)
+		}
+		if dropped != nil {
+			h.droppedReceived(len(dropped.data))
+			putReceivedDatagram(dropped)
 		}
 	}
-	h.rcvMx.Unlock()
-	if !queued && h.logger.Debug() {
-		h.logger.Debugf("Discarding received DATAGRAM frame (%d bytes payload)", len(f.Data))
+	h.signalReceived()
+}
+
+func (h *datagramQueue) droppedReceived(size int) {
+	if h.logger.Debug() {
+		h.logger.Debugf("Discarding received DATAGRAM frame (%d bytes payload)", size)
+	}
+	if h.tracer != nil && h.tracer.DroppedDatagram != nil {
+		h.tracer.DroppedDatagram(protocol.ByteCount(size), logging.DatagramDropReceiveQueueFull)
+	}
+}
+
+func (h *datagramQueue) signalReceived() {
+	select {
+	case h.rcvd <- struct{}{}:
+	default:
 	}
 }
 
 // Receive gets a received DATAGRAM frame.
 func (h *datagramQueue) Receive(ctx context.Context) ([]byte, error) {
 	for {
-		h.rcvMx.Lock()
-		if len(h.rcvQueue) > 0 {
-			data := h.rcvQueue[0]
-			h.rcvQueue = h.rcvQueue[1:]
-			h.rcvMx.Unlock()
+		if d := h.rcvQueue.Pop(); d != nil {
+			h.received()
+			// the data is handed over to the application, so the buffer can't be reused
+			data := d.data
+			d.data = nil
+			putReceivedDatagram(d)
 			return data, nil
 		}
-		h.rcvMx.Unlock()
-		select {
-		case <-h.rcvd:
-			continue
-		case <-h.closed:
-			return nil, h.closeErr
-		case <-ctx.Done():
-			return nil, ctx.Err()
+		if err := h.waitForDatagram(ctx); err != nil {
+			return nil, err
 		}
 	}
 }
 
+// ReceiveBatch gets multiple received DATAGRAM frames at once.
+// The payloads are copied into bufs, growing the buffers if necessary.
+// It blocks until at least one DATAGRAM frame was received, and returns the number of DATAGRAM frames.
+func (h *datagramQueue) ReceiveBatch(ctx context.Context, bufs [][]byte) (int, error) {
+	if len(bufs) == 0 {
+		return 0, nil
+	}
+	for {
+		var n int
+		for n < len(bufs) {
+			d := h.rcvQueue.Pop()
+			if d == nil {
+				break
+			}
+			bufs[n] = append(bufs[n][:0], d.data...)
+			putReceivedDatagram(d)
+			n++
+		}
+		if n > 0 {
+			h.received()
+			return n, nil
+		}
+		if err := h.waitForDatagram(ctx); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// received needs to be called after dequeueing DATAGRAM frames.
+// Since the notification channel only holds a single notification, concurrent calls to Receive
+// would miss DATAGRAM frames that were enqueued in quick succession.
+func (h *datagramQueue) received() {
+	if h.rcvQueue.Len() > 0 {
+		h.signalReceived()
+	}
+}
+
+func (h *datagramQueue) waitForDatagram(ctx context.Context) error {
+	select {
+	case <-h.rcvd:
+		return nil
+	case <-h.closed:
+		return h.closeErr
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 func (h *datagramQueue) CloseWithError(e error) {
 	h.closeErr = e
 	close(h.closed)
+
+	// datagrams that haven't been sent yet will never be sent
+	var callbacks []func(DatagramState)
+	h.sendMx.Lock()
+	if h.peeked.frame != nil && h.peeked.callback != nil {
+		callbacks = append(callbacks, h.peeked.callback)
+	}
+	h.peeked = queuedDatagram{}
+	for !h.sendQueue.Empty() {
+		if d := h.sendQueue.PopFront(); d.callback != nil {
+			callbacks = append(callbacks, d.callback)
+		}
+	}
+	h.sendMx.Unlock()
+	for _, cb := range callbacks {
+		cb(DatagramDropped)
+	}
 }
@@ -3,22 +3,46 @@ package quic
 import (
 	"context"
 	"errors"
+	"testing"
 	"time"
 
+	"github.com/quic-go/quic-go/integrationtests/tools/israce"
+	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/internal/wire"
+	"github.com/quic-go/quic-go/logging"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
 
 var _ = Describe("Datagram Queue", func() {
-	var queue *datagramQueue
-	var queued chan struct{}
+	type droppedDatagram struct {
+		size   protocol.ByteCount
+		reason logging.DatagramDropReason
+	}
+
+	var (
+		queue   *datagramQueue
+		queued  chan struct{}
+		config  *Config
+		dropped []droppedDatagram
+	)
+
+	newQueue := func() {
+		tracer := &logging.ConnectionTracer{
+			DroppedDatagram: func(size logging.ByteCount, reason logging.DatagramDropReason) {
+				dropped = append(dropped, droppedDatagram{size: size, reason: reason})
+			},
+		}
+		queue = newDatagramQueue(func() { queued <- struct{}{} }, populateConfig(config), tracer, utils.DefaultLogger)
+	}
 
 	BeforeEach(func() {
 		queued = make(chan struct{}, 100)
-		queue = newDatagramQueue(func() { queued <- struct{}{} }, utils.DefaultLogger)
+		config = &Config{}
+		dropped = nil
+		newQueue()
 	})
 
 	Context("sending", func() {
@@ -37,7 +61,7 @@ var _ = Describe("Datagram Queue", func() {
 		})
 
 		It("blocks when the maximum number of datagrams have been queued", func() {
-			for i := 0; i < maxDatagramSendQueueLen; i++ {
+			for i := 0; i < protocol.DefaultMaxDatagramSendQueueLen; i++ {
 				Expect(queue.Add(&wire.DatagramFrame{Data: []byte{0}})).To(Succeed())
 			}
 			errChan := make(chan error, 1)
@@ -50,7 +74,7 @@ var _ = Describe("Datagram Queue", func() {
 			Consistently(errChan, 50*time.Millisecond).ShouldNot(Receive())
 			queue.Pop()
 			Eventually(errChan).Should(Receive(BeNil()))
-			for i := 1; i < maxDatagramSendQueueLen; i++ {
+			for i := 1; i < protocol.DefaultMaxDatagramSendQueueLen; i++ {
 				queue.Pop()
 			}
 			f := queue.Peek()
@@ -74,7 +98,7 @@ var _ = Describe("Datagram Queue", func() {
 		})
 
 		It("closes", func() {
-			for i := 0; i < maxDatagramSendQueueLen; i++ {
+			for i := 0; i < protocol.DefaultMaxDatagramSendQueueLen; i++ {
 				Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foo")})).To(Succeed())
 			}
 			errChan := make(chan error, 1)
@@ -87,6 +111,207 @@ var _ = Describe("Datagram Queue", func() {
 			queue.CloseWithError(testErr)
 			Eventually(errChan).Should(Receive(MatchError(testErr)))
 		})
+
+		Context("callbacks", func() {
+			var states []DatagramState
+
+			BeforeEach(func() {
+				states = nil
+			})
+
+			addWithCallback := func(data string) {
+				ExpectWithOffset(1, queue.AddWithCallback(
+					&wire.DatagramFrame{Data: []byte(data)},
+					func(s DatagramState) { states = append(states, s) },
+				)).To(Succeed())
+			}
+
+			It("doesn't return a handler if no callback was set", func() {
+				Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foo")})).To(Succeed())
+				Expect(queue.Peek()).ToNot(BeNil())
+				Expect(queue.Pop()).To(BeNil())
+			})
+
+			It("reports acknowledged and lost datagrams", func() {
+				addWithCallback("foo")
+				addWithCallback("bar")
+				f1 := queue.Peek()
+				h1 := queue.Pop()
+				Expect(h1).ToNot(BeNil())
+				f2 := queue.Peek()
+				h2 := queue.Pop()
+				Expect(h2).ToNot(BeNil())
+				Expect(states).To(BeEmpty())
+				h2.OnLost(f2)
+				Expect(states).To(Equal([]DatagramState{DatagramLost}))
+				h1.OnAcked(f1)
+				Expect(states).To(Equal([]DatagramState{DatagramLost, DatagramAcked}))
+			})
+
+			It("reports dropped datagrams", func() {
+				addWithCallback("foo")
+				Expect(queue.Peek()).ToNot(BeNil())
+				queue.Drop()
+				Expect(queue.Peek()).To(BeNil())
+				Expect(states).To(Equal([]DatagramState{DatagramDropped}))
+				Expect(dropped).To(Equal([]droppedDatagram{{size: 3, reason: logging.DatagramDropTooLarge}}))
+			})
+
+			It("reports queued datagrams as dropped when the queue is closed", func() {
+				addWithCallback("foo")
+				Expect(queue.Add(&wire.DatagramFrame{Data: []byte("bar")})).To(Succeed())
+				addWithCallback("baz")
+				queue.CloseWithError(errors.New("test error"))
+				Expect(states).To(Equal([]DatagramState{DatagramDropped, DatagramDropped}))
+			})
+
+			It("allows the callback to queue a new datagram", func() {
+				Expect(queue.AddWithCallback(&wire.DatagramFrame{Data: []byte("foo")}, func(DatagramState) {
+					Expect(queue.Add(&wire.DatagramFrame{Data: []byte("bar")})).To(Succeed())
+				})).To(Succeed())
+				queue.Drop()
+				f := queue.Peek()
+				Expect(f).ToNot(BeNil())
+				Expect(f.Data).To(Equal([]byte("bar")))
+			})
+		})
+	})
+
+	Context("send queue policies", func() {
+		var states map[string]DatagramState
+
+		BeforeEach(func() {
+			states = make(map[string]DatagramState)
+		})
+
+		add := func(data string) error {
+			return queue.AddWithCallback(
+				&wire.DatagramFrame{Data: []byte(data)},
+				func(s DatagramState) { states[data] = s },
+			)
+		}
+
+		It("uses a custom queue length", func() {
+			config.DatagramSendQueueLen = 2
+			config.DatagramSendQueuePolicy = DatagramQueueError
+			newQueue()
+			Expect(add("foo")).To(Succeed())
+			Expect(add("bar")).To(Succeed())
+			Expect(add("baz")).To(MatchError(ErrDatagramQueueFull))
+		})
+
+		It("returns an error when the queue is full", func() {
+			config.DatagramSendQueuePolicy = DatagramQueueError
+			newQueue()
+			for i := 0; i < protocol.DefaultMaxDatagramSendQueueLen; i++ {
+				Expect(queue.Add(&wire.DatagramFrame{Data: []byte{0}})).To(Succeed())
+			}
+			Expect(add("foobar")).To(MatchError(ErrDatagramQueueFull))
+			Expect(states).To(BeEmpty())
+			Expect(dropped).To(BeEmpty())
+			queue.Peek()
+			queue.Pop()
+			Expect(add("foobar")).To(Succeed())
+		})
+
+		It("drops the newest datagram when the queue is full", func() {
+			config.DatagramSendQueueLen = 2
+			config.DatagramSendQueuePolicy = DatagramQueueDropNewest
+			newQueue()
+			Expect(add("foo")).To(Succeed())
+			Expect(add("bar")).To(Succeed())
+			Expect(add("foobar")).To(Succeed())
+			Expect(states).To(Equal(map[string]DatagramState{"foobar": DatagramDropped}))
+			Expect(dropped).To(Equal([]droppedDatagram{{size: 6, reason: logging.DatagramDropSendQueueFull}}))
+			Expect(queue.Peek().Data).To(Equal([]byte("foo")))
+			queue.Pop()
+			Expect(queue.Peek().Data).To(Equal([]byte("bar")))
+			queue.Pop()
+			Expect(queue.Peek()).To(BeNil())
+		})
+
+		It("drops the oldest datagram when the queue is full", func() {
+			config.DatagramSendQueueLen = 2
+			config.DatagramSendQueuePolicy = DatagramQueueDropOldest
+			newQueue()
+			Expect(add("foo")).To(Succeed())
+			Expect(add("bar")).To(Succeed())
+			Expect(add("foobar")).To(Succeed())
+			Expect(states).To(Equal(map[string]DatagramState{"foo": DatagramDropped}))
+			Expect(dropped).To(Equal([]droppedDatagram{{size: 3, reason: logging.DatagramDropSendQueueFull}}))
+			Expect(queue.Peek().Data).To(Equal([]byte("bar")))
+			queue.Pop()
+			Expect(queue.Peek().Data).To(Equal([]byte("foobar")))
+			queue.Pop()
+			Expect(queue.Peek()).To(BeNil())
+		})
+
+		It("doesn't drop the datagram that is being packed", func() {
+			config.DatagramSendQueueLen = 2
+			config.DatagramSendQueuePolicy = DatagramQueueDropOldest
+			newQueue()
+			Expect(add("foo")).To(Succeed())
+			Expect(add("bar")).To(Succeed())
+			Expect(queue.Peek().Data).To(Equal([]byte("foo")))
+			// foo is being packed, so bar is the oldest datagram that can be dropped
+			Expect(add("foobar")).To(Succeed())
+			Expect(states).To(Equal(map[string]DatagramState{"bar": DatagramDropped}))
+			h := queue.Pop()
+			Expect(h).ToNot(BeNil())
+			h.OnAcked(nil)
+			Expect(states).To(HaveKeyWithValue("foo", DatagramAcked))
+			Expect(queue.Peek().Data).To(Equal([]byte("foobar")))
+			queue.Pop()
+			Expect(queue.Peek()).To(BeNil())
+		})
+
+		It("drops the new datagram if the only queued datagram is being packed", func() {
+			config.DatagramSendQueueLen = 1
+			config.DatagramSendQueuePolicy = DatagramQueueDropOldest
+			newQueue()
+			Expect(add("foo")).To(Succeed())
+			Expect(queue.Peek().Data).To(Equal([]byte("foo")))
+			Expect(add("bar")).To(Succeed())
+			Expect(states).To(Equal(map[string]DatagramState{"bar": DatagramDropped}))
+			Expect(dropped).To(Equal([]droppedDatagram{{size: 3, reason: logging.DatagramDropSendQueueFull}}))
+			queue.Pop().OnAcked(nil)
+			Expect(states).To(Equal(map[string]DatagramState{"foo": DatagramAcked, "bar": DatagramDropped}))
+			Expect(queue.Peek()).To(BeNil())
+		})
+
+		It("drops datagrams that were queued for too long", func() {
+			config.DatagramMaxAge = 50 * time.Millisecond
+			newQueue()
+			Expect(add("foo")).To(Succeed())
+			Expect(add("bar")).To(Succeed())
+			time.Sleep(75 * time.Millisecond)
+			Expect(add("foobar")).To(Succeed())
+			f := queue.Peek()
+			Expect(f).ToNot(BeNil())
+			Expect(f.Data).To(Equal([]byte("foobar")))
+			Expect(states).To(Equal(map[string]DatagramState{"foo": DatagramDropped, "bar": DatagramDropped}))
+			Expect(dropped).To(Equal([]droppedDatagram{
+				{size: 3, reason: logging.DatagramDropExpired},
+				{size: 3, reason: logging.DatagramDropExpired},
+			}))
+		})
+
+		It("unblocks Add when datagrams expire", func() {
+			config.DatagramSendQueueLen = 1
+			config.DatagramMaxAge = 50 * time.Millisecond
+			newQueue()
+			Expect(add("foo")).To(Succeed())
+			errChan := make(chan error, 1)
+			go func() {
+				defer GinkgoRecover()
+				errChan <- queue.Add(&wire.DatagramFrame{Data: []byte("bar")})
+			}()
+			Consistently(errChan, 25*time.Millisecond).ShouldNot(Receive())
+			time.Sleep(50 * time.Millisecond)
+			Expect(queue.Peek()).To(BeNil())
+			Eventually(errChan).Should(Receive(BeNil()))
+			Expect(queue.Peek().Data).To(Equal([]byte("bar")))
+		})
 	})
 
 	Context("receiving", func() {
@@ -129,6 +354,144 @@ var _ = Describe("Datagram Queue", func() {
 			Eventually(errChan).Should(Receive(Equal(context.Canceled)))
 		})
 
+		It("drops the newest datagram when the queue is full", func() {
+			config.DatagramReceiveQueueLen = 2
+			newQueue()
+			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("foo")})
+			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("bar")})
+			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("foobar")})
+			Expect(dropped).To(Equal([]droppedDatagram{{size: 6, reason: logging.DatagramDropReceiveQueueFull}}))
+			Expect(queue.Receive(context.Background())).To(Equal([]byte("foo")))
+			Expect(queue.Receive(context.Background())).To(Equal([]byte("bar")))
+		})
+
+		It("drops the oldest datagram when the queue is full", func() {
+			config.DatagramReceiveQueueLen = 2
+			config.DatagramReceiveQueuePolicy = DatagramQueueDropOldest
+			newQueue()
+			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("foo")})
+			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("bar")})
+			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("foobar")})
+			Expect(dropped).To(Equal([]droppedDatagram{{size: 3, reason: logging.DatagramDropReceiveQueueFull}}))
+			Expect(queue.Receive(context.Background())).To(Equal([]byte("bar")))
+			Expect(queue.Receive(context.Background())).To(Equal([]byte("foobar")))
+		})
+
+		It("receives multiple DATAGRAM frames at once", func() {
+			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("foo")})
+			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("bar")})
+			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("baz")})
+			bufs := make([][]byte, 2)
+			n, err := queue.ReceiveBatch(context.Background(), bufs)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(n).To(Equal(2))
+			Expect(bufs).To(Equal([][]byte{[]byte("foo"), []byte("bar")}))
+			n, err = queue.ReceiveBatch(context.Background(), bufs)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(n).To(Equal(1))
+			Expect(bufs[0]).To(Equal([]byte("baz")))
+		})
+
+		It("receives into the buffers provided", func() {
+			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("foo")})
+			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("foobar")})
+			bufs := [][]byte{make([]byte, 0, 10), make([]byte, 1, 3)}
+			buf0 := bufs[0]
+			n, err := queue.ReceiveBatch(context.Background(), bufs)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(n).To(Equal(2))
+			Expect(bufs[0]).To(Equal([]byte("foo")))
+			Expect(&bufs[0][0]).To(BeIdenticalTo(&buf0[:1][0]))
+			// the second buffer was too small, and needed to be grown
+			Expect(bufs[1]).To(Equal([]byte("foobar")))
+		})
+
+		Context("allocations", func() {
+			// receiveDatagram parses a DATAGRAM frame from a packet, and passes it to the queue, like the connection does.
+			// It doesn't use Gomega, since matchers allocate.
+			receiveDatagram := func(parser *wire.FrameParser, packet []byte) {
+				_, f, err := parser.ParseNext(packet, protocol.Encryption1RTT, protocol.Version1)
+				if err != nil {
+					Fail(err.Error())
+				}
+				queue.HandleDatagramFrame(f.(*wire.DatagramFrame))
+			}
+
+			var (
+				parser *wire.FrameParser
+				packet []byte
+			)
+
+			BeforeEach(func() {
+				if israce.Enabled {
+					Skip("the race detector makes sync.Pool drop objects at random")
+				}
+				parser = wire.NewFrameParser(true)
+				var err error
+				packet, err = (&wire.DatagramFrame{Data: make([]byte, 1000)}).Append(nil, protocol.Version1)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("doesn't allocate when receiving in batches", func() {
+				bufs := [][]byte{make([]byte, 0, 1500)}
+				allocs := testing.AllocsPerRun(100, func() {
+					receiveDatagram(parser, packet)
+					n, err := queue.ReceiveBatch(context.Background(), bufs)
+					if err != nil || n != 1 {
+						Fail("failed to receive datagram")
+					}
+				})
+				Expect(allocs).To(BeZero())
+			})
+
+			It("allocates once per datagram when receiving a single datagram", func() {
+				allocs := testing.AllocsPerRun(100, func() {
+					receiveDatagram(parser, packet)
+					if _, err := queue.Receive(context.Background()); err != nil {
+						Fail("failed to receive datagram")
+					}
+				})
+				// the payload is handed to the application
+				Expect(allocs).To(Equal(1.0))
+			})
+		})
+
+		It("blocks until a frame is received, when receiving in batches", func() {
+			c := make(chan []byte, 1)
+			go func() {
+				defer GinkgoRecover()
+				bufs := make([][]byte, 10)
+				n, err := queue.ReceiveBatch(context.Background(), bufs)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(n).To(Equal(1))
+				c <- bufs[0]
+			}()
+
+			Consistently(c).ShouldNot(Receive())
+			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("foobar")})
+			Eventually(c).Should(Receive(Equal([]byte("foobar"))))
+		})
+
+		It("wakes up all concurrent receivers", func() {
+			const num = 3
+			c := make(chan []byte, num)
+			for i := 0; i < num; i++ {
+				go func() {
+					defer GinkgoRecover()
+					data, err := queue.Receive(context.Background())
+					Expect(err).ToNot(HaveOccurred())
+					c <- data
+				}()
+			}
+			Consistently(c).ShouldNot(Receive())
+			for i := 0; i < num; i++ {
+				queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte{byte(i)}})
+			}
+			for i := 0; i < num; i++ {
+				Eventually(c).Should(Receive())
+			}
+		})
+
 		It("closes", func() {
 			errChan := make(chan error, 1)
 			go func() {
@@ -143,3 +506,39 @@ var _ = Describe("Datagram Queue", func() {
 		})
 	})
 })
+
+func benchmarkReceiveDatagram(b *testing.B, receive func(*datagramQueue)) {
+	queue := newDatagramQueue(func() {}, populateConfig(&Config{}), nil, utils.DefaultLogger)
+	parser := wire.NewFrameParser(true)
+	packet, err := (&wire.DatagramFrame{Data: make([]byte, 1000)}).Append(nil, protocol.Version1)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, f, err := parser.ParseNext(packet, protocol.Encryption1RTT, protocol.Version1)
+		if err != nil {
+			b.Fatal(err)
+		}
+		queue.HandleDatagramFrame(f.(*wire.DatagramFrame))
+		receive(queue)
+	}
+}
+
+func BenchmarkReceiveDatagram(b *testing.B) {
+	benchmarkReceiveDatagram(b, func(q *datagramQueue) {
+		if _, err := q.Receive(context.Background()); err != nil {
+			b.Fatal(err)
+		}
+	})
+}
+
+func BenchmarkReceiveDatagramBatch(b *testing.B) {
+	bufs := [][]byte{make([]byte, 0, 1500)}
+	benchmarkReceiveDatagram(b, func(q *datagramQueue) {
+		if _, err := q.ReceiveBatch(context.Background(), bufs); err != nil {
+			b.Fatal(err)
+		}
+	})
+}
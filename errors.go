@@ -1,6 +1,7 @@
 package quic
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/quic-go/quic-go/internal/qerr"
@@ -22,23 +23,24 @@ type (
 )
 
 const (
-	NoError                   = qerr.NoError
-	InternalError             = qerr.InternalError
-	ConnectionRefused         = qerr.ConnectionRefused
-	FlowControlError          = qerr.FlowControlError
-	StreamLimitError          = qerr.StreamLimitError
-	StreamStateError          = qerr.StreamStateError
-	FinalSizeError            = qerr.FinalSizeError
-	FrameEncodingError        = qerr.FrameEncodingError
-	TransportParameterError   = qerr.TransportParameterError
-	ConnectionIDLimitError    = qerr.ConnectionIDLimitError
-	ProtocolViolation         = qerr.ProtocolViolation
-	InvalidToken              = qerr.InvalidToken
-	ApplicationErrorErrorCode = qerr.ApplicationErrorErrorCode
-	CryptoBufferExceeded      = qerr.CryptoBufferExceeded
-	KeyUpdateError            = qerr.KeyUpdateError
-	AEADLimitReached          = qerr.AEADLimitReached
-	NoViablePathError         = qerr.NoViablePathError
+	NoError                     = qerr.NoError
+	InternalError               = qerr.InternalError
+	ConnectionRefused           = qerr.ConnectionRefused
+	FlowControlError            = qerr.FlowControlError
+	StreamLimitError            = qerr.StreamLimitError
+	StreamStateError            = qerr.StreamStateError
+	FinalSizeError              = qerr.FinalSizeError
+	FrameEncodingError          = qerr.FrameEncodingError
+	TransportParameterError     = qerr.TransportParameterError
+	ConnectionIDLimitError      = qerr.ConnectionIDLimitError
+	ProtocolViolation           = qerr.ProtocolViolation
+	InvalidToken                = qerr.InvalidToken
+	ApplicationErrorErrorCode   = qerr.ApplicationErrorErrorCode
+	CryptoBufferExceeded        = qerr.CryptoBufferExceeded
+	KeyUpdateError              = qerr.KeyUpdateError
+	AEADLimitReached            = qerr.AEADLimitReached
+	NoViablePathError           = qerr.NoViablePathError
+	VersionNegotiationErrorCode = qerr.VersionNegotiationErrorCode
 )
 
 // A StreamError is used for Stream.CancelRead and Stream.CancelWrite.
@@ -62,6 +64,10 @@ func (e *StreamError) Error() string {
 	return fmt.Sprintf("stream %d canceled by %s with error code %d", e.StreamID, pers, e.ErrorCode)
 }
 
+// ErrDatagramQueueFull is returned from Connection.SendDatagram if the send queue is full,
+// and the DatagramQueueError policy is used.
+var ErrDatagramQueueFull = errors.New("DATAGRAM send queue full")
+
 // DatagramTooLargeError is returned from Connection.SendDatagram if the payload is too large to be sent.
 type DatagramTooLargeError struct {
 	PeerMaxDatagramFrameSize int64
@@ -29,6 +29,8 @@ type frameSorter struct {
 	queue   map[protocol.ByteCount]frameSorterEntry
 	readPos protocol.ByteCount
 	gaps    *list.List[byteInterval]
+
+	numOutOfOrder uint64 // number of frames that were received out of order
 }
 
 var errDuplicateStreamData = errors.New("duplicate stream data")
@@ -64,6 +66,8 @@ func (s *frameSorter) push(data []byte, offset protocol.ByteCount, doneCb func()
 	if end <= s.gaps.Front().Value.Start {
 		return errDuplicateStreamData
 	}
+	// Some of the data preceding this frame is still missing.
+	outOfOrder := start > s.gaps.Front().Value.Start
 
 	startGap, startsInGap := s.findStartGap(start)
 	endGap, endsInGap := s.findEndGap(startGap, end)
@@ -173,6 +177,9 @@ func (s *frameSorter) push(data []byte, offset protocol.ByteCount, doneCb func()
 		return errors.New("too many gaps in received data")
 	}
 
+	if outOfOrder {
+		s.numOutOfOrder++
+	}
 	s.queue[start] = frameSorterEntry{Data: data, DoneCb: doneCb}
 	return nil
 }
@@ -115,6 +115,18 @@ var _ = Describe("frame sorter", func() {
 		Expect(doneCb).To(BeNil())
 	})
 
+	It("counts frames received out of order", func() {
+		Expect(s.Push([]byte("foo"), 0, nil)).To(Succeed())
+		Expect(s.numOutOfOrder).To(BeZero())
+		Expect(s.Push([]byte("baz"), 6, nil)).To(Succeed())
+		Expect(s.numOutOfOrder).To(BeEquivalentTo(1))
+		// duplicates are not counted
+		Expect(s.Push([]byte("baz"), 6, nil)).To(Succeed())
+		Expect(s.numOutOfOrder).To(BeEquivalentTo(1))
+		Expect(s.Push([]byte("bar"), 3, nil)).To(Succeed())
+		Expect(s.numOutOfOrder).To(BeEquivalentTo(1))
+	})
+
 	It("says if has more data", func() {
 		Expect(s.HasMoreData()).To(BeFalse())
 		Expect(s.Push([]byte("foo"), 0, nil)).To(Succeed())
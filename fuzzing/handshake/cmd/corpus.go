@@ -43,6 +43,7 @@ func main() {
 		&wire.TransportParameters{ActiveConnectionIDLimit: 2},
 		config,
 		false,
+		nil,
 		utils.NewRTTStats(),
 		nil,
 		utils.DefaultLogger.WithPrefix("server"),
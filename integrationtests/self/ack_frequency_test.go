@@ -0,0 +1,66 @@
+package self_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/logging"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ACK frequency", func() {
+	It("asks the peer to send fewer ACKs", func() {
+		serverCounter, serverTracer := newPacketTracer()
+		server, err := quic.ListenAddr(
+			"localhost:0",
+			getTLSConfig(),
+			getQuicConfig(&quic.Config{
+				EnableAckFrequency: true,
+				Tracer:             newTracer(serverTracer),
+			}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		go func() {
+			defer GinkgoRecover()
+			conn, err := server.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			str, err := conn.OpenStream()
+			Expect(err).ToNot(HaveOccurred())
+			_, err = str.Write(PRDataLong)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(str.Close()).To(Succeed())
+		}()
+
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{EnableAckFrequency: true}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		str, err := conn.AcceptStream(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		data, err := io.ReadAll(str)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal(PRDataLong))
+		Expect(conn.CloseWithError(0, "")).To(Succeed())
+
+		var numAckFrequencyFrames int
+		for _, p := range serverCounter.getSentShortHeaderPackets() {
+			for _, f := range p.frames {
+				if _, ok := f.(*logging.AckFrequencyFrame); ok {
+					numAckFrequencyFrames++
+				}
+			}
+		}
+		fmt.Fprintf(GinkgoWriter, "sent %d ACK_FREQUENCY frames\n", numAckFrequencyFrames)
+		Expect(numAckFrequencyFrames).To(BeNumerically(">", 0))
+	})
+})
@@ -0,0 +1,85 @@
+package self_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/logging"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type countingCongestionController struct {
+	quic.CongestionController
+	sent *atomic.Int64
+}
+
+func (c *countingCongestionController) OnPacketSent(t time.Time, bytesInFlight logging.ByteCount, pn logging.PacketNumber, size logging.ByteCount, isRetransmittable bool) {
+	c.sent.Add(1)
+	c.CongestionController.OnPacketSent(t, bytesInFlight, pn, size, isRetransmittable)
+}
+
+var _ = Describe("Congestion Control", func() {
+	for _, f := range []struct {
+		name          string
+		newController func(*logging.RTTStats, logging.ByteCount, *logging.ConnectionTracer) quic.CongestionController
+	}{
+		{name: "Reno", newController: quic.NewRenoCongestionController},
+		{name: "CUBIC", newController: quic.NewCubicCongestionController},
+		{name: "BBR", newController: quic.NewBBRCongestionController},
+		{name: "Prague", newController: quic.NewPragueCongestionController},
+	} {
+		newController := f.newController
+
+		It(fmt.Sprintf("transfers data using %s", f.name), func() {
+			var numSent atomic.Int64
+			ln, err := quic.ListenAddr(
+				"localhost:0",
+				getTLSConfig(),
+				getQuicConfig(&quic.Config{
+					CongestionController: func(rttStats *logging.RTTStats, size logging.ByteCount, tracer *logging.ConnectionTracer) quic.CongestionController {
+						return &countingCongestionController{
+							CongestionController: newController(rttStats, size, tracer),
+							sent:                 &numSent,
+						}
+					},
+				}),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			defer ln.Close()
+
+			go func() {
+				defer GinkgoRecover()
+				conn, err := ln.Accept(context.Background())
+				Expect(err).ToNot(HaveOccurred())
+				str, err := conn.OpenUniStream()
+				Expect(err).ToNot(HaveOccurred())
+				_, err = str.Write(PRData)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(str.Close()).To(Succeed())
+			}()
+
+			conn, err := quic.DialAddr(
+				context.Background(),
+				fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
+				getTLSClientConfig(),
+				getQuicConfig(nil),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			defer conn.CloseWithError(0, "")
+			str, err := conn.AcceptUniStream(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			data, err := io.ReadAll(str)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(data).To(Equal(PRData))
+			// the server sent at least one packet per 1500 bytes of stream data
+			Expect(numSent.Load()).To(BeNumerically(">", len(PRData)/1500))
+		})
+	}
+})
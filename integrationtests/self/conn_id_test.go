@@ -7,9 +7,14 @@ import (
 	"io"
 	mrand "math/rand"
 	"net"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/quic-go/quic-go"
+	quicproxy "github.com/quic-go/quic-go/integrationtests/tools/proxy"
 	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/wire"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -125,4 +130,125 @@ var _ = Describe("Connection ID lengths tests", func() {
 		defer closeFn()
 		runClient(ln.Addr(), 0, &connIDGenerator{length: randomConnIDLen()})
 	})
+
+	It("uses QUIC-LB connection IDs that can be routed by a load balancer", func() {
+		conf := quic.LoadBalancerConfig{
+			ConfigID:    2,
+			ServerIDLen: 3,
+			NonceLen:    8,
+			Key:         []byte{0x8f, 0x95, 0xf0, 0x92, 0x45, 0x76, 0x5f, 0x80, 0x25, 0x69, 0x34, 0xe5, 0x0c, 0x66, 0x20, 0x7f},
+		}
+		serverID := []byte{0xc0, 0xff, 0xee}
+		gen, err := quic.NewLoadBalancerConnectionIDGenerator(conf, serverID)
+		Expect(err).ToNot(HaveOccurred())
+		decoder, err := quic.NewLoadBalancerDecoder(conf)
+		Expect(err).ToNot(HaveOccurred())
+		ln, closeFn := runServer(0, gen)
+		defer closeFn()
+
+		var numShortHeaderPackets atomic.Int32
+		proxy, err := quicproxy.NewQuicProxy("localhost:0", &quicproxy.Opts{
+			RemoteAddr: fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
+			DropPacket: func(dir quicproxy.Direction, packet []byte) bool {
+				if dir != quicproxy.DirectionIncoming || wire.IsLongHeaderPacket(packet[0]) {
+					return false
+				}
+				numShortHeaderPackets.Add(1)
+				connID, err := wire.ParseConnectionID(packet, gen.ConnectionIDLen())
+				Expect(err).ToNot(HaveOccurred())
+				id, configID, err := decoder.ServerID(connID)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(id).To(Equal(serverID))
+				Expect(configID).To(Equal(conf.ConfigID))
+				return false
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		defer proxy.Close()
+
+		runClient(proxy.LocalAddr(), 0, nil)
+		Expect(numShortHeaderPackets.Load()).ToNot(BeZero())
+	})
+
+	It("rotates connection IDs", func() {
+		conf := getQuicConfig(&quic.Config{ConnectionIDRotationInterval: scaleDuration(25 * time.Millisecond)})
+		ln, err := quic.ListenAddr("localhost:0", getTLSConfig(), conf)
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+
+		var mx sync.Mutex
+		connIDs := make(map[quicproxy.Direction]map[protocol.ConnectionID]struct{})
+		proxy, err := quicproxy.NewQuicProxy("localhost:0", &quicproxy.Opts{
+			RemoteAddr: fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
+			DropPacket: func(dir quicproxy.Direction, packet []byte) bool {
+				if wire.IsLongHeaderPacket(packet[0]) {
+					return false
+				}
+				connID, err := wire.ParseConnectionID(packet, protocol.DefaultConnectionIDLength)
+				if err != nil {
+					return false
+				}
+				mx.Lock()
+				defer mx.Unlock()
+				if connIDs[dir] == nil {
+					connIDs[dir] = make(map[protocol.ConnectionID]struct{})
+				}
+				connIDs[dir][connID] = struct{}{}
+				return false
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		defer proxy.Close()
+
+		// the client needs to use a non-zero length connection ID, otherwise the server can't rotate it
+		udpConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+		Expect(err).ToNot(HaveOccurred())
+		defer udpConn.Close()
+		tr := &quic.Transport{
+			Conn:               udpConn,
+			ConnectionIDLength: protocol.DefaultConnectionIDLength,
+		}
+		defer tr.Close()
+		conn, err := tr.Dial(
+			context.Background(),
+			&net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: proxy.LocalPort()},
+			getTLSClientConfig(),
+			conf,
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		serverConn, err := ln.Accept(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			defer close(done)
+			str, err := serverConn.AcceptStream(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			_, err = io.Copy(str, str)
+			Expect(err).ToNot(HaveOccurred())
+			str.Close()
+		}()
+
+		str, err := conn.OpenStream()
+		Expect(err).ToNot(HaveOccurred())
+		for i := 0; i < 20; i++ {
+			_, err := str.Write([]byte{byte(i)})
+			Expect(err).ToNot(HaveOccurred())
+			b := make([]byte, 1)
+			_, err = io.ReadFull(str, b)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b[0]).To(Equal(byte(i)))
+			time.Sleep(scaleDuration(10 * time.Millisecond))
+		}
+		Expect(str.Close()).To(Succeed())
+		_, err = io.ReadAll(str)
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(done).Should(BeClosed())
+
+		mx.Lock()
+		defer mx.Unlock()
+		Expect(len(connIDs[quicproxy.DirectionIncoming])).To(BeNumerically(">=", 4))
+		Expect(len(connIDs[quicproxy.DirectionOutgoing])).To(BeNumerically(">=", 4))
+	})
 })
@@ -184,4 +184,114 @@ var _ = Describe("Datagram test", func() {
 		close()
 		conn.CloseWithError(0, "")
 	})
+
+	It("receives datagrams in batches", func() {
+		const num = 100
+		ln, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(&quic.Config{
+			EnableDatagrams:         true,
+			DatagramReceiveQueueLen: num,
+		}))
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{EnableDatagrams: true}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		serverConn, err := ln.Accept(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+
+		for i := 0; i < num; i++ {
+			Expect(conn.SendDatagram([]byte{byte(i)})).To(Succeed())
+		}
+		bufs := make([][]byte, 16)
+		var received []byte
+		for len(received) < num {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			n, err := serverConn.ReceiveDatagrams(ctx, bufs)
+			cancel()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(n).To(BeNumerically(">", 0))
+			for _, b := range bufs[:n] {
+				Expect(b).To(HaveLen(1))
+				received = append(received, b[0])
+			}
+		}
+		// datagrams are sent without loss on the loopback interface, and arrive in order
+		for i, b := range received {
+			Expect(b).To(Equal(byte(i)))
+		}
+	})
+
+	It("reports if datagrams were acknowledged or lost", func() {
+		const num = 50
+		ln, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(&quic.Config{EnableDatagrams: true}))
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+
+		var counter int
+		proxy, err := quicproxy.NewQuicProxy("localhost:0", &quicproxy.Opts{
+			RemoteAddr: fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
+			// drop every 5th Short Header packet sent from the client
+			DropPacket: func(dir quicproxy.Direction, packet []byte) bool {
+				if dir == quicproxy.DirectionOutgoing || wire.IsLongHeaderPacket(packet[0]) {
+					return false
+				}
+				counter++
+				return counter%5 == 0
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		defer proxy.Close()
+
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", proxy.LocalPort()),
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{EnableDatagrams: true}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		serverConn, err := ln.Accept(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+
+		states := make(chan quic.DatagramState, num)
+		for i := 0; i < num; i++ {
+			Expect(conn.SendDatagramWithCallback([]byte{byte(i)}, func(s quic.DatagramState) { states <- s })).To(Succeed())
+		}
+		var acked, lost int
+		for i := 0; i < num; i++ {
+			var s quic.DatagramState
+			Eventually(states, 5*time.Second).Should(Receive(&s))
+			switch s {
+			case quic.DatagramAcked:
+				acked++
+			case quic.DatagramLost:
+				lost++
+			default:
+				Fail(fmt.Sprintf("unexpected datagram state: %s", s))
+			}
+		}
+		Consistently(states).ShouldNot(Receive())
+		fmt.Fprintf(GinkgoWriter, "%d datagrams acknowledged, %d lost\n", acked, lost)
+		Expect(acked).ToNot(BeZero())
+		Expect(lost).ToNot(BeZero())
+
+		// all acknowledged datagrams were received by the server
+		var received int
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), scaleDuration(50*time.Millisecond))
+			_, err := serverConn.ReceiveDatagram(ctx)
+			cancel()
+			if err != nil {
+				break
+			}
+			received++
+		}
+		Expect(received).To(BeNumerically(">=", acked))
+	})
 })
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -47,6 +48,15 @@ func (c *tokenStore) Pop(key string) *quic.ClientToken {
 	return c.store.Pop(key)
 }
 
+// ignoringTokenPolicy ignores all tokens presented by the client.
+type ignoringTokenPolicy struct {
+	quic.TokenPolicy
+}
+
+func (ignoringTokenPolicy) ValidateToken([]byte, net.Addr) (*quic.AddressToken, error) {
+	return nil, nil
+}
+
 var _ = Describe("Handshake tests", func() {
 	var (
 		server        *quic.Listener
@@ -599,6 +609,93 @@ var _ = Describe("Handshake tests", func() {
 			Expect(err).ToNot(HaveOccurred())
 			conn.CloseWithError(0, "")
 		})
+
+		It("sends a Retry when requested by the admission control callback", func() {
+			var mx sync.Mutex
+			var infos []*quic.AdmissionInfo
+			tr := quic.Transport{
+				Conn: conn,
+				AdmitConnection: func(info *quic.AdmissionInfo) quic.AdmissionDecision {
+					mx.Lock()
+					defer mx.Unlock()
+					infos = append(infos, info)
+					return quic.AdmissionRetry
+				},
+			}
+			defer tr.Close()
+			ln, err := tr.Listen(getTLSConfig(), getQuicConfig(nil))
+			Expect(err).ToNot(HaveOccurred())
+			defer ln.Close()
+
+			var receivedRetry atomic.Bool
+			c, err := quic.DialAddr(
+				context.Background(),
+				ln.Addr().String(),
+				getTLSClientConfig(),
+				getQuicConfig(&quic.Config{
+					Tracer: func(context.Context, logging.Perspective, quic.ConnectionID) *logging.ConnectionTracer {
+						return &logging.ConnectionTracer{ReceivedRetry: func(*logging.Header) { receivedRetry.Store(true) }}
+					},
+				}),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			defer c.CloseWithError(0, "")
+			Expect(receivedRetry.Load()).To(BeTrue())
+			mx.Lock()
+			defer mx.Unlock()
+			Expect(len(infos)).To(BeNumerically(">=", 2))
+			Expect(infos[0].AddrValidated).To(BeFalse())
+			Expect(infos[0].IsRetryToken).To(BeFalse())
+			Expect(infos[0].Version).To(Equal(c.ConnectionState().Version))
+			Expect(infos[0].RemoteAddr.(*net.UDPAddr).Port).To(Equal(c.LocalAddr().(*net.UDPAddr).Port))
+			// the Initial sent in response to the Retry carries the Retry token
+			Expect(infos[len(infos)-1].AddrValidated).To(BeTrue())
+			Expect(infos[len(infos)-1].IsRetryToken).To(BeTrue())
+		})
+
+		It("refuses connections when requested by the admission control callback", func() {
+			tr := quic.Transport{
+				Conn:            conn,
+				AdmitConnection: func(*quic.AdmissionInfo) quic.AdmissionDecision { return quic.AdmissionRefuse },
+			}
+			defer tr.Close()
+			ln, err := tr.Listen(getTLSConfig(), getQuicConfig(nil))
+			Expect(err).ToNot(HaveOccurred())
+			defer ln.Close()
+
+			_, err = quic.DialAddr(context.Background(), ln.Addr().String(), getTLSClientConfig(), getQuicConfig(nil))
+			Expect(err).To(HaveOccurred())
+			var transportErr *quic.TransportError
+			Expect(errors.As(err, &transportErr)).To(BeTrue())
+			Expect(transportErr.ErrorCode).To(Equal(quic.ConnectionRefused))
+		})
+
+		It("drops packets when requested by the admission control callback", func() {
+			var counter atomic.Int32
+			tr := quic.Transport{
+				Conn: conn,
+				AdmitConnection: func(*quic.AdmissionInfo) quic.AdmissionDecision {
+					counter.Add(1)
+					return quic.AdmissionDrop
+				},
+			}
+			defer tr.Close()
+			ln, err := tr.Listen(getTLSConfig(), getQuicConfig(nil))
+			Expect(err).ToNot(HaveOccurred())
+			defer ln.Close()
+
+			_, err = quic.DialAddr(
+				context.Background(),
+				ln.Addr().String(),
+				getTLSClientConfig(),
+				getQuicConfig(&quic.Config{HandshakeIdleTimeout: scaleDuration(100 * time.Millisecond)}),
+			)
+			Expect(err).To(HaveOccurred())
+			var nerr net.Error
+			Expect(errors.As(err, &nerr)).To(BeTrue())
+			Expect(nerr.Timeout()).To(BeTrue())
+			Expect(counter.Load()).To(BeNumerically(">", 1)) // the client retransmitted its Initial
+		})
 	})
 
 	Context("ALPN", func() {
@@ -743,6 +840,91 @@ var _ = Describe("Handshake tests", func() {
 			Expect(errors.As(err, &transportErr)).To(BeTrue())
 			Expect(transportErr.ErrorCode).To(Equal(quic.InvalidToken))
 		})
+
+		It("uses the TokenPolicy", func() {
+			listen := func(tokenPolicy quic.TokenPolicy, admitted chan<- *quic.AdmissionInfo, rejected chan<- logging.TokenRejectionReason) (*quic.Listener, func()) {
+				udpConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+				Expect(err).ToNot(HaveOccurred())
+				tr := &quic.Transport{
+					Conn:        udpConn,
+					TokenPolicy: tokenPolicy,
+					AdmitConnection: func(info *quic.AdmissionInfo) quic.AdmissionDecision {
+						admitted <- info
+						return quic.AdmissionAccept
+					},
+					Tracer: &logging.Tracer{
+						RejectedToken: func(_ net.Addr, isRetryToken bool, reason logging.TokenRejectionReason) {
+							Expect(isRetryToken).To(BeFalse())
+							rejected <- reason
+						},
+					},
+				}
+				ln, err := tr.Listen(getTLSConfig(), serverConfig)
+				Expect(err).ToNot(HaveOccurred())
+				go func() {
+					for {
+						if _, err := ln.Accept(context.Background()); err != nil {
+							return
+						}
+					}
+				}()
+				return ln, func() {
+					tr.Close()
+					udpConn.Close()
+				}
+			}
+
+			tokenPolicy, err := quic.NewTokenPolicy(&quic.TokenPolicyConfig{
+				IPv4PrefixLen: 24,
+				NewTokenData:  func(net.Addr) ([]byte, bool) { return []byte("foobar"), true },
+			})
+			Expect(err).ToNot(HaveOccurred())
+			admitted := make(chan *quic.AdmissionInfo, 100)
+			rejected := make(chan logging.TokenRejectionReason, 100)
+			server, closeServer := listen(tokenPolicy, admitted, rejected)
+			defer closeServer()
+
+			puts := make(chan string, 100)
+			quicConf := getQuicConfig(&quic.Config{TokenStore: newTokenStore(make(chan string, 100), puts)})
+			dial := func(ln *quic.Listener) {
+				conn, err := quic.DialAddr(context.Background(), ln.Addr().String(), getTLSClientConfig(), quicConf)
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(puts).Should(Receive())
+				conn.CloseWithError(0, "")
+			}
+
+			dial(server)
+			var info *quic.AdmissionInfo
+			Expect(admitted).To(Receive(&info))
+			Expect(info.AddrValidated).To(BeFalse())
+			Expect(info.TokenData).To(BeEmpty())
+
+			// the second connection uses the token
+			dial(server)
+			Expect(admitted).To(Receive(&info))
+			Expect(info.AddrValidated).To(BeTrue())
+			Expect(info.TokenData).To(Equal([]byte("foobar")))
+			Expect(rejected).To(BeEmpty())
+
+			// a server using a different key rejects the token
+			otherTokenPolicy, err := quic.NewTokenPolicy(nil)
+			Expect(err).ToNot(HaveOccurred())
+			otherServer, closeOtherServer := listen(otherTokenPolicy, admitted, rejected)
+			defer closeOtherServer()
+			dial(otherServer)
+			Expect(admitted).To(Receive(&info))
+			Expect(info.AddrValidated).To(BeFalse())
+			Expect(rejected).To(Receive(Equal(logging.TokenRejectionInvalid)))
+
+			// a TokenPolicy can ignore a token by returning neither a token nor an error
+			ignoringServer, closeIgnoringServer := listen(&ignoringTokenPolicy{TokenPolicy: otherTokenPolicy}, admitted, rejected)
+			defer closeIgnoringServer()
+			dial(ignoringServer)
+			Expect(admitted).To(Receive(&info))
+			Expect(info.AddrValidated).To(BeFalse())
+			Expect(info.TokenData).To(BeEmpty())
+			Expect(rejected).To(BeEmpty())
+		})
 	})
 
 	Context("GetConfigForClient", func() {
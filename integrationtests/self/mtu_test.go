@@ -0,0 +1,97 @@
+package self_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	quicproxy "github.com/quic-go/quic-go/integrationtests/tools/proxy"
+	"github.com/quic-go/quic-go/logging"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Path MTU Discovery", func() {
+	const blackHoleSize = 1300
+
+	It("falls back to a smaller MTU when a black hole appears", func() {
+		mtus := make(chan logging.ByteCount, 100)
+		server, err := quic.ListenAddr(
+			"localhost:0",
+			getTLSConfig(),
+			getQuicConfig(&quic.Config{
+				Tracer: func(context.Context, logging.Perspective, quic.ConnectionID) *logging.ConnectionTracer {
+					return &logging.ConnectionTracer{
+						UpdatedMTU: func(mtu logging.ByteCount, _ bool) { mtus <- mtu },
+					}
+				},
+			}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		var blackHole atomic.Bool
+		proxy, err := quicproxy.NewQuicProxy("localhost:0", &quicproxy.Opts{
+			RemoteAddr: fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+			DelayPacket: func(quicproxy.Direction, []byte) time.Duration {
+				return 5 * time.Millisecond
+			},
+			DropPacket: func(dir quicproxy.Direction, b []byte) bool {
+				return dir == quicproxy.DirectionOutgoing && blackHole.Load() && len(b) > blackHoleSize
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		defer proxy.Close()
+
+		stop := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			conn, err := server.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			str, err := conn.OpenUniStream()
+			Expect(err).ToNot(HaveOccurred())
+			defer str.Close()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				_, err := str.Write(PRData)
+				Expect(err).ToNot(HaveOccurred())
+			}
+		}()
+
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", proxy.LocalPort()),
+			getTLSClientConfig(),
+			getQuicConfig(nil),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		str, err := conn.AcceptUniStream(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			defer close(done)
+			_, err := io.Copy(io.Discard, str)
+			Expect(err).ToNot(HaveOccurred())
+		}()
+
+		// wait until Path MTU Discovery found an MTU larger than the black hole size
+		Eventually(mtus, 5*time.Second).Should(Receive(BeNumerically(">", blackHoleSize)))
+		blackHole.Store(true)
+		Eventually(mtus, 5*time.Second).Should(Receive(BeNumerically("<=", blackHoleSize)))
+		// the connection continues to work
+		time.Sleep(200 * time.Millisecond)
+		close(stop)
+		Eventually(done, 5*time.Second).Should(BeClosed())
+	})
+})
@@ -0,0 +1,85 @@
+package self_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	mrand "math/rand"
+	"net"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	quicproxy "github.com/quic-go/quic-go/integrationtests/tools/proxy"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Reliable stream reset", func() {
+	It("delivers the data below the reliable size", func() {
+		const (
+			numStreams   = 20
+			reliableSize = 100
+		)
+
+		server, err := quic.ListenAddr(
+			"localhost:0",
+			getTLSConfig(),
+			getQuicConfig(&quic.Config{EnableResetStreamAt: true}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		proxy, err := quicproxy.NewQuicProxy("localhost:0", &quicproxy.Opts{
+			RemoteAddr: fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+			DelayPacket: func(quicproxy.Direction, []byte) time.Duration {
+				return 5 * time.Millisecond
+			},
+			// drop some packets, so that the data below the reliable size has to be retransmitted
+			DropPacket: func(dir quicproxy.Direction, _ []byte) bool {
+				return dir == quicproxy.DirectionIncoming && mrand.Intn(5) == 0
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		defer proxy.Close()
+
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			defer close(done)
+			conn, err := server.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			for i := 0; i < numStreams; i++ {
+				str, err := conn.AcceptUniStream(context.Background())
+				Expect(err).ToNot(HaveOccurred())
+				data, err := io.ReadAll(str)
+				Expect(err).To(Equal(&quic.StreamError{
+					StreamID:  str.StreamID(),
+					ErrorCode: 42,
+					Remote:    true,
+				}))
+				Expect(len(data)).To(BeNumerically(">=", reliableSize))
+				Expect(data).To(Equal(PRData[:len(data)]))
+			}
+		}()
+
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", proxy.LocalPort()),
+			getTLSClientConfig(),
+			getQuicConfig(nil),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.CloseWithError(0, "")
+		for i := 0; i < numStreams; i++ {
+			str, err := conn.OpenUniStreamSync(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			_, err = str.Write(PRData[:reliableSize])
+			Expect(err).ToNot(HaveOccurred())
+			_, err = str.Write(PRData[reliableSize : 10*reliableSize])
+			Expect(err).ToNot(HaveOccurred())
+			str.CancelWriteAt(42, reliableSize)
+		}
+		Eventually(done, 10*time.Second).Should(BeClosed())
+	})
+})
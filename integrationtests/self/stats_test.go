@@ -0,0 +1,97 @@
+package self_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	quicproxy "github.com/quic-go/quic-go/integrationtests/tools/proxy"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Statistics", func() {
+	It("reports connection and stream statistics", func() {
+		server, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(nil))
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		const rtt = 10 * time.Millisecond
+		var counter int
+		proxy, err := quicproxy.NewQuicProxy("localhost:0", &quicproxy.Opts{
+			RemoteAddr:  fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+			DelayPacket: func(quicproxy.Direction, []byte) time.Duration { return rtt / 2 },
+			DropPacket: func(dir quicproxy.Direction, _ []byte) bool {
+				if dir != quicproxy.DirectionOutgoing {
+					return false
+				}
+				counter++
+				// drop a few packets once the handshake is complete
+				return counter > 10 && counter%20 == 0
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		defer proxy.Close()
+
+		serverConnChan := make(chan quic.Connection, 1)
+		serverStreamDone := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			conn, err := server.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			serverConnChan <- conn
+			str, err := conn.OpenUniStream()
+			Expect(err).ToNot(HaveOccurred())
+			_, err = str.Write(PRData)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(str.Close()).To(Succeed())
+			Eventually(func() uint64 { return str.Stats().BytesAcked }).Should(BeEquivalentTo(len(PRData)))
+			stats := str.Stats()
+			Expect(stats.BytesWritten).To(BeEquivalentTo(len(PRData)))
+			Expect(stats.BytesSent).To(BeEquivalentTo(len(PRData)))
+			Expect(stats.BytesRetransmitted).ToNot(BeZero())
+			close(serverStreamDone)
+		}()
+
+		conn, err := quic.DialAddr(
+			context.Background(),
+			fmt.Sprintf("localhost:%d", proxy.LocalPort()),
+			getTLSClientConfig(),
+			getQuicConfig(nil),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		str, err := conn.AcceptUniStream(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		data, err := io.ReadAll(str)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal(PRData))
+		Expect(str.Stats().ReceiveWindow).ToNot(BeZero())
+		Eventually(serverStreamDone).Should(BeClosed())
+
+		var serverConn quic.Connection
+		Eventually(serverConnChan).Should(Receive(&serverConn))
+		clientStats := conn.Stats()
+		serverStats := serverConn.Stats()
+		Expect(clientStats.BytesReceived).To(BeNumerically(">", len(PRData)))
+		Expect(clientStats.PacketsReceived).To(BeNumerically(">", 10))
+		Expect(serverStats.BytesSent).To(BeNumerically(">", len(PRData)))
+		Expect(serverStats.PacketsSent).To(BeNumerically(">=", clientStats.PacketsReceived))
+		Expect(serverStats.PacketsReceived).To(BeNumerically("<=", clientStats.PacketsSent))
+		Expect(serverStats.PacketsLost).ToNot(BeZero())
+		Expect(serverStats.PacketsRetransmitted).ToNot(BeZero())
+		Expect(serverStats.MinRTT).To(BeNumerically(">=", rtt))
+		Expect(serverStats.SmoothedRTT).To(BeNumerically(">=", rtt))
+		Expect(serverStats.CongestionWindow).ToNot(BeZero())
+		Expect(serverStats.MTU).To(BeNumerically(">=", 1200))
+
+		Expect(conn.CloseWithError(0, "")).To(Succeed())
+		Eventually(serverConn.Context().Done()).Should(BeClosed())
+		// statistics are still available after the connection was closed
+		Expect(conn.Stats().PacketsReceived).To(BeNumerically(">=", clientStats.PacketsReceived))
+		Expect(serverConn.Stats().PacketsSent).To(BeNumerically(">=", serverStats.PacketsSent))
+	})
+})
@@ -1,12 +1,14 @@
 package self_test
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"fmt"
 	"io"
 	mrand "math/rand"
 	"net"
+	"path/filepath"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -523,6 +525,37 @@ var _ = Describe("0-RTT", func() {
 		Expect(zeroRTTPackets[0]).To(BeNumerically(">=", protocol.PacketNumber(5)))
 	})
 
+	It("uses 0-RTT with a session ticket restored from a file", func() {
+		filename := filepath.Join(GinkgoT().TempDir(), "sessions")
+		cache, err := quic.NewFileClientSessionCache(filename, 10, 0)
+		Expect(err).ToNot(HaveOccurred())
+		tlsConf := getTLSConfig()
+		clientConf := getTLSClientConfig()
+		clientConf.ClientSessionCache = cache
+		dialAndReceiveSessionTicket(tlsConf, nil, clientConf)
+		// the session ticket is written to the file in the background
+		cache.Flush()
+
+		// simulate a restart of the client
+		cache, err = quic.NewFileClientSessionCache(filename, 10, 0)
+		Expect(err).ToNot(HaveOccurred())
+		clientConf = getTLSClientConfig()
+		clientConf.ClientSessionCache = cache
+
+		ln, err := quic.ListenAddrEarly(
+			"localhost:0",
+			tlsConf,
+			getQuicConfig(&quic.Config{Allow0RTT: true}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+		proxy, num0RTTPackets := runCountingProxy(ln.Addr().(*net.UDPAddr).Port)
+		defer proxy.Close()
+
+		transfer0RTTData(ln, proxy.LocalPort(), protocol.DefaultConnectionIDLength, clientConf, getQuicConfig(nil), PRData)
+		Expect(num0RTTPackets.Load()).To(BeNumerically(">", 0))
+	})
+
 	It("doesn't use 0-RTT when Dial is used for the resumed connection", func() {
 		tlsConf := getTLSConfig()
 		clientConf := getTLSClientConfig()
@@ -1119,4 +1152,90 @@ var _ = Describe("0-RTT", func() {
 		Expect(num0RTT).ToNot(BeZero())
 		Expect(get0RTTPackets(counter.getRcvdLongHeaderPackets())).To(BeEmpty())
 	})
+
+	Context("sharing session ticket keys", func() {
+		secret := bytes.Repeat([]byte{0x42}, 32)
+
+		listen := func(conf *quic.SessionTicketKeyConfig) (*quic.EarlyListener, func()) {
+			m, err := quic.NewSessionTicketKeyManager(conf)
+			Expect(err).ToNot(HaveOccurred())
+			udpConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+			Expect(err).ToNot(HaveOccurred())
+			tr := &quic.Transport{Conn: udpConn, SessionTicketKeys: m}
+			ln, err := tr.ListenEarly(getTLSConfig(), getQuicConfig(&quic.Config{Allow0RTT: true}))
+			Expect(err).ToNot(HaveOccurred())
+			return ln, func() {
+				ln.Close()
+				tr.Close()
+				udpConn.Close()
+			}
+		}
+
+		// dial dials a connection and waits until the handshake completes.
+		// It returns the connection state of the client and the server.
+		dial := func(ln *quic.EarlyListener, tlsConf *tls.Config) (client, server quic.ConnectionState) {
+			conn, err := quic.DialAddrEarly(
+				context.Background(),
+				fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
+				tlsConf,
+				getQuicConfig(nil),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			defer conn.CloseWithError(0, "")
+			sconn, err := ln.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(sconn.HandshakeComplete()).Should(BeClosed())
+			Eventually(conn.HandshakeComplete()).Should(BeClosed())
+			return conn.ConnectionState(), sconn.ConnectionState()
+		}
+
+		receiveSessionTicket := func(conf *quic.SessionTicketKeyConfig) *tls.Config {
+			ln, closeFn := listen(conf)
+			defer closeFn()
+			puts := make(chan string, 10)
+			tlsConf := getTLSClientConfig()
+			tlsConf.ClientSessionCache = newClientSessionCache(tls.NewLRUClientSessionCache(10), make(chan string, 10), puts)
+			dial(ln, tlsConf)
+			Eventually(puts).Should(Receive())
+			return tlsConf
+		}
+
+		It("uses 0-RTT with a session ticket issued by a different server", func() {
+			tlsConf := receiveSessionTicket(&quic.SessionTicketKeyConfig{Secret: secret})
+
+			ln, closeFn := listen(&quic.SessionTicketKeyConfig{Secret: secret})
+			defer closeFn()
+			client, server := dial(ln, tlsConf)
+			Expect(server.TLS.DidResume).To(BeTrue())
+			Expect(server.Used0RTT).To(BeTrue())
+			Expect(client.Used0RTT).To(BeTrue())
+		})
+
+		It("doesn't resume sessions if the servers use different keys", func() {
+			tlsConf := receiveSessionTicket(&quic.SessionTicketKeyConfig{Secret: secret})
+
+			ln, closeFn := listen(nil)
+			defer closeFn()
+			client, server := dial(ln, tlsConf)
+			Expect(server.TLS.DidResume).To(BeFalse())
+			Expect(client.Used0RTT).To(BeFalse())
+		})
+
+		It("rejects 0-RTT if the session ticket key is too old", func() {
+			conf := &quic.SessionTicketKeyConfig{
+				Keys: func() []quic.SessionTicketKey {
+					return []quic.SessionTicketKey{{Key: [32]byte{1, 2, 3}, Created: time.Now().Add(-2 * time.Minute)}}
+				},
+				Max0RTTKeyAge: time.Minute,
+			}
+			tlsConf := receiveSessionTicket(conf)
+
+			ln, closeFn := listen(conf)
+			defer closeFn()
+			client, server := dial(ln, tlsConf)
+			Expect(server.TLS.DidResume).To(BeTrue())
+			Expect(server.Used0RTT).To(BeFalse())
+			Expect(client.Used0RTT).To(BeFalse())
+		})
+	})
 })
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"sync"
 	"time"
 
 	"github.com/quic-go/quic-go"
@@ -150,6 +151,69 @@ var _ = Describe("Handshake tests", func() {
 			Expect(serverResult.clientVersions).To(BeEmpty())
 		})
 
+		It("upgrades to a compatible version without a Version Negotiation packet", func() {
+			// Both endpoints first log the version of the client's first Initial, and then the negotiated version.
+			var mx sync.Mutex
+			var clientVersions, serverVersions []protocol.Version
+			var receivedVersionNegotiation bool
+			newTracer := func(versions *[]protocol.Version) *logging.ConnectionTracer {
+				return &logging.ConnectionTracer{
+					NegotiatedVersion: func(chosen logging.VersionNumber, _, _ []logging.VersionNumber) {
+						mx.Lock()
+						defer mx.Unlock()
+						*versions = append(*versions, chosen)
+					},
+					ReceivedVersionNegotiationPacket: func(_, _ logging.ArbitraryLenConnectionID, _ []logging.VersionNumber) {
+						mx.Lock()
+						defer mx.Unlock()
+						receivedVersionNegotiation = true
+					},
+				}
+			}
+			serverConfig := &quic.Config{
+				Versions: []protocol.Version{protocol.Version2, protocol.Version1},
+				Tracer: func(context.Context, logging.Perspective, quic.ConnectionID) *logging.ConnectionTracer {
+					return newTracer(&serverVersions)
+				},
+			}
+			server, cl := startServer(getTLSConfig(), serverConfig)
+			defer cl()
+			conn, err := quic.DialAddr(
+				context.Background(),
+				fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+				getTLSClientConfig(),
+				maybeAddQLOGTracer(&quic.Config{
+					Versions: []protocol.Version{protocol.Version1, protocol.Version2},
+					Tracer: func(context.Context, logging.Perspective, quic.ConnectionID) *logging.ConnectionTracer {
+						return newTracer(&clientVersions)
+					},
+				}),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(conn.(versioner).GetVersion()).To(Equal(protocol.Version2))
+			Expect(conn.ConnectionState().Version).To(Equal(protocol.Version2))
+			Expect(conn.CloseWithError(0, "")).To(Succeed())
+			mx.Lock()
+			defer mx.Unlock()
+			Expect(receivedVersionNegotiation).To(BeFalse())
+			Expect(clientVersions).To(Equal([]protocol.Version{protocol.Version1, protocol.Version2}))
+			Expect(serverVersions).To(Equal([]protocol.Version{protocol.Version1, protocol.Version2}))
+		})
+
+		It("doesn't upgrade if the client doesn't support the server's preferred version", func() {
+			server, cl := startServer(getTLSConfig(), &quic.Config{Versions: []protocol.Version{protocol.Version2, protocol.Version1}})
+			defer cl()
+			conn, err := quic.DialAddr(
+				context.Background(),
+				fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+				getTLSClientConfig(),
+				maybeAddQLOGTracer(&quic.Config{Versions: []protocol.Version{protocol.Version1}}),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(conn.(versioner).GetVersion()).To(Equal(protocol.Version1))
+			Expect(conn.CloseWithError(0, "")).To(Succeed())
+		})
+
 		It("fails if the server disables version negotiation", func() {
 			// The server doesn't support the highest supported version, which is the first one the client will try,
 			// but it supports a bunch of versions that the client doesn't speak
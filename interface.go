@@ -4,8 +4,10 @@ import (
 	"context"
 	"crypto/tls"
 	"errors"
+	"fmt"
 	"io"
 	"net"
+	"net/netip"
 	"time"
 
 	"github.com/quic-go/quic-go/internal/handshake"
@@ -100,6 +102,9 @@ type ReceiveStream interface {
 	// A zero value for t means Read will not time out.
 
 	SetReadDeadline(t time.Time) error
+	// Stats returns statistics about the stream.
+	// Only the fields concerning the receive direction are set.
+	Stats() StreamStats
 }
 
 // A SendStream is a unidirectional Send Stream.
@@ -124,6 +129,14 @@ type SendStream interface {
 	// Write will unblock immediately, and future calls to Write will fail.
 	// When called multiple times or after closing the stream it is a no-op.
 	CancelWrite(StreamErrorCode)
+	// CancelWriteAt aborts sending on this stream, but keeps retransmitting the first reliableSize bytes
+	// until they are delivered (draft-ietf-quic-reliable-stream-reset).
+	// The peer's Read only returns the reset error after it has consumed these bytes.
+	// Write will unblock immediately. Data of a blocked Write call that wasn't sent yet is not delivered,
+	// and reliableSize is reduced accordingly.
+	// If the peer doesn't support reliable stream resets, this behaves like CancelWrite.
+	// When called multiple times or after closing the stream it is a no-op.
+	CancelWriteAt(code StreamErrorCode, reliableSize uint64)
 	// The Context is canceled as soon as the write-side of the stream is closed.
 	// This happens when Close() or CancelWrite() is called, or when the peer
 	// cancels the read-side of their stream.
@@ -136,6 +149,41 @@ type SendStream interface {
 	// some data was successfully written.
 	// A zero value for t means Write will not time out.
 	SetWriteDeadline(t time.Time) error
+	// SetDeliveryDeadline limits the time data written to the stream remains useful.
+	// Data written after this call that hasn't been acknowledged by the peer within d
+	// is discarded, both from the send buffers and from the retransmission queue,
+	// and the stream is reset with the given error code.
+	// A zero value for d means that data written afterwards never expires.
+	SetDeliveryDeadline(d time.Duration, code StreamErrorCode)
+	// Stats returns statistics about the stream.
+	// Only the fields concerning the send direction are set.
+	Stats() StreamStats
+}
+
+// StreamStats contains statistics about a stream.
+// For bidirectional streams, the fields concerning both directions are set.
+type StreamStats struct {
+	// BytesWritten is the number of bytes passed to Write.
+	BytesWritten uint64
+	// BytesSent is the number of bytes sent for the first time.
+	BytesSent uint64
+	// BytesAcked is the number of bytes acknowledged by the peer.
+	BytesAcked uint64
+	// BytesRetransmitted is the number of bytes retransmitted, after the packet containing them was declared lost.
+	BytesRetransmitted uint64
+	// StreamFlowControlBlockedTime is the total amount of time that sending was blocked by stream-level flow control.
+	StreamFlowControlBlockedTime time.Duration
+	// ConnectionFlowControlBlockedTime is the total amount of time that sending on this stream
+	// was blocked by connection-level flow control.
+	ConnectionFlowControlBlockedTime time.Duration
+	// SendWindow is the number of bytes that can currently be sent, as limited by stream-level and connection-level flow control.
+	SendWindow uint64
+
+	// FramesReceivedOutOfOrder is the number of STREAM frames received that didn't start
+	// at the lowest offset not yet received.
+	FramesReceivedOutOfOrder uint64
+	// ReceiveWindow is the number of bytes the peer is currently allowed to send.
+	ReceiveWindow uint64
 }
 
 // A Connection is a QUIC connection between two peers.
@@ -181,6 +229,13 @@ type Connection interface {
 	LocalAddr() net.Addr
 	// RemoteAddr returns the address of the peer.
 	RemoteAddr() net.Addr
+	// Migrate migrates the connection to a new local address, using conn to send and receive packets.
+	// It validates the new path and only switches to it once the peer has responded.
+	// Migration is only possible for clients, after the handshake is confirmed,
+	// and if the peer didn't disable active migration.
+	// The connection takes ownership of conn: it is closed when path validation fails,
+	// or when the connection is closed.
+	Migrate(conn net.PacketConn) error
 	// CloseWithError closes the connection with an error.
 	// The error string will be sent to the peer.
 	CloseWithError(ApplicationErrorCode, string) error
@@ -191,20 +246,103 @@ type Connection interface {
 	// ConnectionState returns basic details about the QUIC connection.
 	// Warning: This API should not be considered stable and might change soon.
 	ConnectionState() ConnectionState
+	// Stats returns statistics about the QUIC connection.
+	// After the connection is closed, it returns the statistics at the time the connection was closed.
+	Stats() ConnectionStats
 
 	// SendDatagram sends a message using a QUIC datagram, as specified in RFC 9221.
 	// There is no delivery guarantee for DATAGRAM frames, they are not retransmitted if lost.
 	// The payload of the datagram needs to fit into a single QUIC packet.
 	// In addition, a datagram may be dropped before being sent out if the available packet size suddenly decreases.
 	// If the payload is too large to be sent at the current time, a DatagramTooLargeError is returned.
+	// If the send queue is full, the behavior depends on Config.DatagramSendQueuePolicy.
 	SendDatagram(payload []byte) error
+	// SendDatagramWithCallback sends a message using a QUIC datagram, just like SendDatagram.
+	// The callback is called once the fate of the datagram is known: when the packet containing it is acknowledged
+	// or declared lost, or when the datagram is dropped without being sent.
+	// If the connection is closed while the datagram is in flight, the callback is not called.
+	// The callback is usually called from the connection's run loop.
+	// However, if the datagram is dropped because the send queue is full (see Config.DatagramSendQueuePolicy),
+	// the callback is called synchronously on the calling goroutine, before SendDatagramWithCallback returns.
+	// In both cases, the callback must not block.
+	SendDatagramWithCallback(payload []byte, cb func(DatagramState)) error
 	// ReceiveDatagram gets a message received in a datagram, as specified in RFC 9221.
 	ReceiveDatagram(context.Context) ([]byte, error)
+	// ReceiveDatagrams gets multiple messages received in datagrams at once.
+	// The messages are copied into the buffers in bufs: bufs[i] is set to append(bufs[i][:0], message...).
+	// When reusing buffers of sufficient capacity, no allocations are necessary.
+	// It blocks until at least one message was received, and returns the number of messages.
+	ReceiveDatagrams(ctx context.Context, bufs [][]byte) (int, error)
 
 	// prioritize the stream in the streambalancer
 	PrioritizeStream(protocol.StreamID) error
 }
 
+// DatagramState is the fate of a datagram sent using SendDatagramWithCallback.
+type DatagramState uint8
+
+const (
+	// DatagramAcked means that the packet containing the datagram was acknowledged.
+	DatagramAcked DatagramState = iota + 1
+	// DatagramLost means that the packet containing the datagram was declared lost.
+	// The datagram is not retransmitted.
+	DatagramLost
+	// DatagramDropped means that the datagram was never sent,
+	// either because it didn't fit into a packet, or because the connection was closed.
+	DatagramDropped
+)
+
+func (s DatagramState) String() string {
+	switch s {
+	case DatagramAcked:
+		return "acknowledged"
+	case DatagramLost:
+		return "lost"
+	case DatagramDropped:
+		return "dropped"
+	default:
+		return fmt.Sprintf("unknown datagram state: %d", s)
+	}
+}
+
+// DatagramQueuePolicy determines what happens when a datagram is added to a full queue.
+type DatagramQueuePolicy uint8
+
+const (
+	// DatagramQueueDefault selects the default policy of the queue:
+	// DatagramQueueBlock for the send queue, and DatagramQueueDropNewest for the receive queue.
+	DatagramQueueDefault DatagramQueuePolicy = iota
+	// DatagramQueueBlock blocks until there's space in the queue.
+	// Only valid for the send queue.
+	DatagramQueueBlock
+	// DatagramQueueDropNewest drops the datagram that is added to the queue.
+	DatagramQueueDropNewest
+	// DatagramQueueDropOldest drops the datagram that has been queued the longest.
+	// A datagram that is currently being packed into a packet is not dropped.
+	// If it is the only queued datagram, the datagram that is added to the queue is dropped.
+	DatagramQueueDropOldest
+	// DatagramQueueError returns an ErrDatagramQueueFull from SendDatagram.
+	// Only valid for the send queue.
+	DatagramQueueError
+)
+
+func (p DatagramQueuePolicy) String() string {
+	switch p {
+	case DatagramQueueDefault:
+		return "default"
+	case DatagramQueueBlock:
+		return "block"
+	case DatagramQueueDropNewest:
+		return "drop newest"
+	case DatagramQueueDropOldest:
+		return "drop oldest"
+	case DatagramQueueError:
+		return "error"
+	default:
+		return fmt.Sprintf("unknown datagram queue policy: %d", p)
+	}
+}
+
 // An EarlyConnection is a connection that is handshaking.
 // Data sent during the handshake is encrypted using the forward secure keys.
 // When using client certificates, the client's identity is only verified
@@ -321,20 +459,103 @@ type Config struct {
 	// If set to 0, then no keep alive is sent. Otherwise, the keep alive is sent on that period (or at most
 	// every half of MaxIdleTimeout, whichever is smaller).
 	KeepAlivePeriod time.Duration
+	// ConnectionIDRotationInterval is the interval at which connection IDs are rotated,
+	// making it harder for on-path observers to link packets of long-lived connections.
+	// When it passes, a new connection ID is used for sending packets to the peer,
+	// and all connection IDs issued to the peer are replaced with new ones.
+	// If zero, connection IDs are not rotated periodically.
+	ConnectionIDRotationInterval time.Duration
+	// ConnectionIDRotationPackets is the number of packets sent after which connection IDs are rotated.
+	// The connection ID used for sending packets to the peer is changed after on average this number of packets.
+	// If set, all connection IDs issued to the peer are replaced after sending this number of packets.
+	// If zero, a new connection ID is used after on average 10000 packets, and issued connection IDs are not replaced.
+	ConnectionIDRotationPackets uint32
+	// ConnectionIDRotationIdlePeriod is the duration without sending any packets after which
+	// a new connection ID is used for sending packets to the peer.
+	// If zero, the connection ID is not changed after idle periods.
+	ConnectionIDRotationIdlePeriod time.Duration
 	// DisablePathMTUDiscovery disables Path MTU Discovery (RFC 8899).
 	// This allows the sending of QUIC packets that fully utilize the available MTU of the path.
 	// Path MTU discovery is only available on systems that allow setting of the Don't Fragment (DF) bit.
 	// If unavailable or disabled, packets will be at most 1252 (IPv4) / 1232 (IPv6) bytes in size.
 	DisablePathMTUDiscovery bool
+	// MaxPathMTU is the largest UDP payload size (in bytes) that Path MTU Discovery searches for.
+	// If not set, it defaults to 1452 bytes. Larger values are clipped to that value.
+	MaxPathMTU uint16
 	// Allow0RTT allows the application to decide if a 0-RTT connection attempt should be accepted.
 	// Only valid for the server.
 	Allow0RTT bool
 	// Enable QUIC datagram support (RFC 9221).
-	EnableDatagrams     bool
+	EnableDatagrams bool
+	// DatagramSendQueueLen is the maximum number of datagrams queued for sending.
+	// If not set, it defaults to 32.
+	DatagramSendQueueLen int
+	// DatagramSendQueuePolicy determines what happens when a datagram is sent while the send queue is full.
+	// By default, SendDatagram blocks until there's space in the queue.
+	DatagramSendQueuePolicy DatagramQueuePolicy
+	// DatagramReceiveQueueLen is the maximum number of received datagrams queued until read by the application.
+	// If not set, it defaults to 128.
+	DatagramReceiveQueueLen int
+	// DatagramReceiveQueuePolicy determines which datagram is dropped when a datagram is received while the receive queue is full.
+	// Received datagrams can't be blocked, therefore only DatagramQueueDropNewest and DatagramQueueDropOldest can be set.
+	// By default, the newly received datagram is dropped.
+	DatagramReceiveQueuePolicy DatagramQueuePolicy
+	// DatagramMaxAge is the maximum amount of time that a datagram is queued for sending.
+	// Datagrams that couldn't be sent in time are dropped.
+	// If not set, datagrams are queued until they are sent.
+	DatagramMaxAge time.Duration
+	// PreferredAddress is the server's preferred address (see section 9.6 of RFC 9000).
+	// It is sent to the client in the preferred_address transport parameter.
+	// Once the handshake is confirmed, the client validates the path to the preferred address and migrates the connection.
+	// Packets sent to the preferred address need to be received by the same Transport,
+	// e.g. by listening on the unspecified address.
+	// A preferred address can't be used with zero-length connection IDs.
+	// The connection ID for the preferred address is taken from the ConnectionIDGenerator.
+	// Only valid for the server.
+	PreferredAddress *PreferredAddress
+	// AllowActiveMigration allows clients to migrate the connection to a new address (see Connection.Migrate).
+	// If not set, the server sends the disable_active_migration transport parameter (see section 18.2 of RFC 9000).
+	// Changes of the client's address caused by NAT rebindings are handled in both cases.
+	// Only valid for the server.
+	AllowActiveMigration bool
+	// CongestionController creates the congestion controller of the connection.
+	// It is called with the RTT statistics, the initial maximum packet size and the connection tracer.
+	// The tracer is nil if no tracer is configured.
+	// NewRenoCongestionController, NewCubicCongestionController, NewBBRCongestionController and NewPragueCongestionController
+	// can be used to select one of the built-in algorithms.
+	// NewRenoCongestionControllerWithHyStartPlusPlus and NewCubicCongestionControllerWithHyStartPlusPlus
+	// use HyStart++ (RFC 9406) to exit slow start.
+	// If nil, NewRenoCongestionController is used, unless EnableL4S is set.
+	CongestionController func(rttStats *logging.RTTStats, initialMaxDatagramSize logging.ByteCount, tracer *logging.ConnectionTracer) CongestionController
+	// EnableL4S enables Low Latency, Low Loss, and Scalable throughput (L4S, RFC 9330).
+	// Packets are sent with the ECT(1) codepoint, and the Prague congestion controller is used,
+	// which reduces the congestion window in proportion to the fraction of CE-marked packets.
+	// It has no effect if a CongestionController is configured.
+	EnableL4S bool
+	// EnableAckFrequency enables the ACK frequency extension (draft-ietf-quic-ack-frequency).
+	// The peer can then ask us to acknowledge packets less frequently.
+	// If the peer supports the extension as well, we ask it to send fewer ACKs when the congestion window is large,
+	// which reduces the CPU cost of processing ACKs on high-throughput connections.
+	EnableAckFrequency bool
+	// EnableResetStreamAt enables the reliable stream reset extension (draft-ietf-quic-reliable-stream-reset).
+	// The peer can then reset streams using SendStream.CancelWriteAt.
+	EnableResetStreamAt bool
 	Tracer              func(context.Context, logging.Perspective, ConnectionID) *logging.ConnectionTracer
 	Tracer_and_Balancer func(context.Context, logging.Perspective, ConnectionID) (*logging.ConnectionTracer, *streamtypebalancer.Balancer)
 }
 
+// PreferredAddress is the server's preferred address.
+// At least one of IPv4 and IPv6 needs to be set.
+// The connection ID sent along with the address can't be configured:
+// it is generated by the ConnectionIDGenerator, like all other connection IDs of the connection,
+// and uses sequence number 1 (see section 5.1.1 of RFC 9000).
+type PreferredAddress struct {
+	// IPv4 is the IPv4 address and port of the preferred address.
+	IPv4 netip.AddrPort
+	// IPv6 is the IPv6 address and port of the preferred address.
+	IPv6 netip.AddrPort
+}
+
 type ClientHelloInfo struct {
 	RemoteAddr net.Addr
 }
@@ -355,3 +576,45 @@ type ConnectionState struct {
 	// GSO says if generic segmentation offload is used
 	GSO bool
 }
+
+// ConnectionStats contains statistics about a QUIC connection.
+type ConnectionStats struct {
+	// MinRTT is the minimum RTT observed on the connection.
+	MinRTT time.Duration
+	// LatestRTT is the most recent RTT sample.
+	LatestRTT time.Duration
+	// SmoothedRTT is the smoothed RTT, as defined in RFC 9002.
+	SmoothedRTT time.Duration
+	// MeanDeviation is the RTT variance, as defined in RFC 9002.
+	MeanDeviation time.Duration
+
+	// CongestionWindow is the current congestion window, in bytes.
+	CongestionWindow uint64
+	// BytesInFlight is the number of bytes sent in ack-eliciting packets that are neither acknowledged nor declared lost.
+	BytesInFlight uint64
+
+	PacketsSent     uint64
+	BytesSent       uint64
+	PacketsReceived uint64
+	BytesReceived   uint64
+	PacketsLost     uint64
+	BytesLost       uint64
+	// PacketsRetransmitted is the number of packets whose frames were retransmitted,
+	// either because the packet was declared lost, or because it was sent as a probe packet.
+	// Path MTU probe packets and path probe packets are not counted.
+	PacketsRetransmitted uint64
+	BytesRetransmitted   uint64
+
+	// PTOCount is the number of consecutive Probe Timeouts (PTOs), as defined in RFC 9002.
+	// It is reset when an acknowledgement is received.
+	PTOCount uint32
+	// MTU is the maximum size of a QUIC packet sent on the connection.
+	MTU uint64
+	// ECNState is the state of ECN validation.
+	// It is 0 if ECN is not used on the connection.
+	ECNState logging.ECNState
+	// KeyUpdates is the number of 1-RTT key updates, initiated by either endpoint.
+	KeyUpdates uint64
+	// FlowControlBlockedTime is the total amount of time that sending was blocked by connection-level flow control.
+	FlowControlBlockedTime time.Duration
+}
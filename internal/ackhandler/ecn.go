@@ -26,6 +26,7 @@ type ecnHandler interface {
 	Mode() protocol.ECN
 	HandleNewlyAcked(packets []*packet, ect0, ect1, ecnce int64) (congested bool)
 	LostPacket(protocol.PacketNumber)
+	State() logging.ECNState
 }
 
 // The ecnTracker performs ECN validation of a path.
@@ -45,14 +46,18 @@ type ecnTracker struct {
 	numSentECT0, numSentECT1                  int64
 	numAckedECT0, numAckedECT1, numAckedECNCE int64
 
+	// The ECT codepoint used: ECT(1) for L4S, ECT(0) otherwise.
+	ect protocol.ECN
+
 	tracer *logging.ConnectionTracer
 	logger utils.Logger
 }
 
 var _ ecnHandler = &ecnTracker{}
 
-func newECNTracker(logger utils.Logger, tracer *logging.ConnectionTracer) *ecnTracker {
+func newECNTracker(logger utils.Logger, tracer *logging.ConnectionTracer, ect protocol.ECN) *ecnTracker {
 	return &ecnTracker{
+		ect:                ect,
 		firstTestingPacket: protocol.InvalidPacketNumber,
 		lastTestingPacket:  protocol.InvalidPacketNumber,
 		firstCapablePacket: protocol.InvalidPacketNumber,
@@ -109,7 +114,7 @@ func (e *ecnTracker) Mode() protocol.ECN {
 		e.state = ecnStateTesting
 		return e.Mode()
 	case ecnStateTesting, ecnStateCapable:
-		return protocol.ECT0
+		return e.ect
 	case ecnStateUnknown, ecnStateFailed:
 		return protocol.ECNNon
 	default:
@@ -117,6 +122,23 @@ func (e *ecnTracker) Mode() protocol.ECN {
 	}
 }
 
+// State returns the state of the ECN validation.
+// It returns 0 if ECN validation hasn't started yet.
+func (e *ecnTracker) State() logging.ECNState {
+	switch e.state {
+	case ecnStateTesting:
+		return logging.ECNStateTesting
+	case ecnStateUnknown:
+		return logging.ECNStateUnknown
+	case ecnStateCapable:
+		return logging.ECNStateCapable
+	case ecnStateFailed:
+		return logging.ECNStateFailed
+	default:
+		return 0
+	}
+}
+
 func (e *ecnTracker) LostPacket(pn protocol.PacketNumber) {
 	if e.state != ecnStateTesting && e.state != ecnStateUnknown {
 		return
@@ -278,14 +300,14 @@ func (e *ecnTracker) ecnMarking(pn protocol.PacketNumber) protocol.ECN {
 		return protocol.ECNNon
 	}
 	if pn < e.lastTestingPacket || e.lastTestingPacket == protocol.InvalidPacketNumber {
-		return protocol.ECT0
+		return e.ect
 	}
 	if pn < e.firstCapablePacket || e.firstCapablePacket == protocol.InvalidPacketNumber {
 		return protocol.ECNNon
 	}
 	// We don't need to deal with the case when ECN validation fails,
 	// since we're ignoring any ECN counts reported in ACK frames in that case.
-	return protocol.ECT0
+	return e.ect
 }
 
 func (e *ecnTracker) isTestingPacket(pn protocol.PacketNumber) bool {
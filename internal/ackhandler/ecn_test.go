@@ -25,10 +25,11 @@ var _ = Describe("ECN tracker", func() {
 	BeforeEach(func() {
 		var tr *logging.ConnectionTracer
 		tr, tracer = mocklogging.NewMockConnectionTracer(mockCtrl)
-		ecnTracker = newECNTracker(utils.DefaultLogger, tr)
+		ecnTracker = newECNTracker(utils.DefaultLogger, tr, protocol.ECT0)
 	})
 
 	It("sends exactly 10 testing packets", func() {
+		Expect(ecnTracker.State()).To(BeZero())
 		tracer.EXPECT().ECNStateUpdated(logging.ECNStateTesting, logging.ECNTriggerNoTrigger)
 		for i := 0; i < 9; i++ {
 			Expect(ecnTracker.Mode()).To(Equal(protocol.ECT0))
@@ -36,9 +37,11 @@ var _ = Describe("ECN tracker", func() {
 			Expect(ecnTracker.Mode()).To(Equal(protocol.ECT0))
 			ecnTracker.SentPacket(protocol.PacketNumber(10+i), protocol.ECT0)
 		}
+		Expect(ecnTracker.State()).To(Equal(logging.ECNStateTesting))
 		Expect(ecnTracker.Mode()).To(Equal(protocol.ECT0))
 		tracer.EXPECT().ECNStateUpdated(logging.ECNStateUnknown, logging.ECNTriggerNoTrigger)
 		ecnTracker.SentPacket(20, protocol.ECT0)
+		Expect(ecnTracker.State()).To(Equal(logging.ECNStateUnknown))
 		// In unknown state, packets shouldn't be ECN-marked.
 		Expect(ecnTracker.Mode()).To(Equal(protocol.ECNNon))
 	})
@@ -67,6 +70,7 @@ var _ = Describe("ECN tracker", func() {
 		tracer.EXPECT().ECNStateUpdated(logging.ECNStateFailed, logging.ECNFailedLostAllTestingPackets)
 		ecnTracker.LostPacket(9)
 		Expect(ecnTracker.Mode()).To(Equal(protocol.ECNNon))
+		Expect(ecnTracker.State()).To(Equal(logging.ECNStateFailed))
 		// We still don't care about more non-testing packets being lost
 		ecnTracker.LostPacket(16)
 	})
@@ -95,6 +99,7 @@ var _ = Describe("ECN tracker", func() {
 		}
 		tracer.EXPECT().ECNStateUpdated(logging.ECNStateCapable, logging.ECNTriggerNoTrigger)
 		Expect(ecnTracker.HandleNewlyAcked(getAckedPackets(3), 1, 0, 0)).To(BeFalse())
+		Expect(ecnTracker.State()).To(Equal(logging.ECNStateCapable))
 		// make sure we continue sending ECT(0) packets
 		for i := 5; i < 100; i++ {
 			Expect(ecnTracker.Mode()).To(Equal(protocol.ECT0))
@@ -269,4 +274,18 @@ var _ = Describe("ECN tracker", func() {
 		// Increase in CE. More congestion.
 		Expect(ecnTracker.HandleNewlyAcked(getAckedPackets(7, 8, 9, 14), 7, 0, 2)).To(BeTrue())
 	})
+
+	It("uses ECT(1) for L4S", func() {
+		ecnTracker = newECNTracker(utils.DefaultLogger, ecnTracker.tracer, protocol.ECT1)
+		tracer.EXPECT().ECNStateUpdated(logging.ECNStateTesting, logging.ECNTriggerNoTrigger)
+		tracer.EXPECT().ECNStateUpdated(logging.ECNStateUnknown, logging.ECNTriggerNoTrigger)
+		for i := 0; i < 10; i++ {
+			Expect(ecnTracker.Mode()).To(Equal(protocol.ECT1))
+			ecnTracker.SentPacket(protocol.PacketNumber(i), protocol.ECT1)
+		}
+		// 2 packets arrived with ECT(1), one of them was CE-marked
+		tracer.EXPECT().ECNStateUpdated(logging.ECNStateCapable, logging.ECNTriggerNoTrigger)
+		Expect(ecnTracker.HandleNewlyAcked(getAckedPackets(0, 1, 2), 0, 2, 1)).To(BeTrue())
+		Expect(ecnTracker.Mode()).To(Equal(protocol.ECT1))
+	})
 })
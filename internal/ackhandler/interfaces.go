@@ -11,6 +11,9 @@ import (
 type SentPacketHandler interface {
 	// SentPacket may modify the packet
 	SentPacket(t time.Time, pn, largestAcked protocol.PacketNumber, streamFrames []StreamFrame, frames []Frame, encLevel protocol.EncryptionLevel, ecn protocol.ECN, size protocol.ByteCount, isPathMTUProbePacket bool)
+	// SentPathProbePacket is called for 1-RTT packets containing a PATH_CHALLENGE sent on a path that is being validated.
+	// These packets are not congestion controlled.
+	SentPathProbePacket(t time.Time, pn protocol.PacketNumber, frames []Frame, size protocol.ByteCount)
 	// ReceivedAck processes an ACK frame.
 	// It does not store a copy of the frame.
 	ReceivedAck(f *wire.AckFrame, encLevel protocol.EncryptionLevel, rcvTime time.Time) (bool /* 1-RTT packet acked */, error)
@@ -18,6 +21,16 @@ type SentPacketHandler interface {
 	DropPackets(protocol.EncryptionLevel)
 	ResetForRetry(rcvTime time.Time) error
 	SetHandshakeConfirmed()
+	// MigratedPath is called when the connection migrated to a new path.
+	MigratedPath()
+	// PeerAddressChanged is called by the server when a packet was received from a new client address.
+	// Until the new address is validated, the amplification limit applies to it.
+	PeerAddressChanged(bytesReceived protocol.ByteCount)
+	// PeerAddressValidated is called by the server once the client's (new) address was validated.
+	PeerAddressValidated()
+
+	// Stats returns statistics about the packets sent on the connection.
+	Stats() SentPacketStats
 
 	// The SendMode determines if and what kind of packets can be sent.
 	SendMode(now time.Time) SendMode
@@ -25,6 +38,10 @@ type SentPacketHandler interface {
 	// It is used for pacing packets.
 	TimeUntilSend() time.Time
 	SetMaxDatagramSize(count protocol.ByteCount)
+	// GetCongestionWindow returns the current congestion window.
+	GetCongestionWindow() protocol.ByteCount
+	// SetPacketSizeObserver sets the observer that is informed about acknowledged and lost 1-RTT packets.
+	SetPacketSizeObserver(PacketSizeObserver)
 
 	// only to be called once the handshake is complete
 	QueueProbePacket(protocol.EncryptionLevel) bool /* was a packet queued */
@@ -37,6 +54,16 @@ type SentPacketHandler interface {
 	OnLossDetectionTimeout() error
 }
 
+// A PacketSizeObserver is informed about the fate of 1-RTT packets, excluding Path MTU probe packets.
+// Path MTU Discovery uses it to detect black holes.
+type PacketSizeObserver interface {
+	OnPacketAcked(size protocol.ByteCount)
+	OnPacketLost(size protocol.ByteCount)
+	// OnPTO is called when the PTO timer fires for 1-RTT packets.
+	// smallestSize is the size of the smallest packet in flight, or protocol.MaxByteCount if there is none.
+	OnPTO(smallestSize protocol.ByteCount)
+}
+
 type sentPacketTracker interface {
 	GetLowestPacketNotConfirmedAcked() protocol.PacketNumber
 	ReceivedPacket(protocol.EncryptionLevel)
@@ -46,6 +73,10 @@ type sentPacketTracker interface {
 type ReceivedPacketHandler interface {
 	IsPotentiallyDuplicate(protocol.PacketNumber, protocol.EncryptionLevel) bool
 	ReceivedPacket(pn protocol.PacketNumber, ecn protocol.ECN, encLevel protocol.EncryptionLevel, rcvTime time.Time, ackEliciting bool) error
+	// ReceivedAckFrequencyFrame applies the ACK frequency requested by the peer (ACK frequency extension).
+	ReceivedAckFrequencyFrame(*wire.AckFrequencyFrame)
+	// ReceivedImmediateAck makes sure that an ACK is sent right away.
+	ReceivedImmediateAck()
 	DropPackets(protocol.EncryptionLevel)
 
 	GetAlarmTimeout() time.Time
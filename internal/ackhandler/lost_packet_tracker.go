@@ -0,0 +1,71 @@
+package ackhandler
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/wire"
+)
+
+type lostPacket struct {
+	PacketNumber protocol.PacketNumber
+	SendTime     time.Time
+}
+
+// The lostPacketTracker keeps track of packets that were recently declared lost.
+// If one of these packets is acknowledged later, the loss was spurious.
+type lostPacketTracker struct {
+	maxLength int
+	packets   []lostPacket
+}
+
+func newLostPacketTracker(maxLength int) *lostPacketTracker {
+	return &lostPacketTracker{maxLength: maxLength}
+}
+
+// Add adds a lost packet.
+// If the maximum number of tracked packets is reached, the oldest packet is removed.
+func (t *lostPacketTracker) Add(pn protocol.PacketNumber, sendTime time.Time) {
+	if len(t.packets) >= t.maxLength {
+		copy(t.packets, t.packets[1:])
+		t.packets = t.packets[:len(t.packets)-1]
+	}
+	t.packets = append(t.packets, lostPacket{PacketNumber: pn, SendTime: sendTime})
+}
+
+// RemoveAcked removes all packets that are acknowledged by the ACK frame.
+// The callback is called for every removed packet.
+func (t *lostPacketTracker) RemoveAcked(ack *wire.AckFrame, cb func(lostPacket)) {
+	var n int
+	for _, p := range t.packets {
+		if ack.AcksPacket(p.PacketNumber) {
+			cb(p)
+			continue
+		}
+		t.packets[n] = p
+		n++
+	}
+	t.packets = t.packets[:n]
+}
+
+// DeleteBefore deletes all packets sent before the given time.
+func (t *lostPacketTracker) DeleteBefore(sendTime time.Time) {
+	var n int
+	for _, p := range t.packets {
+		if p.SendTime.Before(sendTime) {
+			continue
+		}
+		t.packets[n] = p
+		n++
+	}
+	t.packets = t.packets[:n]
+}
+
+// Reset removes all packets.
+func (t *lostPacketTracker) Reset() {
+	t.packets = t.packets[:0]
+}
+
+func (t *lostPacketTracker) Len() int {
+	return len(t.packets)
+}
@@ -0,0 +1,63 @@
+package ackhandler
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/wire"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Lost Packet Tracker", func() {
+	var tracker *lostPacketTracker
+
+	BeforeEach(func() {
+		tracker = newLostPacketTracker(5)
+	})
+
+	removeAcked := func(ack *wire.AckFrame) []protocol.PacketNumber {
+		var pns []protocol.PacketNumber
+		tracker.RemoveAcked(ack, func(p lostPacket) { pns = append(pns, p.PacketNumber) })
+		return pns
+	}
+
+	It("removes acknowledged packets", func() {
+		now := time.Now()
+		for i := protocol.PacketNumber(1); i <= 5; i++ {
+			tracker.Add(i, now)
+		}
+		Expect(removeAcked(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 4, Largest: 10}, {Smallest: 2, Largest: 2}}})).To(Equal([]protocol.PacketNumber{2, 4, 5}))
+		Expect(tracker.Len()).To(Equal(2))
+		Expect(removeAcked(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 1, Largest: 10}}})).To(Equal([]protocol.PacketNumber{1, 3}))
+		Expect(tracker.Len()).To(BeZero())
+	})
+
+	It("limits the number of tracked packets", func() {
+		now := time.Now()
+		for i := protocol.PacketNumber(1); i <= 8; i++ {
+			tracker.Add(i, now)
+		}
+		Expect(tracker.Len()).To(Equal(5))
+		Expect(removeAcked(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 1, Largest: 10}}})).To(Equal([]protocol.PacketNumber{4, 5, 6, 7, 8}))
+	})
+
+	It("deletes old packets", func() {
+		now := time.Now()
+		tracker.Add(1, now.Add(-2*time.Second))
+		tracker.Add(2, now)
+		tracker.Add(3, now.Add(-3*time.Second))
+		tracker.DeleteBefore(now.Add(-time.Second))
+		Expect(tracker.Len()).To(Equal(1))
+		var lost []lostPacket
+		tracker.RemoveAcked(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 1, Largest: 3}}}, func(p lostPacket) { lost = append(lost, p) })
+		Expect(lost).To(Equal([]lostPacket{{PacketNumber: 2, SendTime: now}}))
+	})
+
+	It("resets", func() {
+		tracker.Add(1, time.Now())
+		tracker.Reset()
+		Expect(tracker.Len()).To(BeZero())
+	})
+})
@@ -12,6 +12,7 @@ import (
 	reflect "reflect"
 
 	protocol "github.com/quic-go/quic-go/internal/protocol"
+	logging "github.com/quic-go/quic-go/logging"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -89,3 +90,17 @@ func (mr *MockECNHandlerMockRecorder) SentPacket(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SentPacket", reflect.TypeOf((*MockECNHandler)(nil).SentPacket), arg0, arg1)
 }
+
+// State mocks base method.
+func (m *MockECNHandler) State() logging.ECNState {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "State")
+	ret0, _ := ret[0].(logging.ECNState)
+	return ret0
+}
+
+// State indicates an expected call of State.
+func (mr *MockECNHandlerMockRecorder) State() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "State", reflect.TypeOf((*MockECNHandler)(nil).State))
+}
@@ -18,6 +18,10 @@ type packet struct {
 	EncryptionLevel protocol.EncryptionLevel
 
 	IsPathMTUProbePacket bool // We don't report the loss of Path MTU probe packets to the congestion controller.
+	// Path probe packets are sent on a path that is being validated.
+	// They are not congestion controlled, and they don't arm the PTO timer,
+	// since the connection retransmits PATH_CHALLENGE frames itself.
+	IsPathProbePacket bool
 
 	includedInBytesInFlight bool
 	declaredLost            bool
@@ -25,7 +29,7 @@ type packet struct {
 }
 
 func (p *packet) outstanding() bool {
-	return !p.declaredLost && !p.skippedPacket && !p.IsPathMTUProbePacket
+	return !p.declaredLost && !p.skippedPacket && !p.IsPathMTUProbePacket && !p.IsPathProbePacket
 }
 
 var packetPool = sync.Pool{New: func() any { return &packet{} }}
@@ -40,6 +44,7 @@ func getPacket() *packet {
 	p.EncryptionLevel = protocol.EncryptionLevel(0)
 	p.SendTime = time.Time{}
 	p.IsPathMTUProbePacket = false
+	p.IsPathProbePacket = false
 	p.includedInBytesInFlight = false
 	p.declaredLost = false
 	p.skippedPacket = false
@@ -68,6 +68,14 @@ func (h *receivedPacketHandler) ReceivedPacket(
 	}
 }
 
+func (h *receivedPacketHandler) ReceivedAckFrequencyFrame(f *wire.AckFrequencyFrame) {
+	h.appDataPackets.ReceivedAckFrequencyFrame(f)
+}
+
+func (h *receivedPacketHandler) ReceivedImmediateAck() {
+	h.appDataPackets.ReceivedImmediateAck()
+}
+
 func (h *receivedPacketHandler) DropPackets(encLevel protocol.EncryptionLevel) {
 	//nolint:exhaustive // 1-RTT packet number space is never dropped.
 	switch encLevel {
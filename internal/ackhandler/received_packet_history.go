@@ -135,6 +135,22 @@ func (h *receivedPacketHistory) GetHighestAckRange() wire.AckRange {
 	return ackRange
 }
 
+// SmallestMissingAbove returns the smallest packet number larger than p that hasn't been received,
+// but that is smaller than the largest packet number received.
+// It returns protocol.InvalidPacketNumber if there's no such packet number.
+func (h *receivedPacketHistory) SmallestMissingAbove(p protocol.PacketNumber) protocol.PacketNumber {
+	for el := h.ranges.Front(); el != nil; el = el.Next() {
+		next := el.Next()
+		if next == nil {
+			break
+		}
+		if next.Value.Start-1 > p {
+			return max(el.Value.End+1, p+1)
+		}
+	}
+	return protocol.InvalidPacketNumber
+}
+
 func (h *receivedPacketHistory) IsPotentiallyDuplicate(p protocol.PacketNumber) bool {
 	if p < h.deletedBelow {
 		return true
@@ -259,6 +259,26 @@ var _ = Describe("receivedPacketHistory", func() {
 		})
 	})
 
+	Context("finding missing packets", func() {
+		It("returns an invalid packet number if there are no missing packets", func() {
+			Expect(hist.SmallestMissingAbove(0)).To(Equal(protocol.InvalidPacketNumber))
+			Expect(hist.ReceivedPacket(4)).To(BeTrue())
+			Expect(hist.ReceivedPacket(5)).To(BeTrue())
+			Expect(hist.SmallestMissingAbove(0)).To(Equal(protocol.InvalidPacketNumber))
+		})
+
+		It("finds the smallest missing packet", func() {
+			for _, pn := range []protocol.PacketNumber{1, 2, 5, 6, 9} {
+				Expect(hist.ReceivedPacket(pn)).To(BeTrue())
+			}
+			Expect(hist.SmallestMissingAbove(0)).To(Equal(protocol.PacketNumber(3)))
+			Expect(hist.SmallestMissingAbove(3)).To(Equal(protocol.PacketNumber(4)))
+			Expect(hist.SmallestMissingAbove(4)).To(Equal(protocol.PacketNumber(7)))
+			Expect(hist.SmallestMissingAbove(7)).To(Equal(protocol.PacketNumber(8)))
+			Expect(hist.SmallestMissingAbove(8)).To(Equal(protocol.InvalidPacketNumber))
+		})
+	})
+
 	Context("duplicate detection", func() {
 		It("doesn't declare the first packet a duplicate", func() {
 			Expect(hist.IsPotentiallyDuplicate(5)).To(BeFalse())
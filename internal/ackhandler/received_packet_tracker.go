@@ -70,11 +70,16 @@ func (h *receivedPacketTracker) IsPotentiallyDuplicate(pn protocol.PacketNumber)
 	return h.packetHistory.IsPotentiallyDuplicate(pn)
 }
 
-// number of ack-eliciting packets received before sending an ACK
-const packetsBeforeAck = 2
+const (
+	// number of ack-eliciting packets that can be received without sending an ACK
+	defaultAckElicitingThreshold = 1
+	// number of out-of-order packets that trigger sending an ACK immediately
+	defaultReorderingThreshold = 1
+)
 
 // The appDataReceivedPacketTracker tracks packets received in the Application Data packet number space.
 // It waits until at least 2 packets were received before queueing an ACK, or until the max_ack_delay was reached.
+// The peer can change these values by sending ACK_FREQUENCY frames (draft-ietf-quic-ack-frequency).
 type appDataReceivedPacketTracker struct {
 	receivedPacketTracker
 
@@ -83,8 +88,15 @@ type appDataReceivedPacketTracker struct {
 	largestObserved protocol.PacketNumber
 	ignoreBelow     protocol.PacketNumber
 
-	maxAckDelay time.Duration
-	ackQueued   bool // true if we need send a new ACK
+	maxAckDelay           time.Duration
+	ackElicitingThreshold uint64
+	// A reordering threshold of 0 means that out-of-order packets don't trigger an immediate ACK.
+	reorderingThreshold protocol.PacketNumber
+	// the sequence number of the last ACK_FREQUENCY frame that was applied
+	ackFrequencySeqNum   uint64
+	receivedAckFrequency bool
+
+	ackQueued bool // true if we need send a new ACK
 
 	ackElicitingPacketsReceivedSinceLastAck int
 	ackAlarm                                time.Time
@@ -96,6 +108,8 @@ func newAppDataReceivedPacketTracker(logger utils.Logger) *appDataReceivedPacket
 	h := &appDataReceivedPacketTracker{
 		receivedPacketTracker: *newReceivedPacketTracker(),
 		maxAckDelay:           protocol.MaxAckDelay,
+		ackElicitingThreshold: defaultAckElicitingThreshold,
+		reorderingThreshold:   defaultReorderingThreshold,
 		logger:                logger,
 	}
 	return h
@@ -128,6 +142,28 @@ func (h *appDataReceivedPacketTracker) ReceivedPacket(pn protocol.PacketNumber,
 	return nil
 }
 
+// ReceivedAckFrequencyFrame applies the values requested in an ACK_FREQUENCY frame.
+// Frames that arrive out of order (i.e. with a smaller sequence number than the last one) are ignored.
+func (h *appDataReceivedPacketTracker) ReceivedAckFrequencyFrame(f *wire.AckFrequencyFrame) {
+	if h.receivedAckFrequency && f.SequenceNumber <= h.ackFrequencySeqNum {
+		return
+	}
+	h.receivedAckFrequency = true
+	h.ackFrequencySeqNum = f.SequenceNumber
+	h.ackElicitingThreshold = f.AckElicitingThreshold
+	h.maxAckDelay = f.RequestedMaxAckDelay
+	h.reorderingThreshold = protocol.PacketNumber(f.ReorderingThreshold)
+	if h.logger.Debug() {
+		h.logger.Debugf("\tUpdating ACK frequency: ack-eliciting threshold %d, max ack delay %s, reordering threshold %d", h.ackElicitingThreshold, h.maxAckDelay, h.reorderingThreshold)
+	}
+}
+
+// ReceivedImmediateAck queues an ACK, after receiving an IMMEDIATE_ACK frame.
+func (h *appDataReceivedPacketTracker) ReceivedImmediateAck() {
+	h.ackQueued = true
+	h.ackAlarm = time.Time{}
+}
+
 // IgnoreBelow sets a lower limit for acknowledging packets.
 // Packets with packet numbers smaller than p will not be acked.
 func (h *appDataReceivedPacketTracker) IgnoreBelow(pn protocol.PacketNumber) {
@@ -150,11 +186,16 @@ func (h *appDataReceivedPacketTracker) isMissing(p protocol.PacketNumber) bool {
 }
 
 func (h *appDataReceivedPacketTracker) hasNewMissingPackets() bool {
-	if h.lastAck == nil {
+	if h.lastAck == nil || h.reorderingThreshold == 0 {
 		return false
 	}
-	highestRange := h.packetHistory.GetHighestAckRange()
-	return highestRange.Smallest > h.lastAck.LargestAcked()+1 && highestRange.Len() == 1
+	if h.reorderingThreshold == 1 {
+		highestRange := h.packetHistory.GetHighestAckRange()
+		return highestRange.Smallest > h.lastAck.LargestAcked()+1 && highestRange.Len() == 1
+	}
+	// Only report the missing packet once enough packets were received after it.
+	missing := h.packetHistory.SmallestMissingAbove(h.lastAck.LargestAcked())
+	return missing != protocol.InvalidPacketNumber && h.largestObserved-missing >= h.reorderingThreshold
 }
 
 func (h *appDataReceivedPacketTracker) shouldQueueACK(pn protocol.PacketNumber, ecn protocol.ECN, wasMissing bool) bool {
@@ -167,17 +208,18 @@ func (h *appDataReceivedPacketTracker) shouldQueueACK(pn protocol.PacketNumber,
 	// Send an ACK if this packet was reported missing in an ACK sent before.
 	// Ack decimation with reordering relies on the timer to send an ACK, but if
 	// missing packets we reported in the previous ACK, send an ACK immediately.
-	if wasMissing {
+	// This doesn't apply if the peer requested a different reordering threshold.
+	if wasMissing && h.reorderingThreshold == 1 {
 		if h.logger.Debug() {
 			h.logger.Debugf("\tQueueing ACK because packet %d was missing before.", pn)
 		}
 		return true
 	}
 
-	// send an ACK every 2 ack-eliciting packets
-	if h.ackElicitingPacketsReceivedSinceLastAck >= packetsBeforeAck {
+	// send an ACK every 2 ack-eliciting packets (unless the peer requested a different threshold)
+	if uint64(h.ackElicitingPacketsReceivedSinceLastAck) > h.ackElicitingThreshold {
 		if h.logger.Debug() {
-			h.logger.Debugf("\tQueueing ACK because packet %d packets were received after the last ACK (using initial threshold: %d).", h.ackElicitingPacketsReceivedSinceLastAck, packetsBeforeAck)
+			h.logger.Debugf("\tQueueing ACK because %d packets were received after the last ACK (using threshold: %d).", h.ackElicitingPacketsReceivedSinceLastAck, h.ackElicitingThreshold)
 		}
 		return true
 	}
@@ -227,6 +227,67 @@ var _ = Describe("Application Data Received Packet Tracker", func() {
 				Expect(tracker.GetAckFrame(true)).To(BeNil())
 			})
 
+			It("queues an ACK when receiving an IMMEDIATE_ACK frame", func() {
+				receiveAndAck10Packets()
+				tracker.ReceivedImmediateAck()
+				Expect(tracker.ReceivedPacket(11, protocol.ECNNon, time.Now(), true)).To(Succeed())
+				Expect(tracker.ackQueued).To(BeTrue())
+				Expect(tracker.GetAlarmTimeout()).To(BeZero())
+				Expect(tracker.GetAckFrame(true)).ToNot(BeNil())
+			})
+
+			It("uses the ack-eliciting threshold and max ack delay requested by the peer", func() {
+				receiveAndAck10Packets()
+				tracker.ReceivedAckFrequencyFrame(&wire.AckFrequencyFrame{
+					SequenceNumber:        1,
+					AckElicitingThreshold: 4,
+					RequestedMaxAckDelay:  10 * time.Millisecond,
+					ReorderingThreshold:   1,
+				})
+				rcvTime := time.Now()
+				for pn := protocol.PacketNumber(11); pn < 15; pn++ {
+					Expect(tracker.ReceivedPacket(pn, protocol.ECNNon, rcvTime, true)).To(Succeed())
+					Expect(tracker.ackQueued).To(BeFalse())
+					Expect(tracker.GetAlarmTimeout()).To(Equal(rcvTime.Add(10 * time.Millisecond)))
+				}
+				Expect(tracker.ReceivedPacket(15, protocol.ECNNon, rcvTime, true)).To(Succeed())
+				Expect(tracker.ackQueued).To(BeTrue())
+			})
+
+			It("ignores reordered ACK_FREQUENCY frames", func() {
+				tracker.ReceivedAckFrequencyFrame(&wire.AckFrequencyFrame{SequenceNumber: 2, AckElicitingThreshold: 4, RequestedMaxAckDelay: 10 * time.Millisecond})
+				tracker.ReceivedAckFrequencyFrame(&wire.AckFrequencyFrame{SequenceNumber: 1, AckElicitingThreshold: 8, RequestedMaxAckDelay: 20 * time.Millisecond})
+				Expect(tracker.ackElicitingThreshold).To(BeEquivalentTo(4))
+				Expect(tracker.maxAckDelay).To(Equal(10 * time.Millisecond))
+				tracker.ReceivedAckFrequencyFrame(&wire.AckFrequencyFrame{SequenceNumber: 3, AckElicitingThreshold: 8, RequestedMaxAckDelay: 20 * time.Millisecond})
+				Expect(tracker.ackElicitingThreshold).To(BeEquivalentTo(8))
+				Expect(tracker.maxAckDelay).To(Equal(20 * time.Millisecond))
+			})
+
+			It("doesn't queue ACKs for out-of-order packets if the reordering threshold is 0", func() {
+				receiveAndAck10Packets()
+				tracker.ReceivedAckFrequencyFrame(&wire.AckFrequencyFrame{AckElicitingThreshold: 10, RequestedMaxAckDelay: 10 * time.Millisecond})
+				Expect(tracker.ReceivedPacket(12, protocol.ECNNon, time.Now(), true)).To(Succeed())
+				Expect(tracker.ReceivedPacket(14, protocol.ECNNon, time.Now(), true)).To(Succeed())
+				Expect(tracker.ackQueued).To(BeFalse())
+			})
+
+			It("queues an ACK once a missing packet exceeds the reordering threshold", func() {
+				receiveAndAck10Packets()
+				tracker.ReceivedAckFrequencyFrame(&wire.AckFrequencyFrame{AckElicitingThreshold: 10, RequestedMaxAckDelay: 10 * time.Millisecond, ReorderingThreshold: 3})
+				// packet 11 is missing
+				Expect(tracker.ReceivedPacket(12, protocol.ECNNon, time.Now(), true)).To(Succeed())
+				Expect(tracker.ReceivedPacket(13, protocol.ECNNon, time.Now(), true)).To(Succeed())
+				Expect(tracker.ackQueued).To(BeFalse())
+				Expect(tracker.ReceivedPacket(14, protocol.ECNNon, time.Now(), true)).To(Succeed())
+				Expect(tracker.ackQueued).To(BeTrue())
+				ack := tracker.GetAckFrame(true)
+				Expect(ack.AckRanges).To(Equal([]wire.AckRange{{Smallest: 12, Largest: 14}, {Smallest: 1, Largest: 10}}))
+				// packet 11 was already reported missing
+				Expect(tracker.ReceivedPacket(15, protocol.ECNNon, time.Now(), true)).To(Succeed())
+				Expect(tracker.ackQueued).To(BeFalse())
+			})
+
 			It("doesn't queue an ACK if packets arrive out-of-order, but haven't been acknowledged yet", func() {
 				receiveAndAck10Packets()
 				Expect(tracker.lastAck).ToNot(BeNil())
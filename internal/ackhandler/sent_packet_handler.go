@@ -16,9 +16,15 @@ import (
 const (
 	// Maximum reordering in time space before time based loss detection considers a packet lost.
 	// Specified as an RTT multiplier.
-	timeThreshold = 9.0 / 8
+	// The threshold is increased when spurious losses are detected, up to maxTimeThreshold.
+	initialTimeThreshold = 9.0 / 8
+	maxTimeThreshold     = 2
 	// Maximum reordering in packets before packet threshold loss detection considers a packet lost.
-	packetThreshold = 3
+	// The threshold is increased when spurious losses are detected, up to maxPacketThreshold.
+	initialPacketThreshold = 3
+	maxPacketThreshold     = 32
+	// The maximum number of lost packets that are tracked to detect spurious losses.
+	maxTrackedLostPackets = 64
 	// Before validating the client's address, the server won't send more than 3x bytes than it received.
 	amplificationFactor = 3
 	// We use Retry packets to derive an RTT estimate. Make sure we don't set the RTT to a super low value yet.
@@ -53,6 +59,25 @@ func newPacketNumberSpace(initialPN protocol.PacketNumber, skipPNs bool) *packet
 	}
 }
 
+// SentPacketStats contains statistics about the packets sent on a connection.
+type SentPacketStats struct {
+	PacketsSent uint64
+	BytesSent   uint64
+	PacketsLost uint64
+	BytesLost   uint64
+	// Packets whose frames were queued for retransmission,
+	// either because the packet was declared lost, or in order to send a probe packet.
+	// Path MTU probe packets and path probe packets are not counted.
+	PacketsRetransmitted uint64
+	BytesRetransmitted   uint64
+
+	CongestionWindow protocol.ByteCount
+	BytesInFlight    protocol.ByteCount
+	PTOCount         uint32
+	// ECNState is 0 if ECN is not used.
+	ECNState logging.ECNState
+}
+
 type sentPacketHandler struct {
 	initialPackets   *packetNumberSpace
 	handshakePackets *packetNumberSpace
@@ -92,8 +117,23 @@ type sentPacketHandler struct {
 	// The alarm timeout
 	alarm time.Time
 
+	// The loss detection thresholds, adapted to the reordering observed on this connection.
+	packetThreshold protocol.PacketNumber
+	timeThreshold   float64
+	// Recently lost 1-RTT packets, used to detect spurious losses.
+	lostPackets *lostPacketTracker
+
 	enableECN  bool
 	ecnTracker ecnHandler
+	// The ECN-CE count of the last ACK frame passed to the ecnTracker.
+	// Only used for congestion controllers that implement congestion.ScalableECNSendAlgorithm.
+	numAckedECNCE int64
+
+	// Informed about acknowledged and lost 1-RTT packets, used for Path MTU black hole detection.
+	packetSizeObserver PacketSizeObserver
+
+	// The counters of the SentPacketStats.
+	stats SentPacketStats
 
 	perspective protocol.Perspective
 
@@ -115,16 +155,13 @@ func newSentPacketHandler(
 	clientAddressValidated bool,
 	enableECN bool,
 	pers protocol.Perspective,
+	newCongestion congestion.SendAlgorithmFactory,
 	tracer *logging.ConnectionTracer,
 	logger utils.Logger,
 ) *sentPacketHandler {
-	congestion := congestion.NewCubicSender(
-		congestion.DefaultClock{},
-		rttStats,
-		initialMaxDatagramSize,
-		true, // use Reno
-		tracer,
-	)
+	if newCongestion == nil {
+		newCongestion = congestion.NewRenoSender
+	}
 
 	h := &sentPacketHandler{
 		peerCompletedAddressValidation: pers == protocol.PerspectiveServer,
@@ -133,18 +170,45 @@ func newSentPacketHandler(
 		handshakePackets:               newPacketNumberSpace(0, false),
 		appDataPackets:                 newPacketNumberSpace(0, true),
 		rttStats:                       rttStats,
-		congestion:                     congestion,
+		congestion:                     newCongestion(rttStats, initialMaxDatagramSize, tracer),
+		packetThreshold:                initialPacketThreshold,
+		timeThreshold:                  initialTimeThreshold,
+		lostPackets:                    newLostPacketTracker(maxTrackedLostPackets),
 		perspective:                    pers,
 		tracer:                         tracer,
 		logger:                         logger,
 	}
 	if enableECN {
 		h.enableECN = true
-		h.ecnTracker = newECNTracker(logger, tracer)
+		ect := protocol.ECT0
+		if _, ok := h.congestion.(congestion.ScalableECNSendAlgorithm); ok {
+			ect = protocol.ECT1
+		}
+		h.ecnTracker = newECNTracker(logger, tracer, ect)
 	}
 	return h
 }
 
+// handleECNFeedback passes the number of acknowledged and CE-marked bytes to a scalable congestion controller.
+// ACK frames only report packet counts, so the number of CE-marked bytes is an estimate.
+func (h *sentPacketHandler) handleECNFeedback(scalable congestion.ScalableECNSendAlgorithm, largestAcked protocol.PacketNumber, ackedPackets []*packet, congested bool, ecnce int64) {
+	var numPackets int64
+	var ackedBytes protocol.ByteCount
+	for _, p := range ackedPackets {
+		numPackets++
+		ackedBytes += p.Length
+	}
+	var newECNCE int64
+	if congested {
+		newECNCE = min(ecnce-h.numAckedECNCE, numPackets)
+	}
+	h.numAckedECNCE = max(h.numAckedECNCE, ecnce)
+	if numPackets == 0 {
+		return
+	}
+	scalable.OnECNFeedback(largestAcked, ackedBytes, ackedBytes*protocol.ByteCount(newECNCE)/protocol.ByteCount(numPackets))
+}
+
 func (h *sentPacketHandler) removeFromBytesInFlight(p *packet) {
 	if p.includedInBytesInFlight {
 		if p.Length > h.bytesInFlight {
@@ -240,8 +304,27 @@ func (h *sentPacketHandler) SentPacket(
 	ecn protocol.ECN,
 	size protocol.ByteCount,
 	isPathMTUProbePacket bool,
+) {
+	h.sentPacket(t, pn, largestAcked, streamFrames, frames, encLevel, ecn, size, isPathMTUProbePacket, false)
+}
+
+func (h *sentPacketHandler) SentPathProbePacket(t time.Time, pn protocol.PacketNumber, frames []Frame, size protocol.ByteCount) {
+	h.sentPacket(t, pn, protocol.InvalidPacketNumber, nil, frames, protocol.Encryption1RTT, protocol.ECNNon, size, false, true)
+}
+
+func (h *sentPacketHandler) sentPacket(
+	t time.Time,
+	pn, largestAcked protocol.PacketNumber,
+	streamFrames []StreamFrame,
+	frames []Frame,
+	encLevel protocol.EncryptionLevel,
+	ecn protocol.ECN,
+	size protocol.ByteCount,
+	isPathMTUProbePacket, isPathProbePacket bool,
 ) {
 	h.bytesSent += size
+	h.stats.PacketsSent++
+	h.stats.BytesSent += uint64(size)
 
 	pnSpace := h.getPacketNumberSpace(encLevel)
 	if h.logger.Debug() && pnSpace.history.HasOutstandingPackets() {
@@ -253,14 +336,17 @@ func (h *sentPacketHandler) SentPacket(
 	pnSpace.largestSent = pn
 	isAckEliciting := len(streamFrames) > 0 || len(frames) > 0
 
-	if isAckEliciting {
-		pnSpace.lastAckElicitingPacketTime = t
-		h.bytesInFlight += size
-		if h.numProbesToSend > 0 {
-			h.numProbesToSend--
+	// Path probe packets are sent on a different path than the one the congestion controller is tracking.
+	if !isPathProbePacket {
+		if isAckEliciting {
+			pnSpace.lastAckElicitingPacketTime = t
+			h.bytesInFlight += size
+			if h.numProbesToSend > 0 {
+				h.numProbesToSend--
+			}
 		}
+		h.congestion.OnPacketSent(t, h.bytesInFlight, pn, size, isAckEliciting)
 	}
-	h.congestion.OnPacketSent(t, h.bytesInFlight, pn, size, isAckEliciting)
 
 	if encLevel == protocol.Encryption1RTT && h.ecnTracker != nil {
 		h.ecnTracker.SentPacket(pn, ecn)
@@ -283,7 +369,8 @@ func (h *sentPacketHandler) SentPacket(
 	p.StreamFrames = streamFrames
 	p.Frames = frames
 	p.IsPathMTUProbePacket = isPathMTUProbePacket
-	p.includedInBytesInFlight = true
+	p.IsPathProbePacket = isPathProbePacket
+	p.includedInBytesInFlight = !isPathProbePacket
 
 	pnSpace.history.SentAckElicitingPacket(p)
 	if h.tracer != nil && h.tracer.UpdatedMetrics != nil {
@@ -326,6 +413,9 @@ func (h *sentPacketHandler) ReceivedAck(ack *wire.AckFrame, encLevel protocol.En
 	}
 
 	priorInFlight := h.bytesInFlight
+	if encLevel == protocol.Encryption1RTT {
+		h.detectSpuriousLosses(ack, rcvTime)
+	}
 	ackedPackets, err := h.detectAndRemoveAckedPackets(ack, encLevel)
 	if err != nil || len(ackedPackets) == 0 {
 		return false, err
@@ -349,7 +439,9 @@ func (h *sentPacketHandler) ReceivedAck(ack *wire.AckFrame, encLevel protocol.En
 	// Only inform the ECN tracker about new 1-RTT ACKs if the ACK increases the largest acked.
 	if encLevel == protocol.Encryption1RTT && h.ecnTracker != nil && largestAcked > pnSpace.largestAcked {
 		congested := h.ecnTracker.HandleNewlyAcked(ackedPackets, int64(ack.ECT0), int64(ack.ECT1), int64(ack.ECNCE))
-		if congested {
+		if scalable, ok := h.congestion.(congestion.ScalableECNSendAlgorithm); ok {
+			h.handleECNFeedback(scalable, largestAcked, ackedPackets, congested, int64(ack.ECNCE))
+		} else if congested {
 			h.congestion.OnCongestionEvent(largestAcked, 0, priorInFlight)
 		}
 	}
@@ -459,6 +551,9 @@ func (h *sentPacketHandler) detectAndRemoveAckedPackets(ack *wire.AckFrame, encL
 				f.Handler.OnAcked(f.Frame)
 			}
 		}
+		if h.packetSizeObserver != nil && encLevel == protocol.Encryption1RTT && !p.IsPathMTUProbePacket && !p.IsPathProbePacket {
+			h.packetSizeObserver.OnPacketAcked(p.Length)
+		}
 		if err := pnSpace.history.Remove(p.PacketNumber); err != nil {
 			return nil, err
 		}
@@ -470,6 +565,31 @@ func (h *sentPacketHandler) detectAndRemoveAckedPackets(ack *wire.AckFrame, encL
 	return h.ackedPackets, err
 }
 
+// detectSpuriousLosses detects packets that were declared lost, but are acknowledged by this ACK frame.
+// The loss detection thresholds are increased to accommodate the observed reordering,
+// and the congestion controller is given the chance to undo its response to the loss.
+func (h *sentPacketHandler) detectSpuriousLosses(ack *wire.AckFrame, rcvTime time.Time) {
+	h.lostPackets.RemoveAcked(ack, func(p lostPacket) {
+		packetReordering := h.appDataPackets.largestAcked - p.PacketNumber
+		timeReordering := rcvTime.Sub(p.SendTime)
+		if h.logger.Debug() {
+			h.logger.Debugf("\tspurious loss of packet %d (reordering: %d packets, %s)", p.PacketNumber, packetReordering, timeReordering)
+		}
+		if h.tracer != nil && h.tracer.DetectedSpuriousLoss != nil {
+			h.tracer.DetectedSpuriousLoss(protocol.Encryption1RTT, p.PacketNumber, uint64(packetReordering), timeReordering)
+		}
+		h.packetThreshold = min(max(h.packetThreshold, packetReordering+1), maxPacketThreshold)
+		if maxRTT := max(h.rttStats.LatestRTT(), h.rttStats.SmoothedRTT()); maxRTT > 0 {
+			h.timeThreshold = min(max(h.timeThreshold, float64(timeReordering)/float64(maxRTT)), maxTimeThreshold)
+		}
+		if c, ok := h.congestion.(congestion.SpuriousLossSendAlgorithm); ok {
+			c.OnSpuriousLoss(p.PacketNumber)
+		}
+	})
+	// Packets that are not acknowledged within a few PTOs were most likely actually lost.
+	h.lostPackets.DeleteBefore(rcvTime.Add(-3 * h.rttStats.PTO(true)))
+}
+
 func (h *sentPacketHandler) getLossTimeAndSpace() (time.Time, protocol.EncryptionLevel) {
 	var encLevel protocol.EncryptionLevel
 	var lossTime time.Time
@@ -607,12 +727,7 @@ func (h *sentPacketHandler) detectLostPackets(now time.Time, encLevel protocol.E
 	pnSpace := h.getPacketNumberSpace(encLevel)
 	pnSpace.lossTime = time.Time{}
 
-	maxRTT := float64(max(h.rttStats.LatestRTT(), h.rttStats.SmoothedRTT()))
-	lossDelay := time.Duration(timeThreshold * maxRTT)
-
-	// Minimum time of granularity before packets are deemed lost.
-	lossDelay = max(lossDelay, protocol.TimerGranularity)
-
+	lossDelay := getLossDelay(h.rttStats, h.timeThreshold)
 	// Packets sent before this time are deemed lost.
 	lostSendTime := now.Add(-lossDelay)
 
@@ -633,7 +748,7 @@ func (h *sentPacketHandler) detectLostPackets(now time.Time, encLevel protocol.E
 					h.tracer.LostPacket(p.EncryptionLevel, p.PacketNumber, logging.PacketLossTimeThreshold)
 				}
 			}
-		} else if pnSpace.largestAcked >= p.PacketNumber+packetThreshold {
+		} else if pnSpace.largestAcked >= p.PacketNumber+h.packetThreshold {
 			packetLost = true
 			if !p.skippedPacket {
 				if h.logger.Debug() {
@@ -654,21 +769,38 @@ func (h *sentPacketHandler) detectLostPackets(now time.Time, encLevel protocol.E
 		if packetLost {
 			pnSpace.history.DeclareLost(p.PacketNumber)
 			if !p.skippedPacket {
+				h.stats.PacketsLost++
+				h.stats.BytesLost += uint64(p.Length)
 				// the bytes in flight need to be reduced no matter if the frames in this packet will be retransmitted
 				h.removeFromBytesInFlight(p)
 				h.queueFramesForRetransmission(p)
-				if !p.IsPathMTUProbePacket {
+				if !p.IsPathMTUProbePacket && !p.IsPathProbePacket {
 					h.congestion.OnCongestionEvent(p.PacketNumber, p.Length, priorInFlight)
 				}
 				if encLevel == protocol.Encryption1RTT && h.ecnTracker != nil {
 					h.ecnTracker.LostPacket(p.PacketNumber)
 				}
+				// Path probe packets are sent on a different path, and are not subject to congestion control.
+				// They don't say anything about the packet size or reordering on the path used for loss detection.
+				if encLevel == protocol.Encryption1RTT && !p.IsPathMTUProbePacket && !p.IsPathProbePacket {
+					h.lostPackets.Add(p.PacketNumber, p.SendTime)
+					if h.packetSizeObserver != nil {
+						h.packetSizeObserver.OnPacketLost(p.Length)
+					}
+				}
 			}
 		}
 		return true, nil
 	})
 }
 
+func getLossDelay(rttStats *utils.RTTStats, timeThreshold float64) time.Duration {
+	maxRTT := float64(max(rttStats.LatestRTT(), rttStats.SmoothedRTT()))
+	lossDelay := time.Duration(timeThreshold * maxRTT)
+	// Minimum time of granularity before packets are deemed lost.
+	return max(lossDelay, protocol.TimerGranularity)
+}
+
 func (h *sentPacketHandler) OnLossDetectionTimeout() error {
 	defer h.setLossDetectionTimer()
 	earliestLossTime, encLevel := h.getLossTimeAndSpace()
@@ -732,6 +864,16 @@ func (h *sentPacketHandler) OnLossDetectionTimeout() error {
 		pn := h.PopPacketNumber(protocol.Encryption1RTT)
 		h.getPacketNumberSpace(protocol.Encryption1RTT).history.SkippedPacket(pn)
 		h.ptoMode = SendPTOAppData
+		if h.packetSizeObserver != nil {
+			smallestSize := protocol.MaxByteCount
+			h.appDataPackets.history.Iterate(func(p *packet) (bool, error) {
+				if p.outstanding() && p.EncryptionLevel == protocol.Encryption1RTT {
+					smallestSize = min(smallestSize, p.Length)
+				}
+				return true, nil
+			})
+			h.packetSizeObserver.OnPTO(smallestSize)
+		}
 	default:
 		return fmt.Errorf("PTO timer in unexpected encryption level: %s", encLevel)
 	}
@@ -821,10 +963,29 @@ func (h *sentPacketHandler) TimeUntilSend() time.Time {
 	return h.congestion.TimeUntilSend(h.bytesInFlight)
 }
 
+func (h *sentPacketHandler) GetCongestionWindow() protocol.ByteCount {
+	return h.congestion.GetCongestionWindow()
+}
+
 func (h *sentPacketHandler) SetMaxDatagramSize(s protocol.ByteCount) {
 	h.congestion.SetMaxDatagramSize(s)
 }
 
+func (h *sentPacketHandler) SetPacketSizeObserver(o PacketSizeObserver) {
+	h.packetSizeObserver = o
+}
+
+func (h *sentPacketHandler) Stats() SentPacketStats {
+	stats := h.stats
+	stats.CongestionWindow = h.congestion.GetCongestionWindow()
+	stats.BytesInFlight = h.bytesInFlight
+	stats.PTOCount = h.ptoCount
+	if h.ecnTracker != nil {
+		stats.ECNState = h.ecnTracker.State()
+	}
+	return stats
+}
+
 func (h *sentPacketHandler) isAmplificationLimited() bool {
 	if h.peerAddressValidated {
 		return false
@@ -850,6 +1011,11 @@ func (h *sentPacketHandler) queueFramesForRetransmission(p *packet) {
 	if len(p.Frames) == 0 && len(p.StreamFrames) == 0 {
 		panic("no frames")
 	}
+	// Path MTU and path probe packets don't contain any retransmittable data for the current path.
+	if !p.IsPathMTUProbePacket && !p.IsPathProbePacket {
+		h.stats.PacketsRetransmitted++
+		h.stats.BytesRetransmitted += uint64(p.Length)
+	}
 	for _, f := range p.Frames {
 		if f.Handler != nil {
 			f.Handler.OnLost(f.Frame)
@@ -914,6 +1080,33 @@ func (h *sentPacketHandler) ResetForRetry(now time.Time) error {
 	return nil
 }
 
+// MigratedPath resets the RTT estimate, the congestion controller and the loss detection thresholds,
+// since none of them are valid for the new path.
+func (h *sentPacketHandler) MigratedPath() {
+	h.rttStats.OnConnectionMigration()
+	h.congestion.OnConnectionMigration()
+	// The reordering observed on the old path doesn't apply to the new path.
+	h.packetThreshold = initialPacketThreshold
+	h.timeThreshold = initialTimeThreshold
+	h.lostPackets.Reset()
+	h.setLossDetectionTimer()
+}
+
+func (h *sentPacketHandler) PeerAddressChanged(bytesReceived protocol.ByteCount) {
+	h.peerAddressValidated = false
+	h.bytesReceived = bytesReceived
+	h.bytesSent = 0
+	h.setLossDetectionTimer()
+}
+
+func (h *sentPacketHandler) PeerAddressValidated() {
+	if h.peerAddressValidated {
+		return
+	}
+	h.peerAddressValidated = true
+	h.setLossDetectionTimer()
+}
+
 func (h *sentPacketHandler) SetHandshakeConfirmed() {
 	if h.initialPackets != nil {
 		panic("didn't drop initial correctly")
@@ -4,11 +4,14 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/quic-go/quic-go/internal/congestion"
 	"github.com/quic-go/quic-go/internal/mocks"
+	mocklogging "github.com/quic-go/quic-go/internal/mocks/logging"
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/qerr"
 	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/internal/wire"
+	"github.com/quic-go/quic-go/logging"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -31,6 +34,32 @@ func (h *customFrameHandler) OnAcked(f wire.Frame) {
 	}
 }
 
+type scalableSendAlgorithm struct {
+	*mocks.MockSendAlgorithmWithDebugInfos
+	feedback [][2]protocol.ByteCount
+}
+
+func (s *scalableSendAlgorithm) OnECNFeedback(_ protocol.PacketNumber, ackedBytes, ceMarkedBytes protocol.ByteCount) {
+	s.feedback = append(s.feedback, [2]protocol.ByteCount{ackedBytes, ceMarkedBytes})
+}
+
+type undoSendAlgorithm struct {
+	*mocks.MockSendAlgorithmWithDebugInfos
+	spuriousLosses []protocol.PacketNumber
+}
+
+func (s *undoSendAlgorithm) OnSpuriousLoss(pn protocol.PacketNumber) {
+	s.spuriousLosses = append(s.spuriousLosses, pn)
+}
+
+type packetSizeObserver struct {
+	acked, lost, ptos []protocol.ByteCount
+}
+
+func (o *packetSizeObserver) OnPacketAcked(size protocol.ByteCount) { o.acked = append(o.acked, size) }
+func (o *packetSizeObserver) OnPacketLost(size protocol.ByteCount)  { o.lost = append(o.lost, size) }
+func (o *packetSizeObserver) OnPTO(size protocol.ByteCount)         { o.ptos = append(o.ptos, size) }
+
 var _ = Describe("SentPacketHandler", func() {
 	var (
 		handler     *sentPacketHandler
@@ -44,7 +73,7 @@ var _ = Describe("SentPacketHandler", func() {
 	JustBeforeEach(func() {
 		lostPackets = nil
 		rttStats := utils.NewRTTStats()
-		handler = newSentPacketHandler(42, protocol.InitialPacketSizeIPv4, rttStats, false, false, perspective, nil, utils.DefaultLogger)
+		handler = newSentPacketHandler(42, protocol.InitialPacketSizeIPv4, rttStats, false, false, perspective, nil, nil, utils.DefaultLogger)
 		streamFrame = wire.StreamFrame{
 			StreamID: 5,
 			Data:     []byte{0x13, 0x37},
@@ -106,6 +135,10 @@ var _ = Describe("SentPacketHandler", func() {
 	}
 
 	sentPacket := func(p *packet) {
+		if p.IsPathProbePacket {
+			handler.SentPathProbePacket(p.SendTime, p.PacketNumber, p.Frames, p.Length)
+			return
+		}
 		handler.SentPacket(p.SendTime, p.PacketNumber, p.LargestAcked, p.StreamFrames, p.Frames, p.EncryptionLevel, protocol.ECNNon, p.Length, p.IsPathMTUProbePacket)
 	}
 
@@ -540,6 +573,13 @@ var _ = Describe("SentPacketHandler", func() {
 			})
 		})
 
+		It("resets the congestion controller and the RTT estimate on path migration", func() {
+			handler.rttStats.UpdateRTT(time.Second, 0, time.Now())
+			cong.EXPECT().OnConnectionMigration()
+			handler.MigratedPath()
+			Expect(handler.rttStats.SmoothedRTT()).To(BeZero())
+		})
+
 		It("should call MaybeExitSlowStart and OnPacketAcked", func() {
 			rcvTime := time.Now().Add(-5 * time.Second)
 			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(3)
@@ -602,6 +642,53 @@ var _ = Describe("SentPacketHandler", func() {
 			Expect(handler.bytesInFlight).To(BeZero())
 		})
 
+		It("doesn't pass path probe packets to the congestion controller", func() {
+			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), protocol.PacketNumber(2), gomock.Any(), gomock.Any())
+			sentPacket(ackElicitingPacket(&packet{
+				PacketNumber:      1,
+				Length:            1200,
+				SendTime:          time.Now().Add(-time.Hour),
+				IsPathProbePacket: true,
+			}))
+			Expect(handler.bytesInFlight).To(BeZero())
+			sentPacket(ackElicitingPacket(&packet{PacketNumber: 2}))
+			// lose packet 1, but don't EXPECT any calls to OnCongestionEvent()
+			gomock.InOrder(
+				cong.EXPECT().MaybeExitSlowStart(),
+				cong.EXPECT().OnPacketAcked(protocol.PacketNumber(2), protocol.ByteCount(1), protocol.ByteCount(1), gomock.Any()),
+			)
+			ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 2, Largest: 2}}}
+			_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lostPackets).To(Equal([]protocol.PacketNumber{1}))
+			Expect(handler.bytesInFlight).To(BeZero())
+		})
+
+		It("doesn't undo a congestion window reduction when a lost path probe packet is acknowledged", func() {
+			undo := &undoSendAlgorithm{MockSendAlgorithmWithDebugInfos: cong}
+			handler.congestion = undo
+			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), protocol.PacketNumber(2), gomock.Any(), gomock.Any())
+			sentPacket(ackElicitingPacket(&packet{
+				PacketNumber:      1,
+				Length:            1200,
+				SendTime:          time.Now().Add(-time.Hour),
+				IsPathProbePacket: true,
+			}))
+			sentPacket(ackElicitingPacket(&packet{PacketNumber: 2}))
+			gomock.InOrder(
+				cong.EXPECT().MaybeExitSlowStart(),
+				cong.EXPECT().OnPacketAcked(protocol.PacketNumber(2), protocol.ByteCount(1), protocol.ByteCount(1), gomock.Any()),
+			)
+			_, err := handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 2, Largest: 2}}}, protocol.Encryption1RTT, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lostPackets).To(Equal([]protocol.PacketNumber{1}))
+			_, err = handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 1, Largest: 2}}}, protocol.Encryption1RTT, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(undo.spuriousLosses).To(BeEmpty())
+			Expect(handler.packetThreshold).To(Equal(protocol.PacketNumber(initialPacketThreshold)))
+			Expect(handler.timeThreshold).To(Equal(initialTimeThreshold))
+		})
+
 		It("calls OnPacketAcked and OnCongestionEvent with the right bytes_in_flight value", func() {
 			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(4)
 			sentPacket(ackElicitingPacket(&packet{PacketNumber: 1, SendTime: time.Now().Add(-time.Hour)}))
@@ -913,6 +1000,14 @@ var _ = Describe("SentPacketHandler", func() {
 			sentPacket(ackElicitingPacket(&packet{PacketNumber: 5, SendTime: time.Now(), IsPathMTUProbePacket: true}))
 			Expect(handler.GetLossDetectionTimeout()).To(BeZero())
 		})
+
+		It("doesn't set the PTO timer for path probe packets", func() {
+			handler.ReceivedPacket(protocol.EncryptionHandshake)
+			setHandshakeConfirmed()
+			updateRTT(time.Second)
+			sentPacket(ackElicitingPacket(&packet{PacketNumber: 5, SendTime: time.Now(), IsPathProbePacket: true}))
+			Expect(handler.GetLossDetectionTimeout()).To(BeZero())
+		})
 	})
 
 	Context("amplification limit, for the server", func() {
@@ -984,7 +1079,7 @@ var _ = Describe("SentPacketHandler", func() {
 	Context("amplification limit, for the server, with validated address", func() {
 		JustBeforeEach(func() {
 			rttStats := utils.NewRTTStats()
-			handler = newSentPacketHandler(42, protocol.InitialPacketSizeIPv4, rttStats, true, false, perspective, nil, utils.DefaultLogger)
+			handler = newSentPacketHandler(42, protocol.InitialPacketSizeIPv4, rttStats, true, false, perspective, nil, nil, utils.DefaultLogger)
 		})
 
 		It("do not limits the window", func() {
@@ -999,6 +1094,20 @@ var _ = Describe("SentPacketHandler", func() {
 			})
 			Expect(handler.SendMode(time.Now())).To(Equal(SendAny))
 		})
+
+		It("limits the window after the client's address changed, until the new address is validated", func() {
+			handler.PeerAddressChanged(100)
+			sentPacket(&packet{
+				PacketNumber:    1,
+				Length:          300,
+				EncryptionLevel: protocol.Encryption1RTT,
+				Frames:          []Frame{{Frame: &wire.PingFrame{}}},
+				SendTime:        time.Now(),
+			})
+			Expect(handler.SendMode(time.Now())).To(Equal(SendNone))
+			handler.PeerAddressValidated()
+			Expect(handler.SendMode(time.Now())).To(Equal(SendAny))
+		})
 	})
 
 	Context("amplification limit, for the client", func() {
@@ -1208,6 +1317,301 @@ var _ = Describe("SentPacketHandler", func() {
 		})
 	})
 
+	Context("observing packet sizes", func() {
+		var observer *packetSizeObserver
+
+		JustBeforeEach(func() {
+			observer = &packetSizeObserver{}
+			handler.SetPacketSizeObserver(observer)
+		})
+
+		It("informs the observer about acknowledged and lost 1-RTT packets", func() {
+			now := time.Now()
+			for i := protocol.PacketNumber(1); i <= 5; i++ {
+				sentPacket(ackElicitingPacket(&packet{PacketNumber: i, Length: 1000 + protocol.ByteCount(i)}))
+			}
+			ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 4, Largest: 5}}}
+			_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(observer.acked).To(Equal([]protocol.ByteCount{1004, 1005}))
+			Expect(observer.lost).To(Equal([]protocol.ByteCount{1001, 1002}))
+		})
+
+		It("doesn't inform the observer about Path MTU probe packets", func() {
+			now := time.Now()
+			sentPacket(ackElicitingPacket(&packet{PacketNumber: 1, Length: 1400, IsPathMTUProbePacket: true}))
+			sentPacket(ackElicitingPacket(&packet{PacketNumber: 2, Length: 1400, IsPathMTUProbePacket: true}))
+			for i := protocol.PacketNumber(3); i <= 5; i++ {
+				sentPacket(ackElicitingPacket(&packet{PacketNumber: i, Length: 1000}))
+			}
+			ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 4, Largest: 5}, {Smallest: 2, Largest: 2}}}
+			_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(observer.acked).To(Equal([]protocol.ByteCount{1000, 1000}))
+			Expect(observer.lost).To(BeEmpty())
+			Expect(lostPackets).To(Equal([]protocol.PacketNumber{1}))
+		})
+
+		It("doesn't inform the observer about lost path probe packets", func() {
+			now := time.Now()
+			sentPacket(ackElicitingPacket(&packet{PacketNumber: 1, Length: 1200, IsPathProbePacket: true}))
+			for i := protocol.PacketNumber(2); i <= 5; i++ {
+				sentPacket(ackElicitingPacket(&packet{PacketNumber: i, Length: 1000}))
+			}
+			ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 4, Largest: 5}}}
+			_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lostPackets).To(Equal([]protocol.PacketNumber{1, 2}))
+			Expect(observer.acked).To(Equal([]protocol.ByteCount{1000, 1000}))
+			Expect(observer.lost).To(Equal([]protocol.ByteCount{1000}))
+		})
+
+		It("doesn't inform the observer about Handshake packets", func() {
+			now := time.Now()
+			for i := protocol.PacketNumber(1); i <= 5; i++ {
+				sentPacket(handshakePacket(&packet{PacketNumber: i, Length: 1000}))
+			}
+			ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 5, Largest: 5}}}
+			_, err := handler.ReceivedAck(ack, protocol.EncryptionHandshake, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lostPackets).To(Equal([]protocol.PacketNumber{1, 2}))
+			Expect(observer.acked).To(BeEmpty())
+			Expect(observer.lost).To(BeEmpty())
+		})
+
+		It("informs the observer when the PTO fires for 1-RTT packets", func() {
+			handler.ReceivedPacket(protocol.EncryptionHandshake)
+			setHandshakeConfirmed()
+			sentPacket(ackElicitingPacket(&packet{PacketNumber: 1, Length: 1200, SendTime: time.Now().Add(-time.Minute)}))
+			sentPacket(ackElicitingPacket(&packet{PacketNumber: 2, Length: 1100, SendTime: time.Now().Add(-time.Minute)}))
+			sentPacket(ackElicitingPacket(&packet{PacketNumber: 3, Length: 1000, SendTime: time.Now().Add(-time.Minute), IsPathMTUProbePacket: true}))
+			handler.appDataPackets.pns.(*skippingPacketNumberGenerator).next = 4
+			Expect(handler.OnLossDetectionTimeout()).To(Succeed())
+			Expect(handler.SendMode(time.Now())).To(Equal(SendPTOAppData))
+			// the Path MTU probe packet is not taken into account
+			Expect(observer.ptos).To(Equal([]protocol.ByteCount{1100}))
+		})
+	})
+
+	Context("statistics", func() {
+		It("counts sent, lost and retransmitted packets", func() {
+			now := time.Now()
+			for i := protocol.PacketNumber(1); i <= 5; i++ {
+				sentPacket(ackElicitingPacket(&packet{PacketNumber: i, Length: 1000 + protocol.ByteCount(i)}))
+			}
+			stats := handler.Stats()
+			Expect(stats.PacketsSent).To(BeEquivalentTo(5))
+			Expect(stats.BytesSent).To(BeEquivalentTo(5015))
+			Expect(stats.BytesInFlight).To(BeEquivalentTo(5015))
+			Expect(stats.PacketsLost).To(BeZero())
+			ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 4, Largest: 5}}}
+			_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lostPackets).To(Equal([]protocol.PacketNumber{1, 2}))
+			stats = handler.Stats()
+			Expect(stats.PacketsSent).To(BeEquivalentTo(5))
+			Expect(stats.PacketsLost).To(BeEquivalentTo(2))
+			Expect(stats.BytesLost).To(BeEquivalentTo(2003))
+			Expect(stats.PacketsRetransmitted).To(BeEquivalentTo(2))
+			Expect(stats.BytesRetransmitted).To(BeEquivalentTo(2003))
+			Expect(stats.BytesInFlight).To(BeEquivalentTo(1003))
+			Expect(stats.CongestionWindow).To(BeNumerically(">", 0))
+		})
+
+		It("doesn't count Path MTU probe packets as retransmitted", func() {
+			now := time.Now()
+			sentPacket(ackElicitingPacket(&packet{PacketNumber: 1, Length: 1400, IsPathMTUProbePacket: true}))
+			for i := protocol.PacketNumber(2); i <= 5; i++ {
+				sentPacket(ackElicitingPacket(&packet{PacketNumber: i, Length: 1000}))
+			}
+			ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 4, Largest: 5}}}
+			_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, now)
+			Expect(err).ToNot(HaveOccurred())
+			stats := handler.Stats()
+			Expect(stats.PacketsLost).To(BeEquivalentTo(2))
+			Expect(stats.BytesLost).To(BeEquivalentTo(2400))
+			Expect(stats.PacketsRetransmitted).To(BeEquivalentTo(1))
+			Expect(stats.BytesRetransmitted).To(BeEquivalentTo(1000))
+		})
+
+		It("doesn't count path probe packets as retransmitted", func() {
+			now := time.Now()
+			sentPacket(ackElicitingPacket(&packet{PacketNumber: 1, Length: 1200, IsPathProbePacket: true}))
+			for i := protocol.PacketNumber(2); i <= 5; i++ {
+				sentPacket(ackElicitingPacket(&packet{PacketNumber: i, Length: 1000}))
+			}
+			Expect(handler.Stats().BytesInFlight).To(BeEquivalentTo(4000))
+			ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 4, Largest: 5}}}
+			_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, now)
+			Expect(err).ToNot(HaveOccurred())
+			stats := handler.Stats()
+			Expect(stats.PacketsSent).To(BeEquivalentTo(5))
+			Expect(stats.PacketsLost).To(BeEquivalentTo(2))
+			Expect(stats.PacketsRetransmitted).To(BeEquivalentTo(1))
+			Expect(stats.BytesRetransmitted).To(BeEquivalentTo(1000))
+		})
+
+		It("reports the PTO count", func() {
+			handler.ReceivedPacket(protocol.EncryptionHandshake)
+			setHandshakeConfirmed()
+			sentPacket(ackElicitingPacket(&packet{PacketNumber: 1, SendTime: time.Now().Add(-time.Minute)}))
+			handler.appDataPackets.pns.(*skippingPacketNumberGenerator).next = 2
+			Expect(handler.Stats().PTOCount).To(BeZero())
+			Expect(handler.OnLossDetectionTimeout()).To(Succeed())
+			Expect(handler.Stats().PTOCount).To(BeEquivalentTo(1))
+		})
+	})
+
+	Context("spurious loss detection", func() {
+		type spuriousLoss struct {
+			pn               protocol.PacketNumber
+			packetReordering uint64
+			timeReordering   time.Duration
+		}
+		var spuriousLosses []spuriousLoss
+
+		JustBeforeEach(func() {
+			spuriousLosses = nil
+			handler.tracer = &logging.ConnectionTracer{
+				DetectedSpuriousLoss: func(encLevel logging.EncryptionLevel, pn logging.PacketNumber, packetReordering uint64, timeReordering time.Duration) {
+					Expect(encLevel).To(Equal(protocol.Encryption1RTT))
+					spuriousLosses = append(spuriousLosses, spuriousLoss{pn: pn, packetReordering: packetReordering, timeReordering: timeReordering})
+				},
+			}
+		})
+
+		It("detects spurious losses and increases the packet threshold", func() {
+			now := time.Now()
+			for i := protocol.PacketNumber(1); i <= 10; i++ {
+				sentPacket(ackElicitingPacket(&packet{PacketNumber: i, SendTime: now}))
+			}
+			_, err := handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 5, Largest: 6}}}, protocol.Encryption1RTT, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lostPackets).To(Equal([]protocol.PacketNumber{1, 2, 3}))
+			// packets 1 and 2 arrive late
+			_, err = handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 5, Largest: 6}, {Smallest: 1, Largest: 2}}}, protocol.Encryption1RTT, now.Add(time.Millisecond))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(spuriousLosses).To(Equal([]spuriousLoss{
+				{pn: 1, packetReordering: 5, timeReordering: time.Millisecond},
+				{pn: 2, packetReordering: 4, timeReordering: time.Millisecond},
+			}))
+			Expect(handler.packetThreshold).To(Equal(protocol.PacketNumber(6)))
+			// the packet is only declared lost when 6 packets with higher packet numbers are acknowledged
+			lostPackets = nil
+			_, err = handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 5, Largest: 9}, {Smallest: 1, Largest: 2}}}, protocol.Encryption1RTT, now.Add(time.Millisecond))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lostPackets).To(BeEmpty())
+			_, err = handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 5, Largest: 10}, {Smallest: 1, Largest: 2}}}, protocol.Encryption1RTT, now.Add(time.Millisecond))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lostPackets).To(Equal([]protocol.PacketNumber{4}))
+		})
+
+		It("doesn't detect spurious losses of path probe packets", func() {
+			now := time.Now()
+			sentPacket(ackElicitingPacket(&packet{PacketNumber: 1, SendTime: now.Add(-time.Second), IsPathProbePacket: true}))
+			for i := protocol.PacketNumber(2); i <= 5; i++ {
+				sentPacket(ackElicitingPacket(&packet{PacketNumber: i, SendTime: now}))
+			}
+			_, err := handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 4, Largest: 4}}}, protocol.Encryption1RTT, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lostPackets).To(Equal([]protocol.PacketNumber{1}))
+			// the path probe is acknowledged late
+			_, err = handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 4, Largest: 4}, {Smallest: 1, Largest: 1}}}, protocol.Encryption1RTT, now.Add(time.Millisecond))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(spuriousLosses).To(BeEmpty())
+			Expect(handler.packetThreshold).To(Equal(protocol.PacketNumber(initialPacketThreshold)))
+			Expect(handler.timeThreshold).To(Equal(initialTimeThreshold))
+		})
+
+		It("limits the packet threshold", func() {
+			now := time.Now()
+			for i := protocol.PacketNumber(1); i <= 100; i++ {
+				sentPacket(ackElicitingPacket(&packet{PacketNumber: i, SendTime: now}))
+			}
+			_, err := handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 100, Largest: 100}}}, protocol.Encryption1RTT, now)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 100, Largest: 100}, {Smallest: 50, Largest: 50}}}, protocol.Encryption1RTT, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(spuriousLosses).To(HaveLen(1))
+			Expect(spuriousLosses[0].packetReordering).To(BeEquivalentTo(50))
+			Expect(handler.packetThreshold).To(Equal(protocol.PacketNumber(maxPacketThreshold)))
+		})
+
+		It("increases the time threshold", func() {
+			now := time.Now()
+			sentPacket(ackElicitingPacket(&packet{PacketNumber: 1, SendTime: now.Add(-1500 * time.Millisecond)}))
+			sentPacket(ackElicitingPacket(&packet{PacketNumber: 2, SendTime: now.Add(-time.Second)}))
+			_, err := handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 2, Largest: 2}}}, protocol.Encryption1RTT, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(handler.rttStats.SmoothedRTT()).To(Equal(time.Second))
+			Expect(lostPackets).To(Equal([]protocol.PacketNumber{1}))
+			_, err = handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 1, Largest: 2}}}, protocol.Encryption1RTT, now.Add(100*time.Millisecond))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(spuriousLosses).To(Equal([]spuriousLoss{{pn: 1, packetReordering: 1, timeReordering: 1600 * time.Millisecond}}))
+			Expect(handler.timeThreshold).To(Equal(1.6))
+			Expect(getLossDelay(handler.rttStats, handler.timeThreshold)).To(Equal(1600 * time.Millisecond))
+		})
+
+		It("limits the time threshold", func() {
+			now := time.Now()
+			sentPacket(ackElicitingPacket(&packet{PacketNumber: 1, SendTime: now.Add(-1500 * time.Millisecond)}))
+			sentPacket(ackElicitingPacket(&packet{PacketNumber: 2, SendTime: now.Add(-time.Second)}))
+			_, err := handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 2, Largest: 2}}}, protocol.Encryption1RTT, now)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 1, Largest: 2}}}, protocol.Encryption1RTT, now.Add(time.Second))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(spuriousLosses).To(HaveLen(1))
+			Expect(handler.timeThreshold).To(Equal(float64(maxTimeThreshold)))
+		})
+
+		It("doesn't detect spurious losses for packets acknowledged after a long time", func() {
+			now := time.Now()
+			for i := protocol.PacketNumber(1); i <= 4; i++ {
+				sentPacket(ackElicitingPacket(&packet{PacketNumber: i, SendTime: now.Add(-2 * time.Second)}))
+			}
+			sentPacket(ackElicitingPacket(&packet{PacketNumber: 5, SendTime: now.Add(-time.Second)}))
+			_, err := handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 5, Largest: 5}}}, protocol.Encryption1RTT, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lostPackets).To(Equal([]protocol.PacketNumber{1, 2, 3, 4}))
+			Expect(handler.lostPackets.Len()).To(Equal(4))
+			// the lost packets are forgotten 3 PTOs after they were sent
+			_, err = handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 4, Largest: 5}}}, protocol.Encryption1RTT, now.Add(3*handler.rttStats.PTO(true)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(spuriousLosses).To(HaveLen(1))
+			Expect(handler.lostPackets.Len()).To(BeZero())
+			_, err = handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 1, Largest: 5}}}, protocol.Encryption1RTT, now.Add(3*handler.rttStats.PTO(true)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(spuriousLosses).To(HaveLen(1))
+		})
+
+		It("informs the congestion controller about spurious losses", func() {
+			cong := &undoSendAlgorithm{MockSendAlgorithmWithDebugInfos: mocks.NewMockSendAlgorithmWithDebugInfos(mockCtrl)}
+			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+			cong.EXPECT().OnPacketAcked(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+			cong.EXPECT().MaybeExitSlowStart().AnyTimes()
+			cong.EXPECT().GetCongestionWindow().AnyTimes()
+			cong.EXPECT().OnCongestionEvent(gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+			handler.congestion = cong
+			now := time.Now()
+			for i := protocol.PacketNumber(1); i <= 5; i++ {
+				sentPacket(ackElicitingPacket(&packet{PacketNumber: i, SendTime: now}))
+			}
+			_, err := handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 5, Largest: 5}}}, protocol.Encryption1RTT, now)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 5, Largest: 5}, {Smallest: 2, Largest: 2}}}, protocol.Encryption1RTT, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cong.spuriousLosses).To(Equal([]protocol.PacketNumber{2}))
+		})
+
+		It("resets the thresholds on path migration", func() {
+			handler.packetThreshold = 10
+			handler.timeThreshold = 1.5
+			handler.MigratedPath()
+			Expect(handler.packetThreshold).To(Equal(protocol.PacketNumber(initialPacketThreshold)))
+			Expect(handler.timeThreshold).To(Equal(initialTimeThreshold))
+		})
+	})
+
 	Context("crypto packets", func() {
 		It("rejects an ACK that acks packets with a higher encryption level", func() {
 			sentPacket(ackElicitingPacket(&packet{
@@ -1443,7 +1847,7 @@ var _ = Describe("SentPacketHandler", func() {
 			lostPackets = nil
 			rttStats := utils.NewRTTStats()
 			rttStats.UpdateRTT(time.Hour, 0, time.Now())
-			handler = newSentPacketHandler(42, protocol.InitialPacketSizeIPv4, rttStats, false, false, perspective, nil, utils.DefaultLogger)
+			handler = newSentPacketHandler(42, protocol.InitialPacketSizeIPv4, rttStats, false, false, perspective, nil, nil, utils.DefaultLogger)
 			handler.ecnTracker = ecnHandler
 			handler.congestion = cong
 		})
@@ -1575,5 +1979,45 @@ var _ = Describe("SentPacketHandler", func() {
 			_, err := handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Largest: 15, Smallest: 10}}}, protocol.Encryption1RTT, time.Now())
 			Expect(err).ToNot(HaveOccurred())
 		})
+
+		It("passes ECN feedback to scalable congestion controllers", func() {
+			scalable := &scalableSendAlgorithm{MockSendAlgorithmWithDebugInfos: cong}
+			handler.congestion = scalable
+			for i := 10; i < 20; i++ {
+				ecnHandler.EXPECT().SentPacket(protocol.PacketNumber(i), protocol.ECT1)
+				handler.SentPacket(time.Now(), protocol.PacketNumber(i), -1, []StreamFrame{{Frame: &streamFrame}}, nil, protocol.Encryption1RTT, protocol.ECT1, 1000, false)
+			}
+			// 2 of the 4 acknowledged packets were CE-marked
+			ecnHandler.EXPECT().HandleNewlyAcked(gomock.Any(), int64(0), int64(2), int64(2)).Return(true)
+			_, err := handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Largest: 13, Smallest: 10}}, ECT1: 2, ECNCE: 2}, protocol.Encryption1RTT, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			ecnHandler.EXPECT().HandleNewlyAcked(gomock.Any(), int64(0), int64(4), int64(2)).Return(false)
+			_, err = handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Largest: 15, Smallest: 10}}, ECT1: 4, ECNCE: 2}, protocol.Encryption1RTT, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(scalable.feedback).To(Equal([][2]protocol.ByteCount{{4000, 2000}, {2000, 0}}))
+		})
+	})
+
+	It("uses ECT(1) with scalable congestion controllers", func() {
+		rttStats := utils.NewRTTStats()
+		h := newSentPacketHandler(0, 1234, rttStats, false, true, protocol.PerspectiveClient, func(r *utils.RTTStats, size protocol.ByteCount, tr *logging.ConnectionTracer) congestion.SendAlgorithmWithDebugInfos {
+			return congestion.NewPragueSender(r, size, tr)
+		}, nil, utils.DefaultLogger)
+		Expect(h.ECNMode(true)).To(Equal(protocol.ECT1))
+		h = newSentPacketHandler(0, 1234, rttStats, false, true, protocol.PerspectiveClient, nil, nil, utils.DefaultLogger)
+		Expect(h.ECNMode(true)).To(Equal(protocol.ECT0))
+	})
+
+	It("uses the congestion controller factory", func() {
+		cong := mocks.NewMockSendAlgorithmWithDebugInfos(mockCtrl)
+		tracer, _ := mocklogging.NewMockConnectionTracer(mockCtrl)
+		rttStats := utils.NewRTTStats()
+		h := newSentPacketHandler(0, 1234, rttStats, false, false, protocol.PerspectiveClient, func(r *utils.RTTStats, size protocol.ByteCount, tr *logging.ConnectionTracer) congestion.SendAlgorithmWithDebugInfos {
+			Expect(r).To(BeIdenticalTo(rttStats))
+			Expect(size).To(Equal(protocol.ByteCount(1234)))
+			Expect(tr).To(BeIdenticalTo(tracer))
+			return cong
+		}, tracer, utils.DefaultLogger)
+		Expect(h.congestion).To(BeIdenticalTo(cong))
 	})
 })
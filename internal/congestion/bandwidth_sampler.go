@@ -0,0 +1,137 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+)
+
+// A sendState is a snapshot of the delivery state when a packet was sent.
+type sendState struct {
+	sentTime time.Time
+	size     protocol.ByteCount
+	// the total number of bytes delivered when the packet was sent
+	delivered protocol.ByteCount
+	// the time when the last packet was acknowledged before this packet was sent
+	deliveredTime time.Time
+	// the send time of the packet that was acknowledged last before this packet was sent
+	firstSentTime time.Time
+	isAppLimited  bool
+}
+
+// A rateSample is a delivery rate sample, taken when a packet is acknowledged.
+type rateSample struct {
+	// deliveryRate is 0 if the sampling interval was too short to obtain a valid sample
+	deliveryRate Bandwidth
+	// the total number of bytes delivered when the acknowledged packet was sent
+	priorDelivered protocol.ByteCount
+	isAppLimited   bool
+}
+
+// The bandwidthSampler estimates the delivery rate,
+// as described in draft-cheng-iccrg-delivery-rate-estimation.
+// Every time a packet is acknowledged, the number of bytes delivered since that packet was sent
+// is divided by the time that has passed, giving a sample of the current delivery rate.
+type bandwidthSampler struct {
+	packets map[protocol.PacketNumber]sendState
+
+	delivered     protocol.ByteCount
+	deliveredTime time.Time
+	firstSentTime time.Time
+	bytesInFlight protocol.ByteCount
+
+	// The sampler is application-limited until this number of bytes has been delivered.
+	// 0 if the sampler is not application-limited.
+	appLimitedUntil protocol.ByteCount
+}
+
+func newBandwidthSampler() *bandwidthSampler {
+	return &bandwidthSampler{packets: make(map[protocol.PacketNumber]sendState)}
+}
+
+func (s *bandwidthSampler) OnPacketSent(sentTime time.Time, pn protocol.PacketNumber, size protocol.ByteCount) {
+	// Packet numbers are not unique across packet number spaces.
+	if old, ok := s.packets[pn]; ok {
+		s.bytesInFlight -= min(old.size, s.bytesInFlight)
+	}
+	if s.bytesInFlight == 0 {
+		s.firstSentTime = sentTime
+		s.deliveredTime = sentTime
+	}
+	s.packets[pn] = sendState{
+		sentTime:      sentTime,
+		size:          size,
+		delivered:     s.delivered,
+		deliveredTime: s.deliveredTime,
+		firstSentTime: s.firstSentTime,
+		isAppLimited:  s.appLimitedUntil > 0,
+	}
+	s.bytesInFlight += size
+}
+
+// OnAppLimited is called when the sender runs out of data to send.
+// All samples taken until the packets currently in flight are acknowledged are marked as application-limited.
+func (s *bandwidthSampler) OnAppLimited() {
+	s.appLimitedUntil = max(s.delivered+s.bytesInFlight, 1)
+}
+
+func (s *bandwidthSampler) IsAppLimited() bool {
+	return s.appLimitedUntil > 0
+}
+
+// OnPacketAcked is called when a packet is acknowledged.
+// It returns false if the packet wasn't tracked by the sampler.
+// Samples with a sampling interval shorter than minRTT are not valid,
+// since they would overestimate the delivery rate in case of ACK compression.
+func (s *bandwidthSampler) OnPacketAcked(now time.Time, pn protocol.PacketNumber, minRTT time.Duration) (rateSample, bool) {
+	p, ok := s.packets[pn]
+	if !ok {
+		return rateSample{}, false
+	}
+	delete(s.packets, pn)
+	s.bytesInFlight -= min(p.size, s.bytesInFlight)
+	s.delivered += p.size
+	s.deliveredTime = now
+	if s.appLimitedUntil > 0 && s.delivered > s.appLimitedUntil {
+		s.appLimitedUntil = 0
+	}
+	// The send time of this packet marks the beginning of the next send interval.
+	s.firstSentTime = p.sentTime
+
+	sample := rateSample{priorDelivered: p.delivered, isAppLimited: p.isAppLimited}
+	// Use the longer of the send and the ACK interval,
+	// to avoid overestimating the delivery rate when ACKs are compressed.
+	interval := max(p.sentTime.Sub(p.firstSentTime), now.Sub(p.deliveredTime))
+	if interval > 0 && interval >= minRTT {
+		sample.deliveryRate = BandwidthFromDelta(s.delivered-p.delivered, interval)
+	}
+	return sample, true
+}
+
+// OnPacketLost is called when a packet is declared lost.
+func (s *bandwidthSampler) OnPacketLost(pn protocol.PacketNumber) {
+	p, ok := s.packets[pn]
+	if !ok {
+		return
+	}
+	delete(s.packets, pn)
+	s.bytesInFlight -= min(p.size, s.bytesInFlight)
+}
+
+// RemoveOlderThan removes the state of all packets sent before t.
+// Packets might never be acknowledged or declared lost, for example when keys are dropped.
+func (s *bandwidthSampler) RemoveOlderThan(t time.Time) {
+	for pn, p := range s.packets {
+		if p.sentTime.Before(t) {
+			s.OnPacketLost(pn)
+		}
+	}
+}
+
+func (s *bandwidthSampler) BytesInFlight() protocol.ByteCount {
+	return s.bytesInFlight
+}
+
+func (s *bandwidthSampler) TotalDelivered() protocol.ByteCount {
+	return s.delivered
+}
@@ -0,0 +1,102 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Bandwidth Sampler", func() {
+	var (
+		s   *bandwidthSampler
+		now time.Time
+	)
+
+	BeforeEach(func() {
+		s = newBandwidthSampler()
+		now = time.Now()
+	})
+
+	It("tracks the bytes in flight", func() {
+		s.OnPacketSent(now, 1, 1000)
+		s.OnPacketSent(now, 2, 1000)
+		s.OnPacketSent(now, 3, 1000)
+		Expect(s.BytesInFlight()).To(BeEquivalentTo(3000))
+		_, ok := s.OnPacketAcked(now.Add(time.Second), 1, 0)
+		Expect(ok).To(BeTrue())
+		Expect(s.BytesInFlight()).To(BeEquivalentTo(2000))
+		Expect(s.TotalDelivered()).To(BeEquivalentTo(1000))
+		s.OnPacketLost(2)
+		Expect(s.BytesInFlight()).To(BeEquivalentTo(1000))
+		Expect(s.TotalDelivered()).To(BeEquivalentTo(1000))
+		_, ok = s.OnPacketAcked(now.Add(time.Second), 2, 0)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("doesn't count packets twice when packet numbers are reused", func() {
+		s.OnPacketSent(now, 0, 1000)
+		s.OnPacketSent(now, 0, 1200)
+		Expect(s.BytesInFlight()).To(BeEquivalentTo(1200))
+	})
+
+	It("measures the delivery rate", func() {
+		// send one packet every 10ms, and receive the acknowledgement 100ms later
+		const packetSize = 1000
+		var lastSample rateSample
+		for i := 0; i < 100; i++ {
+			s.OnPacketSent(now.Add(time.Duration(i)*10*time.Millisecond), protocol.PacketNumber(i), packetSize)
+			if i >= 10 {
+				sample, ok := s.OnPacketAcked(now.Add(time.Duration(i)*10*time.Millisecond), protocol.PacketNumber(i-10), 0)
+				Expect(ok).To(BeTrue())
+				lastSample = sample
+			}
+		}
+		Expect(lastSample.deliveryRate).To(Equal(BandwidthFromDelta(packetSize, 10*time.Millisecond)))
+		Expect(lastSample.isAppLimited).To(BeFalse())
+	})
+
+	It("doesn't take samples when the interval is shorter than the min RTT", func() {
+		s.OnPacketSent(now, 1, 1000)
+		sample, ok := s.OnPacketAcked(now.Add(10*time.Millisecond), 1, 20*time.Millisecond)
+		Expect(ok).To(BeTrue())
+		Expect(sample.deliveryRate).To(BeZero())
+		s.OnPacketSent(now.Add(20*time.Millisecond), 2, 1000)
+		sample, ok = s.OnPacketAcked(now.Add(50*time.Millisecond), 2, 20*time.Millisecond)
+		Expect(ok).To(BeTrue())
+		Expect(sample.deliveryRate).To(Equal(BandwidthFromDelta(1000, 30*time.Millisecond)))
+	})
+
+	It("marks samples as application-limited", func() {
+		s.OnPacketSent(now, 1, 1000)
+		s.OnPacketSent(now, 2, 1000)
+		s.OnAppLimited()
+		Expect(s.IsAppLimited()).To(BeTrue())
+		s.OnPacketSent(now, 3, 1000)
+		sample, ok := s.OnPacketAcked(now.Add(time.Second), 1, 0)
+		Expect(ok).To(BeTrue())
+		Expect(sample.isAppLimited).To(BeFalse())
+		sample, ok = s.OnPacketAcked(now.Add(time.Second), 2, 0)
+		Expect(ok).To(BeTrue())
+		Expect(sample.isAppLimited).To(BeFalse())
+		// all packets sent before the sender became application-limited were delivered
+		Expect(s.IsAppLimited()).To(BeTrue())
+		sample, ok = s.OnPacketAcked(now.Add(time.Second), 3, 0)
+		Expect(ok).To(BeTrue())
+		Expect(sample.isAppLimited).To(BeTrue())
+		Expect(s.IsAppLimited()).To(BeFalse())
+	})
+
+	It("removes old packets", func() {
+		s.OnPacketSent(now, 1, 1000)
+		s.OnPacketSent(now.Add(time.Second), 2, 1000)
+		s.RemoveOlderThan(now.Add(time.Millisecond))
+		Expect(s.BytesInFlight()).To(BeEquivalentTo(1000))
+		_, ok := s.OnPacketAcked(now.Add(2*time.Second), 1, 0)
+		Expect(ok).To(BeFalse())
+		_, ok = s.OnPacketAcked(now.Add(2*time.Second), 2, 0)
+		Expect(ok).To(BeTrue())
+	})
+})
@@ -0,0 +1,711 @@
+package congestion
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
+	"github.com/quic-go/quic-go/logging"
+)
+
+// This file implements BBR congestion control, loosely following BBRv3
+// (draft-cardwell-iccrg-bbr-congestion-control-02).
+
+type bbrMode uint8
+
+const (
+	bbrModeStartup bbrMode = iota
+	bbrModeDrain
+	bbrModeProbeBW
+	bbrModeProbeRTT
+)
+
+func (m bbrMode) String() string {
+	switch m {
+	case bbrModeStartup:
+		return "Startup"
+	case bbrModeDrain:
+		return "Drain"
+	case bbrModeProbeBW:
+		return "ProbeBW"
+	case bbrModeProbeRTT:
+		return "ProbeRTT"
+	default:
+		return fmt.Sprintf("unknown BBR mode: %d", m)
+	}
+}
+
+type bbrProbeBWPhase uint8
+
+const (
+	bbrProbeBWDown bbrProbeBWPhase = iota
+	bbrProbeBWCruise
+	bbrProbeBWRefill
+	bbrProbeBWUp
+)
+
+func (p bbrProbeBWPhase) String() string {
+	switch p {
+	case bbrProbeBWDown:
+		return "Down"
+	case bbrProbeBWCruise:
+		return "Cruise"
+	case bbrProbeBWRefill:
+		return "Refill"
+	case bbrProbeBWUp:
+		return "Up"
+	default:
+		return fmt.Sprintf("unknown ProbeBW phase: %d", p)
+	}
+}
+
+const (
+	// The pacing gain used in Startup, 4*ln(2).
+	// This allows the sending rate to double every round trip.
+	bbrStartupPacingGain = 2.77
+	bbrStartupCwndGain   = 2
+	// The pacing gain used in Drain, chosen to drain the queue built up in Startup in a single round trip.
+	bbrDrainPacingGain     = 0.35
+	bbrProbeBWDownGain     = 0.9
+	bbrProbeBWUpGain       = 1.25
+	bbrProbeBWCwndGain     = 2
+	bbrProbeBWUpCwndGain   = 2.25
+	bbrProbeRTTCwndGain    = 0.5
+	bbrPacingMarginPercent = 1
+	// The maximum tolerated loss rate per round trip.
+	bbrLossThreshold = 0.02
+	// The multiplicative decrease applied to inflight_hi when the loss rate exceeds bbrLossThreshold.
+	bbrBeta = 0.7
+	// The fraction of inflight_hi that is used when not probing for bandwidth.
+	// This leaves some space in the bottleneck buffer for other flows.
+	bbrHeadroom = 0.85
+	// Startup is exited when the bandwidth estimate didn't grow by 25% for 3 consecutive round trips.
+	bbrFullBandwidthGrowth = 1.25
+	bbrFullBandwidthRounds = 3
+	bbrMinPipeCwndPackets  = 4
+	// The number of extra packets added to the congestion window to account for delayed and aggregated ACKs.
+	bbrExtraAckedPackets = 3
+	// The max bandwidth filter spans the current and the previous ProbeBW cycle.
+	bbrMaxBandwidthFilterCycles = 1
+	bbrMinRTTFilterLength       = 10 * time.Second
+	bbrProbeRTTInterval         = 5 * time.Second
+	bbrProbeRTTDuration         = 200 * time.Millisecond
+	// The minimum and maximum time spent in ProbeBW_Cruise before probing for bandwidth.
+	bbrMinProbeBWWait = 2 * time.Second
+	bbrMaxProbeBWWait = 3 * time.Second
+	// Probe for bandwidth after at most this number of round trips, to coexist with Reno and CUBIC flows.
+	bbrMaxProbeBWRounds = 63
+)
+
+type bbrSender struct {
+	rttStats *utils.RTTStats
+	clock    Clock
+	pacer    *pacer
+	sampler  *bandwidthSampler
+	rand     utils.Rand
+
+	// The start time is used as the time base for the min RTT filter.
+	start time.Time
+	// The max bandwidth filter uses the ProbeBW cycle count as its time base.
+	maxBandwidthFilter *windowedFilter[Bandwidth, uint64]
+	minRTTFilter       *windowedFilter[time.Duration, time.Duration]
+	cycleCount         uint64
+
+	mode       bbrMode
+	phase      bbrProbeBWPhase
+	pacingGain float64
+	cwndGain   float64
+	pacingRate Bandwidth
+
+	// Round trip counting
+	roundCount         uint64
+	roundStart         bool
+	nextRoundDelivered protocol.ByteCount
+	roundsInPhase      uint64
+
+	// Startup
+	fullBandwidthReached bool
+	fullBandwidth        Bandwidth
+	fullBandwidthCount   int
+
+	// Loss handling
+	lostInRound       protocol.ByteCount
+	lossInRoundActed  bool
+	inflightHi        protocol.ByteCount // 0 if not set
+	probeUpStep       protocol.ByteCount
+	largestSentAtLoss protocol.PacketNumber
+
+	// ProbeBW
+	cycleStart    time.Time
+	roundsInCycle uint64
+	probeBWWait   time.Duration
+
+	// ProbeRTT
+	probeRTTMinRTT    time.Duration
+	probeRTTMinStamp  time.Time
+	probeRTTExpired   bool
+	probeRTTDoneStamp time.Time
+	probeRTTRoundDone bool
+	priorCwnd         protocol.ByteCount
+
+	// App-limited detection
+	lastSentTime time.Time
+	cwndLimited  bool
+
+	largestSentPacketNumber  protocol.PacketNumber
+	largestAckedPacketNumber protocol.PacketNumber
+
+	congestionWindow        protocol.ByteCount
+	initialCongestionWindow protocol.ByteCount
+	maxDatagramSize         protocol.ByteCount
+
+	lastState logging.CongestionState
+	tracer    *logging.ConnectionTracer
+}
+
+var (
+	_ SendAlgorithm               = &bbrSender{}
+	_ SendAlgorithmWithDebugInfos = &bbrSender{}
+)
+
+// NewBBRSender makes a new BBR sender
+func NewBBRSender(
+	clock Clock,
+	rttStats *utils.RTTStats,
+	initialMaxDatagramSize protocol.ByteCount,
+	tracer *logging.ConnectionTracer,
+) *bbrSender {
+	c := &bbrSender{
+		rttStats:                rttStats,
+		clock:                   clock,
+		initialCongestionWindow: initialCongestionWindow * initialMaxDatagramSize,
+		maxDatagramSize:         initialMaxDatagramSize,
+		tracer:                  tracer,
+	}
+	c.pacer = newRatePacer(func() uint64 { return max(uint64(c.pacingRate/BytesPerSecond), 1) })
+	c.reset()
+	if c.tracer != nil && c.tracer.UpdatedCongestionState != nil {
+		c.lastState = logging.CongestionStateSlowStart
+		c.tracer.UpdatedCongestionState(logging.CongestionStateSlowStart)
+	}
+	return c
+}
+
+func (c *bbrSender) reset() {
+	c.sampler = newBandwidthSampler()
+	c.start = c.clock.Now()
+	c.maxBandwidthFilter = newMaxFilter[Bandwidth, uint64](bbrMaxBandwidthFilterCycles)
+	c.minRTTFilter = newMinFilter[time.Duration, time.Duration](bbrMinRTTFilterLength)
+	c.cycleCount = 0
+	c.roundCount = 0
+	c.roundStart = false
+	c.nextRoundDelivered = 0
+	c.roundsInPhase = 0
+	c.fullBandwidthReached = false
+	c.fullBandwidth = 0
+	c.fullBandwidthCount = 0
+	c.lostInRound = 0
+	c.lossInRoundActed = false
+	c.inflightHi = 0
+	c.probeUpStep = 0
+	c.probeRTTMinRTT = 0
+	c.probeRTTMinStamp = c.start
+	c.probeRTTExpired = false
+	c.probeRTTDoneStamp = time.Time{}
+	c.probeRTTRoundDone = false
+	c.lastSentTime = time.Time{}
+	c.cwndLimited = false
+	c.largestSentPacketNumber = protocol.InvalidPacketNumber
+	c.largestAckedPacketNumber = protocol.InvalidPacketNumber
+	c.largestSentAtLoss = protocol.InvalidPacketNumber
+	c.congestionWindow = c.initialCongestionWindow
+	c.enterStartup()
+	c.pacingRate = c.initialPacingRate()
+}
+
+// TimeUntilSend returns when the next packet should be sent.
+func (c *bbrSender) TimeUntilSend(_ protocol.ByteCount) time.Time {
+	return c.pacer.TimeUntilSend()
+}
+
+func (c *bbrSender) HasPacingBudget(now time.Time) bool {
+	return c.pacer.Budget(now) >= c.maxDatagramSize
+}
+
+func (c *bbrSender) OnPacketSent(
+	sentTime time.Time,
+	bytesInFlight protocol.ByteCount,
+	packetNumber protocol.PacketNumber,
+	bytes protocol.ByteCount,
+	isRetransmittable bool,
+) {
+	c.pacer.SentPacket(sentTime, bytes)
+	if !isRetransmittable {
+		return
+	}
+	c.largestSentPacketNumber = packetNumber
+	// bytesInFlight already includes this packet.
+	if c.isApplicationLimited(sentTime, bytesInFlight-min(bytes, bytesInFlight)) {
+		c.sampler.OnAppLimited()
+		c.maybeTraceStateChange()
+	}
+	c.sampler.OnPacketSent(sentTime, packetNumber, bytes)
+	c.lastSentTime = sentTime
+	c.cwndLimited = false
+}
+
+// isApplicationLimited detects if the sender ran out of data to send.
+// This is the case if neither the congestion window nor the pacer prevented us from sending
+// since the last packet was sent, but there was a pause in sending anyway.
+func (c *bbrSender) isApplicationLimited(now time.Time, bytesInFlight protocol.ByteCount) bool {
+	if c.lastSentTime.IsZero() || c.cwndLimited || bytesInFlight >= c.congestionWindow {
+		return false
+	}
+	var pacingInterval time.Duration
+	if rate := c.pacingRate / BytesPerSecond; rate > 0 {
+		pacingInterval = time.Duration(uint64(c.maxDatagramSize) * uint64(time.Second) / uint64(rate))
+	}
+	return now.Sub(c.lastSentTime) > 2*pacingInterval+protocol.TimerGranularity
+}
+
+func (c *bbrSender) CanSend(bytesInFlight protocol.ByteCount) bool {
+	if bytesInFlight >= c.GetCongestionWindow() {
+		c.cwndLimited = true
+		return false
+	}
+	return true
+}
+
+func (c *bbrSender) InRecovery() bool {
+	return c.largestAckedPacketNumber != protocol.InvalidPacketNumber && c.largestAckedPacketNumber <= c.largestSentAtLoss
+}
+
+func (c *bbrSender) InSlowStart() bool {
+	return c.mode == bbrModeStartup
+}
+
+func (c *bbrSender) GetCongestionWindow() protocol.ByteCount {
+	return c.congestionWindow
+}
+
+// MaybeExitSlowStart is called after every new RTT sample.
+// BBR exits Startup based on the bandwidth estimate, so it only uses the RTT sample to update the min RTT filter.
+func (c *bbrSender) MaybeExitSlowStart() {
+	rtt := c.rttStats.LatestRTT()
+	if rtt <= 0 {
+		return
+	}
+	now := c.clock.Now()
+	c.minRTTFilter.Update(rtt, max(now.Sub(c.start), 0))
+	if now.Sub(c.probeRTTMinStamp) > bbrProbeRTTInterval {
+		c.probeRTTExpired = true
+	}
+	if c.probeRTTMinRTT == 0 || rtt <= c.probeRTTMinRTT || c.probeRTTExpired {
+		c.probeRTTMinRTT = rtt
+		c.probeRTTMinStamp = now
+	}
+	if c.bandwidth() == 0 {
+		// Use the first RTT samples to derive the initial pacing rate.
+		c.pacingRate = c.initialPacingRate()
+	}
+}
+
+func (c *bbrSender) OnPacketAcked(
+	ackedPacketNumber protocol.PacketNumber,
+	ackedBytes protocol.ByteCount,
+	priorInFlight protocol.ByteCount,
+	eventTime time.Time,
+) {
+	c.largestAckedPacketNumber = max(ackedPacketNumber, c.largestAckedPacketNumber)
+	sample, ok := c.sampler.OnPacketAcked(eventTime, ackedPacketNumber, c.minRTT())
+	if !ok {
+		return
+	}
+	c.updateRound(eventTime, sample)
+	c.updateMaxBandwidth(sample)
+	c.checkFullBandwidthReached(sample)
+	c.updateProbeBWUp(priorInFlight)
+	c.updateStateMachine(eventTime)
+	c.updatePacingRate()
+	c.updateCongestionWindow(ackedBytes)
+	c.maybeTraceStateChange()
+}
+
+func (c *bbrSender) updateRound(now time.Time, sample rateSample) {
+	c.roundStart = false
+	if sample.priorDelivered < c.nextRoundDelivered {
+		return
+	}
+	c.nextRoundDelivered = c.sampler.TotalDelivered()
+	c.roundCount++
+	c.roundsInPhase++
+	c.roundsInCycle++
+	c.roundStart = true
+	c.lostInRound = 0
+	c.lossInRoundActed = false
+	// Packets might never be acknowledged or declared lost, for example when keys are dropped.
+	c.sampler.RemoveOlderThan(now.Add(-10 * c.rttStats.PTO(true)))
+}
+
+func (c *bbrSender) updateMaxBandwidth(sample rateSample) {
+	if sample.deliveryRate == 0 {
+		return
+	}
+	// Application-limited samples underestimate the bandwidth,
+	// unless they're larger than the current estimate.
+	if !sample.isAppLimited || sample.deliveryRate >= c.maxBandwidthFilter.Best() {
+		c.maxBandwidthFilter.Update(sample.deliveryRate, c.cycleCount)
+	}
+}
+
+func (c *bbrSender) checkFullBandwidthReached(sample rateSample) {
+	if c.fullBandwidthReached || !c.roundStart || sample.isAppLimited {
+		return
+	}
+	bw := c.bandwidth()
+	if bw == 0 {
+		return
+	}
+	if float64(bw) >= float64(c.fullBandwidth)*bbrFullBandwidthGrowth {
+		c.fullBandwidth = bw
+		c.fullBandwidthCount = 0
+		return
+	}
+	c.fullBandwidthCount++
+	if c.fullBandwidthCount >= bbrFullBandwidthRounds {
+		c.fullBandwidthReached = true
+	}
+}
+
+// updateProbeBWUp raises inflight_hi while probing for bandwidth.
+// The step size doubles every round trip, as long as the sender is limited by the congestion window.
+func (c *bbrSender) updateProbeBWUp(priorInFlight protocol.ByteCount) {
+	if c.mode != bbrModeProbeBW || c.phase != bbrProbeBWUp || !c.roundStart || c.inflightHi == 0 {
+		return
+	}
+	if priorInFlight+c.maxDatagramSize < c.congestionWindow {
+		return
+	}
+	c.inflightHi += c.probeUpStep
+	c.probeUpStep = min(2*c.probeUpStep, c.maxCongestionWindow())
+}
+
+func (c *bbrSender) updateStateMachine(now time.Time) {
+	inflight := c.sampler.BytesInFlight()
+	switch c.mode {
+	case bbrModeStartup:
+		if c.fullBandwidthReached {
+			c.enterDrain()
+		}
+	case bbrModeDrain:
+		if inflight <= c.bdp(1) {
+			c.enterProbeBW(now)
+		}
+	case bbrModeProbeBW:
+		c.updateProbeBWPhase(now, inflight)
+	}
+	c.updateProbeRTT(now, inflight)
+}
+
+func (c *bbrSender) updateProbeBWPhase(now time.Time, inflight protocol.ByteCount) {
+	switch c.phase {
+	case bbrProbeBWDown:
+		if c.isTimeToProbeBW(now) {
+			c.enterProbeBWPhase(bbrProbeBWRefill)
+			return
+		}
+		if inflight <= min(c.bdp(1), c.inflightWithHeadroom()) {
+			c.enterProbeBWPhase(bbrProbeBWCruise)
+		}
+	case bbrProbeBWCruise:
+		if c.isTimeToProbeBW(now) {
+			c.enterProbeBWPhase(bbrProbeBWRefill)
+		}
+	case bbrProbeBWRefill:
+		// Refill the pipe for one round trip, so that the bandwidth probe measures the bottleneck,
+		// and not the queue drained in the previous phases.
+		if c.roundStart {
+			c.probeUpStep = c.maxDatagramSize
+			c.enterProbeBWPhase(bbrProbeBWUp)
+		}
+	case bbrProbeBWUp:
+		if c.roundsInPhase >= 1 && inflight > c.bdp(bbrProbeBWUpGain) {
+			c.startProbeBWDown(now)
+		}
+	}
+}
+
+func (c *bbrSender) isTimeToProbeBW(now time.Time) bool {
+	if now.Sub(c.cycleStart) >= c.probeBWWait {
+		return true
+	}
+	// Probe at least as often as a Reno flow with the same BDP would grow its congestion window by one packet per round trip.
+	return c.roundsInCycle >= min(uint64(c.bdp(1)/c.maxDatagramSize), bbrMaxProbeBWRounds)
+}
+
+func (c *bbrSender) updateProbeRTT(now time.Time, inflight protocol.ByteCount) {
+	if c.mode != bbrModeProbeRTT {
+		if c.probeRTTExpired {
+			c.enterProbeRTT()
+		}
+		return
+	}
+	if c.probeRTTDoneStamp.IsZero() {
+		if inflight <= c.probeRTTCwnd() {
+			c.probeRTTDoneStamp = now.Add(bbrProbeRTTDuration)
+			c.probeRTTRoundDone = false
+			c.nextRoundDelivered = c.sampler.TotalDelivered()
+		}
+		return
+	}
+	if c.roundStart {
+		c.probeRTTRoundDone = true
+	}
+	if c.probeRTTRoundDone && !now.Before(c.probeRTTDoneStamp) {
+		c.probeRTTMinStamp = now
+		c.congestionWindow = max(c.congestionWindow, c.priorCwnd)
+		if c.fullBandwidthReached {
+			c.enterProbeBW(now)
+		} else {
+			c.enterStartup()
+		}
+	}
+}
+
+func (c *bbrSender) enterStartup() {
+	c.mode = bbrModeStartup
+	c.pacingGain = bbrStartupPacingGain
+	c.cwndGain = bbrStartupCwndGain
+}
+
+func (c *bbrSender) enterDrain() {
+	c.mode = bbrModeDrain
+	c.pacingGain = bbrDrainPacingGain
+	c.cwndGain = bbrStartupCwndGain
+}
+
+func (c *bbrSender) enterProbeBW(now time.Time) {
+	c.mode = bbrModeProbeBW
+	c.cwndGain = bbrProbeBWCwndGain
+	c.startProbeBWDown(now)
+}
+
+func (c *bbrSender) startProbeBWDown(now time.Time) {
+	c.cycleCount++
+	c.cycleStart = now
+	c.roundsInCycle = 0
+	c.probeBWWait = bbrMinProbeBWWait + time.Duration(c.rand.Int31n(int32((bbrMaxProbeBWWait-bbrMinProbeBWWait)/time.Millisecond)))*time.Millisecond
+	c.enterProbeBWPhase(bbrProbeBWDown)
+}
+
+func (c *bbrSender) enterProbeBWPhase(phase bbrProbeBWPhase) {
+	c.phase = phase
+	c.roundsInPhase = 0
+	c.cwndGain = bbrProbeBWCwndGain
+	switch phase {
+	case bbrProbeBWDown:
+		c.pacingGain = bbrProbeBWDownGain
+	case bbrProbeBWCruise, bbrProbeBWRefill:
+		c.pacingGain = 1
+	case bbrProbeBWUp:
+		c.pacingGain = bbrProbeBWUpGain
+		c.cwndGain = bbrProbeBWUpCwndGain
+	}
+}
+
+func (c *bbrSender) enterProbeRTT() {
+	c.mode = bbrModeProbeRTT
+	c.pacingGain = 1
+	c.cwndGain = bbrProbeRTTCwndGain
+	c.probeRTTExpired = false
+	c.probeRTTDoneStamp = time.Time{}
+	c.priorCwnd = c.congestionWindow
+}
+
+func (c *bbrSender) updatePacingRate() {
+	bw := c.bandwidth()
+	if bw == 0 {
+		c.pacingRate = c.initialPacingRate()
+		return
+	}
+	rate := Bandwidth(c.pacingGain * float64(bw) * (100 - bbrPacingMarginPercent) / 100)
+	// Don't decrease the pacing rate in Startup. Early bandwidth samples might be application-limited.
+	if c.fullBandwidthReached || rate > c.pacingRate {
+		c.pacingRate = rate
+	}
+}
+
+func (c *bbrSender) initialPacingRate() Bandwidth {
+	srtt := c.rttStats.SmoothedRTT()
+	if srtt == 0 {
+		// We haven't measured an RTT yet, assume a nominal RTT of 1ms.
+		srtt = time.Millisecond
+	}
+	return Bandwidth(bbrStartupPacingGain * float64(BandwidthFromDelta(c.initialCongestionWindow, srtt)))
+}
+
+func (c *bbrSender) updateCongestionWindow(ackedBytes protocol.ByteCount) {
+	if c.bandwidth() == 0 {
+		// We don't have a bandwidth estimate yet, and therefore can't calculate the BDP.
+		c.congestionWindow += ackedBytes
+	} else {
+		target := c.bdp(c.cwndGain) + bbrExtraAckedPackets*c.maxDatagramSize
+		if c.fullBandwidthReached {
+			c.congestionWindow = min(c.congestionWindow+ackedBytes, target)
+		} else if c.congestionWindow < target || c.sampler.TotalDelivered() < c.initialCongestionWindow {
+			c.congestionWindow += ackedBytes
+		}
+	}
+	c.boundCongestionWindow()
+}
+
+func (c *bbrSender) boundCongestionWindow() {
+	if c.inflightHi > 0 {
+		if c.mode == bbrModeProbeBW && c.phase != bbrProbeBWCruise {
+			c.congestionWindow = min(c.congestionWindow, c.inflightHi)
+		} else {
+			c.congestionWindow = min(c.congestionWindow, c.inflightWithHeadroom())
+		}
+	}
+	if c.mode == bbrModeProbeRTT {
+		c.congestionWindow = min(c.congestionWindow, c.probeRTTCwnd())
+	}
+	c.congestionWindow = max(c.congestionWindow, c.minCongestionWindow())
+	c.congestionWindow = min(c.congestionWindow, c.maxCongestionWindow())
+}
+
+func (c *bbrSender) OnCongestionEvent(packetNumber protocol.PacketNumber, lostBytes, priorInFlight protocol.ByteCount) {
+	// A congestion event without lost bytes is an ECN-CE mark.
+	// It is treated as a signal that inflight is too high, just like excessive loss.
+	if lostBytes > 0 {
+		c.sampler.OnPacketLost(packetNumber)
+		c.lostInRound += lostBytes
+		if float64(c.lostInRound) <= bbrLossThreshold*float64(priorInFlight) {
+			return
+		}
+	}
+	if packetNumber > c.largestSentAtLoss {
+		c.largestSentAtLoss = c.largestSentPacketNumber
+	}
+	c.maybeTraceStateChange()
+	// React at most once per round trip.
+	if c.lossInRoundActed {
+		return
+	}
+	c.lossInRoundActed = true
+	c.inflightHi = max(
+		protocol.ByteCount(bbrBeta*float64(max(priorInFlight, c.bdp(1)))),
+		c.minCongestionWindow(),
+	)
+	switch c.mode {
+	case bbrModeStartup:
+		c.fullBandwidthReached = true
+		c.enterDrain()
+	case bbrModeProbeBW:
+		if c.phase == bbrProbeBWUp || c.phase == bbrProbeBWRefill {
+			c.startProbeBWDown(c.clock.Now())
+		}
+	}
+	c.updatePacingRate()
+	c.boundCongestionWindow()
+	c.maybeTraceStateChange()
+}
+
+// OnRetransmissionTimeout is called on an retransmission timeout
+func (c *bbrSender) OnRetransmissionTimeout(packetsRetransmitted bool) {
+	c.largestSentAtLoss = protocol.InvalidPacketNumber
+	if !packetsRetransmitted {
+		return
+	}
+	c.congestionWindow = c.minCongestionWindow()
+}
+
+// OnConnectionMigration is called when the connection is migrated to a new path.
+// The congestion controller starts from scratch on the new path.
+func (c *bbrSender) OnConnectionMigration() {
+	c.reset()
+	c.maybeTraceStateChange()
+}
+
+// bandwidth returns the current bandwidth estimate.
+// It returns 0 if no bandwidth sample was taken yet.
+func (c *bbrSender) bandwidth() Bandwidth {
+	return c.maxBandwidthFilter.Best()
+}
+
+// BandwidthEstimate returns the current bandwidth estimate
+func (c *bbrSender) BandwidthEstimate() Bandwidth {
+	if bw := c.bandwidth(); bw > 0 {
+		return bw
+	}
+	return c.initialPacingRate()
+}
+
+func (c *bbrSender) minRTT() time.Duration {
+	if rtt := c.minRTTFilter.Best(); rtt > 0 {
+		return rtt
+	}
+	return c.rttStats.MinRTT()
+}
+
+// bdp returns the bandwidth-delay product, multiplied by gain.
+func (c *bbrSender) bdp(gain float64) protocol.ByteCount {
+	bw := c.bandwidth()
+	minRTT := c.minRTT()
+	if bw == 0 || minRTT == 0 {
+		return c.initialCongestionWindow
+	}
+	return protocol.ByteCount(gain * float64(bw/BytesPerSecond) * minRTT.Seconds())
+}
+
+func (c *bbrSender) inflightWithHeadroom() protocol.ByteCount {
+	if c.inflightHi == 0 {
+		return protocol.MaxByteCount
+	}
+	return max(protocol.ByteCount(bbrHeadroom*float64(c.inflightHi)), c.minCongestionWindow())
+}
+
+func (c *bbrSender) probeRTTCwnd() protocol.ByteCount {
+	return max(c.bdp(bbrProbeRTTCwndGain), c.minCongestionWindow())
+}
+
+func (c *bbrSender) minCongestionWindow() protocol.ByteCount {
+	return bbrMinPipeCwndPackets * c.maxDatagramSize
+}
+
+func (c *bbrSender) maxCongestionWindow() protocol.ByteCount {
+	return protocol.MaxCongestionWindowPackets * c.maxDatagramSize
+}
+
+func (c *bbrSender) maybeTraceStateChange() {
+	if c.tracer == nil || c.tracer.UpdatedCongestionState == nil {
+		return
+	}
+	var new logging.CongestionState
+	switch {
+	case c.InRecovery():
+		new = logging.CongestionStateRecovery
+	case c.sampler.IsAppLimited():
+		new = logging.CongestionStateApplicationLimited
+	case c.InSlowStart():
+		new = logging.CongestionStateSlowStart
+	default:
+		new = logging.CongestionStateCongestionAvoidance
+	}
+	if new == c.lastState {
+		return
+	}
+	c.tracer.UpdatedCongestionState(new)
+	c.lastState = new
+}
+
+func (c *bbrSender) SetMaxDatagramSize(s protocol.ByteCount) {
+	cwndIsMinCwnd := c.congestionWindow == c.minCongestionWindow()
+	c.maxDatagramSize = s
+	if cwndIsMinCwnd {
+		c.congestionWindow = c.minCongestionWindow()
+	}
+	c.congestionWindow = min(c.congestionWindow, c.maxCongestionWindow())
+	c.pacer.SetMaxDatagramSize(s)
+}
@@ -0,0 +1,267 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
+	"github.com/quic-go/quic-go/logging"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BBR Sender", func() {
+	const (
+		linkBandwidth = 10 * 1000 * 1000 * BitsPerSecond
+		packetSize    = protocol.ByteCount(1200)
+	)
+
+	type simulatedPacket struct {
+		pn      protocol.PacketNumber
+		sent    time.Time
+		arrival time.Time // when the ACK for this packet is received
+	}
+
+	var (
+		sender        *bbrSender
+		clock         mockClock
+		rttStats      *utils.RTTStats
+		bytesInFlight protocol.ByteCount
+		packetNumber  protocol.PacketNumber
+		inFlight      []simulatedPacket
+		linkFree      time.Time
+		linkRTT       time.Duration
+		states        []logging.CongestionState
+	)
+
+	BeforeEach(func() {
+		clock = mockClock(time.Now())
+		rttStats = utils.NewRTTStats()
+		bytesInFlight = 0
+		packetNumber = 0
+		inFlight = nil
+		linkFree = time.Time{}
+		linkRTT = 50 * time.Millisecond
+		states = nil
+		sender = NewBBRSender(
+			&clock,
+			rttStats,
+			packetSize,
+			&logging.ConnectionTracer{
+				UpdatedCongestionState: func(s logging.CongestionState) { states = append(states, s) },
+			},
+		)
+	})
+
+	sendPacket := func() {
+		now := clock.Now()
+		// the bottleneck link serializes packets
+		linkFree = maxTime(linkFree, now).Add(time.Duration(uint64(packetSize) * uint64(time.Second) / uint64(linkBandwidth/BytesPerSecond)))
+		packetNumber++
+		bytesInFlight += packetSize
+		sender.OnPacketSent(now, bytesInFlight, packetNumber, packetSize, true)
+		inFlight = append(inFlight, simulatedPacket{pn: packetNumber, sent: now, arrival: linkFree.Add(linkRTT)})
+	}
+
+	ackPacket := func(p simulatedPacket) {
+		rttStats.UpdateRTT(clock.Now().Sub(p.sent), 0, clock.Now())
+		sender.MaybeExitSlowStart()
+		sender.OnPacketAcked(p.pn, packetSize, bytesInFlight, clock.Now())
+		bytesInFlight -= packetSize
+	}
+
+	// simulate runs a bulk transfer over a link with a fixed bandwidth and RTT.
+	// It calls the callback after every event.
+	simulate := func(d time.Duration, cb func()) {
+		end := clock.Now().Add(d)
+		for clock.Now().Before(end) {
+			for sender.CanSend(bytesInFlight) && sender.HasPacingBudget(clock.Now()) {
+				sendPacket()
+			}
+			next := end
+			if len(inFlight) > 0 && inFlight[0].arrival.Before(next) {
+				next = inFlight[0].arrival
+			}
+			if t := sender.TimeUntilSend(bytesInFlight); sender.CanSend(bytesInFlight) && t.After(clock.Now()) && t.Before(next) {
+				next = t
+			}
+			if !next.After(clock.Now()) {
+				next = clock.Now().Add(time.Microsecond)
+			}
+			clock = mockClock(next)
+			for len(inFlight) > 0 && !inFlight[0].arrival.After(clock.Now()) {
+				ackPacket(inFlight[0])
+				inFlight = inFlight[1:]
+			}
+			if cb != nil {
+				cb()
+			}
+		}
+	}
+
+	bdp := func() protocol.ByteCount {
+		return protocol.ByteCount(uint64(linkBandwidth/BytesPerSecond) * uint64(linkRTT) / uint64(time.Second))
+	}
+
+	It("starts in Startup", func() {
+		Expect(sender.InSlowStart()).To(BeTrue())
+		Expect(sender.InRecovery()).To(BeFalse())
+		Expect(sender.mode).To(Equal(bbrModeStartup))
+		Expect(sender.GetCongestionWindow()).To(Equal(initialCongestionWindow * packetSize))
+		Expect(sender.CanSend(0)).To(BeTrue())
+		Expect(states).To(Equal([]logging.CongestionState{logging.CongestionStateSlowStart}))
+	})
+
+	It("estimates the bandwidth and the min RTT", func() {
+		simulate(3*time.Second, nil)
+		Expect(sender.bandwidth()).To(BeNumerically("~", linkBandwidth, linkBandwidth/20))
+		Expect(sender.minRTT()).To(BeNumerically("~", linkRTT, 2*time.Millisecond))
+		Expect(sender.BandwidthEstimate()).To(Equal(sender.bandwidth()))
+	})
+
+	It("goes through Startup and Drain, and then probes for bandwidth", func() {
+		modes := []bbrMode{sender.mode}
+		phases := make(map[bbrProbeBWPhase]struct{})
+		simulate(10*time.Second, func() {
+			if sender.mode != modes[len(modes)-1] {
+				modes = append(modes, sender.mode)
+			}
+			if sender.mode == bbrModeProbeBW {
+				phases[sender.phase] = struct{}{}
+			}
+		})
+		Expect(modes).To(Equal([]bbrMode{bbrModeStartup, bbrModeDrain, bbrModeProbeBW}))
+		Expect(phases).To(HaveLen(4))
+		Expect(sender.InSlowStart()).To(BeFalse())
+		Expect(states).To(Equal([]logging.CongestionState{logging.CongestionStateSlowStart, logging.CongestionStateCongestionAvoidance}))
+		// the congestion window is limited to a multiple of the BDP
+		Expect(sender.GetCongestionWindow()).To(BeNumerically("<=", 3*bdp()))
+		Expect(sender.GetCongestionWindow()).To(BeNumerically(">=", bdp()))
+	})
+
+	It("paces at a multiple of the bandwidth estimate", func() {
+		simulate(100*time.Millisecond, nil)
+		Expect(sender.mode).To(Equal(bbrModeStartup))
+		Expect(sender.pacingRate).To(BeNumerically(">=", 2*sender.bandwidth()))
+		simulate(5*time.Second, nil)
+		Expect(sender.mode).To(Equal(bbrModeProbeBW))
+		Expect(sender.pacingRate).To(BeNumerically("<=", bbrProbeBWUpGain*float64(sender.bandwidth())))
+		Expect(sender.pacingRate).To(BeNumerically(">=", bbrProbeBWDownGain*float64(sender.bandwidth())*0.99))
+	})
+
+	It("enters ProbeRTT if the min RTT wasn't observed for a while", func() {
+		simulate(3*time.Second, nil)
+		Expect(sender.mode).To(Equal(bbrModeProbeBW))
+		// increase the RTT, such that the min RTT estimate isn't refreshed
+		linkRTT += 20 * time.Millisecond
+		var enteredProbeRTT, exitedProbeRTT bool
+		var probeRTTStart time.Time
+		simulate(8*time.Second, func() {
+			switch {
+			case sender.mode == bbrModeProbeRTT && !enteredProbeRTT:
+				enteredProbeRTT = true
+				probeRTTStart = clock.Now()
+			case sender.mode == bbrModeProbeRTT:
+				Expect(sender.GetCongestionWindow()).To(Equal(sender.probeRTTCwnd()))
+			case enteredProbeRTT && !exitedProbeRTT:
+				exitedProbeRTT = true
+				Expect(sender.mode).To(Equal(bbrModeProbeBW))
+				Expect(clock.Now().Sub(probeRTTStart)).To(BeNumerically(">=", bbrProbeRTTDuration))
+			}
+		})
+		Expect(enteredProbeRTT).To(BeTrue())
+		Expect(exitedProbeRTT).To(BeTrue())
+	})
+
+	It("exits Startup on high loss", func() {
+		for i := 0; i < 10; i++ {
+			sendPacket()
+		}
+		sender.OnCongestionEvent(1, packetSize, bytesInFlight)
+		Expect(sender.mode).To(Equal(bbrModeDrain))
+		Expect(sender.InRecovery()).To(BeFalse()) // no packets were acknowledged yet
+		Expect(sender.inflightHi).To(Equal(protocol.ByteCount(bbrBeta * float64(initialCongestionWindow*packetSize))))
+		Expect(sender.GetCongestionWindow()).To(BeNumerically("<=", sender.inflightHi))
+		sender.OnPacketAcked(2, packetSize, bytesInFlight, clock.Now())
+		Expect(sender.InRecovery()).To(BeTrue())
+		Expect(states).To(ContainElement(logging.CongestionStateRecovery))
+		// acknowledging a packet sent after the loss ends the recovery period
+		sendPacket()
+		sender.OnPacketAcked(packetNumber, packetSize, bytesInFlight, clock.Now())
+		Expect(sender.InRecovery()).To(BeFalse())
+	})
+
+	It("ignores losses below the loss threshold", func() {
+		simulate(3*time.Second, nil)
+		Expect(sender.mode).To(Equal(bbrModeProbeBW))
+		Expect(bytesInFlight).To(BeNumerically(">", 50*packetSize))
+		cwnd := sender.GetCongestionWindow()
+		sender.OnCongestionEvent(inFlight[0].pn, packetSize, bytesInFlight)
+		Expect(sender.GetCongestionWindow()).To(Equal(cwnd))
+		Expect(sender.InRecovery()).To(BeFalse())
+		Expect(sender.inflightHi).To(BeZero())
+	})
+
+	It("treats ECN-CE marks as a congestion signal", func() {
+		simulate(3*time.Second, nil)
+		Expect(sender.mode).To(Equal(bbrModeProbeBW))
+		sender.OnCongestionEvent(inFlight[0].pn, 0, bytesInFlight)
+		Expect(sender.inflightHi).ToNot(BeZero())
+		Expect(sender.GetCongestionWindow()).To(BeNumerically("<=", sender.inflightHi))
+	})
+
+	It("detects when the application is not sending enough data", func() {
+		sendPacket()
+		clock.Advance(100 * time.Millisecond)
+		Expect(sender.sampler.IsAppLimited()).To(BeFalse())
+		sendPacket()
+		Expect(sender.sampler.IsAppLimited()).To(BeTrue())
+		Expect(states).To(Equal([]logging.CongestionState{
+			logging.CongestionStateSlowStart,
+			logging.CongestionStateApplicationLimited,
+		}))
+	})
+
+	It("isn't application-limited when limited by the congestion window", func() {
+		for sender.CanSend(bytesInFlight) {
+			sendPacket()
+		}
+		clock.Advance(100 * time.Millisecond)
+		bytesInFlight -= packetSize
+		sendPacket()
+		Expect(sender.sampler.IsAppLimited()).To(BeFalse())
+	})
+
+	It("resets the congestion window after a retransmission timeout", func() {
+		sender.OnRetransmissionTimeout(false)
+		Expect(sender.GetCongestionWindow()).To(Equal(initialCongestionWindow * packetSize))
+		sender.OnRetransmissionTimeout(true)
+		Expect(sender.GetCongestionWindow()).To(Equal(bbrMinPipeCwndPackets * packetSize))
+	})
+
+	It("starts from scratch after a connection migration", func() {
+		simulate(3*time.Second, nil)
+		Expect(sender.mode).To(Equal(bbrModeProbeBW))
+		sender.OnConnectionMigration()
+		Expect(sender.mode).To(Equal(bbrModeStartup))
+		Expect(sender.bandwidth()).To(BeZero())
+		Expect(sender.GetCongestionWindow()).To(Equal(initialCongestionWindow * packetSize))
+		Expect(states[len(states)-1]).To(Equal(logging.CongestionStateSlowStart))
+	})
+
+	It("updates the max datagram size", func() {
+		sender.OnRetransmissionTimeout(true)
+		sender.SetMaxDatagramSize(packetSize + 100)
+		Expect(sender.GetCongestionWindow()).To(Equal(bbrMinPipeCwndPackets * (packetSize + 100)))
+		sender.SetMaxDatagramSize(packetSize)
+		Expect(sender.GetCongestionWindow()).To(Equal(bbrMinPipeCwndPackets * packetSize))
+	})
+})
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
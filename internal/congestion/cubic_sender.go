@@ -1,7 +1,6 @@
 package congestion
 
 import (
-	"fmt"
 	"time"
 
 	"github.com/quic-go/quic-go/internal/protocol"
@@ -21,6 +20,8 @@ const (
 
 type cubicSender struct {
 	hybridSlowStart HybridSlowStart
+	// nil if HyStart++ is not used
+	hystartPlusPlus *HyStartPlusPlus
 	rttStats        *utils.RTTStats
 	cubic           *Cubic
 	pacer           *pacer
@@ -44,6 +45,13 @@ type cubicSender struct {
 	// Congestion window in bytes.
 	congestionWindow protocol.ByteCount
 
+	// The state before the last cutback, used to undo the cutback if all losses were spurious.
+	undo cubicSenderUndoState
+	// The number of packets declared lost since the last cutback that have not been acknowledged yet.
+	// Only losses of packets sent after undo.largestSentAtLastCutback are counted.
+	// -1 if the cutback can't be undone.
+	numLostSinceCutback int
+
 	// Slow start congestion window in bytes, aka ssthresh.
 	slowStartThreshold protocol.ByteCount
 
@@ -59,23 +67,37 @@ type cubicSender struct {
 	tracer    *logging.ConnectionTracer
 }
 
+type cubicSenderUndoState struct {
+	congestionWindow           protocol.ByteCount
+	slowStartThreshold         protocol.ByteCount
+	largestSentAtLastCutback   protocol.PacketNumber
+	lastCutbackExitedSlowstart bool
+	numAckedPackets            uint64
+	cubic                      Cubic
+}
+
 var (
 	_ SendAlgorithm               = &cubicSender{}
 	_ SendAlgorithmWithDebugInfos = &cubicSender{}
+	_ SpuriousLossSendAlgorithm   = &cubicSender{}
 )
 
-// NewCubicSender makes a new cubic sender
+// NewCubicSender makes a new cubic sender.
+// If hystartPlusPlus is set, HyStart++ (RFC 9406) is used to exit slow start,
+// instead of the delay-based hybrid slow start.
 func NewCubicSender(
 	clock Clock,
 	rttStats *utils.RTTStats,
 	initialMaxDatagramSize protocol.ByteCount,
 	reno bool,
+	hystartPlusPlus bool,
 	tracer *logging.ConnectionTracer,
 ) *cubicSender {
 	return newCubicSender(
 		clock,
 		rttStats,
 		reno,
+		hystartPlusPlus,
 		initialMaxDatagramSize,
 		initialCongestionWindow*initialMaxDatagramSize,
 		protocol.MaxCongestionWindowPackets*initialMaxDatagramSize,
@@ -87,6 +109,7 @@ func newCubicSender(
 	clock Clock,
 	rttStats *utils.RTTStats,
 	reno bool,
+	hystartPlusPlus bool,
 	initialMaxDatagramSize,
 	initialCongestionWindow,
 	initialMaxCongestionWindow protocol.ByteCount,
@@ -97,6 +120,7 @@ func newCubicSender(
 		largestSentPacketNumber:    protocol.InvalidPacketNumber,
 		largestAckedPacketNumber:   protocol.InvalidPacketNumber,
 		largestSentAtLastCutback:   protocol.InvalidPacketNumber,
+		numLostSinceCutback:        -1,
 		initialCongestionWindow:    initialCongestionWindow,
 		initialMaxCongestionWindow: initialMaxCongestionWindow,
 		congestionWindow:           initialCongestionWindow,
@@ -107,6 +131,9 @@ func newCubicSender(
 		tracer:                     tracer,
 		maxDatagramSize:            initialMaxDatagramSize,
 	}
+	if hystartPlusPlus {
+		c.hystartPlusPlus = &HyStartPlusPlus{}
+	}
 	c.pacer = newPacer(c.BandwidthEstimate)
 	if c.tracer != nil && c.tracer.UpdatedCongestionState != nil {
 		c.lastState = logging.CongestionStateSlowStart
@@ -144,6 +171,10 @@ func (c *cubicSender) OnPacketSent(
 		return
 	}
 	c.largestSentPacketNumber = packetNumber
+	if c.hystartPlusPlus != nil {
+		c.hystartPlusPlus.OnPacketSent(packetNumber)
+		return
+	}
 	c.hybridSlowStart.OnPacketSent(packetNumber)
 }
 
@@ -164,6 +195,13 @@ func (c *cubicSender) GetCongestionWindow() protocol.ByteCount {
 }
 
 func (c *cubicSender) MaybeExitSlowStart() {
+	if c.hystartPlusPlus != nil {
+		// HyStart++ exits slow start when a round ends, see OnPacketAcked.
+		if c.InSlowStart() {
+			c.hystartPlusPlus.OnRTTSample(c.rttStats.LatestRTT())
+		}
+		return
+	}
 	if c.InSlowStart() &&
 		c.hybridSlowStart.ShouldExitSlowStart(c.rttStats.LatestRTT(), c.rttStats.MinRTT(), c.GetCongestionWindow()/c.maxDatagramSize) {
 		// exit slow start
@@ -183,8 +221,17 @@ func (c *cubicSender) OnPacketAcked(
 		return
 	}
 	c.maybeIncreaseCwnd(ackedPacketNumber, ackedBytes, priorInFlight, eventTime)
-	if c.InSlowStart() {
+	if !c.InSlowStart() {
+		return
+	}
+	if c.hystartPlusPlus == nil {
 		c.hybridSlowStart.OnPacketAcked(ackedPacketNumber)
+		return
+	}
+	if c.hystartPlusPlus.OnPacketAcked(ackedPacketNumber) {
+		// Conservative Slow Start is over, enter congestion avoidance.
+		c.slowStartThreshold = c.congestionWindow
+		c.maybeTraceStateChange(logging.CongestionStateCongestionAvoidance)
 	}
 }
 
@@ -192,8 +239,24 @@ func (c *cubicSender) OnCongestionEvent(packetNumber protocol.PacketNumber, lost
 	// TCP NewReno (RFC6582) says that once a loss occurs, any losses in packets
 	// already sent should be treated as a single loss event, since it's expected.
 	if packetNumber <= c.largestSentAtLastCutback {
+		if c.numLostSinceCutback >= 0 && packetNumber > c.undo.largestSentAtLastCutback {
+			c.numLostSinceCutback++
+		}
 		return
 	}
+	// Congestion events caused by ECN-CE marks can't be undone.
+	c.numLostSinceCutback = -1
+	if lostBytes > 0 {
+		c.numLostSinceCutback = 1
+		c.undo = cubicSenderUndoState{
+			congestionWindow:           c.congestionWindow,
+			slowStartThreshold:         c.slowStartThreshold,
+			largestSentAtLastCutback:   c.largestSentAtLastCutback,
+			lastCutbackExitedSlowstart: c.lastCutbackExitedSlowstart,
+			numAckedPackets:            c.numAckedPackets,
+			cubic:                      *c.cubic,
+		}
+	}
 	c.lastCutbackExitedSlowstart = c.InSlowStart()
 	c.maybeTraceStateChange(logging.CongestionStateRecovery)
 
@@ -212,6 +275,31 @@ func (c *cubicSender) OnCongestionEvent(packetNumber protocol.PacketNumber, lost
 	c.numAckedPackets = 0
 }
 
+// OnSpuriousLoss is called when a packet that was declared lost is acknowledged.
+// Once all packets declared lost since the last cutback are acknowledged,
+// the cutback is undone, similar to the Eifel response algorithm (RFC 4015).
+func (c *cubicSender) OnSpuriousLoss(packetNumber protocol.PacketNumber) {
+	if c.numLostSinceCutback <= 0 || packetNumber <= c.undo.largestSentAtLastCutback || packetNumber > c.largestSentAtLastCutback {
+		return
+	}
+	c.numLostSinceCutback--
+	if c.numLostSinceCutback > 0 {
+		return
+	}
+	c.numLostSinceCutback = -1
+	c.congestionWindow = max(c.congestionWindow, c.undo.congestionWindow)
+	c.slowStartThreshold = max(c.slowStartThreshold, c.undo.slowStartThreshold)
+	c.largestSentAtLastCutback = c.undo.largestSentAtLastCutback
+	c.lastCutbackExitedSlowstart = c.undo.lastCutbackExitedSlowstart
+	c.numAckedPackets = c.undo.numAckedPackets
+	*c.cubic = c.undo.cubic
+	if c.InSlowStart() {
+		c.maybeTraceStateChange(logging.CongestionStateSlowStart)
+	} else {
+		c.maybeTraceStateChange(logging.CongestionStateCongestionAvoidance)
+	}
+}
+
 // Called when we receive an ack. Normal TCP tracks how many packets one ack
 // represents, but quic has a separate ack for each packet.
 func (c *cubicSender) maybeIncreaseCwnd(
@@ -231,6 +319,12 @@ func (c *cubicSender) maybeIncreaseCwnd(
 		return
 	}
 	if c.InSlowStart() {
+		if c.hystartPlusPlus != nil && c.hystartPlusPlus.InConservativeSlowStart() {
+			// Conservative Slow Start, see RFC 9406, section 4.2.
+			c.congestionWindow += c.maxDatagramSize / hystartPlusPlusCSSGrowthDivisor
+			c.maybeTraceStateChange(logging.CongestionStateSlowStart)
+			return
+		}
 		// TCP slow start, exponential growth, increase by one for each ACK.
 		c.congestionWindow += c.maxDatagramSize
 		c.maybeTraceStateChange(logging.CongestionStateSlowStart)
@@ -273,21 +367,24 @@ func (c *cubicSender) BandwidthEstimate() Bandwidth {
 // OnRetransmissionTimeout is called on an retransmission timeout
 func (c *cubicSender) OnRetransmissionTimeout(packetsRetransmitted bool) {
 	c.largestSentAtLastCutback = protocol.InvalidPacketNumber
+	c.numLostSinceCutback = -1
 	if !packetsRetransmitted {
 		return
 	}
-	c.hybridSlowStart.Restart()
+	c.restartSlowStart()
 	c.cubic.Reset()
 	c.slowStartThreshold = c.congestionWindow / 2
 	c.congestionWindow = c.minCongestionWindow()
 }
 
-// OnConnectionMigration is called when the connection is migrated (?)
+// OnConnectionMigration is called when the connection is migrated to a new path.
+// The congestion controller starts from scratch on the new path.
 func (c *cubicSender) OnConnectionMigration() {
-	c.hybridSlowStart.Restart()
+	c.restartSlowStart()
 	c.largestSentPacketNumber = protocol.InvalidPacketNumber
 	c.largestAckedPacketNumber = protocol.InvalidPacketNumber
 	c.largestSentAtLastCutback = protocol.InvalidPacketNumber
+	c.numLostSinceCutback = -1
 	c.lastCutbackExitedSlowstart = false
 	c.cubic.Reset()
 	c.numAckedPackets = 0
@@ -295,6 +392,13 @@ func (c *cubicSender) OnConnectionMigration() {
 	c.slowStartThreshold = c.initialMaxCongestionWindow
 }
 
+func (c *cubicSender) restartSlowStart() {
+	c.hybridSlowStart.Restart()
+	if c.hystartPlusPlus != nil {
+		c.hystartPlusPlus.Restart()
+	}
+}
+
 func (c *cubicSender) maybeTraceStateChange(new logging.CongestionState) {
 	if c.tracer == nil || c.tracer.UpdatedCongestionState == nil || new == c.lastState {
 		return
@@ -304,13 +408,11 @@ func (c *cubicSender) maybeTraceStateChange(new logging.CongestionState) {
 }
 
 func (c *cubicSender) SetMaxDatagramSize(s protocol.ByteCount) {
-	if s < c.maxDatagramSize {
-		panic(fmt.Sprintf("congestion BUG: decreased max datagram size from %d to %d", c.maxDatagramSize, s))
-	}
 	cwndIsMinCwnd := c.congestionWindow == c.minCongestionWindow()
 	c.maxDatagramSize = s
 	if cwndIsMinCwnd {
 		c.congestionWindow = c.minCongestionWindow()
 	}
+	c.congestionWindow = min(c.congestionWindow, c.maxCongestionWindow())
 	c.pacer.SetMaxDatagramSize(s)
 }
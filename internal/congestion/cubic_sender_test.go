@@ -46,7 +46,8 @@ var _ = Describe("Cubic Sender", func() {
 		sender = newCubicSender(
 			&clock,
 			rttStats,
-			true, /*reno*/
+			true,  /*reno*/
+			false, /*HyStart++*/
 			protocol.InitialPacketSizeIPv4,
 			initialCongestionWindowPackets*maxDatagramSize,
 			MaxCongestionWindow,
@@ -94,6 +95,16 @@ var _ = Describe("Cubic Sender", func() {
 	SendAvailableSendWindow := func() int { return SendAvailableSendWindowLen(maxDatagramSize) }
 	LoseNPackets := func(n int) { LoseNPacketsLen(n, maxDatagramSize) }
 
+	// AckWithRTT fills the congestion window, and then acknowledges a single packet with the given RTT.
+	AckWithRTT := func(rtt time.Duration) {
+		SendAvailableSendWindow()
+		rttStats.UpdateRTT(rtt, 0, clock.Now())
+		sender.MaybeExitSlowStart()
+		ackedPacketNumber++
+		sender.OnPacketAcked(ackedPacketNumber, maxDatagramSize, bytesInFlight, clock.Now())
+		bytesInFlight -= maxDatagramSize
+	}
+
 	It("has the right values at startup", func() {
 		// At startup make sure we are at the default.
 		Expect(sender.GetCongestionWindow()).To(Equal(defaultWindowTCP))
@@ -319,7 +330,7 @@ var _ = Describe("Cubic Sender", func() {
 	It("tcp cubic reset epoch on quiescence", func() {
 		const maxCongestionWindow = 50
 		const maxCongestionWindowBytes = maxCongestionWindow * maxDatagramSize
-		sender = newCubicSender(&clock, rttStats, false, protocol.InitialPacketSizeIPv4, initialCongestionWindowPackets*maxDatagramSize, maxCongestionWindowBytes, nil)
+		sender = newCubicSender(&clock, rttStats, false, false, protocol.InitialPacketSizeIPv4, initialCongestionWindowPackets*maxDatagramSize, maxCongestionWindowBytes, nil)
 
 		numSent := SendAvailableSendWindow()
 
@@ -416,6 +427,68 @@ var _ = Describe("Cubic Sender", func() {
 		Expect(sender.GetCongestionWindow()).To(Equal(expectedSendWindow))
 	})
 
+	It("undoes the cutback if all losses were spurious", func() {
+		SendAvailableSendWindow()
+		AckNPackets(2)
+		SendAvailableSendWindow()
+		cwnd := sender.GetCongestionWindow()
+		Expect(sender.InSlowStart()).To(BeTrue())
+		LosePacket(ackedPacketNumber + 1)
+		LosePacket(ackedPacketNumber + 3)
+		Expect(sender.GetCongestionWindow()).To(BeNumerically("<", cwnd))
+		Expect(sender.InRecovery()).To(BeTrue())
+		sender.OnSpuriousLoss(ackedPacketNumber + 1)
+		Expect(sender.GetCongestionWindow()).To(BeNumerically("<", cwnd))
+		sender.OnSpuriousLoss(ackedPacketNumber + 3)
+		Expect(sender.GetCongestionWindow()).To(Equal(cwnd))
+		Expect(sender.InRecovery()).To(BeFalse())
+		Expect(sender.InSlowStart()).To(BeTrue())
+	})
+
+	It("doesn't undo the cutback if some losses were not spurious", func() {
+		SendAvailableSendWindow()
+		AckNPackets(2)
+		SendAvailableSendWindow()
+		LosePacket(ackedPacketNumber + 1)
+		LosePacket(ackedPacketNumber + 3)
+		cwnd := sender.GetCongestionWindow()
+		sender.OnSpuriousLoss(ackedPacketNumber + 3)
+		// the loss of a packet sent after the cutback is not part of this loss episode
+		sender.OnSpuriousLoss(packetNumber + 10)
+		Expect(sender.GetCongestionWindow()).To(Equal(cwnd))
+		Expect(sender.InRecovery()).To(BeTrue())
+	})
+
+	It("doesn't undo a previous cutback", func() {
+		SendAvailableSendWindow()
+		ackedPacketNumber++
+		LosePacket(ackedPacketNumber)
+		cwnd := sender.GetCongestionWindow()
+		// ack all other packets, and a packet sent after the cutback to exit recovery
+		AckNPackets(int(packetNumber - 2))
+		SendAvailableSendWindow()
+		AckNPackets(1)
+		Expect(sender.InRecovery()).To(BeFalse())
+		cwnd = sender.GetCongestionWindow()
+		LosePacket(ackedPacketNumber + 1)
+		Expect(sender.GetCongestionWindow()).To(BeNumerically("<", cwnd))
+		reducedCwnd := sender.GetCongestionWindow()
+		sender.OnSpuriousLoss(1)
+		Expect(sender.GetCongestionWindow()).To(Equal(reducedCwnd))
+		sender.OnSpuriousLoss(ackedPacketNumber + 1)
+		Expect(sender.GetCongestionWindow()).To(Equal(cwnd))
+	})
+
+	It("doesn't undo a cutback caused by ECN", func() {
+		SendAvailableSendWindow()
+		AckNPackets(2)
+		sender.OnCongestionEvent(ackedPacketNumber, 0, bytesInFlight)
+		cwnd := sender.GetCongestionWindow()
+		LosePacket(ackedPacketNumber + 1)
+		sender.OnSpuriousLoss(ackedPacketNumber + 1)
+		Expect(sender.GetCongestionWindow()).To(Equal(cwnd))
+	})
+
 	It("no PRR", func() {
 		SendAvailableSendWindow()
 		LoseNPackets(9)
@@ -460,7 +533,7 @@ var _ = Describe("Cubic Sender", func() {
 
 	It("slow starts up to the maximum congestion window", func() {
 		const initialMaxCongestionWindow = protocol.MaxCongestionWindowPackets * initialMaxDatagramSize
-		sender = newCubicSender(&clock, rttStats, true, protocol.InitialPacketSizeIPv4, initialCongestionWindowPackets*maxDatagramSize, initialMaxCongestionWindow, nil)
+		sender = newCubicSender(&clock, rttStats, true, false, protocol.InitialPacketSizeIPv4, initialCongestionWindowPackets*maxDatagramSize, initialMaxCongestionWindow, nil)
 
 		for i := 1; i < protocol.MaxCongestionWindowPackets; i++ {
 			sender.MaybeExitSlowStart()
@@ -469,13 +542,28 @@ var _ = Describe("Cubic Sender", func() {
 		Expect(sender.GetCongestionWindow()).To(Equal(initialMaxCongestionWindow))
 	})
 
-	It("doesn't allow reductions of the maximum packet size", func() {
-		Expect(func() { sender.SetMaxDatagramSize(initialMaxDatagramSize - 1) }).To(Panic())
+	It("reduces the maximum packet size", func() {
+		sender.OnRetransmissionTimeout(true)
+		Expect(sender.GetCongestionWindow()).To(Equal(minCongestionWindowPackets * initialMaxDatagramSize))
+		sender.SetMaxDatagramSize(initialMaxDatagramSize - 100)
+		Expect(sender.GetCongestionWindow()).To(Equal(minCongestionWindowPackets * (initialMaxDatagramSize - 100)))
+	})
+
+	It("caps the congestion window when the maximum packet size is reduced", func() {
+		const initialMaxCongestionWindow = protocol.MaxCongestionWindowPackets * initialMaxDatagramSize
+		sender = newCubicSender(&clock, rttStats, true, false, protocol.InitialPacketSizeIPv4, initialCongestionWindowPackets*maxDatagramSize, initialMaxCongestionWindow, nil)
+		for i := 1; i < protocol.MaxCongestionWindowPackets; i++ {
+			sender.MaybeExitSlowStart()
+			sender.OnPacketAcked(protocol.PacketNumber(i), 1350, sender.GetCongestionWindow(), clock.Now())
+		}
+		Expect(sender.GetCongestionWindow()).To(Equal(initialMaxCongestionWindow))
+		sender.SetMaxDatagramSize(protocol.MinInitialPacketSize)
+		Expect(sender.GetCongestionWindow()).To(Equal(protocol.MaxCongestionWindowPackets * protocol.ByteCount(protocol.MinInitialPacketSize)))
 	})
 
 	It("slow starts up to maximum congestion window, if larger packets are sent", func() {
 		const initialMaxCongestionWindow = protocol.MaxCongestionWindowPackets * initialMaxDatagramSize
-		sender = newCubicSender(&clock, rttStats, true, protocol.InitialPacketSizeIPv4, initialCongestionWindowPackets*maxDatagramSize, initialMaxCongestionWindow, nil)
+		sender = newCubicSender(&clock, rttStats, true, false, protocol.InitialPacketSizeIPv4, initialCongestionWindowPackets*maxDatagramSize, initialMaxCongestionWindow, nil)
 		const packetSize = initialMaxDatagramSize + 100
 		sender.SetMaxDatagramSize(packetSize)
 		for i := 1; i < protocol.MaxCongestionWindowPackets; i++ {
@@ -490,7 +578,7 @@ var _ = Describe("Cubic Sender", func() {
 
 	It("limit cwnd increase in congestion avoidance", func() {
 		// Enable Cubic.
-		sender = newCubicSender(&clock, rttStats, false, protocol.InitialPacketSizeIPv4, initialCongestionWindowPackets*maxDatagramSize, MaxCongestionWindow, nil)
+		sender = newCubicSender(&clock, rttStats, false, false, protocol.InitialPacketSizeIPv4, initialCongestionWindowPackets*maxDatagramSize, MaxCongestionWindow, nil)
 		numSent := SendAvailableSendWindow()
 
 		// Make sure we fall out of slow start.
@@ -524,4 +612,50 @@ var _ = Describe("Cubic Sender", func() {
 		AckNPackets(2)
 		Expect(sender.GetCongestionWindow()).To(Equal(savedCwnd + maxDatagramSize))
 	})
+
+	It("uses HyStart++", func() {
+		sender = newCubicSender(&clock, rttStats, true, true, protocol.InitialPacketSizeIPv4, initialCongestionWindowPackets*maxDatagramSize, MaxCongestionWindow, nil)
+		// slow start: the congestion window grows by one packet per ACK
+		for i := 0; i < 100; i++ {
+			AckWithRTT(60 * time.Millisecond)
+		}
+		Expect(sender.GetCongestionWindow()).To(Equal(defaultWindowTCP + 100*maxDatagramSize))
+		// the RTT increases
+		for !sender.hystartPlusPlus.InConservativeSlowStart() {
+			AckWithRTT(80 * time.Millisecond)
+		}
+		Expect(sender.InSlowStart()).To(BeTrue())
+		// in Conservative Slow Start, the congestion window grows by a quarter packet per ACK
+		cwnd := sender.GetCongestionWindow()
+		for i := 0; i < 4; i++ {
+			AckWithRTT(80 * time.Millisecond)
+		}
+		Expect(sender.GetCongestionWindow()).To(Equal(cwnd + maxDatagramSize))
+		// slow start is exited after 5 rounds
+		var numAcks int
+		for sender.InSlowStart() {
+			AckWithRTT(80 * time.Millisecond)
+			numAcks++
+		}
+		Expect(numAcks).To(BeNumerically(">", 4*int(cwnd/maxDatagramSize)))
+		Expect(numAcks).To(BeNumerically("<", 6*int(sender.GetCongestionWindow()/maxDatagramSize)))
+	})
+
+	It("resumes slow start with HyStart++ if the RTT increase was spurious", func() {
+		sender = newCubicSender(&clock, rttStats, true, true, protocol.InitialPacketSizeIPv4, initialCongestionWindowPackets*maxDatagramSize, MaxCongestionWindow, nil)
+		for i := 0; i < 100; i++ {
+			AckWithRTT(60 * time.Millisecond)
+		}
+		for !sender.hystartPlusPlus.InConservativeSlowStart() {
+			AckWithRTT(80 * time.Millisecond)
+		}
+		// the RTT goes back to its previous value
+		for sender.hystartPlusPlus.InConservativeSlowStart() {
+			AckWithRTT(60 * time.Millisecond)
+		}
+		Expect(sender.InSlowStart()).To(BeTrue())
+		cwnd := sender.GetCongestionWindow()
+		AckWithRTT(60 * time.Millisecond)
+		Expect(sender.GetCongestionWindow()).To(Equal(cwnd + maxDatagramSize))
+	})
 })
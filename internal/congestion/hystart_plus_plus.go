@@ -0,0 +1,113 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+)
+
+// Constants recommended by RFC 9406, section 4.3.
+const (
+	hystartPlusPlusMinRTTThreshold = 4 * time.Millisecond
+	hystartPlusPlusMaxRTTThreshold = 16 * time.Millisecond
+	hystartPlusPlusMinRTTDivisor   = 8
+	hystartPlusPlusNRTTSample      = 8
+	// During Conservative Slow Start, the congestion window grows by 1/4 of the acknowledged bytes.
+	hystartPlusPlusCSSGrowthDivisor = 4
+	// The number of rounds spent in Conservative Slow Start before entering congestion avoidance.
+	hystartPlusPlusCSSRounds = 5
+)
+
+// HyStartPlusPlus implements HyStart++, as specified in RFC 9406.
+// When an increase in RTT is detected, it enters Conservative Slow Start (CSS),
+// slowing down the growth of the congestion window.
+// If the RTT increase turns out to be spurious, it resumes regular slow start.
+// Otherwise, slow start is exited after hystartPlusPlusCSSRounds rounds.
+type HyStartPlusPlus struct {
+	// A round ends when windowEnd is acknowledged.
+	windowEnd            protocol.PacketNumber
+	lastSentPacketNumber protocol.PacketNumber
+	started              bool
+
+	lastRoundMinRTT    time.Duration
+	currentRoundMinRTT time.Duration
+	rttSampleCount     uint32
+
+	inCSS             bool
+	cssBaselineMinRTT time.Duration
+	cssRounds         int
+}
+
+// startRound is called for the start of each round in the slow start phase.
+func (s *HyStartPlusPlus) startRound() {
+	s.windowEnd = s.lastSentPacketNumber
+	s.lastRoundMinRTT = s.currentRoundMinRTT
+	s.currentRoundMinRTT = 0
+	s.rttSampleCount = 0
+	s.started = true
+}
+
+// OnPacketSent is called when a packet was sent
+func (s *HyStartPlusPlus) OnPacketSent(packetNumber protocol.PacketNumber) {
+	s.lastSentPacketNumber = packetNumber
+}
+
+// OnRTTSample is called for every new RTT sample in slow start.
+func (s *HyStartPlusPlus) OnRTTSample(latestRTT time.Duration) {
+	if !s.started {
+		s.startRound()
+	}
+	s.rttSampleCount++
+	if s.currentRoundMinRTT == 0 || latestRTT < s.currentRoundMinRTT {
+		s.currentRoundMinRTT = latestRTT
+	}
+	if s.rttSampleCount < hystartPlusPlusNRTTSample || s.lastRoundMinRTT == 0 {
+		return
+	}
+	if s.inCSS {
+		// The RTT increase was spurious. Resume slow start.
+		if s.currentRoundMinRTT < s.cssBaselineMinRTT {
+			s.inCSS = false
+			s.cssBaselineMinRTT = 0
+			s.cssRounds = 0
+		}
+		return
+	}
+	rttThreshold := min(max(s.lastRoundMinRTT/hystartPlusPlusMinRTTDivisor, hystartPlusPlusMinRTTThreshold), hystartPlusPlusMaxRTTThreshold)
+	if s.currentRoundMinRTT >= s.lastRoundMinRTT+rttThreshold {
+		s.inCSS = true
+		s.cssBaselineMinRTT = s.currentRoundMinRTT
+		s.cssRounds = 0
+	}
+}
+
+// OnPacketAcked is called for every packet acknowledged in slow start, after OnRTTSample.
+// It returns true if slow start should be exited.
+func (s *HyStartPlusPlus) OnPacketAcked(ackedPacketNumber protocol.PacketNumber) bool {
+	if !s.started || ackedPacketNumber < s.windowEnd {
+		return false
+	}
+	// The round ended. The next round starts with the next RTT sample.
+	s.started = false
+	if !s.inCSS {
+		return false
+	}
+	s.cssRounds++
+	return s.cssRounds >= hystartPlusPlusCSSRounds
+}
+
+// InConservativeSlowStart says if we're in Conservative Slow Start.
+func (s *HyStartPlusPlus) InConservativeSlowStart() bool {
+	return s.inCSS
+}
+
+// Restart the slow start phase
+func (s *HyStartPlusPlus) Restart() {
+	s.started = false
+	s.lastRoundMinRTT = 0
+	s.currentRoundMinRTT = 0
+	s.rttSampleCount = 0
+	s.inCSS = false
+	s.cssBaselineMinRTT = 0
+	s.cssRounds = 0
+}
@@ -0,0 +1,151 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// A hystartRound is one round of a HyStart++ trace:
+// the RTT samples (in milliseconds) obtained during the round, one per acknowledged packet,
+// and the state at the end of the round.
+type hystartRound struct {
+	rtts  []int
+	inCSS bool
+	exit  bool
+}
+
+// ssRound is a slow start round of 10 samples, with the minimum RTT at the given value.
+func ssRound(minRTT int) hystartRound {
+	return hystartRound{rtts: []int{minRTT + 3, minRTT + 1, minRTT, minRTT + 2, minRTT + 5, minRTT + 1, minRTT + 4, minRTT + 2, minRTT + 1, minRTT + 3}}
+}
+
+// cssRound is a Conservative Slow Start round of 10 samples, with the minimum RTT at the given value.
+func cssRound(minRTT int) hystartRound {
+	r := ssRound(minRTT)
+	r.inCSS = true
+	return r
+}
+
+func exitRound(minRTT int) hystartRound {
+	r := cssRound(minRTT)
+	r.exit = true
+	return r
+}
+
+// The traces follow the algorithm in section 4.2 of RFC 9406, using the constants from section 4.3:
+// MIN_RTT_THRESH = 4ms, MAX_RTT_THRESH = 16ms, MIN_RTT_DIVISOR = 8, N_RTT_SAMPLE = 8, CSS_ROUNDS = 5.
+var _ = Describe("HyStart++", func() {
+	var (
+		s            *HyStartPlusPlus
+		packetNumber protocol.PacketNumber
+	)
+
+	BeforeEach(func() {
+		s = &HyStartPlusPlus{}
+		packetNumber = 0
+	})
+
+	runTrace := func(trace []hystartRound) {
+		for i, round := range trace {
+			first := packetNumber + 1
+			for range round.rtts {
+				packetNumber++
+				s.OnPacketSent(packetNumber)
+			}
+			var exit bool
+			for j, rtt := range round.rtts {
+				s.OnRTTSample(time.Duration(rtt) * time.Millisecond)
+				if s.OnPacketAcked(first + protocol.PacketNumber(j)) {
+					exit = true
+				}
+			}
+			ExpectWithOffset(1, s.InConservativeSlowStart()).To(Equal(round.inCSS), "round %d", i)
+			ExpectWithOffset(1, exit).To(Equal(round.exit), "round %d", i)
+		}
+	}
+
+	It("stays in slow start if the RTT doesn't increase", func() {
+		runTrace([]hystartRound{ssRound(100), ssRound(100), ssRound(99), ssRound(101), ssRound(100), ssRound(100), ssRound(100)})
+	})
+
+	It("ignores RTT increases below RttThresh", func() {
+		// RttThresh = clamp(MIN_RTT_THRESH, 100ms / MIN_RTT_DIVISOR, MAX_RTT_THRESH) = 12.5ms
+		runTrace([]hystartRound{ssRound(100), ssRound(112), ssRound(124), ssRound(124)})
+	})
+
+	It("enters CSS when the RTT increases by RttThresh, and exits slow start after CSS_ROUNDS rounds", func() {
+		// The round in which the RTT increase is detected is the first CSS round.
+		runTrace([]hystartRound{
+			ssRound(100),
+			cssRound(113),
+			cssRound(113),
+			cssRound(114),
+			cssRound(113),
+			exitRound(115),
+		})
+	})
+
+	It("compares the minimum RTT of the rounds", func() {
+		// Individual samples exceed the threshold, but the minimum RTT doesn't.
+		round := hystartRound{rtts: []int{140, 130, 150, 111, 140, 160, 135, 145, 150, 140}}
+		runTrace([]hystartRound{ssRound(100), round})
+	})
+
+	It("only checks for an RTT increase after N_RTT_SAMPLE samples", func() {
+		runTrace([]hystartRound{ssRound(100)})
+		for i := 0; i < 10; i++ {
+			packetNumber++
+			s.OnPacketSent(packetNumber)
+		}
+		for i := 1; i <= 10; i++ {
+			s.OnRTTSample(113 * time.Millisecond)
+			Expect(s.InConservativeSlowStart()).To(Equal(i >= hystartPlusPlusNRTTSample))
+		}
+	})
+
+	It("doesn't take rounds with fewer than N_RTT_SAMPLE samples into account", func() {
+		runTrace([]hystartRound{
+			ssRound(100),
+			{rtts: []int{150, 150, 150, 150, 150, 150, 150}},
+		})
+	})
+
+	It("clamps RttThresh to MIN_RTT_THRESH", func() {
+		// 10ms / MIN_RTT_DIVISOR = 1.25ms, so MIN_RTT_THRESH = 4ms applies
+		runTrace([]hystartRound{ssRound(10), ssRound(13), cssRound(17)})
+	})
+
+	It("clamps RttThresh to MAX_RTT_THRESH", func() {
+		// 400ms / MIN_RTT_DIVISOR = 50ms, so MAX_RTT_THRESH = 16ms applies
+		runTrace([]hystartRound{ssRound(400), ssRound(415), cssRound(431)})
+	})
+
+	It("resumes slow start if the RTT increase was spurious", func() {
+		// cssBaselineMinRtt is 120ms
+		runTrace([]hystartRound{
+			ssRound(100),
+			cssRound(120),
+			cssRound(121),
+			// currentRoundMinRTT < cssBaselineMinRtt
+			ssRound(110),
+			// the CSS round count starts from scratch
+			cssRound(130),
+			cssRound(130),
+			cssRound(130),
+			cssRound(130),
+			exitRound(130),
+		})
+	})
+
+	It("restarts", func() {
+		runTrace([]hystartRound{ssRound(100), cssRound(120)})
+		s.Restart()
+		Expect(s.InConservativeSlowStart()).To(BeFalse())
+		// The RTT of the previous round is not used after a restart.
+		runTrace([]hystartRound{ssRound(150), ssRound(150)})
+	})
+})
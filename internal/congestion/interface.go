@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
+	"github.com/quic-go/quic-go/logging"
 )
 
 // A SendAlgorithm performs congestion control
@@ -16,6 +18,7 @@ type SendAlgorithm interface {
 	OnPacketAcked(number protocol.PacketNumber, ackedBytes protocol.ByteCount, priorInFlight protocol.ByteCount, eventTime time.Time)
 	OnCongestionEvent(number protocol.PacketNumber, lostBytes protocol.ByteCount, priorInFlight protocol.ByteCount)
 	OnRetransmissionTimeout(packetsRetransmitted bool)
+	OnConnectionMigration()
 	SetMaxDatagramSize(protocol.ByteCount)
 }
 
@@ -26,3 +29,29 @@ type SendAlgorithmWithDebugInfos interface {
 	InRecovery() bool
 	GetCongestionWindow() protocol.ByteCount
 }
+
+// A ScalableECNSendAlgorithm is a SendAlgorithm that implements a scalable congestion response to ECN-CE marks,
+// as used by L4S (RFC 9330). Packets are sent with the ECT(1) codepoint,
+// and CE marks are reported using OnECNFeedback instead of being treated like packet loss.
+type ScalableECNSendAlgorithm interface {
+	// OnECNFeedback is called for every ACK frame that increases the largest acknowledged 1-RTT packet number,
+	// before OnPacketAcked is called for the newly acknowledged packets.
+	// ceMarkedBytes is an estimate, since ACK frames only contain the number of CE-marked packets.
+	OnECNFeedback(largestAcked protocol.PacketNumber, ackedBytes, ceMarkedBytes protocol.ByteCount)
+}
+
+// A SpuriousLossSendAlgorithm is a SendAlgorithm that can undo its response to packet loss,
+// if it later turns out that the lost packets were not actually lost (e.g. due to reordering).
+type SpuriousLossSendAlgorithm interface {
+	// OnSpuriousLoss is called when a packet that was declared lost (and passed to OnCongestionEvent) is acknowledged.
+	OnSpuriousLoss(packetNumber protocol.PacketNumber)
+}
+
+// A SendAlgorithmFactory creates the SendAlgorithm of a connection.
+// The tracer is nil if no tracer is configured.
+type SendAlgorithmFactory func(rttStats *utils.RTTStats, initialMaxDatagramSize protocol.ByteCount, tracer *logging.ConnectionTracer) SendAlgorithmWithDebugInfos
+
+// NewRenoSender is the default SendAlgorithmFactory.
+func NewRenoSender(rttStats *utils.RTTStats, initialMaxDatagramSize protocol.ByteCount, tracer *logging.ConnectionTracer) SendAlgorithmWithDebugInfos {
+	return NewCubicSender(DefaultClock{}, rttStats, initialMaxDatagramSize, true, false, tracer)
+}
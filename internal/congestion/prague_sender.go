@@ -0,0 +1,287 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
+	"github.com/quic-go/quic-go/logging"
+)
+
+const (
+	// The gain of the moving average of the CE marking fraction.
+	pragueAlphaGain = 1.0 / 16
+	// The reduction of the congestion window on packet loss.
+	pragueLossBeta = 0.5
+)
+
+// The pragueSender implements a scalable congestion control for L4S (RFC 9330),
+// loosely following draft-briscoe-iccrg-prague-congestion-control.
+// Like DCTCP, it maintains a moving average (alpha) of the fraction of CE-marked bytes per round trip,
+// and reduces the congestion window by alpha/2 at most once per round trip when CE marks are received.
+// Packet loss is handled like in Reno.
+type pragueSender struct {
+	rttStats *utils.RTTStats
+	pacer    *pacer
+
+	largestSentPacketNumber  protocol.PacketNumber
+	largestAckedPacketNumber protocol.PacketNumber
+	// Track the largest packet number outstanding when a CWND cutback due to packet loss occurs.
+	largestSentAtLastCutback protocol.PacketNumber
+	// Track the largest packet number outstanding when a CWND cutback due to CE marks occurs.
+	largestSentAtLastCECutback protocol.PacketNumber
+
+	// The moving average of the CE marking fraction.
+	alpha float64
+	// The round ends when a packet sent after roundEnd is acknowledged.
+	roundEnd           protocol.PacketNumber
+	ackedBytesInRound  protocol.ByteCount
+	markedBytesInRound protocol.ByteCount
+
+	congestionWindow   protocol.ByteCount
+	slowStartThreshold protocol.ByteCount
+	// Bytes acknowledged in congestion avoidance since the last increase of the congestion window.
+	ackedBytesInCA protocol.ByteCount
+
+	initialCongestionWindow protocol.ByteCount
+	maxDatagramSize         protocol.ByteCount
+
+	lastState logging.CongestionState
+	tracer    *logging.ConnectionTracer
+}
+
+var (
+	_ SendAlgorithm               = &pragueSender{}
+	_ SendAlgorithmWithDebugInfos = &pragueSender{}
+	_ ScalableECNSendAlgorithm    = &pragueSender{}
+)
+
+// NewPragueSender makes a new Prague sender
+func NewPragueSender(
+	rttStats *utils.RTTStats,
+	initialMaxDatagramSize protocol.ByteCount,
+	tracer *logging.ConnectionTracer,
+) *pragueSender {
+	c := &pragueSender{
+		rttStats:                rttStats,
+		initialCongestionWindow: initialCongestionWindow * initialMaxDatagramSize,
+		maxDatagramSize:         initialMaxDatagramSize,
+		tracer:                  tracer,
+	}
+	c.reset()
+	c.pacer = newPacer(c.BandwidthEstimate)
+	if c.tracer != nil && c.tracer.UpdatedCongestionState != nil {
+		c.lastState = logging.CongestionStateSlowStart
+		c.tracer.UpdatedCongestionState(logging.CongestionStateSlowStart)
+	}
+	return c
+}
+
+func (c *pragueSender) reset() {
+	c.largestSentPacketNumber = protocol.InvalidPacketNumber
+	c.largestAckedPacketNumber = protocol.InvalidPacketNumber
+	c.largestSentAtLastCutback = protocol.InvalidPacketNumber
+	c.largestSentAtLastCECutback = protocol.InvalidPacketNumber
+	// Start with the most conservative assumption, as DCTCP does.
+	c.alpha = 1
+	c.roundEnd = protocol.InvalidPacketNumber
+	c.ackedBytesInRound = 0
+	c.markedBytesInRound = 0
+	c.congestionWindow = c.initialCongestionWindow
+	c.slowStartThreshold = protocol.MaxByteCount
+	c.ackedBytesInCA = 0
+}
+
+// TimeUntilSend returns when the next packet should be sent.
+func (c *pragueSender) TimeUntilSend(_ protocol.ByteCount) time.Time {
+	return c.pacer.TimeUntilSend()
+}
+
+func (c *pragueSender) HasPacingBudget(now time.Time) bool {
+	return c.pacer.Budget(now) >= c.maxDatagramSize
+}
+
+func (c *pragueSender) OnPacketSent(
+	sentTime time.Time,
+	_ protocol.ByteCount,
+	packetNumber protocol.PacketNumber,
+	bytes protocol.ByteCount,
+	isRetransmittable bool,
+) {
+	c.pacer.SentPacket(sentTime, bytes)
+	if !isRetransmittable {
+		return
+	}
+	c.largestSentPacketNumber = packetNumber
+}
+
+func (c *pragueSender) CanSend(bytesInFlight protocol.ByteCount) bool {
+	return bytesInFlight < c.GetCongestionWindow()
+}
+
+func (c *pragueSender) InRecovery() bool {
+	return c.largestAckedPacketNumber != protocol.InvalidPacketNumber && c.largestAckedPacketNumber <= c.largestSentAtLastCutback
+}
+
+func (c *pragueSender) InSlowStart() bool {
+	return c.GetCongestionWindow() < c.slowStartThreshold
+}
+
+func (c *pragueSender) GetCongestionWindow() protocol.ByteCount {
+	return c.congestionWindow
+}
+
+// MaybeExitSlowStart is a no-op. Slow start is exited on the first congestion signal.
+func (c *pragueSender) MaybeExitSlowStart() {}
+
+// OnECNFeedback is called for ACK frames that acknowledge new 1-RTT packets.
+func (c *pragueSender) OnECNFeedback(largestAcked protocol.PacketNumber, ackedBytes, ceMarkedBytes protocol.ByteCount) {
+	if largestAcked > c.roundEnd {
+		c.updateAlpha()
+		c.roundEnd = c.largestSentPacketNumber
+	}
+	c.ackedBytesInRound += ackedBytes
+	c.markedBytesInRound += ceMarkedBytes
+	if ceMarkedBytes == 0 {
+		return
+	}
+	// React at most once per round trip.
+	if largestAcked <= c.largestSentAtLastCECutback {
+		return
+	}
+	c.congestionWindow = max(
+		protocol.ByteCount(float64(c.congestionWindow)*(1-c.alpha/2)),
+		c.minCongestionWindow(),
+	)
+	c.slowStartThreshold = c.congestionWindow
+	c.largestSentAtLastCECutback = c.largestSentPacketNumber
+	c.ackedBytesInCA = 0
+	c.maybeTraceStateChange(logging.CongestionStateCongestionAvoidance)
+}
+
+func (c *pragueSender) OnPacketAcked(
+	ackedPacketNumber protocol.PacketNumber,
+	ackedBytes protocol.ByteCount,
+	priorInFlight protocol.ByteCount,
+	_ time.Time,
+) {
+	c.largestAckedPacketNumber = max(ackedPacketNumber, c.largestAckedPacketNumber)
+	if c.InRecovery() {
+		return
+	}
+	c.maybeIncreaseCwnd(ackedBytes, priorInFlight)
+}
+
+// updateAlpha is called once per round trip.
+func (c *pragueSender) updateAlpha() {
+	if c.ackedBytesInRound == 0 {
+		return
+	}
+	fraction := min(float64(c.markedBytesInRound)/float64(c.ackedBytesInRound), 1)
+	c.alpha = (1-pragueAlphaGain)*c.alpha + pragueAlphaGain*fraction
+	c.ackedBytesInRound = 0
+	c.markedBytesInRound = 0
+	if c.tracer != nil && c.tracer.UpdatedECNMarkingFraction != nil {
+		c.tracer.UpdatedECNMarkingFraction(fraction, c.alpha)
+	}
+}
+
+func (c *pragueSender) maybeIncreaseCwnd(ackedBytes, priorInFlight protocol.ByteCount) {
+	// Do not increase the congestion window unless the sender is close to using the current window.
+	if !c.isCwndLimited(priorInFlight) {
+		c.maybeTraceStateChange(logging.CongestionStateApplicationLimited)
+		return
+	}
+	if c.congestionWindow >= c.maxCongestionWindow() {
+		return
+	}
+	if c.InSlowStart() {
+		c.congestionWindow += ackedBytes
+		c.maybeTraceStateChange(logging.CongestionStateSlowStart)
+		return
+	}
+	// Additive increase: one packet per round trip.
+	c.maybeTraceStateChange(logging.CongestionStateCongestionAvoidance)
+	c.ackedBytesInCA += ackedBytes
+	if c.ackedBytesInCA >= c.congestionWindow {
+		c.ackedBytesInCA -= c.congestionWindow
+		c.congestionWindow += c.maxDatagramSize
+	}
+}
+
+func (c *pragueSender) isCwndLimited(bytesInFlight protocol.ByteCount) bool {
+	congestionWindow := c.GetCongestionWindow()
+	if bytesInFlight >= congestionWindow {
+		return true
+	}
+	availableBytes := congestionWindow - bytesInFlight
+	slowStartLimited := c.InSlowStart() && bytesInFlight > congestionWindow/2
+	return slowStartLimited || availableBytes <= maxBurstPackets*c.maxDatagramSize
+}
+
+func (c *pragueSender) OnCongestionEvent(packetNumber protocol.PacketNumber, _, _ protocol.ByteCount) {
+	// Any losses in packets already sent are treated as a single loss event.
+	if packetNumber <= c.largestSentAtLastCutback {
+		return
+	}
+	c.maybeTraceStateChange(logging.CongestionStateRecovery)
+	c.congestionWindow = max(
+		protocol.ByteCount(float64(c.congestionWindow)*pragueLossBeta),
+		c.minCongestionWindow(),
+	)
+	c.slowStartThreshold = c.congestionWindow
+	c.largestSentAtLastCutback = c.largestSentPacketNumber
+	c.ackedBytesInCA = 0
+}
+
+// BandwidthEstimate returns the current bandwidth estimate
+func (c *pragueSender) BandwidthEstimate() Bandwidth {
+	srtt := c.rttStats.SmoothedRTT()
+	if srtt == 0 {
+		// If we haven't measured an rtt, the bandwidth estimate is unknown.
+		return infBandwidth
+	}
+	return BandwidthFromDelta(c.GetCongestionWindow(), srtt)
+}
+
+// OnRetransmissionTimeout is called on an retransmission timeout
+func (c *pragueSender) OnRetransmissionTimeout(packetsRetransmitted bool) {
+	c.largestSentAtLastCutback = protocol.InvalidPacketNumber
+	if !packetsRetransmitted {
+		return
+	}
+	c.slowStartThreshold = c.congestionWindow / 2
+	c.congestionWindow = c.minCongestionWindow()
+}
+
+// OnConnectionMigration is called when the connection is migrated to a new path.
+// The congestion controller starts from scratch on the new path.
+func (c *pragueSender) OnConnectionMigration() {
+	c.reset()
+}
+
+func (c *pragueSender) minCongestionWindow() protocol.ByteCount {
+	return c.maxDatagramSize * minCongestionWindowPackets
+}
+
+func (c *pragueSender) maxCongestionWindow() protocol.ByteCount {
+	return c.maxDatagramSize * protocol.MaxCongestionWindowPackets
+}
+
+func (c *pragueSender) maybeTraceStateChange(new logging.CongestionState) {
+	if c.tracer == nil || c.tracer.UpdatedCongestionState == nil || new == c.lastState {
+		return
+	}
+	c.tracer.UpdatedCongestionState(new)
+	c.lastState = new
+}
+
+func (c *pragueSender) SetMaxDatagramSize(s protocol.ByteCount) {
+	cwndIsMinCwnd := c.congestionWindow == c.minCongestionWindow()
+	c.maxDatagramSize = s
+	if cwndIsMinCwnd {
+		c.congestionWindow = c.minCongestionWindow()
+	}
+	c.congestionWindow = min(c.congestionWindow, c.maxCongestionWindow())
+	c.pacer.SetMaxDatagramSize(s)
+}
@@ -0,0 +1,151 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
+	"github.com/quic-go/quic-go/logging"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Prague Sender", func() {
+	var (
+		sender            *pragueSender
+		rttStats          *utils.RTTStats
+		bytesInFlight     protocol.ByteCount
+		packetNumber      protocol.PacketNumber
+		ackedPacketNumber protocol.PacketNumber
+		fractions, alphas []float64
+	)
+
+	BeforeEach(func() {
+		bytesInFlight = 0
+		packetNumber = 0
+		ackedPacketNumber = 0
+		fractions = nil
+		alphas = nil
+		rttStats = utils.NewRTTStats()
+		rttStats.UpdateRTT(10*time.Millisecond, 0, time.Now())
+		sender = NewPragueSender(rttStats, maxDatagramSize, &logging.ConnectionTracer{
+			UpdatedECNMarkingFraction: func(fraction, alpha float64) {
+				fractions = append(fractions, fraction)
+				alphas = append(alphas, alpha)
+			},
+		})
+	})
+
+	sendWindow := func() int {
+		var n int
+		for sender.CanSend(bytesInFlight) {
+			packetNumber++
+			sender.OnPacketSent(time.Now(), bytesInFlight, packetNumber, maxDatagramSize, true)
+			bytesInFlight += maxDatagramSize
+			n++
+		}
+		return n
+	}
+
+	// ack acknowledges the next n packets in a single ACK frame, ceMarked of which were CE-marked.
+	ack := func(n, ceMarked int) {
+		priorInFlight := bytesInFlight
+		sender.OnECNFeedback(ackedPacketNumber+protocol.PacketNumber(n), protocol.ByteCount(n)*maxDatagramSize, protocol.ByteCount(ceMarked)*maxDatagramSize)
+		for i := 0; i < n; i++ {
+			ackedPacketNumber++
+			sender.OnPacketAcked(ackedPacketNumber, maxDatagramSize, priorInFlight, time.Now())
+		}
+		bytesInFlight -= protocol.ByteCount(n) * maxDatagramSize
+	}
+
+	It("has the right values at startup", func() {
+		Expect(sender.GetCongestionWindow()).To(Equal(initialCongestionWindow * maxDatagramSize))
+		Expect(sender.InSlowStart()).To(BeTrue())
+		Expect(sender.InRecovery()).To(BeFalse())
+		Expect(sender.alpha).To(Equal(1.0))
+	})
+
+	It("grows the congestion window in slow start", func() {
+		Expect(sendWindow()).To(Equal(initialCongestionWindow))
+		ack(initialCongestionWindow, 0)
+		Expect(sender.GetCongestionWindow()).To(Equal(2 * initialCongestionWindow * maxDatagramSize))
+		Expect(sender.InSlowStart()).To(BeTrue())
+	})
+
+	It("reduces the congestion window by alpha/2 once per round trip", func() {
+		sendWindow()
+		ack(initialCongestionWindow, 0)
+		Expect(sendWindow()).To(Equal(2 * initialCongestionWindow))
+		// alpha was updated at the beginning of this round
+		Expect(alphas).To(BeEmpty())
+		ack(5, 2)
+		Expect(alphas).To(Equal([]float64{1 - pragueAlphaGain}))
+		cwnd := protocol.ByteCount(float64(2*initialCongestionWindow*maxDatagramSize) * (1 - (1-pragueAlphaGain)/2))
+		Expect(sender.GetCongestionWindow()).To(Equal(cwnd))
+		Expect(sender.InSlowStart()).To(BeFalse())
+		// more CE marks in the same round don't reduce the congestion window again
+		ack(5, 5)
+		Expect(sender.GetCongestionWindow()).To(Equal(cwnd))
+	})
+
+	It("updates alpha at the end of every round", func() {
+		sendWindow()
+		ack(initialCongestionWindow, 0)
+		sendWindow()
+		ack(2*initialCongestionWindow, initialCongestionWindow)
+		Expect(fractions).To(Equal([]float64{0}))
+		sendWindow()
+		ack(1, 0)
+		Expect(fractions).To(Equal([]float64{0, 0.5}))
+		alpha := 1 - pragueAlphaGain
+		alpha = (1-pragueAlphaGain)*alpha + pragueAlphaGain*0.5
+		Expect(alphas).To(Equal([]float64{1 - pragueAlphaGain, alpha}))
+		Expect(sender.alpha).To(Equal(alpha))
+	})
+
+	It("reduces the congestion window less with a low marking fraction", func() {
+		// Run many rounds without any CE marks, so that alpha converges to 0.
+		for i := 0; i < 100; i++ {
+			n := sendWindow()
+			ack(n, 0)
+		}
+		Expect(sender.alpha).To(BeNumerically("<", 0.01))
+		sendWindow()
+		cwnd := sender.GetCongestionWindow()
+		ack(1, 1)
+		Expect(sender.GetCongestionWindow()).To(BeNumerically(">", cwnd*99/100))
+	})
+
+	It("halves the congestion window on packet loss", func() {
+		sendWindow()
+		ack(initialCongestionWindow, 0)
+		sendWindow()
+		cwnd := sender.GetCongestionWindow()
+		sender.OnCongestionEvent(ackedPacketNumber+1, maxDatagramSize, bytesInFlight)
+		Expect(sender.GetCongestionWindow()).To(Equal(cwnd / 2))
+		Expect(sender.InRecovery()).To(BeTrue())
+		ackedPacketNumber++
+		bytesInFlight -= maxDatagramSize
+		// further losses in the same window don't reduce the congestion window
+		sender.OnCongestionEvent(ackedPacketNumber+1, maxDatagramSize, bytesInFlight)
+		Expect(sender.GetCongestionWindow()).To(Equal(cwnd / 2))
+		ackedPacketNumber++
+		bytesInFlight -= maxDatagramSize
+		// the congestion window isn't increased during recovery
+		ack(1, 0)
+		Expect(sender.GetCongestionWindow()).To(Equal(cwnd / 2))
+	})
+
+	It("resets the state on connection migration", func() {
+		sendWindow()
+		ack(initialCongestionWindow, 0)
+		sendWindow()
+		ack(1, 1)
+		Expect(sender.alpha).ToNot(Equal(1.0))
+		sender.OnConnectionMigration()
+		Expect(sender.GetCongestionWindow()).To(Equal(initialCongestionWindow * maxDatagramSize))
+		Expect(sender.InSlowStart()).To(BeTrue())
+		Expect(sender.alpha).To(Equal(1.0))
+	})
+})
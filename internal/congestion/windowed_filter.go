@@ -0,0 +1,86 @@
+package congestion
+
+// The windowedFilter tracks the best (i.e. the maximum or the minimum) value seen during a window.
+// It implements Kathleen Nichols' algorithm, which keeps track of the best, second best and
+// third best values. This allows the filter to quickly pick up a new best value when the
+// current best value expires, without having to store all samples.
+// The window is either defined in time, or in number of round trips.
+type windowedFilter[V, T ~int64 | ~uint64] struct {
+	windowLength T
+	isBetter     func(a, b V) bool
+	estimates    [3]filterSample[V, T]
+}
+
+type filterSample[V, T ~int64 | ~uint64] struct {
+	value V
+	time  T
+}
+
+func newMaxFilter[V, T ~int64 | ~uint64](windowLength T) *windowedFilter[V, T] {
+	return &windowedFilter[V, T]{
+		windowLength: windowLength,
+		isBetter:     func(a, b V) bool { return a >= b },
+	}
+}
+
+func newMinFilter[V, T ~int64 | ~uint64](windowLength T) *windowedFilter[V, T] {
+	return &windowedFilter[V, T]{
+		windowLength: windowLength,
+		isBetter:     func(a, b V) bool { return a <= b },
+	}
+}
+
+// Update adds a new sample.
+// The zero value is used as a marker for an empty filter, so value must not be 0.
+func (f *windowedFilter[V, T]) Update(value V, now T) {
+	if f.estimates[0].value == 0 || f.isBetter(value, f.estimates[0].value) || now-f.estimates[2].time > f.windowLength {
+		f.Reset(value, now)
+		return
+	}
+
+	if f.isBetter(value, f.estimates[1].value) {
+		f.estimates[1] = filterSample[V, T]{value: value, time: now}
+		f.estimates[2] = f.estimates[1]
+	} else if f.isBetter(value, f.estimates[2].value) {
+		f.estimates[2] = filterSample[V, T]{value: value, time: now}
+	}
+
+	// Expire and update estimates as necessary.
+	if now-f.estimates[0].time > f.windowLength {
+		// The best estimate hasn't been updated for an entire window, so promote the second and third best estimates.
+		f.estimates[0] = f.estimates[1]
+		f.estimates[1] = f.estimates[2]
+		f.estimates[2] = filterSample[V, T]{value: value, time: now}
+		// Need to iterate one more time.
+		// Check if the new best estimate is outside the window as well,
+		// since it may also have been recorded a long time ago.
+		if now-f.estimates[0].time > f.windowLength {
+			f.estimates[0] = f.estimates[1]
+			f.estimates[1] = f.estimates[2]
+		}
+		return
+	}
+	if f.estimates[1].value == f.estimates[0].value && now-f.estimates[1].time > f.windowLength/4 {
+		// A quarter of the window has passed without a better sample,
+		// so the second best estimate is taken from the second quarter of the window.
+		f.estimates[1] = filterSample[V, T]{value: value, time: now}
+		f.estimates[2] = f.estimates[1]
+		return
+	}
+	if f.estimates[2].value == f.estimates[1].value && now-f.estimates[2].time > f.windowLength/2 {
+		// We've passed half of the window without a better estimate,
+		// so take a third best estimate from the second half of the window.
+		f.estimates[2] = filterSample[V, T]{value: value, time: now}
+	}
+}
+
+// Reset resets all estimates to a new sample.
+func (f *windowedFilter[V, T]) Reset(value V, now T) {
+	s := filterSample[V, T]{value: value, time: now}
+	f.estimates = [3]filterSample[V, T]{s, s, s}
+}
+
+// Best returns the best value. It returns 0 if no sample was added.
+func (f *windowedFilter[V, T]) Best() V {
+	return f.estimates[0].value
+}
@@ -0,0 +1,77 @@
+package congestion
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Windowed Filter", func() {
+	Context("min filter", func() {
+		var f *windowedFilter[time.Duration, time.Duration]
+
+		BeforeEach(func() {
+			f = newMinFilter[time.Duration, time.Duration](100 * time.Millisecond)
+		})
+
+		It("returns 0 if no sample was added", func() {
+			Expect(f.Best()).To(BeZero())
+		})
+
+		It("tracks the minimum", func() {
+			f.Update(50*time.Millisecond, 0)
+			Expect(f.Best()).To(Equal(50 * time.Millisecond))
+			f.Update(60*time.Millisecond, 10*time.Millisecond)
+			Expect(f.Best()).To(Equal(50 * time.Millisecond))
+			f.Update(40*time.Millisecond, 20*time.Millisecond)
+			Expect(f.Best()).To(Equal(40 * time.Millisecond))
+		})
+
+		It("expires the minimum after the window", func() {
+			f.Update(10*time.Millisecond, 0)
+			f.Update(20*time.Millisecond, 30*time.Millisecond)
+			f.Update(30*time.Millisecond, 90*time.Millisecond)
+			Expect(f.Best()).To(Equal(10 * time.Millisecond))
+			f.Update(40*time.Millisecond, 101*time.Millisecond)
+			Expect(f.Best()).To(Equal(20 * time.Millisecond))
+			f.Update(40*time.Millisecond, 131*time.Millisecond)
+			Expect(f.Best()).To(Equal(30 * time.Millisecond))
+			f.Update(40*time.Millisecond, 191*time.Millisecond)
+			Expect(f.Best()).To(Equal(40 * time.Millisecond))
+		})
+
+		It("resets if no sample was added for an entire window", func() {
+			f.Update(10*time.Millisecond, 0)
+			f.Update(30*time.Millisecond, time.Second)
+			Expect(f.Best()).To(Equal(30 * time.Millisecond))
+		})
+	})
+
+	Context("max filter", func() {
+		var f *windowedFilter[Bandwidth, uint64]
+
+		BeforeEach(func() {
+			f = newMaxFilter[Bandwidth, uint64](10)
+		})
+
+		It("tracks the maximum", func() {
+			f.Update(1000, 1)
+			f.Update(900, 2)
+			Expect(f.Best()).To(BeEquivalentTo(1000))
+			f.Update(1100, 3)
+			Expect(f.Best()).To(BeEquivalentTo(1100))
+		})
+
+		It("expires the maximum after the window", func() {
+			f.Update(1000, 0)
+			for i := uint64(1); i <= 10; i++ {
+				f.Update(Bandwidth(900-i), i)
+				Expect(f.Best()).To(BeEquivalentTo(1000))
+			}
+			f.Update(500, 11)
+			Expect(f.Best()).To(BeNumerically("<", 1000))
+			Expect(f.Best()).To(BeNumerically(">", 500))
+		})
+	})
+})
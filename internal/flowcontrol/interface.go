@@ -24,6 +24,11 @@ type StreamFlowController interface {
 	// Abandon should be called when reading from the stream is aborted early,
 	// and there won't be any further calls to AddBytesRead.
 	Abandon()
+	// for statistics
+	// IsBlocked says if sending is blocked by stream-level and / or by connection-level flow control.
+	IsBlocked() (streamLevel, connectionLevel bool)
+	// RemainingReceiveWindow returns the number of bytes the peer is still allowed to send.
+	RemainingReceiveWindow() protocol.ByteCount
 }
 
 // The ConnectionFlowController is the flow controller for the connection.
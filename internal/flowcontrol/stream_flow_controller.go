@@ -126,6 +126,19 @@ func (c *streamFlowController) SendWindowSize() protocol.ByteCount {
 	return min(c.baseFlowController.sendWindowSize(), c.connection.SendWindowSize())
 }
 
+func (c *streamFlowController) IsBlocked() (streamLevel, connectionLevel bool) {
+	return c.baseFlowController.sendWindowSize() == 0, c.connection.SendWindowSize() == 0
+}
+
+func (c *streamFlowController) RemainingReceiveWindow() protocol.ByteCount {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.highestReceived > c.receiveWindow {
+		return 0
+	}
+	return c.receiveWindow - c.highestReceived
+}
+
 func (c *streamFlowController) shouldQueueWindowUpdate() bool {
 	return !c.receivedFinalOffset && c.hasWindowUpdate()
 }
@@ -112,6 +112,13 @@ var _ = Describe("Stream Flow controller", func() {
 				}))
 			})
 
+			It("returns the remaining receive window", func() {
+				Expect(controller.RemainingReceiveWindow()).To(Equal(receiveWindow))
+				controller.connection.(*connectionFlowController).receiveWindow = receiveWindow
+				Expect(controller.UpdateHighestReceived(1000, false)).To(Succeed())
+				Expect(controller.RemainingReceiveWindow()).To(Equal(receiveWindow - 1000))
+			})
+
 			It("accepts a final offset higher than the highest received", func() {
 				Expect(controller.UpdateHighestReceived(100, false)).To(Succeed())
 				Expect(controller.UpdateHighestReceived(101, true)).To(Succeed())
@@ -268,5 +275,22 @@ var _ = Describe("Stream Flow controller", func() {
 			Expect(blocked).To(BeTrue())
 			Expect(controller.IsNewlyBlocked()).To(BeFalse())
 		})
+
+		It("says if it's blocked by stream-level or by connection-level flow control", func() {
+			controller.connection.UpdateSendWindow(50)
+			controller.UpdateSendWindow(100)
+			streamBlocked, connBlocked := controller.IsBlocked()
+			Expect(streamBlocked).To(BeFalse())
+			Expect(connBlocked).To(BeFalse())
+			controller.AddBytesSent(50)
+			streamBlocked, connBlocked = controller.IsBlocked()
+			Expect(streamBlocked).To(BeFalse())
+			Expect(connBlocked).To(BeTrue())
+			controller.connection.UpdateSendWindow(200)
+			controller.AddBytesSent(50)
+			streamBlocked, connBlocked = controller.IsBlocked()
+			Expect(streamBlocked).To(BeTrue())
+			Expect(connBlocked).To(BeFalse())
+		})
 	})
 })
@@ -33,6 +33,7 @@ type cryptoSetup struct {
 	events []Event
 
 	version protocol.Version
+	connID  protocol.ConnectionID // the connection ID used to derive the Initial keys
 
 	ourParams  *wire.TransportParameters
 	peerParams *wire.TransportParameters
@@ -40,6 +41,10 @@ type cryptoSetup struct {
 	zeroRTTParameters *wire.TransportParameters
 	allow0RTT         bool
 
+	ticketKeys    *SessionTicketKeyRing // only set for the server, if session tickets are not encrypted by crypto/tls
+	clientHello   []byte                // only set for the server, while handling the ClientHello
+	sessionTicket []byte                // only set for the server, while handling the ClientHello
+
 	rttStats *utils.RTTStats
 
 	tracer *logging.ConnectionTracer
@@ -55,6 +60,11 @@ type cryptoSetup struct {
 	initialOpener LongHeaderOpener
 	initialSealer LongHeaderSealer
 
+	// Only set on the server after switching versions using compatible version negotiation.
+	// The client keeps sending Initial packets using the original version until it receives our first Initial packet.
+	originalVersion       protocol.Version
+	originalInitialOpener LongHeaderOpener
+
 	handshakeOpener LongHeaderOpener
 	handshakeSealer LongHeaderSealer
 
@@ -108,6 +118,7 @@ func NewCryptoSetupServer(
 	tp *wire.TransportParameters,
 	tlsConf *tls.Config,
 	allow0RTT bool,
+	ticketKeys *SessionTicketKeyRing,
 	rttStats *utils.RTTStats,
 	tracer *logging.ConnectionTracer,
 	logger utils.Logger,
@@ -123,6 +134,25 @@ func NewCryptoSetupServer(
 		version,
 	)
 	cs.allow0RTT = allow0RTT
+	if ticketKeys != nil {
+		cs.ticketKeys = ticketKeys
+		tlsConf = tlsConf.Clone()
+		tlsConf.WrapSession = cs.wrapSession
+		tlsConf.UnwrapSession = cs.unwrapSession
+		// The tls.Config returned by GetConfigForClient is used for the rest of the handshake.
+		// It needs to use the key ring as well.
+		if gcfc := tlsConf.GetConfigForClient; gcfc != nil {
+			tlsConf.GetConfigForClient = func(info *tls.ClientHelloInfo) (*tls.Config, error) {
+				c, err := gcfc(info)
+				if c != nil {
+					c = c.Clone()
+					c.WrapSession = cs.wrapSession
+					c.UnwrapSession = cs.unwrapSession
+				}
+				return c, err
+			}
+		}
+	}
 
 	quicConf := &tls.QUICConfig{TLSConfig: tlsConf}
 	qtls.SetupConfigForServer(quicConf, cs.allow0RTT, cs.getDataForSessionTicket, cs.handleSessionTicket)
@@ -187,11 +217,25 @@ func newCryptoSetup(
 		logger:        logger,
 		perspective:   perspective,
 		version:       version,
+		connID:        connID,
 	}
 }
 
 func (h *cryptoSetup) ChangeConnectionID(id protocol.ConnectionID) {
-	initialSealer, initialOpener := NewInitialAEAD(id, h.perspective, h.version)
+	h.connID = id
+	h.resetInitialKeys()
+}
+
+// ChangeVersion changes the QUIC version after compatible version negotiation (RFC 9368).
+// The Initial keys are derived again, and all keys derived later use the new version.
+func (h *cryptoSetup) ChangeVersion(v protocol.Version) {
+	h.version = v
+	h.aead.version = v
+	h.resetInitialKeys()
+}
+
+func (h *cryptoSetup) resetInitialKeys() {
+	initialSealer, initialOpener := NewInitialAEAD(h.connID, h.perspective, h.version)
 	h.initialSealer = initialSealer
 	h.initialOpener = initialOpener
 	if h.tracer != nil && h.tracer.UpdatedKeyFromTLS != nil {
@@ -246,7 +290,15 @@ func (h *cryptoSetup) HandleMessage(data []byte, encLevel protocol.EncryptionLev
 }
 
 func (h *cryptoSetup) handleMessage(data []byte, encLevel protocol.EncryptionLevel) error {
-	if err := h.conn.HandleData(qtls.ToTLSEncryptionLevel(encLevel), data); err != nil {
+	// The ClientHello is needed to detect replayed 0-RTT connection attempts.
+	// It's only accessed while crypto/tls processes it.
+	if h.ticketKeys != nil && encLevel == protocol.EncryptionInitial {
+		h.clientHello = data
+	}
+	err := h.conn.HandleData(qtls.ToTLSEncryptionLevel(encLevel), data)
+	h.clientHello = nil
+	h.sessionTicket = nil
+	if err != nil {
 		return err
 	}
 	for {
@@ -306,6 +358,42 @@ func (h *cryptoSetup) handleTransportParameters(data []byte) error {
 	}
 	h.peerParams = &tp
 	h.events = append(h.events, Event{Kind: EventReceivedTransportParameters, TransportParameters: h.peerParams})
+	if h.perspective == protocol.PerspectiveServer {
+		return h.negotiateVersion(tp.VersionInformation)
+	}
+	return nil
+}
+
+// negotiateVersion performs compatible version negotiation (RFC 9368) on the server side.
+// It is called when receiving the client's transport parameters, which happens before
+// the Handshake keys are derived and before the ServerHello is sent.
+// The versions supported by the server, in order of preference, are taken from our
+// version_information transport parameter.
+func (h *cryptoSetup) negotiateVersion(vi *wire.VersionInformation) error {
+	ours := h.ourParams.VersionInformation
+	if ours == nil || vi == nil {
+		return nil
+	}
+	if vi.ChosenVersion != h.version {
+		return &qerr.TransportError{
+			ErrorCode:    qerr.VersionNegotiationErrorCode,
+			ErrorMessage: fmt.Sprintf("chosen version (%s) doesn't match the packet's version (%s)", vi.ChosenVersion, h.version),
+		}
+	}
+	for _, v := range ours.AvailableVersions {
+		if v == h.version {
+			break
+		}
+		if protocol.IsSupportedVersion(vi.AvailableVersions, v) && protocol.IsCompatibleVersion(h.version, v) {
+			h.logger.Debugf("Switching from QUIC version %s to %s using compatible version negotiation.", h.version, v)
+			h.originalVersion = h.version
+			h.originalInitialOpener = h.initialOpener
+			h.ChangeVersion(v)
+			h.events = append(h.events, Event{Kind: EventNegotiatedVersion, Version: v})
+			break
+		}
+	}
+	ours.ChosenVersion = h.version
 	return nil
 }
 
@@ -423,10 +511,44 @@ func (h *cryptoSetup) handleSessionTicket(sessionTicketData []byte, using0RTT bo
 		h.logger.Debugf("0-RTT not allowed. Rejecting 0-RTT.")
 		return false
 	}
+	if h.ticketKeys != nil && h.ticketKeys.IsReplay(h.sessionTicket, h.clientHello) {
+		h.logger.Debugf("ClientHello was replayed. Rejecting 0-RTT.")
+		return false
+	}
 	h.logger.Debugf("Accepting 0-RTT. Restoring RTT from session ticket: %s", t.RTT)
 	return true
 }
 
+// wrapSession is used by the server to encrypt session tickets, if a SessionTicketKeyRing is used.
+func (h *cryptoSetup) wrapSession(_ tls.ConnectionState, state *tls.SessionState) ([]byte, error) {
+	b, err := state.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return h.ticketKeys.Seal(b)
+}
+
+// unwrapSession is used by the server to decrypt session tickets, if a SessionTicketKeyRing is used.
+// Session tickets that can't be decrypted are ignored, and a full handshake is performed.
+func (h *cryptoSetup) unwrapSession(identity []byte, _ tls.ConnectionState) (*tls.SessionState, error) {
+	h.sessionTicket = identity
+	b, allow0RTT, err := h.ticketKeys.Open(identity)
+	if err != nil {
+		h.logger.Debugf("Decrypting session ticket failed: %s", err.Error())
+		return nil, nil
+	}
+	state, err := tls.ParseSessionState(b)
+	if err != nil {
+		h.logger.Debugf("Parsing session ticket failed: %s", err.Error())
+		return nil, nil
+	}
+	if state.EarlyData && !allow0RTT {
+		h.logger.Debugf("Session ticket key too old for 0-RTT. Rejecting 0-RTT.")
+		state.EarlyData = false
+	}
+	return state, nil
+}
+
 // rejected0RTT is called for the client when the server rejects 0-RTT.
 func (h *cryptoSetup) rejected0RTT() {
 	h.logger.Debugf("0-RTT was rejected. Dropping 0-RTT keys.")
@@ -548,6 +670,7 @@ func (h *cryptoSetup) DiscardInitialKeys() {
 	dropped := h.initialOpener != nil
 	h.initialOpener = nil
 	h.initialSealer = nil
+	h.originalInitialOpener = nil
 	if dropped {
 		h.logger.Debugf("Dropping Initial keys.")
 	}
@@ -603,10 +726,16 @@ func (h *cryptoSetup) Get1RTTSealer() (ShortHeaderSealer, error) {
 	return h.aead, nil
 }
 
-func (h *cryptoSetup) GetInitialOpener() (LongHeaderOpener, error) {
+// GetInitialOpener returns the opener for Initial packets using version v.
+// After compatible version negotiation, the server uses the opener of the original version
+// for the client's Initial packets that still use that version.
+func (h *cryptoSetup) GetInitialOpener(v protocol.Version) (LongHeaderOpener, error) {
 	if h.initialOpener == nil {
 		return nil, ErrKeysDropped
 	}
+	if v != h.version && v == h.originalVersion && h.originalInitialOpener != nil {
+		return h.originalInitialOpener, nil
+	}
 	return h.initialOpener, nil
 }
 
@@ -647,6 +776,10 @@ func (h *cryptoSetup) Get1RTTOpener() (ShortHeaderOpener, error) {
 	return h.aead, nil
 }
 
+func (h *cryptoSetup) NumKeyUpdates() uint64 {
+	return uint64(h.aead.keyPhase)
+}
+
 func (h *cryptoSetup) ConnectionState() ConnectionState {
 	return ConnectionState{
 		ConnectionState: h.conn.ConnectionState(),
@@ -655,6 +788,10 @@ func (h *cryptoSetup) ConnectionState() ConnectionState {
 }
 
 func wrapError(err error) error {
+	// errors returned when processing the transport parameters already carry a QUIC error code
+	if transportErr := (&qerr.TransportError{}); errors.As(err, &transportErr) {
+		return transportErr
+	}
 	// alert 80 is an internal error
 	if alertErr := tls.AlertError(0); errors.As(err, &alertErr) && alertErr != 80 {
 		return qerr.NewLocalCryptoError(uint8(alertErr), err)
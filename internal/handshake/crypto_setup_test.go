@@ -90,6 +90,7 @@ var _ = Describe("Crypto Setup TLS", func() {
 			&wire.TransportParameters{StatelessResetToken: &token},
 			testdata.GetTLSConfig(),
 			false,
+			nil,
 			&utils.RTTStats{},
 			nil,
 			utils.DefaultLogger.WithPrefix("server"),
@@ -179,6 +180,12 @@ var _ = Describe("Crypto Setup TLS", func() {
 	})
 
 	Context("doing the handshake", func() {
+		var serverTicketKeys *SessionTicketKeyRing
+
+		BeforeEach(func() {
+			serverTicketKeys = nil
+		})
+
 		newRTTStatsWithRTT := func(rtt time.Duration) *utils.RTTStats {
 			rttStats := &utils.RTTStats{}
 			rttStats.UpdateRTT(rtt, 0, time.Now())
@@ -285,6 +292,7 @@ var _ = Describe("Crypto Setup TLS", func() {
 				serverTransportParameters,
 				serverConf,
 				enable0RTT,
+				serverTicketKeys,
 				serverRTTStats,
 				nil,
 				utils.DefaultLogger.WithPrefix("server"),
@@ -305,6 +313,22 @@ var _ = Describe("Crypto Setup TLS", func() {
 			Expect(serverErr).ToNot(HaveOccurred())
 		})
 
+		It("counts key updates", func() {
+			client, _, clientErr, server, _, serverErr := handshakeWithTLSConf(
+				clientConf, serverConf,
+				&utils.RTTStats{}, &utils.RTTStats{},
+				&wire.TransportParameters{ActiveConnectionIDLimit: 2}, &wire.TransportParameters{ActiveConnectionIDLimit: 2},
+				false,
+			)
+			Expect(clientErr).ToNot(HaveOccurred())
+			Expect(serverErr).ToNot(HaveOccurred())
+			Expect(client.NumKeyUpdates()).To(BeZero())
+			Expect(server.NumKeyUpdates()).To(BeZero())
+			client.(*cryptoSetup).aead.rollKeys()
+			client.(*cryptoSetup).aead.rollKeys()
+			Expect(client.NumKeyUpdates()).To(BeEquivalentTo(2))
+		})
+
 		It("performs a HelloRetryRequst", func() {
 			serverConf.CurvePreferences = []tls.CurveID{tls.CurveP384}
 			_, _, clientErr, _, _, serverErr := handshakeWithTLSConf(
@@ -356,6 +380,7 @@ var _ = Describe("Crypto Setup TLS", func() {
 				sTransportParameters,
 				serverConf,
 				false,
+				nil,
 				&utils.RTTStats{},
 				nil,
 				utils.DefaultLogger.WithPrefix("server"),
@@ -384,6 +409,155 @@ var _ = Describe("Crypto Setup TLS", func() {
 			Expect(serverReceivedTransportParameters.MaxIdleTimeout).To(Equal(42 * time.Second))
 		})
 
+		Context("compatible version negotiation", func() {
+			versionInformation := func(chosen protocol.Version, available ...protocol.Version) *wire.VersionInformation {
+				return &wire.VersionInformation{ChosenVersion: chosen, AvailableVersions: available}
+			}
+
+			negotiatedVersions := func(events []Event) []protocol.Version {
+				var versions []protocol.Version
+				for _, ev := range events {
+					if ev.Kind == EventNegotiatedVersion {
+						versions = append(versions, ev.Version)
+					}
+				}
+				return versions
+			}
+
+			It("switches to the version preferred by the server", func() {
+				serverParams := &wire.TransportParameters{
+					ActiveConnectionIDLimit: 2,
+					VersionInformation:      versionInformation(protocol.Version1, protocol.Version2, protocol.Version1),
+				}
+				_, _, clientErr, server, serverEvents, serverErr := handshakeWithTLSConf(
+					clientConf, serverConf,
+					&utils.RTTStats{}, &utils.RTTStats{},
+					&wire.TransportParameters{
+						ActiveConnectionIDLimit: 2,
+						VersionInformation:      versionInformation(protocol.Version1, protocol.Version1, protocol.Version2),
+					},
+					serverParams,
+					false,
+				)
+				Expect(clientErr).ToNot(HaveOccurred())
+				Expect(serverErr).ToNot(HaveOccurred())
+				Expect(negotiatedVersions(serverEvents)).To(Equal([]protocol.Version{protocol.Version2}))
+				Expect(server.(*cryptoSetup).version).To(Equal(protocol.Version2))
+				Expect(serverParams.VersionInformation.ChosenVersion).To(Equal(protocol.Version2))
+			})
+
+			It("keeps opening the client's Initial packets using the original version", func() {
+				_, _, clientErr, server, _, serverErr := handshakeWithTLSConf(
+					clientConf, serverConf,
+					&utils.RTTStats{}, &utils.RTTStats{},
+					&wire.TransportParameters{
+						ActiveConnectionIDLimit: 2,
+						VersionInformation:      versionInformation(protocol.Version1, protocol.Version1, protocol.Version2),
+					},
+					&wire.TransportParameters{
+						ActiveConnectionIDLimit: 2,
+						VersionInformation:      versionInformation(protocol.Version1, protocol.Version2, protocol.Version1),
+					},
+					false,
+				)
+				Expect(clientErr).ToNot(HaveOccurred())
+				Expect(serverErr).ToNot(HaveOccurred())
+				Expect(server.(*cryptoSetup).version).To(Equal(protocol.Version2))
+
+				for _, v := range []protocol.Version{protocol.Version1, protocol.Version2} {
+					clientSealer, _ := NewInitialAEAD(protocol.ConnectionID{}, protocol.PerspectiveClient, v)
+					opener, err := server.GetInitialOpener(v)
+					Expect(err).ToNot(HaveOccurred())
+					m, err := opener.Open(nil, clientSealer.Seal(nil, []byte("foobar"), 42, []byte("aad")), 42, []byte("aad"))
+					Expect(err).ToNot(HaveOccurred())
+					Expect(m).To(Equal([]byte("foobar")))
+				}
+
+				server.DiscardInitialKeys()
+				_, err := server.GetInitialOpener(protocol.Version1)
+				Expect(err).To(MatchError(ErrKeysDropped))
+			})
+
+			It("keeps the version if the server prefers it", func() {
+				serverParams := &wire.TransportParameters{
+					ActiveConnectionIDLimit: 2,
+					VersionInformation:      versionInformation(protocol.Version1, protocol.Version1, protocol.Version2),
+				}
+				_, _, clientErr, server, serverEvents, serverErr := handshakeWithTLSConf(
+					clientConf, serverConf,
+					&utils.RTTStats{}, &utils.RTTStats{},
+					&wire.TransportParameters{
+						ActiveConnectionIDLimit: 2,
+						VersionInformation:      versionInformation(protocol.Version1, protocol.Version1, protocol.Version2),
+					},
+					serverParams,
+					false,
+				)
+				Expect(clientErr).ToNot(HaveOccurred())
+				Expect(serverErr).ToNot(HaveOccurred())
+				Expect(negotiatedVersions(serverEvents)).To(BeEmpty())
+				Expect(server.(*cryptoSetup).version).To(Equal(protocol.Version1))
+				Expect(serverParams.VersionInformation.ChosenVersion).To(Equal(protocol.Version1))
+			})
+
+			It("doesn't switch to a version the client doesn't support", func() {
+				_, _, clientErr, server, serverEvents, serverErr := handshakeWithTLSConf(
+					clientConf, serverConf,
+					&utils.RTTStats{}, &utils.RTTStats{},
+					&wire.TransportParameters{
+						ActiveConnectionIDLimit: 2,
+						VersionInformation:      versionInformation(protocol.Version1, protocol.Version1),
+					},
+					&wire.TransportParameters{
+						ActiveConnectionIDLimit: 2,
+						VersionInformation:      versionInformation(protocol.Version1, protocol.Version2, protocol.Version1),
+					},
+					false,
+				)
+				Expect(clientErr).ToNot(HaveOccurred())
+				Expect(serverErr).ToNot(HaveOccurred())
+				Expect(negotiatedVersions(serverEvents)).To(BeEmpty())
+				Expect(server.(*cryptoSetup).version).To(Equal(protocol.Version1))
+			})
+
+			It("doesn't switch versions if the client doesn't send the version_information", func() {
+				_, _, clientErr, server, serverEvents, serverErr := handshakeWithTLSConf(
+					clientConf, serverConf,
+					&utils.RTTStats{}, &utils.RTTStats{},
+					&wire.TransportParameters{ActiveConnectionIDLimit: 2},
+					&wire.TransportParameters{
+						ActiveConnectionIDLimit: 2,
+						VersionInformation:      versionInformation(protocol.Version1, protocol.Version2, protocol.Version1),
+					},
+					false,
+				)
+				Expect(clientErr).ToNot(HaveOccurred())
+				Expect(serverErr).ToNot(HaveOccurred())
+				Expect(negotiatedVersions(serverEvents)).To(BeEmpty())
+				Expect(server.(*cryptoSetup).version).To(Equal(protocol.Version1))
+			})
+
+			It("errors if the client's chosen version doesn't match the version of the Initial packet", func() {
+				_, _, _, _, _, serverErr := handshakeWithTLSConf(
+					clientConf, serverConf,
+					&utils.RTTStats{}, &utils.RTTStats{},
+					&wire.TransportParameters{
+						ActiveConnectionIDLimit: 2,
+						VersionInformation:      versionInformation(protocol.Version2, protocol.Version2, protocol.Version1),
+					},
+					&wire.TransportParameters{
+						ActiveConnectionIDLimit: 2,
+						VersionInformation:      versionInformation(protocol.Version1, protocol.Version1, protocol.Version2),
+					},
+					false,
+				)
+				Expect(serverErr).To(MatchError(&qerr.TransportError{
+					ErrorCode:    qerr.VersionNegotiationErrorCode,
+					ErrorMessage: "chosen version (v2) doesn't match the packet's version (v1)",
+				}))
+			})
+		})
+
 		Context("with session tickets", func() {
 			It("errors when the NewSessionTicket is sent at the wrong encryption level", func() {
 				client, _, clientErr, _, _, serverErr := handshakeWithTLSConf(
@@ -577,6 +751,136 @@ var _ = Describe("Crypto Setup TLS", func() {
 				Expect(client.ConnectionState().Used0RTT).To(BeTrue())
 			})
 
+			Context("using a session ticket key ring", func() {
+				var key SessionTicketKey
+
+				BeforeEach(func() {
+					key = SessionTicketKey{Created: time.Now()}
+					rand.Read(key.Key[:])
+					clientConf.ClientSessionCache = tls.NewLRUClientSessionCache(1)
+				})
+
+				keyRing := func(max0RTTKeyAge time.Duration, keys ...SessionTicketKey) *SessionTicketKeyRing {
+					return NewSessionTicketKeyRing(func() []SessionTicketKey { return keys }, 0, max0RTTKeyAge, 100)
+				}
+
+				resume := func() (client, server CryptoSetup) {
+					client, _, clientErr, server, _, serverErr := handshakeWithTLSConf(
+						clientConf, serverConf,
+						&utils.RTTStats{}, &utils.RTTStats{},
+						&wire.TransportParameters{ActiveConnectionIDLimit: 2}, &wire.TransportParameters{ActiveConnectionIDLimit: 2},
+						true,
+					)
+					ExpectWithOffset(1, clientErr).ToNot(HaveOccurred())
+					ExpectWithOffset(1, serverErr).ToNot(HaveOccurred())
+					return client, server
+				}
+
+				It("uses 0-RTT", func() {
+					serverTicketKeys = keyRing(time.Hour, key)
+					_, server := resume()
+					Expect(server.ConnectionState().DidResume).To(BeFalse())
+					client, server := resume()
+					Expect(server.ConnectionState().DidResume).To(BeTrue())
+					Expect(server.ConnectionState().Used0RTT).To(BeTrue())
+					Expect(client.ConnectionState().Used0RTT).To(BeTrue())
+				})
+
+				It("accepts session tickets encrypted using a previous key", func() {
+					serverTicketKeys = keyRing(time.Hour, key)
+					resume()
+					newKey := SessionTicketKey{Created: time.Now()}
+					rand.Read(newKey.Key[:])
+					serverTicketKeys = keyRing(time.Hour, newKey, key)
+					_, server := resume()
+					Expect(server.ConnectionState().DidResume).To(BeTrue())
+					Expect(server.ConnectionState().Used0RTT).To(BeTrue())
+				})
+
+				It("uses the key ring for configs returned by GetConfigForClient", func() {
+					conf := serverConf.Clone()
+					// crypto/tls would resume sessions protected by its own session ticket keys
+					conf.SetSessionTicketKeys([][32]byte{{1, 2, 3}})
+					serverConf.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) { return conf, nil }
+					serverTicketKeys = keyRing(time.Hour, key)
+					resume()
+					_, server := resume()
+					Expect(server.ConnectionState().DidResume).To(BeTrue())
+					newKey := SessionTicketKey{Created: time.Now()}
+					rand.Read(newKey.Key[:])
+					serverTicketKeys = keyRing(time.Hour, newKey)
+					_, server = resume()
+					Expect(server.ConnectionState().DidResume).To(BeFalse())
+				})
+
+				It("doesn't resume sessions encrypted using an unknown key", func() {
+					serverTicketKeys = keyRing(time.Hour, key)
+					resume()
+					newKey := SessionTicketKey{Created: time.Now()}
+					rand.Read(newKey.Key[:])
+					serverTicketKeys = keyRing(time.Hour, newKey)
+					client, server := resume()
+					Expect(server.ConnectionState().DidResume).To(BeFalse())
+					Expect(client.ConnectionState().Used0RTT).To(BeFalse())
+				})
+
+				It("rejects 0-RTT if the key is too old", func() {
+					key.Created = time.Now().Add(-time.Minute)
+					serverTicketKeys = keyRing(time.Minute, key)
+					resume()
+					client, server := resume()
+					Expect(server.ConnectionState().DidResume).To(BeTrue())
+					Expect(server.ConnectionState().Used0RTT).To(BeFalse())
+					Expect(client.ConnectionState().Used0RTT).To(BeFalse())
+				})
+
+				It("rejects 0-RTT for replayed ClientHellos", func() {
+					serverTicketKeys = keyRing(time.Hour, key)
+					resume()
+
+					client := NewCryptoSetupClient(
+						protocol.ConnectionID{},
+						&wire.TransportParameters{ActiveConnectionIDLimit: 2},
+						clientConf,
+						true,
+						&utils.RTTStats{},
+						nil,
+						utils.DefaultLogger.WithPrefix("client"),
+						protocol.Version1,
+					)
+					Expect(client.StartHandshake()).To(Succeed())
+					var clientHello []byte
+					for ev := client.NextEvent(); ev.Kind != EventNoEvent; ev = client.NextEvent() {
+						if ev.Kind == EventWriteInitialData {
+							clientHello = append([]byte{}, ev.Data...)
+						}
+					}
+					Expect(clientHello).ToNot(BeEmpty())
+
+					receiveClientHello := func() CryptoSetup {
+						var token protocol.StatelessResetToken
+						server := NewCryptoSetupServer(
+							protocol.ConnectionID{},
+							&net.UDPAddr{IP: net.IPv6loopback, Port: 1234},
+							&net.UDPAddr{IP: net.IPv6loopback, Port: 4321},
+							&wire.TransportParameters{ActiveConnectionIDLimit: 2, StatelessResetToken: &token},
+							serverConf,
+							true,
+							serverTicketKeys,
+							&utils.RTTStats{},
+							nil,
+							utils.DefaultLogger.WithPrefix("server"),
+							protocol.Version1,
+						)
+						Expect(server.StartHandshake()).To(Succeed())
+						Expect(server.HandleMessage(clientHello, protocol.EncryptionInitial)).To(Succeed())
+						return server
+					}
+					Expect(receiveClientHello().ConnectionState().Used0RTT).To(BeTrue())
+					Expect(receiveClientHello().ConnectionState().Used0RTT).To(BeFalse())
+				})
+			})
+
 			It("rejects 0-RTT, when the transport parameters changed", func() {
 				csc := mocktls.NewMockClientSessionCache(mockCtrl)
 				var state *tls.ClientSessionState
@@ -80,6 +80,10 @@ const (
 	EventRestoredTransportParameters
 	// EventHandshakeComplete signals that the TLS handshake was completed.
 	EventHandshakeComplete
+	// EventNegotiatedVersion signals that a different QUIC version was selected using
+	// compatible version negotiation (RFC 9368).
+	// It is only used for the server.
+	EventNegotiatedVersion
 )
 
 // Event is a handshake event.
@@ -87,6 +91,7 @@ type Event struct {
 	Kind                EventKind
 	Data                []byte
 	TransportParameters *wire.TransportParameters
+	Version             protocol.Version
 }
 
 // CryptoSetup handles the handshake and protecting / unprotecting packets
@@ -94,6 +99,7 @@ type CryptoSetup interface {
 	StartHandshake() error
 	io.Closer
 	ChangeConnectionID(protocol.ConnectionID)
+	ChangeVersion(protocol.Version)
 	GetSessionTicket() ([]byte, error)
 
 	HandleMessage([]byte, protocol.EncryptionLevel) error
@@ -103,8 +109,10 @@ type CryptoSetup interface {
 	DiscardInitialKeys()
 	SetHandshakeConfirmed()
 	ConnectionState() ConnectionState
+	// NumKeyUpdates returns the number of 1-RTT key updates, initiated by either endpoint.
+	NumKeyUpdates() uint64
 
-	GetInitialOpener() (LongHeaderOpener, error)
+	GetInitialOpener(protocol.Version) (LongHeaderOpener, error)
 	GetHandshakeOpener() (LongHeaderOpener, error)
 	Get0RTTOpener() (LongHeaderOpener, error)
 	Get1RTTOpener() (ShortHeaderOpener, error)
@@ -0,0 +1,97 @@
+package handshake
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"sync"
+	"time"
+)
+
+// A Bloom filter using 20 bits per entry and 14 hash functions has a false positive rate of less than 1e-4.
+// A false positive causes 0-RTT to be rejected for a connection that wasn't replayed,
+// in which case the handshake falls back to 1-RTT.
+const (
+	replayFilterBitsPerEntry = 20
+	replayFilterNumHashes    = 14
+)
+
+// replayFilter detects replayed ClientHellos, see section 8.2 of RFC 8446.
+// It uses two generations of Bloom filters, each covering (at least) one window.
+// A ClientHello is remembered for at least one window after it was first seen.
+type replayFilter struct {
+	mutex sync.Mutex
+
+	window  time.Duration
+	numBits uint64
+
+	current, previous []uint64
+	currentStart      time.Time
+}
+
+// newReplayFilter creates a new replay filter.
+// capacity is the expected number of entries per window.
+// Exceeding it increases the false positive rate.
+func newReplayFilter(capacity int, window time.Duration) *replayFilter {
+	numWords := (uint64(capacity)*replayFilterBitsPerEntry + 63) / 64
+	return &replayFilter{
+		window:   window,
+		numBits:  numWords * 64,
+		current:  make([]uint64, numWords),
+		previous: make([]uint64, numWords),
+	}
+}
+
+// IsReplay records the session ticket and the ClientHello,
+// and reports if this combination was seen before.
+func (f *replayFilter) IsReplay(ticket, clientHello []byte, now time.Time) bool {
+	h := sha256.New()
+	var l [8]byte
+	binary.BigEndian.PutUint64(l[:], uint64(len(ticket)))
+	h.Write(l[:])
+	h.Write(ticket)
+	h.Write(clientHello)
+	sum := h.Sum(nil)
+	h1 := binary.BigEndian.Uint64(sum[:8])
+	h2 := binary.BigEndian.Uint64(sum[8:16]) | 1
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.rotate(now)
+	var bits [replayFilterNumHashes]uint64
+	for i := range bits {
+		bits[i] = (h1 + uint64(i)*h2) % f.numBits
+	}
+	if f.contains(f.current, bits) || f.contains(f.previous, bits) {
+		return true
+	}
+	for _, bit := range bits {
+		f.current[bit/64] |= 1 << (bit % 64)
+	}
+	return false
+}
+
+func (f *replayFilter) contains(filter []uint64, bits [replayFilterNumHashes]uint64) bool {
+	for _, bit := range bits {
+		if filter[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *replayFilter) rotate(now time.Time) {
+	age := now.Sub(f.currentStart)
+	if age < f.window {
+		return
+	}
+	// All entries in the current filter were added less than one window after it was started.
+	// If two windows have passed, all of them are older than one window.
+	if age >= 2*f.window {
+		clear(f.previous)
+	} else {
+		f.previous, f.current = f.current, f.previous
+	}
+	clear(f.current)
+	f.currentStart = now
+}
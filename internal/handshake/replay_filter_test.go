@@ -0,0 +1,55 @@
+package handshake
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Replay Filter", func() {
+	It("detects replays", func() {
+		f := newReplayFilter(100, time.Minute)
+		now := time.Now()
+		Expect(f.IsReplay([]byte("ticket"), []byte("client hello"), now)).To(BeFalse())
+		Expect(f.IsReplay([]byte("ticket"), []byte("client hello"), now)).To(BeTrue())
+		Expect(f.IsReplay([]byte("ticket"), []byte("another client hello"), now)).To(BeFalse())
+		Expect(f.IsReplay([]byte("another ticket"), []byte("client hello"), now)).To(BeFalse())
+		// the ticket and the ClientHello are not just concatenated
+		Expect(f.IsReplay([]byte("ticketclient"), []byte(" hello"), now)).To(BeFalse())
+	})
+
+	It("remembers entries for at least one window", func() {
+		f := newReplayFilter(100, time.Minute)
+		now := time.Now()
+		Expect(f.IsReplay([]byte("ticket"), []byte("foo"), now)).To(BeFalse())
+		Expect(f.IsReplay([]byte("ticket"), []byte("bar"), now.Add(59*time.Second))).To(BeFalse())
+		// rotate
+		Expect(f.IsReplay([]byte("ticket"), []byte("foo"), now.Add(61*time.Second))).To(BeTrue())
+		Expect(f.IsReplay([]byte("ticket"), []byte("bar"), now.Add(61*time.Second))).To(BeTrue())
+		Expect(f.IsReplay([]byte("ticket"), []byte("foo"), now.Add(120*time.Second))).To(BeTrue())
+		// rotate again
+		Expect(f.IsReplay([]byte("ticket"), []byte("foo"), now.Add(122*time.Second))).To(BeFalse())
+	})
+
+	It("forgets all entries after two windows", func() {
+		f := newReplayFilter(100, time.Minute)
+		now := time.Now()
+		Expect(f.IsReplay([]byte("ticket"), []byte("foo"), now)).To(BeFalse())
+		Expect(f.IsReplay([]byte("ticket"), []byte("foo"), now.Add(2*time.Minute))).To(BeFalse())
+	})
+
+	It("has a low false positive rate", func() {
+		const num = 1000
+		f := newReplayFilter(num, time.Minute)
+		now := time.Now()
+		var falsePositives int
+		for i := 0; i < num; i++ {
+			if f.IsReplay([]byte("ticket"), []byte(fmt.Sprintf("client hello %d", i)), now) {
+				falsePositives++
+			}
+		}
+		Expect(falsePositives).To(BeNumerically("<=", 1))
+	})
+})
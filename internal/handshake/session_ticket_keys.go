@@ -0,0 +1,93 @@
+package handshake
+
+import (
+	"errors"
+	"time"
+)
+
+// SessionTicketKey is a key used to encrypt session tickets.
+type SessionTicketKey struct {
+	Key [32]byte
+	// Created is the time the key was created.
+	// It is used to determine if a key is still valid, and if it may be used for 0-RTT.
+	Created time.Time
+}
+
+// SessionTicketKeyRing encrypts and decrypts session tickets using a ring of keys.
+// New session tickets are encrypted using the first key,
+// and session tickets encrypted using any of the keys are accepted.
+type SessionTicketKeyRing struct {
+	keys          func() []SessionTicketKey
+	maxKeyAge     time.Duration
+	max0RTTKeyAge time.Duration
+	replayFilter  *replayFilter // nil if replay protection is disabled
+}
+
+// NewSessionTicketKeyRing creates a new key ring.
+// Keys older than maxKeyAge are not used, and 0-RTT is only accepted for session tickets
+// encrypted using keys younger than max0RTTKeyAge.
+// Keys without a creation time never expire, and are never used for 0-RTT.
+// If replayFilterCapacity is positive, replayed 0-RTT ClientHellos are detected.
+func NewSessionTicketKeyRing(
+	keys func() []SessionTicketKey,
+	maxKeyAge, max0RTTKeyAge time.Duration,
+	replayFilterCapacity int,
+) *SessionTicketKeyRing {
+	r := &SessionTicketKeyRing{
+		keys:          keys,
+		maxKeyAge:     maxKeyAge,
+		max0RTTKeyAge: max0RTTKeyAge,
+	}
+	if replayFilterCapacity > 0 {
+		// 0-RTT is only accepted within max0RTTKeyAge after creation of the key.
+		// A ClientHello can't be replayed later than that.
+		r.replayFilter = newReplayFilter(replayFilterCapacity, max0RTTKeyAge)
+	}
+	return r
+}
+
+// Seal encrypts a serialized session state.
+func (r *SessionTicketKeyRing) Seal(state []byte) ([]byte, error) {
+	now := time.Now()
+	for _, key := range r.keys() {
+		if r.isExpired(key, now) {
+			continue
+		}
+		return newSessionTicketProtector(key.Key).NewToken(state)
+	}
+	return nil, errors.New("no session ticket key")
+}
+
+// Open decrypts a session ticket.
+// It returns if the key used to encrypt the session ticket is young enough to accept 0-RTT.
+func (r *SessionTicketKeyRing) Open(ticket []byte) (state []byte, allow0RTT bool, _ error) {
+	now := time.Now()
+	err := errors.New("no session ticket key")
+	for _, key := range r.keys() {
+		if r.isExpired(key, now) {
+			continue
+		}
+		state, err = newSessionTicketProtector(key.Key).DecodeToken(ticket)
+		if err == nil {
+			return state, !key.Created.IsZero() && now.Sub(key.Created) < r.max0RTTKeyAge, nil
+		}
+	}
+	return nil, false, err
+}
+
+// IsReplay records the session ticket and the ClientHello used for 0-RTT,
+// and reports if this ClientHello was seen before.
+func (r *SessionTicketKeyRing) IsReplay(ticket, clientHello []byte) bool {
+	if r.replayFilter == nil {
+		return false
+	}
+	return r.replayFilter.IsReplay(ticket, clientHello, time.Now())
+}
+
+func (r *SessionTicketKeyRing) isExpired(key SessionTicketKey, now time.Time) bool {
+	return !key.Created.IsZero() && r.maxKeyAge > 0 && now.Sub(key.Created) >= r.maxKeyAge
+}
+
+func newSessionTicketProtector(key [32]byte) tokenProtector {
+	return &tokenProtectorImpl{key: key, label: "quic-go session ticket"}
+}
@@ -0,0 +1,105 @@
+package handshake
+
+import (
+	"crypto/rand"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Session Ticket Key Ring", func() {
+	newKey := func(created time.Time) SessionTicketKey {
+		key := SessionTicketKey{Created: created}
+		rand.Read(key.Key[:])
+		return key
+	}
+
+	It("seals and opens session tickets", func() {
+		key := newKey(time.Now())
+		r := NewSessionTicketKeyRing(func() []SessionTicketKey { return []SessionTicketKey{key} }, time.Hour, time.Minute, 0)
+		ticket, err := r.Seal([]byte("foobar"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ticket).ToNot(ContainSubstring("foobar"))
+		state, allow0RTT, err := r.Open(ticket)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state).To(Equal([]byte("foobar")))
+		Expect(allow0RTT).To(BeTrue())
+	})
+
+	It("doesn't use session tickets as tokens", func() {
+		key := newKey(time.Now())
+		r := NewSessionTicketKeyRing(func() []SessionTicketKey { return []SessionTicketKey{key} }, time.Hour, time.Minute, 0)
+		ticket, err := r.Seal([]byte("foobar"))
+		Expect(err).ToNot(HaveOccurred())
+		_, err = newTokenProtector(key.Key).DecodeToken(ticket)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("rotates keys", func() {
+		key1 := newKey(time.Now())
+		key2 := newKey(time.Now())
+		keys := []SessionTicketKey{key1}
+		r := NewSessionTicketKeyRing(func() []SessionTicketKey { return keys }, time.Hour, time.Minute, 0)
+		ticket1, err := r.Seal([]byte("foo"))
+		Expect(err).ToNot(HaveOccurred())
+		keys = []SessionTicketKey{key2, key1}
+		ticket2, err := r.Seal([]byte("bar"))
+		Expect(err).ToNot(HaveOccurred())
+		state, _, err := r.Open(ticket1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state).To(Equal([]byte("foo")))
+		keys = []SessionTicketKey{key2}
+		_, _, err = r.Open(ticket1)
+		Expect(err).To(HaveOccurred())
+		state, _, err = r.Open(ticket2)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state).To(Equal([]byte("bar")))
+	})
+
+	It("only accepts 0-RTT for young keys", func() {
+		key := newKey(time.Now().Add(-2 * time.Minute))
+		r := NewSessionTicketKeyRing(func() []SessionTicketKey { return []SessionTicketKey{key} }, time.Hour, time.Minute, 0)
+		ticket, err := r.Seal([]byte("foobar"))
+		Expect(err).ToNot(HaveOccurred())
+		_, allow0RTT, err := r.Open(ticket)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(allow0RTT).To(BeFalse())
+	})
+
+	It("never accepts 0-RTT for keys without a creation time", func() {
+		key := newKey(time.Time{})
+		r := NewSessionTicketKeyRing(func() []SessionTicketKey { return []SessionTicketKey{key} }, time.Hour, time.Minute, 0)
+		ticket, err := r.Seal([]byte("foobar"))
+		Expect(err).ToNot(HaveOccurred())
+		_, allow0RTT, err := r.Open(ticket)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(allow0RTT).To(BeFalse())
+	})
+
+	It("doesn't use expired keys", func() {
+		oldKey := newKey(time.Now().Add(-2 * time.Hour))
+		keys := []SessionTicketKey{oldKey}
+		r := NewSessionTicketKeyRing(func() []SessionTicketKey { return keys }, time.Hour, time.Minute, 0)
+		_, err := r.Seal([]byte("foobar"))
+		Expect(err).To(MatchError("no session ticket key"))
+
+		key := newKey(time.Now())
+		keys = []SessionTicketKey{oldKey, key}
+		ticket, err := r.Seal([]byte("foobar"))
+		Expect(err).ToNot(HaveOccurred())
+		_, _, err = NewSessionTicketKeyRing(func() []SessionTicketKey { return []SessionTicketKey{key} }, time.Hour, time.Minute, 0).Open(ticket)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("detects replays", func() {
+		key := newKey(time.Now())
+		r := NewSessionTicketKeyRing(func() []SessionTicketKey { return []SessionTicketKey{key} }, time.Hour, time.Minute, 100)
+		Expect(r.IsReplay([]byte("ticket"), []byte("client hello"))).To(BeFalse())
+		Expect(r.IsReplay([]byte("ticket"), []byte("client hello"))).To(BeTrue())
+
+		r = NewSessionTicketKeyRing(func() []SessionTicketKey { return []SessionTicketKey{key} }, time.Hour, time.Minute, 0)
+		Expect(r.IsReplay([]byte("ticket"), []byte("client hello"))).To(BeFalse())
+		Expect(r.IsReplay([]byte("ticket"), []byte("client hello"))).To(BeFalse())
+	})
+})
@@ -13,6 +13,8 @@ import (
 const (
 	tokenPrefixIP byte = iota
 	tokenPrefixString
+	// the IP prefix length is encoded in the byte following the prefix, followed by the masked IP
+	tokenPrefixIPPrefix
 )
 
 // A Token is derived from the client address and can be used to verify the ownership of this address.
@@ -23,10 +25,19 @@ type Token struct {
 	// only set for retry tokens
 	OriginalDestConnectionID protocol.ConnectionID
 	RetrySrcConnectionID     protocol.ConnectionID
+	// only set for non-retry tokens
+	Data []byte
 }
 
 // ValidateRemoteAddr validates the address, but does not check expiration
 func (t *Token) ValidateRemoteAddr(addr net.Addr) bool {
+	if len(t.encodedRemoteAddr) >= 2 && t.encodedRemoteAddr[0] == tokenPrefixIPPrefix {
+		udpAddr, ok := addr.(*net.UDPAddr)
+		if !ok {
+			return false
+		}
+		return bytes.Equal(encodeIPPrefix(udpAddr.IP, int(t.encodedRemoteAddr[1]), int(t.encodedRemoteAddr[1])), t.encodedRemoteAddr)
+	}
 	return bytes.Equal(encodeRemoteAddr(addr), t.encodedRemoteAddr)
 }
 
@@ -37,11 +48,15 @@ type token struct {
 	Timestamp                int64
 	OriginalDestConnectionID []byte
 	RetrySrcConnectionID     []byte
+	Data                     []byte `asn1:"optional"`
 }
 
 // A TokenGenerator generates tokens
 type TokenGenerator struct {
 	tokenProtector tokenProtector
+	// Only used for non-retry tokens.
+	// If 0, tokens are bound to the exact IP address.
+	ipv4PrefixLen, ipv6PrefixLen int
 }
 
 // NewTokenGenerator initializes a new TokenGenerator
@@ -49,6 +64,18 @@ func NewTokenGenerator(key TokenProtectorKey) *TokenGenerator {
 	return &TokenGenerator{tokenProtector: newTokenProtector(key)}
 }
 
+// NewRotatingTokenGenerator initializes a new TokenGenerator that supports key rotation.
+// New tokens are protected using the first key returned by keys, and tokens protected with any of the keys are accepted.
+// Non-retry tokens are bound to the IP prefix of the given length instead of the exact IP address.
+// Retry tokens are always bound to the exact IP address.
+func NewRotatingTokenGenerator(keys func() []TokenProtectorKey, ipv4PrefixLen, ipv6PrefixLen int) *TokenGenerator {
+	return &TokenGenerator{
+		tokenProtector: newRotatingTokenProtector(keys),
+		ipv4PrefixLen:  ipv4PrefixLen,
+		ipv6PrefixLen:  ipv6PrefixLen,
+	}
+}
+
 // NewRetryToken generates a new token for a Retry for a given source address
 func (g *TokenGenerator) NewRetryToken(
 	raddr net.Addr,
@@ -68,11 +95,17 @@ func (g *TokenGenerator) NewRetryToken(
 	return g.tokenProtector.NewToken(data)
 }
 
-// NewToken generates a new token to be sent in a NEW_TOKEN frame
-func (g *TokenGenerator) NewToken(raddr net.Addr) ([]byte, error) {
+// NewToken generates a new token to be sent in a NEW_TOKEN frame.
+// The data is embedded into the token.
+func (g *TokenGenerator) NewToken(raddr net.Addr, data []byte) ([]byte, error) {
+	encodedAddr := encodeRemoteAddr(raddr)
+	if udpAddr, ok := raddr.(*net.UDPAddr); ok && (g.ipv4PrefixLen > 0 || g.ipv6PrefixLen > 0) {
+		encodedAddr = encodeIPPrefix(udpAddr.IP, g.ipv4PrefixLen, g.ipv6PrefixLen)
+	}
 	data, err := asn1.Marshal(token{
-		RemoteAddr: encodeRemoteAddr(raddr),
+		RemoteAddr: encodedAddr,
 		Timestamp:  time.Now().UnixNano(),
+		Data:       data,
 	})
 	if err != nil {
 		return nil, err
@@ -107,6 +140,8 @@ func (g *TokenGenerator) DecodeToken(encrypted []byte) (*Token, error) {
 	if t.IsRetryToken {
 		token.OriginalDestConnectionID = protocol.ParseConnectionID(t.OriginalDestConnectionID)
 		token.RetrySrcConnectionID = protocol.ParseConnectionID(t.RetrySrcConnectionID)
+	} else {
+		token.Data = t.Data
 	}
 	return token, nil
 }
@@ -118,3 +153,18 @@ func encodeRemoteAddr(remoteAddr net.Addr) []byte {
 	}
 	return append([]byte{tokenPrefixString}, []byte(remoteAddr.String())...)
 }
+
+// encodeIPPrefix encodes the IP prefix of an IP address.
+// A prefix length of 0 binds the token to the exact IP address.
+func encodeIPPrefix(ip net.IP, ipv4PrefixLen, ipv6PrefixLen int) []byte {
+	prefixLen := ipv6PrefixLen
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+		prefixLen = ipv4PrefixLen
+	}
+	if prefixLen <= 0 || prefixLen > 8*len(ip) {
+		prefixLen = 8 * len(ip)
+	}
+	masked := ip.Mask(net.CIDRMask(prefixLen, 8*len(ip)))
+	return append([]byte{tokenPrefixIPPrefix, uint8(prefixLen)}, masked...)
+}
@@ -110,6 +110,50 @@ var _ = Describe("Token Generator", func() {
 		}
 	})
 
+	It("embeds data in non-retry tokens", func() {
+		addr := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1337}
+		tokenEnc, err := tokenGen.NewToken(addr, []byte("foobar"))
+		Expect(err).ToNot(HaveOccurred())
+		token, err := tokenGen.DecodeToken(tokenEnc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(token.IsRetryToken).To(BeFalse())
+		Expect(token.ValidateRemoteAddr(addr)).To(BeTrue())
+		Expect(token.Data).To(Equal([]byte("foobar")))
+	})
+
+	It("binds non-retry tokens to an IP prefix", func() {
+		var key TokenProtectorKey
+		rand.Read(key[:])
+		tokenGen := NewRotatingTokenGenerator(func() []TokenProtectorKey { return []TokenProtectorKey{key} }, 24, 48)
+
+		addr := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1337}
+		tokenEnc, err := tokenGen.NewToken(addr, nil)
+		Expect(err).ToNot(HaveOccurred())
+		token, err := tokenGen.DecodeToken(tokenEnc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(token.ValidateRemoteAddr(addr)).To(BeTrue())
+		Expect(token.ValidateRemoteAddr(&net.UDPAddr{IP: net.IPv4(192, 168, 0, 42), Port: 1234})).To(BeTrue())
+		Expect(token.ValidateRemoteAddr(&net.UDPAddr{IP: net.IPv4(192, 168, 1, 1), Port: 1337})).To(BeFalse())
+		Expect(token.ValidateRemoteAddr(&net.UDPAddr{IP: net.ParseIP("2001:db8::1"), Port: 1337})).To(BeFalse())
+		Expect(token.ValidateRemoteAddr(&net.TCPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1337})).To(BeFalse())
+
+		addr = &net.UDPAddr{IP: net.ParseIP("2001:db8:1:2::1"), Port: 1337}
+		tokenEnc, err = tokenGen.NewToken(addr, nil)
+		Expect(err).ToNot(HaveOccurred())
+		token, err = tokenGen.DecodeToken(tokenEnc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(token.ValidateRemoteAddr(&net.UDPAddr{IP: net.ParseIP("2001:db8:1:3::42"), Port: 1337})).To(BeTrue())
+		Expect(token.ValidateRemoteAddr(&net.UDPAddr{IP: net.ParseIP("2001:db8:2::1"), Port: 1337})).To(BeFalse())
+
+		// Retry tokens are bound to the exact IP address
+		tokenEnc, err = tokenGen.NewRetryToken(addr, protocol.ConnectionID{}, protocol.ConnectionID{})
+		Expect(err).ToNot(HaveOccurred())
+		token, err = tokenGen.DecodeToken(tokenEnc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(token.ValidateRemoteAddr(addr)).To(BeTrue())
+		Expect(token.ValidateRemoteAddr(&net.UDPAddr{IP: net.ParseIP("2001:db8:1:2::2"), Port: 1337})).To(BeFalse())
+	})
+
 	It("uses the string representation an address that is not a UDP address", func() {
 		raddr := &net.TCPAddr{IP: net.IPv4(192, 168, 13, 37), Port: 1337}
 		tokenEnc, err := tokenGen.NewRetryToken(raddr, protocol.ConnectionID{}, protocol.ConnectionID{})
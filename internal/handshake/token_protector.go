@@ -5,6 +5,7 @@ import (
 	"crypto/cipher"
 	"crypto/rand"
 	"crypto/sha256"
+	"errors"
 	"fmt"
 	"io"
 
@@ -26,12 +27,48 @@ const tokenNonceSize = 32
 
 // tokenProtector is used to create and verify a token
 type tokenProtectorImpl struct {
-	key TokenProtectorKey
+	key   TokenProtectorKey
+	label string
 }
 
 // newTokenProtector creates a source for source address tokens
 func newTokenProtector(key TokenProtectorKey) tokenProtector {
-	return &tokenProtectorImpl{key: key}
+	return &tokenProtectorImpl{key: key, label: "quic-go token source"}
+}
+
+// rotatingTokenProtector protects new tokens using the first key,
+// and accepts tokens protected using any of the keys.
+type rotatingTokenProtector struct {
+	keys func() []TokenProtectorKey
+}
+
+// newRotatingTokenProtector creates a token protector that supports key rotation.
+func newRotatingTokenProtector(keys func() []TokenProtectorKey) tokenProtector {
+	return &rotatingTokenProtector{keys: keys}
+}
+
+func (p *rotatingTokenProtector) NewToken(data []byte) ([]byte, error) {
+	keys := p.keys()
+	if len(keys) == 0 {
+		return nil, errors.New("no token key")
+	}
+	return newTokenProtector(keys[0]).NewToken(data)
+}
+
+func (p *rotatingTokenProtector) DecodeToken(token []byte) ([]byte, error) {
+	keys := p.keys()
+	if len(keys) == 0 {
+		return nil, errors.New("no token key")
+	}
+	var err error
+	for _, key := range keys {
+		var data []byte
+		data, err = newTokenProtector(key).DecodeToken(token)
+		if err == nil {
+			return data, nil
+		}
+	}
+	return nil, err
 }
 
 // NewToken encodes data into a new token.
@@ -61,7 +98,7 @@ func (s *tokenProtectorImpl) DecodeToken(p []byte) ([]byte, error) {
 }
 
 func (s *tokenProtectorImpl) createAEAD(nonce []byte) (cipher.AEAD, []byte, error) {
-	h := hkdf.New(sha256.New, s.key[:], nonce, []byte("quic-go token source"))
+	h := hkdf.New(sha256.New, s.key[:], nonce, []byte(s.label))
 	key := make([]byte, 32) // use a 32 byte key, in order to select AES-256
 	if _, err := io.ReadFull(h, key); err != nil {
 		return nil, nil, err
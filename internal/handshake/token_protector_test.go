@@ -51,6 +51,44 @@ var _ = Describe("Token Protector", func() {
 		Expect(err).To(HaveOccurred())
 	})
 
+	It("rotates keys", func() {
+		var key1, key2 TokenProtectorKey
+		rand.Read(key1[:])
+		rand.Read(key2[:])
+		keys := []TokenProtectorKey{key1}
+		tp := newRotatingTokenProtector(func() []TokenProtectorKey { return keys })
+		t1, err := tp.NewToken([]byte("foo"))
+		Expect(err).ToNot(HaveOccurred())
+		_, err = newTokenProtector(key1).DecodeToken(t1)
+		Expect(err).ToNot(HaveOccurred())
+
+		// rotate to key2, but still accept tokens protected with key1
+		keys = []TokenProtectorKey{key2, key1}
+		t2, err := tp.NewToken([]byte("bar"))
+		Expect(err).ToNot(HaveOccurred())
+		_, err = newTokenProtector(key2).DecodeToken(t2)
+		Expect(err).ToNot(HaveOccurred())
+		decoded, err := tp.DecodeToken(t1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(decoded).To(Equal([]byte("foo")))
+
+		// retire key1
+		keys = []TokenProtectorKey{key2}
+		_, err = tp.DecodeToken(t1)
+		Expect(err).To(HaveOccurred())
+		decoded, err = tp.DecodeToken(t2)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(decoded).To(Equal([]byte("bar")))
+	})
+
+	It("errors when no key is configured", func() {
+		tp := newRotatingTokenProtector(func() []TokenProtectorKey { return nil })
+		_, err := tp.NewToken([]byte("foo"))
+		Expect(err).To(MatchError("no token key"))
+		_, err = tp.DecodeToken([]byte("foo"))
+		Expect(err).To(MatchError("no token key"))
+	})
+
 	It("doesn't decode invalid tokens", func() {
 		token, err := tp.NewToken([]byte("foobar"))
 		Expect(err).ToNot(HaveOccurred())
@@ -190,6 +190,78 @@ func (c *ReceivedPacketHandlerIsPotentiallyDuplicateCall) DoAndReturn(f func(pro
 	return c
 }
 
+// ReceivedAckFrequencyFrame mocks base method.
+func (m *MockReceivedPacketHandler) ReceivedAckFrequencyFrame(arg0 *wire.AckFrequencyFrame) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReceivedAckFrequencyFrame", arg0)
+}
+
+// ReceivedAckFrequencyFrame indicates an expected call of ReceivedAckFrequencyFrame.
+func (mr *MockReceivedPacketHandlerMockRecorder) ReceivedAckFrequencyFrame(arg0 any) *ReceivedPacketHandlerReceivedAckFrequencyFrameCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceivedAckFrequencyFrame", reflect.TypeOf((*MockReceivedPacketHandler)(nil).ReceivedAckFrequencyFrame), arg0)
+	return &ReceivedPacketHandlerReceivedAckFrequencyFrameCall{Call: call}
+}
+
+// ReceivedPacketHandlerReceivedAckFrequencyFrameCall wrap *gomock.Call
+type ReceivedPacketHandlerReceivedAckFrequencyFrameCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *ReceivedPacketHandlerReceivedAckFrequencyFrameCall) Return() *ReceivedPacketHandlerReceivedAckFrequencyFrameCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *ReceivedPacketHandlerReceivedAckFrequencyFrameCall) Do(f func(*wire.AckFrequencyFrame)) *ReceivedPacketHandlerReceivedAckFrequencyFrameCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *ReceivedPacketHandlerReceivedAckFrequencyFrameCall) DoAndReturn(f func(*wire.AckFrequencyFrame)) *ReceivedPacketHandlerReceivedAckFrequencyFrameCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ReceivedImmediateAck mocks base method.
+func (m *MockReceivedPacketHandler) ReceivedImmediateAck() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReceivedImmediateAck")
+}
+
+// ReceivedImmediateAck indicates an expected call of ReceivedImmediateAck.
+func (mr *MockReceivedPacketHandlerMockRecorder) ReceivedImmediateAck() *ReceivedPacketHandlerReceivedImmediateAckCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceivedImmediateAck", reflect.TypeOf((*MockReceivedPacketHandler)(nil).ReceivedImmediateAck))
+	return &ReceivedPacketHandlerReceivedImmediateAckCall{Call: call}
+}
+
+// ReceivedPacketHandlerReceivedImmediateAckCall wrap *gomock.Call
+type ReceivedPacketHandlerReceivedImmediateAckCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *ReceivedPacketHandlerReceivedImmediateAckCall) Return() *ReceivedPacketHandlerReceivedImmediateAckCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *ReceivedPacketHandlerReceivedImmediateAckCall) Do(f func()) *ReceivedPacketHandlerReceivedImmediateAckCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *ReceivedPacketHandlerReceivedImmediateAckCall) DoAndReturn(f func()) *ReceivedPacketHandlerReceivedImmediateAckCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // ReceivedPacket mocks base method.
 func (m *MockReceivedPacketHandler) ReceivedPacket(arg0 protocol.PacketNumber, arg1 protocol.ECN, arg2 protocol.EncryptionLevel, arg3 time.Time, arg4 bool) error {
 	m.ctrl.T.Helper()
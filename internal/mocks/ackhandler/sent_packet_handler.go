@@ -115,6 +115,44 @@ func (c *SentPacketHandlerECNModeCall) DoAndReturn(f func(bool) protocol.ECN) *S
 	return c
 }
 
+// GetCongestionWindow mocks base method.
+func (m *MockSentPacketHandler) GetCongestionWindow() protocol.ByteCount {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCongestionWindow")
+	ret0, _ := ret[0].(protocol.ByteCount)
+	return ret0
+}
+
+// GetCongestionWindow indicates an expected call of GetCongestionWindow.
+func (mr *MockSentPacketHandlerMockRecorder) GetCongestionWindow() *SentPacketHandlerGetCongestionWindowCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCongestionWindow", reflect.TypeOf((*MockSentPacketHandler)(nil).GetCongestionWindow))
+	return &SentPacketHandlerGetCongestionWindowCall{Call: call}
+}
+
+// SentPacketHandlerGetCongestionWindowCall wrap *gomock.Call
+type SentPacketHandlerGetCongestionWindowCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *SentPacketHandlerGetCongestionWindowCall) Return(arg0 protocol.ByteCount) *SentPacketHandlerGetCongestionWindowCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *SentPacketHandlerGetCongestionWindowCall) Do(f func() protocol.ByteCount) *SentPacketHandlerGetCongestionWindowCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *SentPacketHandlerGetCongestionWindowCall) DoAndReturn(f func() protocol.ByteCount) *SentPacketHandlerGetCongestionWindowCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // GetLossDetectionTimeout mocks base method.
 func (m *MockSentPacketHandler) GetLossDetectionTimeout() time.Time {
 	m.ctrl.T.Helper()
@@ -153,6 +191,42 @@ func (c *SentPacketHandlerGetLossDetectionTimeoutCall) DoAndReturn(f func() time
 	return c
 }
 
+// MigratedPath mocks base method.
+func (m *MockSentPacketHandler) MigratedPath() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "MigratedPath")
+}
+
+// MigratedPath indicates an expected call of MigratedPath.
+func (mr *MockSentPacketHandlerMockRecorder) MigratedPath() *SentPacketHandlerMigratedPathCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MigratedPath", reflect.TypeOf((*MockSentPacketHandler)(nil).MigratedPath))
+	return &SentPacketHandlerMigratedPathCall{Call: call}
+}
+
+// SentPacketHandlerMigratedPathCall wrap *gomock.Call
+type SentPacketHandlerMigratedPathCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *SentPacketHandlerMigratedPathCall) Return() *SentPacketHandlerMigratedPathCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *SentPacketHandlerMigratedPathCall) Do(f func()) *SentPacketHandlerMigratedPathCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *SentPacketHandlerMigratedPathCall) DoAndReturn(f func()) *SentPacketHandlerMigratedPathCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // OnLossDetectionTimeout mocks base method.
 func (m *MockSentPacketHandler) OnLossDetectionTimeout() error {
 	m.ctrl.T.Helper()
@@ -230,6 +304,78 @@ func (c *SentPacketHandlerPeekPacketNumberCall) DoAndReturn(f func(protocol.Encr
 	return c
 }
 
+// PeerAddressChanged mocks base method.
+func (m *MockSentPacketHandler) PeerAddressChanged(arg0 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "PeerAddressChanged", arg0)
+}
+
+// PeerAddressChanged indicates an expected call of PeerAddressChanged.
+func (mr *MockSentPacketHandlerMockRecorder) PeerAddressChanged(arg0 any) *SentPacketHandlerPeerAddressChangedCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PeerAddressChanged", reflect.TypeOf((*MockSentPacketHandler)(nil).PeerAddressChanged), arg0)
+	return &SentPacketHandlerPeerAddressChangedCall{Call: call}
+}
+
+// SentPacketHandlerPeerAddressChangedCall wrap *gomock.Call
+type SentPacketHandlerPeerAddressChangedCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *SentPacketHandlerPeerAddressChangedCall) Return() *SentPacketHandlerPeerAddressChangedCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *SentPacketHandlerPeerAddressChangedCall) Do(f func(protocol.ByteCount)) *SentPacketHandlerPeerAddressChangedCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *SentPacketHandlerPeerAddressChangedCall) DoAndReturn(f func(protocol.ByteCount)) *SentPacketHandlerPeerAddressChangedCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// PeerAddressValidated mocks base method.
+func (m *MockSentPacketHandler) PeerAddressValidated() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "PeerAddressValidated")
+}
+
+// PeerAddressValidated indicates an expected call of PeerAddressValidated.
+func (mr *MockSentPacketHandlerMockRecorder) PeerAddressValidated() *SentPacketHandlerPeerAddressValidatedCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PeerAddressValidated", reflect.TypeOf((*MockSentPacketHandler)(nil).PeerAddressValidated))
+	return &SentPacketHandlerPeerAddressValidatedCall{Call: call}
+}
+
+// SentPacketHandlerPeerAddressValidatedCall wrap *gomock.Call
+type SentPacketHandlerPeerAddressValidatedCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *SentPacketHandlerPeerAddressValidatedCall) Return() *SentPacketHandlerPeerAddressValidatedCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *SentPacketHandlerPeerAddressValidatedCall) Do(f func()) *SentPacketHandlerPeerAddressValidatedCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *SentPacketHandlerPeerAddressValidatedCall) DoAndReturn(f func()) *SentPacketHandlerPeerAddressValidatedCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // PopPacketNumber mocks base method.
 func (m *MockSentPacketHandler) PopPacketNumber(arg0 protocol.EncryptionLevel) protocol.PacketNumber {
 	m.ctrl.T.Helper()
@@ -493,6 +639,42 @@ func (c *SentPacketHandlerSentPacketCall) DoAndReturn(f func(time.Time, protocol
 	return c
 }
 
+// SentPathProbePacket mocks base method.
+func (m *MockSentPacketHandler) SentPathProbePacket(arg0 time.Time, arg1 protocol.PacketNumber, arg2 []ackhandler.Frame, arg3 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SentPathProbePacket", arg0, arg1, arg2, arg3)
+}
+
+// SentPathProbePacket indicates an expected call of SentPathProbePacket.
+func (mr *MockSentPacketHandlerMockRecorder) SentPathProbePacket(arg0, arg1, arg2, arg3 any) *SentPacketHandlerSentPathProbePacketCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SentPathProbePacket", reflect.TypeOf((*MockSentPacketHandler)(nil).SentPathProbePacket), arg0, arg1, arg2, arg3)
+	return &SentPacketHandlerSentPathProbePacketCall{Call: call}
+}
+
+// SentPacketHandlerSentPathProbePacketCall wrap *gomock.Call
+type SentPacketHandlerSentPathProbePacketCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *SentPacketHandlerSentPathProbePacketCall) Return() *SentPacketHandlerSentPathProbePacketCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *SentPacketHandlerSentPathProbePacketCall) Do(f func(time.Time, protocol.PacketNumber, []ackhandler.Frame, protocol.ByteCount)) *SentPacketHandlerSentPathProbePacketCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *SentPacketHandlerSentPathProbePacketCall) DoAndReturn(f func(time.Time, protocol.PacketNumber, []ackhandler.Frame, protocol.ByteCount)) *SentPacketHandlerSentPathProbePacketCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // SetHandshakeConfirmed mocks base method.
 func (m *MockSentPacketHandler) SetHandshakeConfirmed() {
 	m.ctrl.T.Helper()
@@ -565,6 +747,80 @@ func (c *SentPacketHandlerSetMaxDatagramSizeCall) DoAndReturn(f func(protocol.By
 	return c
 }
 
+// SetPacketSizeObserver mocks base method.
+func (m *MockSentPacketHandler) SetPacketSizeObserver(arg0 ackhandler.PacketSizeObserver) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetPacketSizeObserver", arg0)
+}
+
+// SetPacketSizeObserver indicates an expected call of SetPacketSizeObserver.
+func (mr *MockSentPacketHandlerMockRecorder) SetPacketSizeObserver(arg0 any) *SentPacketHandlerSetPacketSizeObserverCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPacketSizeObserver", reflect.TypeOf((*MockSentPacketHandler)(nil).SetPacketSizeObserver), arg0)
+	return &SentPacketHandlerSetPacketSizeObserverCall{Call: call}
+}
+
+// SentPacketHandlerSetPacketSizeObserverCall wrap *gomock.Call
+type SentPacketHandlerSetPacketSizeObserverCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *SentPacketHandlerSetPacketSizeObserverCall) Return() *SentPacketHandlerSetPacketSizeObserverCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *SentPacketHandlerSetPacketSizeObserverCall) Do(f func(ackhandler.PacketSizeObserver)) *SentPacketHandlerSetPacketSizeObserverCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *SentPacketHandlerSetPacketSizeObserverCall) DoAndReturn(f func(ackhandler.PacketSizeObserver)) *SentPacketHandlerSetPacketSizeObserverCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Stats mocks base method.
+func (m *MockSentPacketHandler) Stats() ackhandler.SentPacketStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(ackhandler.SentPacketStats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockSentPacketHandlerMockRecorder) Stats() *SentPacketHandlerStatsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockSentPacketHandler)(nil).Stats))
+	return &SentPacketHandlerStatsCall{Call: call}
+}
+
+// SentPacketHandlerStatsCall wrap *gomock.Call
+type SentPacketHandlerStatsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *SentPacketHandlerStatsCall) Return(arg0 ackhandler.SentPacketStats) *SentPacketHandlerStatsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *SentPacketHandlerStatsCall) Do(f func() ackhandler.SentPacketStats) *SentPacketHandlerStatsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *SentPacketHandlerStatsCall) DoAndReturn(f func() ackhandler.SentPacketStats) *SentPacketHandlerStatsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // TimeUntilSend mocks base method.
 func (m *MockSentPacketHandler) TimeUntilSend() time.Time {
 	m.ctrl.T.Helper()
@@ -301,6 +301,42 @@ func (c *SendAlgorithmWithDebugInfosOnCongestionEventCall) DoAndReturn(f func(pr
 	return c
 }
 
+// OnConnectionMigration mocks base method.
+func (m *MockSendAlgorithmWithDebugInfos) OnConnectionMigration() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnConnectionMigration")
+}
+
+// OnConnectionMigration indicates an expected call of OnConnectionMigration.
+func (mr *MockSendAlgorithmWithDebugInfosMockRecorder) OnConnectionMigration() *SendAlgorithmWithDebugInfosOnConnectionMigrationCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnConnectionMigration", reflect.TypeOf((*MockSendAlgorithmWithDebugInfos)(nil).OnConnectionMigration))
+	return &SendAlgorithmWithDebugInfosOnConnectionMigrationCall{Call: call}
+}
+
+// SendAlgorithmWithDebugInfosOnConnectionMigrationCall wrap *gomock.Call
+type SendAlgorithmWithDebugInfosOnConnectionMigrationCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *SendAlgorithmWithDebugInfosOnConnectionMigrationCall) Return() *SendAlgorithmWithDebugInfosOnConnectionMigrationCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *SendAlgorithmWithDebugInfosOnConnectionMigrationCall) Do(f func()) *SendAlgorithmWithDebugInfosOnConnectionMigrationCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *SendAlgorithmWithDebugInfosOnConnectionMigrationCall) DoAndReturn(f func()) *SendAlgorithmWithDebugInfosOnConnectionMigrationCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // OnPacketAcked mocks base method.
 func (m *MockSendAlgorithmWithDebugInfos) OnPacketAcked(arg0 protocol.PacketNumber, arg1, arg2 protocol.ByteCount, arg3 time.Time) {
 	m.ctrl.T.Helper()
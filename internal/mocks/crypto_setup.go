@@ -75,6 +75,42 @@ func (c *CryptoSetupChangeConnectionIDCall) DoAndReturn(f func(protocol.Connecti
 	return c
 }
 
+// ChangeVersion mocks base method.
+func (m *MockCryptoSetup) ChangeVersion(arg0 protocol.Version) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ChangeVersion", arg0)
+}
+
+// ChangeVersion indicates an expected call of ChangeVersion.
+func (mr *MockCryptoSetupMockRecorder) ChangeVersion(arg0 any) *CryptoSetupChangeVersionCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeVersion", reflect.TypeOf((*MockCryptoSetup)(nil).ChangeVersion), arg0)
+	return &CryptoSetupChangeVersionCall{Call: call}
+}
+
+// CryptoSetupChangeVersionCall wrap *gomock.Call
+type CryptoSetupChangeVersionCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *CryptoSetupChangeVersionCall) Return() *CryptoSetupChangeVersionCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *CryptoSetupChangeVersionCall) Do(f func(protocol.Version)) *CryptoSetupChangeVersionCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *CryptoSetupChangeVersionCall) DoAndReturn(f func(protocol.Version)) *CryptoSetupChangeVersionCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Close mocks base method.
 func (m *MockCryptoSetup) Close() error {
 	m.ctrl.T.Helper()
@@ -422,18 +458,18 @@ func (c *CryptoSetupGetHandshakeSealerCall) DoAndReturn(f func() (handshake.Long
 }
 
 // GetInitialOpener mocks base method.
-func (m *MockCryptoSetup) GetInitialOpener() (handshake.LongHeaderOpener, error) {
+func (m *MockCryptoSetup) GetInitialOpener(arg0 protocol.Version) (handshake.LongHeaderOpener, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetInitialOpener")
+	ret := m.ctrl.Call(m, "GetInitialOpener", arg0)
 	ret0, _ := ret[0].(handshake.LongHeaderOpener)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetInitialOpener indicates an expected call of GetInitialOpener.
-func (mr *MockCryptoSetupMockRecorder) GetInitialOpener() *CryptoSetupGetInitialOpenerCall {
+func (mr *MockCryptoSetupMockRecorder) GetInitialOpener(arg0 any) *CryptoSetupGetInitialOpenerCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInitialOpener", reflect.TypeOf((*MockCryptoSetup)(nil).GetInitialOpener))
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInitialOpener", reflect.TypeOf((*MockCryptoSetup)(nil).GetInitialOpener), arg0)
 	return &CryptoSetupGetInitialOpenerCall{Call: call}
 }
 
@@ -449,13 +485,13 @@ func (c *CryptoSetupGetInitialOpenerCall) Return(arg0 handshake.LongHeaderOpener
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *CryptoSetupGetInitialOpenerCall) Do(f func() (handshake.LongHeaderOpener, error)) *CryptoSetupGetInitialOpenerCall {
+func (c *CryptoSetupGetInitialOpenerCall) Do(f func(protocol.Version) (handshake.LongHeaderOpener, error)) *CryptoSetupGetInitialOpenerCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *CryptoSetupGetInitialOpenerCall) DoAndReturn(f func() (handshake.LongHeaderOpener, error)) *CryptoSetupGetInitialOpenerCall {
+func (c *CryptoSetupGetInitialOpenerCall) DoAndReturn(f func(protocol.Version) (handshake.LongHeaderOpener, error)) *CryptoSetupGetInitialOpenerCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
@@ -614,6 +650,44 @@ func (c *CryptoSetupNextEventCall) DoAndReturn(f func() handshake.Event) *Crypto
 	return c
 }
 
+// NumKeyUpdates mocks base method.
+func (m *MockCryptoSetup) NumKeyUpdates() uint64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NumKeyUpdates")
+	ret0, _ := ret[0].(uint64)
+	return ret0
+}
+
+// NumKeyUpdates indicates an expected call of NumKeyUpdates.
+func (mr *MockCryptoSetupMockRecorder) NumKeyUpdates() *CryptoSetupNumKeyUpdatesCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NumKeyUpdates", reflect.TypeOf((*MockCryptoSetup)(nil).NumKeyUpdates))
+	return &CryptoSetupNumKeyUpdatesCall{Call: call}
+}
+
+// CryptoSetupNumKeyUpdatesCall wrap *gomock.Call
+type CryptoSetupNumKeyUpdatesCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *CryptoSetupNumKeyUpdatesCall) Return(arg0 uint64) *CryptoSetupNumKeyUpdatesCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *CryptoSetupNumKeyUpdatesCall) Do(f func() uint64) *CryptoSetupNumKeyUpdatesCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *CryptoSetupNumKeyUpdatesCall) DoAndReturn(f func() uint64) *CryptoSetupNumKeyUpdatesCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // SetHandshakeConfirmed mocks base method.
 func (m *MockCryptoSetup) SetHandshakeConfirmed() {
 	m.ctrl.T.Helper()
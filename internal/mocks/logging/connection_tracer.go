@@ -68,12 +68,21 @@ func NewMockConnectionTracer(ctrl *gomock.Controller) (*logging.ConnectionTracer
 		LostPacket: func(encLevel logging.EncryptionLevel, pn logging.PacketNumber, reason logging.PacketLossReason) {
 			t.LostPacket(encLevel, pn, reason)
 		},
+		DetectedSpuriousLoss: func(encLevel logging.EncryptionLevel, pn logging.PacketNumber, packetReordering uint64, timeReordering time.Duration) {
+			t.DetectedSpuriousLoss(encLevel, pn, packetReordering, timeReordering)
+		},
 		UpdatedCongestionState: func(state logging.CongestionState) {
 			t.UpdatedCongestionState(state)
 		},
+		UpdatedECNMarkingFraction: func(fraction, average float64) {
+			t.UpdatedECNMarkingFraction(fraction, average)
+		},
 		UpdatedPTOCount: func(value uint32) {
 			t.UpdatedPTOCount(value)
 		},
+		UpdatedMTU: func(mtu logging.ByteCount, done bool) {
+			t.UpdatedMTU(mtu, done)
+		},
 		UpdatedKeyFromTLS: func(encLevel logging.EncryptionLevel, perspective logging.Perspective) {
 			t.UpdatedKeyFromTLS(encLevel, perspective)
 		},
@@ -98,9 +107,18 @@ func NewMockConnectionTracer(ctrl *gomock.Controller) (*logging.ConnectionTracer
 		ECNStateUpdated: func(state logging.ECNState, trigger logging.ECNStateTrigger) {
 			t.ECNStateUpdated(state, trigger)
 		},
+		ExpiredStreamData: func(id logging.StreamID, offset, length logging.ByteCount) {
+			t.ExpiredStreamData(id, offset, length)
+		},
+		UpdatedPeerAddress: func(oldAddr, newAddr net.Addr) {
+			t.UpdatedPeerAddress(oldAddr, newAddr)
+		},
 		ChoseALPN: func(protocol string) {
 			t.ChoseALPN(protocol)
 		},
+		DroppedDatagram: func(size logging.ByteCount, reason logging.DatagramDropReason) {
+			t.DroppedDatagram(size, reason)
+		},
 		Close: func() {
 			t.Close()
 		},
@@ -259,6 +259,78 @@ func (c *ConnectionTracerDebugCall) DoAndReturn(f func(string, string)) *Connect
 	return c
 }
 
+// DetectedSpuriousLoss mocks base method.
+func (m *MockConnectionTracer) DetectedSpuriousLoss(arg0 protocol.EncryptionLevel, arg1 protocol.PacketNumber, arg2 uint64, arg3 time.Duration) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DetectedSpuriousLoss", arg0, arg1, arg2, arg3)
+}
+
+// DetectedSpuriousLoss indicates an expected call of DetectedSpuriousLoss.
+func (mr *MockConnectionTracerMockRecorder) DetectedSpuriousLoss(arg0, arg1, arg2, arg3 any) *ConnectionTracerDetectedSpuriousLossCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DetectedSpuriousLoss", reflect.TypeOf((*MockConnectionTracer)(nil).DetectedSpuriousLoss), arg0, arg1, arg2, arg3)
+	return &ConnectionTracerDetectedSpuriousLossCall{Call: call}
+}
+
+// ConnectionTracerDetectedSpuriousLossCall wrap *gomock.Call
+type ConnectionTracerDetectedSpuriousLossCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *ConnectionTracerDetectedSpuriousLossCall) Return() *ConnectionTracerDetectedSpuriousLossCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *ConnectionTracerDetectedSpuriousLossCall) Do(f func(protocol.EncryptionLevel, protocol.PacketNumber, uint64, time.Duration)) *ConnectionTracerDetectedSpuriousLossCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *ConnectionTracerDetectedSpuriousLossCall) DoAndReturn(f func(protocol.EncryptionLevel, protocol.PacketNumber, uint64, time.Duration)) *ConnectionTracerDetectedSpuriousLossCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// DroppedDatagram mocks base method.
+func (m *MockConnectionTracer) DroppedDatagram(arg0 protocol.ByteCount, arg1 logging.DatagramDropReason) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DroppedDatagram", arg0, arg1)
+}
+
+// DroppedDatagram indicates an expected call of DroppedDatagram.
+func (mr *MockConnectionTracerMockRecorder) DroppedDatagram(arg0, arg1 any) *ConnectionTracerDroppedDatagramCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DroppedDatagram", reflect.TypeOf((*MockConnectionTracer)(nil).DroppedDatagram), arg0, arg1)
+	return &ConnectionTracerDroppedDatagramCall{Call: call}
+}
+
+// ConnectionTracerDroppedDatagramCall wrap *gomock.Call
+type ConnectionTracerDroppedDatagramCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *ConnectionTracerDroppedDatagramCall) Return() *ConnectionTracerDroppedDatagramCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *ConnectionTracerDroppedDatagramCall) Do(f func(protocol.ByteCount, logging.DatagramDropReason)) *ConnectionTracerDroppedDatagramCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *ConnectionTracerDroppedDatagramCall) DoAndReturn(f func(protocol.ByteCount, logging.DatagramDropReason)) *ConnectionTracerDroppedDatagramCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // DroppedEncryptionLevel mocks base method.
 func (m *MockConnectionTracer) DroppedEncryptionLevel(arg0 protocol.EncryptionLevel) {
 	m.ctrl.T.Helper()
@@ -403,6 +475,42 @@ func (c *ConnectionTracerECNStateUpdatedCall) DoAndReturn(f func(logging.ECNStat
 	return c
 }
 
+// ExpiredStreamData mocks base method.
+func (m *MockConnectionTracer) ExpiredStreamData(arg0 protocol.StreamID, arg1, arg2 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ExpiredStreamData", arg0, arg1, arg2)
+}
+
+// ExpiredStreamData indicates an expected call of ExpiredStreamData.
+func (mr *MockConnectionTracerMockRecorder) ExpiredStreamData(arg0, arg1, arg2 any) *ConnectionTracerExpiredStreamDataCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpiredStreamData", reflect.TypeOf((*MockConnectionTracer)(nil).ExpiredStreamData), arg0, arg1, arg2)
+	return &ConnectionTracerExpiredStreamDataCall{Call: call}
+}
+
+// ConnectionTracerExpiredStreamDataCall wrap *gomock.Call
+type ConnectionTracerExpiredStreamDataCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *ConnectionTracerExpiredStreamDataCall) Return() *ConnectionTracerExpiredStreamDataCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *ConnectionTracerExpiredStreamDataCall) Do(f func(protocol.StreamID, protocol.ByteCount, protocol.ByteCount)) *ConnectionTracerExpiredStreamDataCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *ConnectionTracerExpiredStreamDataCall) DoAndReturn(f func(protocol.StreamID, protocol.ByteCount, protocol.ByteCount)) *ConnectionTracerExpiredStreamDataCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // LossTimerCanceled mocks base method.
 func (m *MockConnectionTracer) LossTimerCanceled() {
 	m.ctrl.T.Helper()
@@ -979,6 +1087,42 @@ func (c *ConnectionTracerUpdatedCongestionStateCall) DoAndReturn(f func(logging.
 	return c
 }
 
+// UpdatedECNMarkingFraction mocks base method.
+func (m *MockConnectionTracer) UpdatedECNMarkingFraction(arg0, arg1 float64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdatedECNMarkingFraction", arg0, arg1)
+}
+
+// UpdatedECNMarkingFraction indicates an expected call of UpdatedECNMarkingFraction.
+func (mr *MockConnectionTracerMockRecorder) UpdatedECNMarkingFraction(arg0, arg1 any) *ConnectionTracerUpdatedECNMarkingFractionCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatedECNMarkingFraction", reflect.TypeOf((*MockConnectionTracer)(nil).UpdatedECNMarkingFraction), arg0, arg1)
+	return &ConnectionTracerUpdatedECNMarkingFractionCall{Call: call}
+}
+
+// ConnectionTracerUpdatedECNMarkingFractionCall wrap *gomock.Call
+type ConnectionTracerUpdatedECNMarkingFractionCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *ConnectionTracerUpdatedECNMarkingFractionCall) Return() *ConnectionTracerUpdatedECNMarkingFractionCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *ConnectionTracerUpdatedECNMarkingFractionCall) Do(f func(float64, float64)) *ConnectionTracerUpdatedECNMarkingFractionCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *ConnectionTracerUpdatedECNMarkingFractionCall) DoAndReturn(f func(float64, float64)) *ConnectionTracerUpdatedECNMarkingFractionCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // UpdatedKey mocks base method.
 func (m *MockConnectionTracer) UpdatedKey(arg0 protocol.KeyPhase, arg1 bool) {
 	m.ctrl.T.Helper()
@@ -1051,6 +1195,42 @@ func (c *ConnectionTracerUpdatedKeyFromTLSCall) DoAndReturn(f func(protocol.Encr
 	return c
 }
 
+// UpdatedMTU mocks base method.
+func (m *MockConnectionTracer) UpdatedMTU(arg0 protocol.ByteCount, arg1 bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdatedMTU", arg0, arg1)
+}
+
+// UpdatedMTU indicates an expected call of UpdatedMTU.
+func (mr *MockConnectionTracerMockRecorder) UpdatedMTU(arg0, arg1 any) *ConnectionTracerUpdatedMTUCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatedMTU", reflect.TypeOf((*MockConnectionTracer)(nil).UpdatedMTU), arg0, arg1)
+	return &ConnectionTracerUpdatedMTUCall{Call: call}
+}
+
+// ConnectionTracerUpdatedMTUCall wrap *gomock.Call
+type ConnectionTracerUpdatedMTUCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *ConnectionTracerUpdatedMTUCall) Return() *ConnectionTracerUpdatedMTUCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *ConnectionTracerUpdatedMTUCall) Do(f func(protocol.ByteCount, bool)) *ConnectionTracerUpdatedMTUCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *ConnectionTracerUpdatedMTUCall) DoAndReturn(f func(protocol.ByteCount, bool)) *ConnectionTracerUpdatedMTUCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // UpdatedMetrics mocks base method.
 func (m *MockConnectionTracer) UpdatedMetrics(arg0 *utils.RTTStats, arg1, arg2 protocol.ByteCount, arg3 int) {
 	m.ctrl.T.Helper()
@@ -1122,3 +1302,39 @@ func (c *ConnectionTracerUpdatedPTOCountCall) DoAndReturn(f func(uint32)) *Conne
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// UpdatedPeerAddress mocks base method.
+func (m *MockConnectionTracer) UpdatedPeerAddress(arg0, arg1 net.Addr) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdatedPeerAddress", arg0, arg1)
+}
+
+// UpdatedPeerAddress indicates an expected call of UpdatedPeerAddress.
+func (mr *MockConnectionTracerMockRecorder) UpdatedPeerAddress(arg0, arg1 any) *ConnectionTracerUpdatedPeerAddressCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatedPeerAddress", reflect.TypeOf((*MockConnectionTracer)(nil).UpdatedPeerAddress), arg0, arg1)
+	return &ConnectionTracerUpdatedPeerAddressCall{Call: call}
+}
+
+// ConnectionTracerUpdatedPeerAddressCall wrap *gomock.Call
+type ConnectionTracerUpdatedPeerAddressCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *ConnectionTracerUpdatedPeerAddressCall) Return() *ConnectionTracerUpdatedPeerAddressCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *ConnectionTracerUpdatedPeerAddressCall) Do(f func(net.Addr, net.Addr)) *ConnectionTracerUpdatedPeerAddressCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *ConnectionTracerUpdatedPeerAddressCall) DoAndReturn(f func(net.Addr, net.Addr)) *ConnectionTracerUpdatedPeerAddressCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
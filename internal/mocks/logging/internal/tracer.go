@@ -77,6 +77,42 @@ func (c *TracerDroppedPacketCall) DoAndReturn(f func(net.Addr, logging.PacketTyp
 	return c
 }
 
+// RejectedToken mocks base method.
+func (m *MockTracer) RejectedToken(arg0 net.Addr, arg1 bool, arg2 logging.TokenRejectionReason) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RejectedToken", arg0, arg1, arg2)
+}
+
+// RejectedToken indicates an expected call of RejectedToken.
+func (mr *MockTracerMockRecorder) RejectedToken(arg0, arg1, arg2 any) *TracerRejectedTokenCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RejectedToken", reflect.TypeOf((*MockTracer)(nil).RejectedToken), arg0, arg1, arg2)
+	return &TracerRejectedTokenCall{Call: call}
+}
+
+// TracerRejectedTokenCall wrap *gomock.Call
+type TracerRejectedTokenCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *TracerRejectedTokenCall) Return() *TracerRejectedTokenCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *TracerRejectedTokenCall) Do(f func(net.Addr, bool, logging.TokenRejectionReason)) *TracerRejectedTokenCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *TracerRejectedTokenCall) DoAndReturn(f func(net.Addr, bool, logging.TokenRejectionReason)) *TracerRejectedTokenCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // SentPacket mocks base method.
 func (m *MockTracer) SentPacket(arg0 net.Addr, arg1 *wire.Header, arg2 protocol.ByteCount, arg3 []logging.Frame) {
 	m.ctrl.T.Helper()
@@ -14,6 +14,7 @@ type Tracer interface {
 	SentPacket(net.Addr, *logging.Header, logging.ByteCount, []logging.Frame)
 	SentVersionNegotiationPacket(_ net.Addr, dest, src logging.ArbitraryLenConnectionID, _ []logging.VersionNumber)
 	DroppedPacket(net.Addr, logging.PacketType, logging.ByteCount, logging.PacketDropReason)
+	RejectedToken(_ net.Addr, isRetryToken bool, _ logging.TokenRejectionReason)
 }
 
 //go:generate sh -c "go run go.uber.org/mock/mockgen -typed -build_flags=\"-tags=gomock\" -package internal -destination internal/connection_tracer.go github.com/quic-go/quic-go/internal/mocks/logging ConnectionTracer"
@@ -35,8 +36,11 @@ type ConnectionTracer interface {
 	UpdatedMetrics(rttStats *logging.RTTStats, cwnd, bytesInFlight logging.ByteCount, packetsInFlight int)
 	AcknowledgedPacket(logging.EncryptionLevel, logging.PacketNumber)
 	LostPacket(logging.EncryptionLevel, logging.PacketNumber, logging.PacketLossReason)
+	DetectedSpuriousLoss(_ logging.EncryptionLevel, _ logging.PacketNumber, packetReordering uint64, timeReordering time.Duration)
 	UpdatedCongestionState(logging.CongestionState)
+	UpdatedECNMarkingFraction(fraction, average float64)
 	UpdatedPTOCount(value uint32)
+	UpdatedMTU(mtu logging.ByteCount, done bool)
 	UpdatedKeyFromTLS(logging.EncryptionLevel, logging.Perspective)
 	UpdatedKey(generation logging.KeyPhase, remote bool)
 	DroppedEncryptionLevel(logging.EncryptionLevel)
@@ -45,7 +49,10 @@ type ConnectionTracer interface {
 	LossTimerExpired(logging.TimerType, logging.EncryptionLevel)
 	LossTimerCanceled()
 	ECNStateUpdated(state logging.ECNState, trigger logging.ECNStateTrigger)
+	ExpiredStreamData(id logging.StreamID, offset, length logging.ByteCount)
+	UpdatedPeerAddress(oldAddr, newAddr net.Addr)
 	ChoseALPN(protocol string)
+	DroppedDatagram(size logging.ByteCount, reason logging.DatagramDropReason)
 	// Close is called when the connection is closed.
 	Close()
 	Debug(name, msg string)
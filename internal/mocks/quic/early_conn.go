@@ -14,6 +14,7 @@ import (
 	reflect "reflect"
 
 	quic "github.com/quic-go/quic-go"
+	protocol "github.com/quic-go/quic-go/internal/protocol"
 	qerr "github.com/quic-go/quic-go/internal/qerr"
 	gomock "go.uber.org/mock/gomock"
 )
@@ -309,6 +310,44 @@ func (c *EarlyConnectionLocalAddrCall) DoAndReturn(f func() net.Addr) *EarlyConn
 	return c
 }
 
+// Migrate mocks base method.
+func (m *MockEarlyConnection) Migrate(arg0 net.PacketConn) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Migrate", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Migrate indicates an expected call of Migrate.
+func (mr *MockEarlyConnectionMockRecorder) Migrate(arg0 any) *EarlyConnectionMigrateCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Migrate", reflect.TypeOf((*MockEarlyConnection)(nil).Migrate), arg0)
+	return &EarlyConnectionMigrateCall{Call: call}
+}
+
+// EarlyConnectionMigrateCall wrap *gomock.Call
+type EarlyConnectionMigrateCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *EarlyConnectionMigrateCall) Return(arg0 error) *EarlyConnectionMigrateCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *EarlyConnectionMigrateCall) Do(f func(net.PacketConn) error) *EarlyConnectionMigrateCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *EarlyConnectionMigrateCall) DoAndReturn(f func(net.PacketConn) error) *EarlyConnectionMigrateCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // NextConnection mocks base method.
 func (m *MockEarlyConnection) NextConnection() quic.Connection {
 	m.ctrl.T.Helper()
@@ -503,6 +542,44 @@ func (c *EarlyConnectionOpenUniStreamSyncCall) DoAndReturn(f func(context.Contex
 	return c
 }
 
+// PrioritizeStream mocks base method.
+func (m *MockEarlyConnection) PrioritizeStream(arg0 protocol.StreamID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PrioritizeStream", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PrioritizeStream indicates an expected call of PrioritizeStream.
+func (mr *MockEarlyConnectionMockRecorder) PrioritizeStream(arg0 any) *EarlyConnectionPrioritizeStreamCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrioritizeStream", reflect.TypeOf((*MockEarlyConnection)(nil).PrioritizeStream), arg0)
+	return &EarlyConnectionPrioritizeStreamCall{Call: call}
+}
+
+// EarlyConnectionPrioritizeStreamCall wrap *gomock.Call
+type EarlyConnectionPrioritizeStreamCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *EarlyConnectionPrioritizeStreamCall) Return(arg0 error) *EarlyConnectionPrioritizeStreamCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *EarlyConnectionPrioritizeStreamCall) Do(f func(protocol.StreamID) error) *EarlyConnectionPrioritizeStreamCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *EarlyConnectionPrioritizeStreamCall) DoAndReturn(f func(protocol.StreamID) error) *EarlyConnectionPrioritizeStreamCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // ReceiveDatagram mocks base method.
 func (m *MockEarlyConnection) ReceiveDatagram(arg0 context.Context) ([]byte, error) {
 	m.ctrl.T.Helper()
@@ -542,6 +619,45 @@ func (c *EarlyConnectionReceiveDatagramCall) DoAndReturn(f func(context.Context)
 	return c
 }
 
+// ReceiveDatagrams mocks base method.
+func (m *MockEarlyConnection) ReceiveDatagrams(arg0 context.Context, arg1 [][]byte) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReceiveDatagrams", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReceiveDatagrams indicates an expected call of ReceiveDatagrams.
+func (mr *MockEarlyConnectionMockRecorder) ReceiveDatagrams(arg0, arg1 any) *EarlyConnectionReceiveDatagramsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceiveDatagrams", reflect.TypeOf((*MockEarlyConnection)(nil).ReceiveDatagrams), arg0, arg1)
+	return &EarlyConnectionReceiveDatagramsCall{Call: call}
+}
+
+// EarlyConnectionReceiveDatagramsCall wrap *gomock.Call
+type EarlyConnectionReceiveDatagramsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *EarlyConnectionReceiveDatagramsCall) Return(arg0 int, arg1 error) *EarlyConnectionReceiveDatagramsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *EarlyConnectionReceiveDatagramsCall) Do(f func(context.Context, [][]byte) (int, error)) *EarlyConnectionReceiveDatagramsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *EarlyConnectionReceiveDatagramsCall) DoAndReturn(f func(context.Context, [][]byte) (int, error)) *EarlyConnectionReceiveDatagramsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // RemoteAddr mocks base method.
 func (m *MockEarlyConnection) RemoteAddr() net.Addr {
 	m.ctrl.T.Helper()
@@ -617,3 +733,79 @@ func (c *EarlyConnectionSendDatagramCall) DoAndReturn(f func([]byte) error) *Ear
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// SendDatagramWithCallback mocks base method.
+func (m *MockEarlyConnection) SendDatagramWithCallback(arg0 []byte, arg1 func(quic.DatagramState)) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendDatagramWithCallback", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendDatagramWithCallback indicates an expected call of SendDatagramWithCallback.
+func (mr *MockEarlyConnectionMockRecorder) SendDatagramWithCallback(arg0, arg1 any) *EarlyConnectionSendDatagramWithCallbackCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDatagramWithCallback", reflect.TypeOf((*MockEarlyConnection)(nil).SendDatagramWithCallback), arg0, arg1)
+	return &EarlyConnectionSendDatagramWithCallbackCall{Call: call}
+}
+
+// EarlyConnectionSendDatagramWithCallbackCall wrap *gomock.Call
+type EarlyConnectionSendDatagramWithCallbackCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *EarlyConnectionSendDatagramWithCallbackCall) Return(arg0 error) *EarlyConnectionSendDatagramWithCallbackCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *EarlyConnectionSendDatagramWithCallbackCall) Do(f func([]byte, func(quic.DatagramState)) error) *EarlyConnectionSendDatagramWithCallbackCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *EarlyConnectionSendDatagramWithCallbackCall) DoAndReturn(f func([]byte, func(quic.DatagramState)) error) *EarlyConnectionSendDatagramWithCallbackCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Stats mocks base method.
+func (m *MockEarlyConnection) Stats() quic.ConnectionStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(quic.ConnectionStats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockEarlyConnectionMockRecorder) Stats() *EarlyConnectionStatsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockEarlyConnection)(nil).Stats))
+	return &EarlyConnectionStatsCall{Call: call}
+}
+
+// EarlyConnectionStatsCall wrap *gomock.Call
+type EarlyConnectionStatsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *EarlyConnectionStatsCall) Return(arg0 quic.ConnectionStats) *EarlyConnectionStatsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *EarlyConnectionStatsCall) Do(f func() quic.ConnectionStats) *EarlyConnectionStatsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *EarlyConnectionStatsCall) DoAndReturn(f func() quic.ConnectionStats) *EarlyConnectionStatsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
@@ -13,6 +13,7 @@ import (
 	reflect "reflect"
 	time "time"
 
+	quic "github.com/quic-go/quic-go"
 	protocol "github.com/quic-go/quic-go/internal/protocol"
 	qerr "github.com/quic-go/quic-go/internal/qerr"
 	gomock "go.uber.org/mock/gomock"
@@ -113,6 +114,42 @@ func (c *StreamCancelWriteCall) DoAndReturn(f func(qerr.StreamErrorCode)) *Strea
 	return c
 }
 
+// CancelWriteAt mocks base method.
+func (m *MockStream) CancelWriteAt(arg0 qerr.StreamErrorCode, arg1 uint64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CancelWriteAt", arg0, arg1)
+}
+
+// CancelWriteAt indicates an expected call of CancelWriteAt.
+func (mr *MockStreamMockRecorder) CancelWriteAt(arg0, arg1 any) *StreamCancelWriteAtCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelWriteAt", reflect.TypeOf((*MockStream)(nil).CancelWriteAt), arg0, arg1)
+	return &StreamCancelWriteAtCall{Call: call}
+}
+
+// StreamCancelWriteAtCall wrap *gomock.Call
+type StreamCancelWriteAtCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *StreamCancelWriteAtCall) Return() *StreamCancelWriteAtCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *StreamCancelWriteAtCall) Do(f func(qerr.StreamErrorCode, uint64)) *StreamCancelWriteAtCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *StreamCancelWriteAtCall) DoAndReturn(f func(qerr.StreamErrorCode, uint64)) *StreamCancelWriteAtCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Close mocks base method.
 func (m *MockStream) Close() error {
 	m.ctrl.T.Helper()
@@ -266,6 +303,42 @@ func (c *StreamSetDeadlineCall) DoAndReturn(f func(time.Time) error) *StreamSetD
 	return c
 }
 
+// SetDeliveryDeadline mocks base method.
+func (m *MockStream) SetDeliveryDeadline(arg0 time.Duration, arg1 qerr.StreamErrorCode) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetDeliveryDeadline", arg0, arg1)
+}
+
+// SetDeliveryDeadline indicates an expected call of SetDeliveryDeadline.
+func (mr *MockStreamMockRecorder) SetDeliveryDeadline(arg0, arg1 any) *StreamSetDeliveryDeadlineCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDeliveryDeadline", reflect.TypeOf((*MockStream)(nil).SetDeliveryDeadline), arg0, arg1)
+	return &StreamSetDeliveryDeadlineCall{Call: call}
+}
+
+// StreamSetDeliveryDeadlineCall wrap *gomock.Call
+type StreamSetDeliveryDeadlineCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *StreamSetDeliveryDeadlineCall) Return() *StreamSetDeliveryDeadlineCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *StreamSetDeliveryDeadlineCall) Do(f func(time.Duration, qerr.StreamErrorCode)) *StreamSetDeliveryDeadlineCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *StreamSetDeliveryDeadlineCall) DoAndReturn(f func(time.Duration, qerr.StreamErrorCode)) *StreamSetDeliveryDeadlineCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // SetReadDeadline mocks base method.
 func (m *MockStream) SetReadDeadline(arg0 time.Time) error {
 	m.ctrl.T.Helper()
@@ -342,6 +415,44 @@ func (c *StreamSetWriteDeadlineCall) DoAndReturn(f func(time.Time) error) *Strea
 	return c
 }
 
+// Stats mocks base method.
+func (m *MockStream) Stats() quic.StreamStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(quic.StreamStats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockStreamMockRecorder) Stats() *StreamStatsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockStream)(nil).Stats))
+	return &StreamStatsCall{Call: call}
+}
+
+// StreamStatsCall wrap *gomock.Call
+type StreamStatsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *StreamStatsCall) Return(arg0 quic.StreamStats) *StreamStatsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *StreamStatsCall) Do(f func() quic.StreamStats) *StreamStatsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *StreamStatsCall) DoAndReturn(f func() quic.StreamStats) *StreamStatsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // StreamID mocks base method.
 func (m *MockStream) StreamID() protocol.StreamID {
 	m.ctrl.T.Helper()
@@ -184,6 +184,45 @@ func (c *StreamFlowControllerGetWindowUpdateCall) DoAndReturn(f func() protocol.
 	return c
 }
 
+// IsBlocked mocks base method.
+func (m *MockStreamFlowController) IsBlocked() (bool, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsBlocked")
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// IsBlocked indicates an expected call of IsBlocked.
+func (mr *MockStreamFlowControllerMockRecorder) IsBlocked() *StreamFlowControllerIsBlockedCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsBlocked", reflect.TypeOf((*MockStreamFlowController)(nil).IsBlocked))
+	return &StreamFlowControllerIsBlockedCall{Call: call}
+}
+
+// StreamFlowControllerIsBlockedCall wrap *gomock.Call
+type StreamFlowControllerIsBlockedCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *StreamFlowControllerIsBlockedCall) Return(arg0, arg1 bool) *StreamFlowControllerIsBlockedCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *StreamFlowControllerIsBlockedCall) Do(f func() (bool, bool)) *StreamFlowControllerIsBlockedCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *StreamFlowControllerIsBlockedCall) DoAndReturn(f func() (bool, bool)) *StreamFlowControllerIsBlockedCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // IsNewlyBlocked mocks base method.
 func (m *MockStreamFlowController) IsNewlyBlocked() (bool, protocol.ByteCount) {
 	m.ctrl.T.Helper()
@@ -223,6 +262,44 @@ func (c *StreamFlowControllerIsNewlyBlockedCall) DoAndReturn(f func() (bool, pro
 	return c
 }
 
+// RemainingReceiveWindow mocks base method.
+func (m *MockStreamFlowController) RemainingReceiveWindow() protocol.ByteCount {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemainingReceiveWindow")
+	ret0, _ := ret[0].(protocol.ByteCount)
+	return ret0
+}
+
+// RemainingReceiveWindow indicates an expected call of RemainingReceiveWindow.
+func (mr *MockStreamFlowControllerMockRecorder) RemainingReceiveWindow() *StreamFlowControllerRemainingReceiveWindowCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemainingReceiveWindow", reflect.TypeOf((*MockStreamFlowController)(nil).RemainingReceiveWindow))
+	return &StreamFlowControllerRemainingReceiveWindowCall{Call: call}
+}
+
+// StreamFlowControllerRemainingReceiveWindowCall wrap *gomock.Call
+type StreamFlowControllerRemainingReceiveWindowCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *StreamFlowControllerRemainingReceiveWindowCall) Return(arg0 protocol.ByteCount) *StreamFlowControllerRemainingReceiveWindowCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *StreamFlowControllerRemainingReceiveWindowCall) Do(f func() protocol.ByteCount) *StreamFlowControllerRemainingReceiveWindowCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *StreamFlowControllerRemainingReceiveWindowCall) DoAndReturn(f func() protocol.ByteCount) *StreamFlowControllerRemainingReceiveWindowCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // SendWindowSize mocks base method.
 func (m *MockStreamFlowController) SendWindowSize() protocol.ByteCount {
 	m.ctrl.T.Helper()
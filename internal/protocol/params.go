@@ -99,6 +99,12 @@ const MaxCryptoStreamOffset = 16 * (1 << 10)
 // MinRemoteIdleTimeout is the minimum value that we accept for the remote idle timeout
 const MinRemoteIdleTimeout = 5 * time.Second
 
+// DefaultMaxDatagramSendQueueLen is the default number of DATAGRAM frames queued for sending
+const DefaultMaxDatagramSendQueueLen = 32
+
+// DefaultMaxDatagramReceiveQueueLen is the default number of received DATAGRAM frames queued until read by the application
+const DefaultMaxDatagramReceiveQueueLen = 128
+
 // DefaultIdleTimeout is the default idle timeout
 const DefaultIdleTimeout = 30 * time.Second
 
@@ -149,6 +155,10 @@ const MaxActiveConnectionIDs = 4
 // MaxIssuedConnectionIDs is the maximum number of connection IDs that we're issuing at the same time.
 const MaxIssuedConnectionIDs = 6
 
+// MinPathValidationTimeout is the minimum time we wait for path validation to succeed.
+// RFC 9000 recommends using the larger of three times the PTO and 6 times the initial RTT.
+const MinPathValidationTimeout = 600 * time.Millisecond
+
 // PacketsPerConnectionID is the number of packets we send using one connection ID.
 // If the peer provices us with enough new connection IDs, we switch to a new connection ID.
 const PacketsPerConnectionID = 10000
@@ -163,6 +173,10 @@ const TimerGranularity = time.Millisecond
 // MaxAckDelay is the maximum time by which we delay sending ACKs.
 const MaxAckDelay = 25 * time.Millisecond
 
+// MinAckDelay is the minimum time by which we can delay sending ACKs.
+// It is advertised in the min_ack_delay transport parameter, when using the ACK frequency extension.
+const MinAckDelay = TimerGranularity
+
 // MaxAckDelayInclGranularity is the max_ack_delay including the timer granularity.
 // This is the value that should be advertised to the peer.
 const MaxAckDelayInclGranularity = MaxAckDelay + TimerGranularity
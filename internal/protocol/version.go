@@ -63,6 +63,16 @@ func (vn Version) toGQUICVersion() int {
 	return int(10*(vn-gquicVersion0)/0x100) + int(vn%0x10)
 }
 
+// IsCompatibleVersion returns true if a connection started using version from can be
+// upgraded to version to using compatible version negotiation (RFC 9368).
+// QUIC v1 and QUIC v2 are compatible with each other (RFC 9369, section 4).
+func IsCompatibleVersion(from, to Version) bool {
+	if from == to {
+		return true
+	}
+	return (from == Version1 || from == Version2) && (to == Version1 || to == Version2)
+}
+
 // IsSupportedVersion returns true if the server supports this version
 func IsSupportedVersion(supported []Version, v Version) bool {
 	for _, t := range supported {
@@ -37,6 +37,14 @@ var _ = Describe("Version", func() {
 		Expect(IsSupportedVersion(SupportedVersions, SupportedVersions[len(SupportedVersions)-1])).To(BeTrue())
 	})
 
+	It("says which versions are compatible", func() {
+		Expect(IsCompatibleVersion(Version1, Version1)).To(BeTrue())
+		Expect(IsCompatibleVersion(Version1, Version2)).To(BeTrue())
+		Expect(IsCompatibleVersion(Version2, Version1)).To(BeTrue())
+		Expect(IsCompatibleVersion(Version1, 0x1234)).To(BeFalse())
+		Expect(IsCompatibleVersion(0x1234, Version2)).To(BeFalse())
+	})
+
 	Context("highest supported version", func() {
 		It("finds the supported version", func() {
 			supportedVersions := []Version{1, 2, 3}
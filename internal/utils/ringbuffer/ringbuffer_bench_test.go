@@ -10,3 +10,13 @@ func BenchmarkRingBuffer(b *testing.B) {
 		r.PopFront()
 	}
 }
+
+func BenchmarkSPMC(b *testing.B) {
+	r := NewSPMC[int](16)
+	v := 42
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Push(&v)
+		r.Pop()
+	}
+}
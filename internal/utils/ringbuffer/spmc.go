@@ -0,0 +1,60 @@
+package ringbuffer
+
+import "sync/atomic"
+
+// SPMC is a fixed-size, lock-free ring buffer for a single producer and multiple consumers.
+// Push must only be called from a single goroutine, whereas Pop and Len may be called concurrently from any goroutine.
+type SPMC[T any] struct {
+	slots []atomic.Pointer[T]
+	head  atomic.Uint64 // position of the next element to pop
+	tail  atomic.Uint64 // position of the next element to push, only modified by the producer
+}
+
+// NewSPMC creates a new ring buffer that can hold up to size elements.
+func NewSPMC[T any](size int) *SPMC[T] {
+	if size <= 0 {
+		panic("ringbuffer: invalid size")
+	}
+	return &SPMC[T]{slots: make([]atomic.Pointer[T], size)}
+}
+
+// Len returns the number of elements in the ring buffer.
+func (r *SPMC[T]) Len() int {
+	// load head first, so that the difference can never be negative
+	head := r.head.Load()
+	return int(r.tail.Load() - head)
+}
+
+// Cap returns the maximum number of elements the ring buffer can hold.
+func (r *SPMC[T]) Cap() int {
+	return len(r.slots)
+}
+
+// Push adds a new element.
+// It returns false if the ring buffer is full.
+func (r *SPMC[T]) Push(t *T) bool {
+	tail := r.tail.Load()
+	if tail-r.head.Load() >= uint64(len(r.slots)) {
+		return false
+	}
+	r.slots[tail%uint64(len(r.slots))].Store(t)
+	r.tail.Store(tail + 1)
+	return true
+}
+
+// Pop removes the oldest element.
+// It returns nil if the ring buffer is empty.
+func (r *SPMC[T]) Pop() *T {
+	for {
+		head := r.head.Load()
+		if head == r.tail.Load() {
+			return nil
+		}
+		// The slot might be overwritten by the producer as soon as another consumer advances head.
+		// In that case, the CompareAndSwap fails, and the value that was read is discarded.
+		t := r.slots[head%uint64(len(r.slots))].Load()
+		if r.head.CompareAndSwap(head, head+1) {
+			return t
+		}
+	}
+}
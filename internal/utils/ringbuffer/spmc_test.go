@@ -0,0 +1,90 @@
+package ringbuffer
+
+import (
+	"runtime"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SPMC", func() {
+	intPtr := func(i int) *int { return &i }
+
+	It("push and pop", func() {
+		r := NewSPMC[int](3)
+		Expect(r.Cap()).To(Equal(3))
+		Expect(r.Len()).To(BeZero())
+		Expect(r.Pop()).To(BeNil())
+		Expect(r.Push(intPtr(1))).To(BeTrue())
+		Expect(r.Push(intPtr(2))).To(BeTrue())
+		Expect(r.Len()).To(Equal(2))
+		Expect(*r.Pop()).To(Equal(1))
+		Expect(r.Push(intPtr(3))).To(BeTrue())
+		Expect(r.Push(intPtr(4))).To(BeTrue())
+		Expect(r.Len()).To(Equal(3))
+		Expect(r.Push(intPtr(5))).To(BeFalse())
+		Expect(*r.Pop()).To(Equal(2))
+		Expect(*r.Pop()).To(Equal(3))
+		Expect(*r.Pop()).To(Equal(4))
+		Expect(r.Pop()).To(BeNil())
+		Expect(r.Len()).To(BeZero())
+	})
+
+	It("panics for an invalid size", func() {
+		Expect(func() { NewSPMC[int](0) }).To(Panic())
+	})
+
+	It("delivers every element exactly once to concurrent consumers", func() {
+		const num = 10000
+		const numConsumers = 4
+		r := NewSPMC[int](16)
+
+		var wg sync.WaitGroup
+		results := make([][]int, numConsumers)
+		done := make(chan struct{})
+		for i := 0; i < numConsumers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for {
+					if v := r.Pop(); v != nil {
+						results[i] = append(results[i], *v)
+						continue
+					}
+					select {
+					case <-done:
+						if r.Len() == 0 {
+							return
+						}
+					default:
+					}
+					runtime.Gosched()
+				}
+			}(i)
+		}
+
+		for i := 0; i < num; i++ {
+			for !r.Push(intPtr(i)) {
+				runtime.Gosched()
+			}
+		}
+		close(done)
+		wg.Wait()
+
+		seen := make([]bool, num)
+		var count int
+		for _, res := range results {
+			// every consumer sees the elements in the order they were pushed
+			for j := 1; j < len(res); j++ {
+				Expect(res[j]).To(BeNumerically(">", res[j-1]))
+			}
+			for _, v := range res {
+				Expect(seen[v]).To(BeFalse())
+				seen[v] = true
+				count++
+			}
+		}
+		Expect(count).To(Equal(num))
+	})
+})
@@ -0,0 +1,61 @@
+package wire
+
+import (
+	"bytes"
+	"errors"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+)
+
+// An AckFrequencyFrame is an ACK_FREQUENCY frame (draft-ietf-quic-ack-frequency)
+type AckFrequencyFrame struct {
+	SequenceNumber        uint64
+	AckElicitingThreshold uint64
+	RequestedMaxAckDelay  time.Duration
+	ReorderingThreshold   uint64
+}
+
+func parseAckFrequencyFrame(r *bytes.Reader, _ protocol.Version) (*AckFrequencyFrame, error) {
+	seq, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	threshold, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	delay, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	if delay > uint64(protocol.MaxMaxAckDelay/time.Microsecond) {
+		return nil, errors.New("invalid requested max ack delay")
+	}
+	reorderingThreshold, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	return &AckFrequencyFrame{
+		SequenceNumber:        seq,
+		AckElicitingThreshold: threshold,
+		RequestedMaxAckDelay:  time.Duration(delay) * time.Microsecond,
+		ReorderingThreshold:   reorderingThreshold,
+	}, nil
+}
+
+func (f *AckFrequencyFrame) Append(b []byte, _ protocol.Version) ([]byte, error) {
+	b = quicvarint.Append(b, ackFrequencyFrameType)
+	b = quicvarint.Append(b, f.SequenceNumber)
+	b = quicvarint.Append(b, f.AckElicitingThreshold)
+	b = quicvarint.Append(b, uint64(f.RequestedMaxAckDelay/time.Microsecond))
+	b = quicvarint.Append(b, f.ReorderingThreshold)
+	return b, nil
+}
+
+// Length of a written frame
+func (f *AckFrequencyFrame) Length(protocol.Version) protocol.ByteCount {
+	return quicvarint.Len(ackFrequencyFrameType) + quicvarint.Len(f.SequenceNumber) + quicvarint.Len(f.AckElicitingThreshold) +
+		quicvarint.Len(uint64(f.RequestedMaxAckDelay/time.Microsecond)) + quicvarint.Len(f.ReorderingThreshold)
+}
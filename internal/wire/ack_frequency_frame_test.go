@@ -0,0 +1,83 @@
+package wire
+
+import (
+	"bytes"
+	"io"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ACK_FREQUENCY frame", func() {
+	Context("when parsing", func() {
+		It("accepts a sample frame", func() {
+			data := encodeVarInt(0x1337)              // sequence number
+			data = append(data, encodeVarInt(9)...)   // ack-eliciting threshold
+			data = append(data, encodeVarInt(1e4)...) // requested max ack delay, in microseconds
+			data = append(data, encodeVarInt(3)...)   // reordering threshold
+			b := bytes.NewReader(data)
+			frame, err := parseAckFrequencyFrame(b, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(frame.SequenceNumber).To(BeEquivalentTo(0x1337))
+			Expect(frame.AckElicitingThreshold).To(BeEquivalentTo(9))
+			Expect(frame.RequestedMaxAckDelay).To(Equal(10 * time.Millisecond))
+			Expect(frame.ReorderingThreshold).To(BeEquivalentTo(3))
+			Expect(b.Len()).To(BeZero())
+		})
+
+		It("rejects frames with a too large requested max ack delay", func() {
+			data := encodeVarInt(0x1337)                                                             // sequence number
+			data = append(data, encodeVarInt(9)...)                                                  // ack-eliciting threshold
+			data = append(data, encodeVarInt(uint64(protocol.MaxMaxAckDelay/time.Microsecond)+1)...) // requested max ack delay
+			data = append(data, encodeVarInt(3)...)                                                  // reordering threshold
+			_, err := parseAckFrequencyFrame(bytes.NewReader(data), protocol.Version1)
+			Expect(err).To(MatchError("invalid requested max ack delay"))
+		})
+
+		It("errors on EOFs", func() {
+			data := encodeVarInt(0x1337)              // sequence number
+			data = append(data, encodeVarInt(9)...)   // ack-eliciting threshold
+			data = append(data, encodeVarInt(1e4)...) // requested max ack delay, in microseconds
+			data = append(data, encodeVarInt(3)...)   // reordering threshold
+			_, err := parseAckFrequencyFrame(bytes.NewReader(data), protocol.Version1)
+			Expect(err).NotTo(HaveOccurred())
+			for i := range data {
+				_, err := parseAckFrequencyFrame(bytes.NewReader(data[:i]), protocol.Version1)
+				Expect(err).To(MatchError(io.EOF))
+			}
+		})
+	})
+
+	Context("when writing", func() {
+		It("writes a sample frame", func() {
+			f := &AckFrequencyFrame{
+				SequenceNumber:        0x1337,
+				AckElicitingThreshold: 9,
+				RequestedMaxAckDelay:  10 * time.Millisecond,
+				ReorderingThreshold:   3,
+			}
+			b, err := f.Append(nil, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			expected := encodeVarInt(ackFrequencyFrameType)
+			expected = append(expected, encodeVarInt(0x1337)...)
+			expected = append(expected, encodeVarInt(9)...)
+			expected = append(expected, encodeVarInt(1e4)...)
+			expected = append(expected, encodeVarInt(3)...)
+			Expect(b).To(Equal(expected))
+		})
+
+		It("has the correct length", func() {
+			f := &AckFrequencyFrame{
+				SequenceNumber:        0x1337,
+				AckElicitingThreshold: 9,
+				RequestedMaxAckDelay:  10 * time.Millisecond,
+				ReorderingThreshold:   3,
+			}
+			Expect(f.Length(protocol.Version1)).To(Equal(quicvarint.Len(ackFrequencyFrameType) + quicvarint.Len(0x1337) + 1 + quicvarint.Len(1e4) + 1))
+		})
+	})
+})
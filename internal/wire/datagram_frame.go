@@ -17,32 +17,34 @@ var MaxDatagramSize protocol.ByteCount = 16383
 // A DatagramFrame is a DATAGRAM frame
 type DatagramFrame struct {
 	DataLenPresent bool
-	Data           []byte
+	// When parsed by the FrameParser, Data references the packet data.
+	// It is only valid until the next frame is parsed.
+	Data []byte
 }
 
-func parseDatagramFrame(r *bytes.Reader, typ uint64, _ protocol.Version) (*DatagramFrame, error) {
-	f := &DatagramFrame{}
-	f.DataLenPresent = typ&0x1 > 0
+// parseDatagramFrame parses a DATAGRAM frame.
+// r reads from data. The payload is not copied, but references data.
+func parseDatagramFrame(frame *DatagramFrame, r *bytes.Reader, data []byte, typ uint64, _ protocol.Version) error {
+	frame.DataLenPresent = typ&0x1 > 0
 
 	var length uint64
-	if f.DataLenPresent {
+	if frame.DataLenPresent {
 		var err error
 		len, err := quicvarint.Read(r)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		if len > uint64(r.Len()) {
-			return nil, io.EOF
+			return io.EOF
 		}
 		length = len
 	} else {
 		length = uint64(r.Len())
 	}
-	f.Data = make([]byte, length)
-	if _, err := io.ReadFull(r, f.Data); err != nil {
-		return nil, err
-	}
-	return f, nil
+	start := len(data) - r.Len()
+	frame.Data = data[start : start+int(length)]
+	_, err := r.Seek(int64(length), io.SeekCurrent)
+	return err
 }
 
 func (f *DatagramFrame) Append(b []byte, _ protocol.Version) ([]byte, error) {
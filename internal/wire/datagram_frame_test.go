@@ -17,8 +17,8 @@ var _ = Describe("STREAM frame", func() {
 			data := encodeVarInt(0x6) // length
 			data = append(data, []byte("foobar")...)
 			r := bytes.NewReader(data)
-			frame, err := parseDatagramFrame(r, 0x30^0x1, protocol.Version1)
-			Expect(err).ToNot(HaveOccurred())
+			var frame DatagramFrame
+			Expect(parseDatagramFrame(&frame, r, data, 0x30^0x1, protocol.Version1)).To(Succeed())
 			Expect(frame.Data).To(Equal([]byte("foobar")))
 			Expect(frame.DataLenPresent).To(BeTrue())
 			Expect(r.Len()).To(BeZero())
@@ -27,30 +27,39 @@ var _ = Describe("STREAM frame", func() {
 		It("parses a frame without length", func() {
 			data := []byte("Lorem ipsum dolor sit amet")
 			r := bytes.NewReader(data)
-			frame, err := parseDatagramFrame(r, 0x30, protocol.Version1)
-			Expect(err).ToNot(HaveOccurred())
+			var frame DatagramFrame
+			Expect(parseDatagramFrame(&frame, r, data, 0x30, protocol.Version1)).To(Succeed())
 			Expect(frame.Data).To(Equal([]byte("Lorem ipsum dolor sit amet")))
 			Expect(frame.DataLenPresent).To(BeFalse())
 			Expect(r.Len()).To(BeZero())
 		})
 
+		It("doesn't copy the payload", func() {
+			data := encodeVarInt(0x6) // length
+			data = append(data, []byte("foobar")...)
+			data = append(data, []byte("raboof")...) // the next frame
+			r := bytes.NewReader(data)
+			var frame DatagramFrame
+			Expect(parseDatagramFrame(&frame, r, data, 0x30^0x1, protocol.Version1)).To(Succeed())
+			Expect(frame.Data).To(Equal([]byte("foobar")))
+			Expect(r.Len()).To(Equal(6))
+			data[1] = 'g'
+			Expect(frame.Data).To(Equal([]byte("goobar")))
+		})
+
 		It("errors when the length is longer than the rest of the frame", func() {
 			data := encodeVarInt(0x6) // length
 			data = append(data, []byte("fooba")...)
-			r := bytes.NewReader(data)
-			_, err := parseDatagramFrame(r, 0x30^0x1, protocol.Version1)
-			Expect(err).To(MatchError(io.EOF))
+			Expect(parseDatagramFrame(&DatagramFrame{}, bytes.NewReader(data), data, 0x30^0x1, protocol.Version1)).To(MatchError(io.EOF))
 		})
 
 		It("errors on EOFs", func() {
 			const typ = 0x30 ^ 0x1
 			data := encodeVarInt(6) // length
 			data = append(data, []byte("foobar")...)
-			_, err := parseDatagramFrame(bytes.NewReader(data), typ, protocol.Version1)
-			Expect(err).NotTo(HaveOccurred())
+			Expect(parseDatagramFrame(&DatagramFrame{}, bytes.NewReader(data), data, typ, protocol.Version1)).To(Succeed())
 			for i := range data {
-				_, err = parseDatagramFrame(bytes.NewReader(data[0:i]), typ, protocol.Version1)
-				Expect(err).To(MatchError(io.EOF))
+				Expect(parseDatagramFrame(&DatagramFrame{}, bytes.NewReader(data[0:i]), data[0:i], typ, protocol.Version1)).To(MatchError(io.EOF))
 			}
 		})
 	})
@@ -34,18 +34,30 @@ const (
 	connectionCloseFrameType    = 0x1c
 	applicationCloseFrameType   = 0x1d
 	handshakeDoneFrameType      = 0x1e
+	// draft-ietf-quic-ack-frequency
+	ackFrequencyFrameType = 0xaf
+	immediateAckFrameType = 0x1f
+	// draft-ietf-quic-reliable-stream-reset
+	resetStreamAtFrameType = 0x24
 )
 
 // The FrameParser parses QUIC frames, one by one.
 type FrameParser struct {
-	r bytes.Reader // cached bytes.Reader, so we don't have to repeatedly allocate them
+	r    bytes.Reader // cached bytes.Reader, so we don't have to repeatedly allocate them
+	data []byte       // the data r reads from
 
 	ackDelayExponent  uint8
 	supportsDatagrams bool
+	// supportsAckFrequency is set if we advertised the min_ack_delay transport parameter
+	supportsAckFrequency bool
+	// supportsResetStreamAt is set if we advertised the reset_stream_at transport parameter
+	supportsResetStreamAt bool
 
 	// To avoid allocating when parsing, keep a single ACK frame struct.
 	// It is used over and over again.
 	ackFrame *AckFrame
+	// The same applies to DATAGRAM frames. Their payload references the packet data.
+	datagramFrame *DatagramFrame
 }
 
 // NewFrameParser creates a new frame parser.
@@ -54,17 +66,22 @@ func NewFrameParser(supportsDatagrams bool) *FrameParser {
 		r:                 *bytes.NewReader(nil),
 		supportsDatagrams: supportsDatagrams,
 		ackFrame:          &AckFrame{},
+		datagramFrame:     &DatagramFrame{},
 	}
 }
 
 // ParseNext parses the next frame.
 // It skips PADDING frames.
+// ACK and DATAGRAM frames are reused, and are only valid until the next call to ParseNext.
+// The payload of DATAGRAM frames references data.
 func (p *FrameParser) ParseNext(data []byte, encLevel protocol.EncryptionLevel, v protocol.Version) (int, Frame, error) {
 	startLen := len(data)
 	p.r.Reset(data)
+	p.data = data
 	frame, err := p.parseNext(&p.r, encLevel, v)
 	n := startLen - p.r.Len()
 	p.r.Reset(nil)
+	p.data = nil
 	return n, frame, err
 }
 
@@ -145,12 +162,22 @@ func (p *FrameParser) parseFrame(r *bytes.Reader, typ uint64, encLevel protocol.
 			frame = &HandshakeDoneFrame{}
 		case 0x30, 0x31:
 			if p.supportsDatagrams {
-				frame, err = parseDatagramFrame(r, typ, v)
+				err = parseDatagramFrame(p.datagramFrame, r, p.data, typ, v)
+				frame = p.datagramFrame
 				break
 			}
 			fallthrough
 		default:
-			err = errors.New("unknown frame type")
+			switch {
+			case typ == ackFrequencyFrameType && p.supportsAckFrequency:
+				frame, err = parseAckFrequencyFrame(r, v)
+			case typ == immediateAckFrameType && p.supportsAckFrequency:
+				frame = &ImmediateAckFrame{}
+			case typ == resetStreamAtFrameType && p.supportsResetStreamAt:
+				frame, err = parseResetStreamAtFrame(r, v)
+			default:
+				err = errors.New("unknown frame type")
+			}
 		}
 	}
 	if err != nil {
@@ -190,3 +217,15 @@ func (p *FrameParser) isAllowedAtEncLevel(f Frame, encLevel protocol.EncryptionL
 func (p *FrameParser) SetAckDelayExponent(exp uint8) {
 	p.ackDelayExponent = exp
 }
+
+// SetSupportsAckFrequency enables parsing of the ACK_FREQUENCY and IMMEDIATE_ACK frames.
+// It is called if we advertise the min_ack_delay transport parameter.
+func (p *FrameParser) SetSupportsAckFrequency() {
+	p.supportsAckFrequency = true
+}
+
+// SetSupportsResetStreamAt enables parsing of the RESET_STREAM_AT frame.
+// It is called if we advertise the reset_stream_at transport parameter.
+func (p *FrameParser) SetSupportsResetStreamAt() {
+	p.supportsResetStreamAt = true
+}
@@ -15,6 +15,8 @@ var _ = Describe("Frame parsing", func() {
 
 	BeforeEach(func() {
 		parser = *NewFrameParser(true)
+		parser.SetSupportsAckFrequency()
+		parser.SetSupportsResetStreamAt()
 	})
 
 	It("returns nil if there's nothing more to read", func() {
@@ -327,6 +329,66 @@ var _ = Describe("Frame parsing", func() {
 		}))
 	})
 
+	It("unpacks ACK_FREQUENCY frames", func() {
+		f := &AckFrequencyFrame{SequenceNumber: 1, AckElicitingThreshold: 10, RequestedMaxAckDelay: 5 * time.Millisecond, ReorderingThreshold: 2}
+		b, err := f.Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		l, frame, err := parser.ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(frame).To(Equal(f))
+		Expect(l).To(Equal(len(b)))
+	})
+
+	It("unpacks IMMEDIATE_ACK frames", func() {
+		f := &ImmediateAckFrame{}
+		b, err := f.Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		l, frame, err := parser.ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(frame).To(Equal(f))
+		Expect(l).To(Equal(len(b)))
+	})
+
+	It("errors when the ACK frequency extension is not supported", func() {
+		parser = *NewFrameParser(true)
+		for _, f := range []Frame{&AckFrequencyFrame{}, &ImmediateAckFrame{}} {
+			b, err := f.Append(nil, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			_, _, err = parser.ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
+			Expect(err).To(BeAssignableToTypeOf(&qerr.TransportError{}))
+			Expect(err.(*qerr.TransportError).ErrorCode).To(Equal(qerr.FrameEncodingError))
+			Expect(err.(*qerr.TransportError).ErrorMessage).To(Equal("unknown frame type"))
+		}
+	})
+
+	It("unpacks RESET_STREAM_AT frames", func() {
+		f := &ResetStreamAtFrame{
+			StreamID:     0xdeadbeef,
+			ErrorCode:    0x1337,
+			FinalSize:    0x1000,
+			ReliableSize: 0x100,
+		}
+		b, err := f.Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		l, frame, err := parser.ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(frame).To(Equal(f))
+		Expect(l).To(Equal(len(b)))
+	})
+
+	It("errors when RESET_STREAM_AT is not supported", func() {
+		parser = *NewFrameParser(true)
+		f := &ResetStreamAtFrame{StreamID: 4, FinalSize: 10, ReliableSize: 5}
+		b, err := f.Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		_, _, err = parser.ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
+		Expect(err).To(MatchError(&qerr.TransportError{
+			ErrorCode:    qerr.FrameEncodingError,
+			FrameType:    resetStreamAtFrameType,
+			ErrorMessage: "unknown frame type",
+		}))
+	})
+
 	It("errors on invalid type", func() {
 		_, _, err := parser.ParseNext(encodeVarInt(0x42), protocol.Encryption1RTT, protocol.Version1)
 		Expect(err).To(MatchError(&qerr.TransportError{
@@ -370,6 +432,9 @@ var _ = Describe("Frame parsing", func() {
 			&ConnectionCloseFrame{},
 			&HandshakeDoneFrame{},
 			&DatagramFrame{},
+			&AckFrequencyFrame{},
+			&ImmediateAckFrame{},
+			&ResetStreamAtFrame{},
 		}
 
 		var framesSerialized [][]byte
@@ -0,0 +1,17 @@
+package wire
+
+import (
+	"github.com/quic-go/quic-go/internal/protocol"
+)
+
+// An ImmediateAckFrame is an IMMEDIATE_ACK frame (draft-ietf-quic-ack-frequency)
+type ImmediateAckFrame struct{}
+
+func (f *ImmediateAckFrame) Append(b []byte, _ protocol.Version) ([]byte, error) {
+	return append(b, immediateAckFrameType), nil
+}
+
+// Length of a written frame
+func (f *ImmediateAckFrame) Length(_ protocol.Version) protocol.ByteCount {
+	return 1
+}
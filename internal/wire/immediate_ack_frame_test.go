@@ -0,0 +1,24 @@
+package wire
+
+import (
+	"github.com/quic-go/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("IMMEDIATE_ACK frame", func() {
+	Context("when writing", func() {
+		It("writes a sample frame", func() {
+			frame := ImmediateAckFrame{}
+			b, err := frame.Append(nil, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b).To(Equal([]byte{immediateAckFrameType}))
+		})
+
+		It("has the correct length", func() {
+			frame := ImmediateAckFrame{}
+			Expect(frame.Length(protocol.Version1)).To(Equal(protocol.ByteCount(1)))
+		})
+	})
+})
@@ -46,6 +46,11 @@ var _ = Describe("Frame logging", func() {
 		Expect(buf.String()).To(ContainSubstring("\t<- &wire.ResetStreamFrame{StreamID: 0, ErrorCode: 0x0, FinalSize: 0}\n"))
 	})
 
+	It("logs RESET_STREAM_AT frames", func() {
+		LogFrame(logger, &ResetStreamAtFrame{StreamID: 4, ErrorCode: 0x42, FinalSize: 100, ReliableSize: 10}, false)
+		Expect(buf.String()).To(ContainSubstring("\t<- &wire.ResetStreamAtFrame{StreamID: 4, ErrorCode: 0x42, FinalSize: 100, ReliableSize: 10}\n"))
+	})
+
 	It("logs CRYPTO frames", func() {
 		frame := &CryptoFrame{
 			Offset: 42,
@@ -171,4 +176,9 @@ var _ = Describe("Frame logging", func() {
 		}, true)
 		Expect(buf.String()).To(ContainSubstring("\t-> &wire.NewTokenFrame{Token: 0xdeadbeef"))
 	})
+
+	It("logs ACK_FREQUENCY frames", func() {
+		LogFrame(logger, &AckFrequencyFrame{SequenceNumber: 3, AckElicitingThreshold: 9, RequestedMaxAckDelay: 10 * time.Millisecond, ReorderingThreshold: 2}, true)
+		Expect(buf.String()).To(ContainSubstring("\t-> &wire.AckFrequencyFrame{SequenceNumber: 3, AckElicitingThreshold: 9, RequestedMaxAckDelay: 10ms, ReorderingThreshold: 2}"))
+	})
 })
@@ -0,0 +1,60 @@
+package wire
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/qerr"
+	"github.com/quic-go/quic-go/quicvarint"
+)
+
+// A ResetStreamAtFrame is a RESET_STREAM_AT frame (draft-ietf-quic-reliable-stream-reset)
+type ResetStreamAtFrame struct {
+	StreamID     protocol.StreamID
+	ErrorCode    qerr.StreamErrorCode
+	FinalSize    protocol.ByteCount
+	ReliableSize protocol.ByteCount
+}
+
+func parseResetStreamAtFrame(r *bytes.Reader, _ protocol.Version) (*ResetStreamAtFrame, error) {
+	streamID, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	errorCode, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	finalSize, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	reliableSize, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	if reliableSize > finalSize {
+		return nil, errors.New("RESET_STREAM_AT frame: reliable size can't be larger than final size")
+	}
+	return &ResetStreamAtFrame{
+		StreamID:     protocol.StreamID(streamID),
+		ErrorCode:    qerr.StreamErrorCode(errorCode),
+		FinalSize:    protocol.ByteCount(finalSize),
+		ReliableSize: protocol.ByteCount(reliableSize),
+	}, nil
+}
+
+func (f *ResetStreamAtFrame) Append(b []byte, _ protocol.Version) ([]byte, error) {
+	b = quicvarint.Append(b, resetStreamAtFrameType)
+	b = quicvarint.Append(b, uint64(f.StreamID))
+	b = quicvarint.Append(b, uint64(f.ErrorCode))
+	b = quicvarint.Append(b, uint64(f.FinalSize))
+	b = quicvarint.Append(b, uint64(f.ReliableSize))
+	return b, nil
+}
+
+// Length of a written frame
+func (f *ResetStreamAtFrame) Length(protocol.Version) protocol.ByteCount {
+	return quicvarint.Len(resetStreamAtFrameType) + quicvarint.Len(uint64(f.StreamID)) + quicvarint.Len(uint64(f.ErrorCode)) + quicvarint.Len(uint64(f.FinalSize)) + quicvarint.Len(uint64(f.ReliableSize))
+}
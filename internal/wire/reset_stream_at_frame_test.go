@@ -0,0 +1,84 @@
+package wire
+
+import (
+	"bytes"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/qerr"
+	"github.com/quic-go/quic-go/quicvarint"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RESET_STREAM_AT frame", func() {
+	Context("when parsing", func() {
+		It("accepts a sample frame", func() {
+			data := encodeVarInt(0xdeadbeef)                  // stream ID
+			data = append(data, encodeVarInt(0x1337)...)      // error code
+			data = append(data, encodeVarInt(0x987654321)...) // final size
+			data = append(data, encodeVarInt(0x42)...)        // reliable size
+			b := bytes.NewReader(data)
+			frame, err := parseResetStreamAtFrame(b, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(frame.StreamID).To(Equal(protocol.StreamID(0xdeadbeef)))
+			Expect(frame.ErrorCode).To(Equal(qerr.StreamErrorCode(0x1337)))
+			Expect(frame.FinalSize).To(Equal(protocol.ByteCount(0x987654321)))
+			Expect(frame.ReliableSize).To(Equal(protocol.ByteCount(0x42)))
+			Expect(b.Len()).To(BeZero())
+		})
+
+		It("rejects frames with a reliable size larger than the final size", func() {
+			data := encodeVarInt(0xdeadbeef)             // stream ID
+			data = append(data, encodeVarInt(0x1337)...) // error code
+			data = append(data, encodeVarInt(100)...)    // final size
+			data = append(data, encodeVarInt(101)...)    // reliable size
+			_, err := parseResetStreamAtFrame(bytes.NewReader(data), protocol.Version1)
+			Expect(err).To(MatchError("RESET_STREAM_AT frame: reliable size can't be larger than final size"))
+		})
+
+		It("errors on EOFs", func() {
+			data := encodeVarInt(0xdeadbeef)                  // stream ID
+			data = append(data, encodeVarInt(0x1337)...)      // error code
+			data = append(data, encodeVarInt(0x987654321)...) // final size
+			data = append(data, encodeVarInt(0x42)...)        // reliable size
+			_, err := parseResetStreamAtFrame(bytes.NewReader(data), protocol.Version1)
+			Expect(err).NotTo(HaveOccurred())
+			for i := range data {
+				_, err := parseResetStreamAtFrame(bytes.NewReader(data[:i]), protocol.Version1)
+				Expect(err).To(HaveOccurred())
+			}
+		})
+	})
+
+	Context("when writing", func() {
+		It("writes a sample frame", func() {
+			frame := &ResetStreamAtFrame{
+				StreamID:     0x1337,
+				ErrorCode:    0xcafe,
+				FinalSize:    0x11223344decafbad,
+				ReliableSize: 0x1234,
+			}
+			b, err := frame.Append(nil, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			expected := encodeVarInt(resetStreamAtFrameType)
+			expected = append(expected, encodeVarInt(0x1337)...)
+			expected = append(expected, encodeVarInt(0xcafe)...)
+			expected = append(expected, encodeVarInt(0x11223344decafbad)...)
+			expected = append(expected, encodeVarInt(0x1234)...)
+			Expect(b).To(Equal(expected))
+			Expect(frame.Length(protocol.Version1)).To(BeEquivalentTo(len(b)))
+		})
+
+		It("has the correct length", func() {
+			frame := &ResetStreamAtFrame{
+				StreamID:     0x1337,
+				ErrorCode:    0xde,
+				FinalSize:    0x1234567,
+				ReliableSize: 0x123,
+			}
+			expectedLen := 1 + quicvarint.Len(0x1337) + 2 + quicvarint.Len(0x1234567) + quicvarint.Len(0x123)
+			Expect(frame.Length(protocol.Version1)).To(Equal(expectedLen))
+		})
+	})
+})
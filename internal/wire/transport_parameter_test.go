@@ -43,6 +43,7 @@ var _ = Describe("Transport Parameters", func() {
 
 	It("has a string representation", func() {
 		rcid := protocol.ParseConnectionID([]byte{0xde, 0xad, 0xc0, 0xde})
+		minAckDelay := time.Millisecond
 		p := &TransportParameters{
 			InitialMaxStreamDataBidiLocal:   1234,
 			InitialMaxStreamDataBidiRemote:  2345,
@@ -59,8 +60,14 @@ var _ = Describe("Transport Parameters", func() {
 			StatelessResetToken:             &protocol.StatelessResetToken{0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff, 0x00},
 			ActiveConnectionIDLimit:         123,
 			MaxDatagramFrameSize:            876,
+			MinAckDelay:                     &minAckDelay,
+			EnableResetStreamAt:             true,
+			VersionInformation: &VersionInformation{
+				ChosenVersion:     protocol.Version1,
+				AvailableVersions: []protocol.Version{protocol.Version2, protocol.Version1},
+			},
 		}
-		Expect(p.String()).To(Equal("&wire.TransportParameters{OriginalDestinationConnectionID: deadbeef, InitialSourceConnectionID: decafbad, RetrySourceConnectionID: deadc0de, InitialMaxStreamDataBidiLocal: 1234, InitialMaxStreamDataBidiRemote: 2345, InitialMaxStreamDataUni: 3456, InitialMaxData: 4567, MaxBidiStreamNum: 1337, MaxUniStreamNum: 7331, MaxIdleTimeout: 42s, AckDelayExponent: 14, MaxAckDelay: 37ms, ActiveConnectionIDLimit: 123, StatelessResetToken: 0x112233445566778899aabbccddeeff00, MaxDatagramFrameSize: 876}"))
+		Expect(p.String()).To(Equal("&wire.TransportParameters{OriginalDestinationConnectionID: deadbeef, InitialSourceConnectionID: decafbad, RetrySourceConnectionID: deadc0de, InitialMaxStreamDataBidiLocal: 1234, InitialMaxStreamDataBidiRemote: 2345, InitialMaxStreamDataUni: 3456, InitialMaxData: 4567, MaxBidiStreamNum: 1337, MaxUniStreamNum: 7331, MaxIdleTimeout: 42s, AckDelayExponent: 14, MaxAckDelay: 37ms, ActiveConnectionIDLimit: 123, StatelessResetToken: 0x112233445566778899aabbccddeeff00, MaxDatagramFrameSize: 876, VersionInformation: {ChosenVersion: v1, AvailableVersions: [v2 v1]}, MinAckDelay: 1ms, EnableResetStreamAt: true}"))
 	})
 
 	It("has a string representation, if there's no stateless reset token, no Retry source connection id and no datagram support", func() {
@@ -86,6 +93,7 @@ var _ = Describe("Transport Parameters", func() {
 		var token protocol.StatelessResetToken
 		rand.Read(token[:])
 		rcid := protocol.ParseConnectionID([]byte{0xde, 0xad, 0xc0, 0xde})
+		minAckDelay := 1337 * time.Microsecond
 		params := &TransportParameters{
 			InitialMaxStreamDataBidiLocal:   protocol.ByteCount(getRandomValue()),
 			InitialMaxStreamDataBidiRemote:  protocol.ByteCount(getRandomValue()),
@@ -103,6 +111,8 @@ var _ = Describe("Transport Parameters", func() {
 			MaxAckDelay:                     42 * time.Millisecond,
 			ActiveConnectionIDLimit:         2 + getRandomValueUpTo(math.MaxInt64-2),
 			MaxDatagramFrameSize:            protocol.ByteCount(getRandomValue()),
+			MinAckDelay:                     &minAckDelay,
+			EnableResetStreamAt:             true,
 		}
 		data := params.Marshal(protocol.PerspectiveServer)
 
@@ -124,6 +134,8 @@ var _ = Describe("Transport Parameters", func() {
 		Expect(p.MaxAckDelay).To(Equal(42 * time.Millisecond))
 		Expect(p.ActiveConnectionIDLimit).To(Equal(params.ActiveConnectionIDLimit))
 		Expect(p.MaxDatagramFrameSize).To(Equal(params.MaxDatagramFrameSize))
+		Expect(p.MinAckDelay).To(Equal(&minAckDelay))
+		Expect(p.EnableResetStreamAt).To(BeTrue())
 	})
 
 	It("marshals additional transport parameters (used for testing large ClientHellos)", func() {
@@ -195,6 +207,16 @@ var _ = Describe("Transport Parameters", func() {
 		}))
 	})
 
+	It("errors when reset_stream_at has content", func() {
+		b := quicvarint.Append(nil, uint64(resetStreamAtParameterID))
+		b = quicvarint.Append(b, 6)
+		b = append(b, []byte("foobar")...)
+		Expect((&TransportParameters{}).Unmarshal(b, protocol.PerspectiveServer)).To(MatchError(&qerr.TransportError{
+			ErrorCode:    qerr.TransportParameterError,
+			ErrorMessage: "wrong length for reset_stream_at: 6 (expected empty)",
+		}))
+	})
+
 	It("errors when the server doesn't set the original_destination_connection_id", func() {
 		b := quicvarint.Append(nil, uint64(statelessResetTokenParameterID))
 		b = quicvarint.Append(b, 16)
@@ -337,6 +359,31 @@ var _ = Describe("Transport Parameters", func() {
 		}))
 	})
 
+	It("errors when the min_ack_delay is too large", func() {
+		b := quicvarint.Append(nil, uint64(minAckDelayParameterID))
+		b = quicvarint.Append(b, uint64(quicvarint.Len(1<<24)))
+		b = quicvarint.Append(b, 1<<24)
+		b = appendInitialSourceConnectionID(b)
+		Expect((&TransportParameters{}).Unmarshal(b, protocol.PerspectiveClient)).To(MatchError(&qerr.TransportError{
+			ErrorCode:    qerr.TransportParameterError,
+			ErrorMessage: "invalid value for min_ack_delay: 16777216us (maximum 16777215us)",
+		}))
+	})
+
+	It("errors when the min_ack_delay is larger than the max_ack_delay", func() {
+		minAckDelay := protocol.DefaultMaxAckDelay + time.Millisecond
+		data := (&TransportParameters{
+			MaxAckDelay:             protocol.DefaultMaxAckDelay,
+			MinAckDelay:             &minAckDelay,
+			ActiveConnectionIDLimit: 2,
+			StatelessResetToken:     &protocol.StatelessResetToken{},
+		}).Marshal(protocol.PerspectiveServer)
+		Expect((&TransportParameters{}).Unmarshal(data, protocol.PerspectiveServer)).To(MatchError(&qerr.TransportError{
+			ErrorCode:    qerr.TransportParameterError,
+			ErrorMessage: "min_ack_delay (26ms) larger than max_ack_delay (25ms)",
+		}))
+	})
+
 	It("handles huge max_ack_delay values", func() {
 		val := uint64(math.MaxUint64) / 5
 		b := quicvarint.Append(nil, uint64(maxAckDelayParameterID))
@@ -489,6 +536,72 @@ var _ = Describe("Transport Parameters", func() {
 		})
 	})
 
+	Context("version information", func() {
+		It("marshals and unmarshals", func() {
+			data := (&TransportParameters{
+				VersionInformation: &VersionInformation{
+					ChosenVersion:     protocol.Version2,
+					AvailableVersions: []protocol.Version{protocol.Version2, protocol.Version1},
+				},
+				ActiveConnectionIDLimit: 2,
+			}).Marshal(protocol.PerspectiveClient)
+			p := &TransportParameters{}
+			Expect(p.Unmarshal(data, protocol.PerspectiveClient)).To(Succeed())
+			Expect(p.VersionInformation).To(Equal(&VersionInformation{
+				ChosenVersion:     protocol.Version2,
+				AvailableVersions: []protocol.Version{protocol.Version2, protocol.Version1},
+			}))
+		})
+
+		It("doesn't send the version_information if it's not set", func() {
+			data := (&TransportParameters{ActiveConnectionIDLimit: 2}).Marshal(protocol.PerspectiveClient)
+			p := &TransportParameters{}
+			Expect(p.Unmarshal(data, protocol.PerspectiveClient)).To(Succeed())
+			Expect(p.VersionInformation).To(BeNil())
+		})
+
+		It("accepts an empty list of available versions", func() {
+			data := (&TransportParameters{
+				VersionInformation:      &VersionInformation{ChosenVersion: protocol.Version1},
+				ActiveConnectionIDLimit: 2,
+			}).Marshal(protocol.PerspectiveClient)
+			p := &TransportParameters{}
+			Expect(p.Unmarshal(data, protocol.PerspectiveClient)).To(Succeed())
+			Expect(p.VersionInformation.ChosenVersion).To(Equal(protocol.Version1))
+			Expect(p.VersionInformation.AvailableVersions).To(BeEmpty())
+		})
+
+		It("errors if the length is not a multiple of 4", func() {
+			b := quicvarint.Append(nil, uint64(versionInformationParameterID))
+			b = quicvarint.Append(b, 6)
+			b = append(b, []byte("foobar")...)
+			p := &TransportParameters{}
+			Expect(p.Unmarshal(appendInitialSourceConnectionID(b), protocol.PerspectiveClient)).To(MatchError(&qerr.TransportError{
+				ErrorCode:    qerr.TransportParameterError,
+				ErrorMessage: "invalid length for version_information: 6",
+			}))
+		})
+
+		It("errors if it contains version 0", func() {
+			b := quicvarint.Append(nil, uint64(versionInformationParameterID))
+			b = quicvarint.Append(b, 8)
+			b = append(b, []byte{0x6b, 0x33, 0x43, 0xcf, 0, 0, 0, 0}...)
+			p := &TransportParameters{}
+			Expect(p.Unmarshal(appendInitialSourceConnectionID(b), protocol.PerspectiveClient)).To(MatchError(&qerr.TransportError{
+				ErrorCode:    qerr.TransportParameterError,
+				ErrorMessage: "version_information contains version 0",
+			}))
+		})
+
+		It("errors on EOF", func() {
+			b := quicvarint.Append(nil, uint64(versionInformationParameterID))
+			b = quicvarint.Append(b, 8)
+			b = append(b, []byte{0x6b, 0x33, 0x43, 0xcf}...)
+			p := &TransportParameters{}
+			Expect(p.Unmarshal(b, protocol.PerspectiveClient)).ToNot(Succeed())
+		})
+	})
+
 	Context("saving and retrieving from a session ticket", func() {
 		It("saves and retrieves the parameters", func() {
 			params := &TransportParameters{
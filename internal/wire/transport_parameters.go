@@ -45,8 +45,14 @@ const (
 	activeConnectionIDLimitParameterID         transportParameterID = 0xe
 	initialSourceConnectionIDParameterID       transportParameterID = 0xf
 	retrySourceConnectionIDParameterID         transportParameterID = 0x10
+	// RFC 9368
+	versionInformationParameterID transportParameterID = 0x11
 	// RFC 9221
 	maxDatagramFrameSizeParameterID transportParameterID = 0x20
+	// draft-ietf-quic-ack-frequency
+	minAckDelayParameterID transportParameterID = 0xff04de1b
+	// draft-ietf-quic-reliable-stream-reset
+	resetStreamAtParameterID transportParameterID = 0x17f7586d2cb571
 )
 
 // PreferredAddress is the value encoding in the preferred_address transport parameter
@@ -56,6 +62,12 @@ type PreferredAddress struct {
 	StatelessResetToken protocol.StatelessResetToken
 }
 
+// VersionInformation is the value encoded in the version_information transport parameter (RFC 9368).
+type VersionInformation struct {
+	ChosenVersion     protocol.Version
+	AvailableVersions []protocol.Version
+}
+
 // TransportParameters are parameters sent to the peer during the handshake
 type TransportParameters struct {
 	InitialMaxStreamDataBidiLocal  protocol.ByteCount
@@ -85,6 +97,16 @@ type TransportParameters struct {
 	ActiveConnectionIDLimit uint64
 
 	MaxDatagramFrameSize protocol.ByteCount
+
+	// VersionInformation is nil if the endpoint doesn't support compatible version negotiation.
+	VersionInformation *VersionInformation
+
+	// MinAckDelay is the minimum amount of time the endpoint is able to delay an acknowledgment.
+	// It is nil if the endpoint doesn't support the ACK frequency extension.
+	MinAckDelay *time.Duration
+
+	// EnableResetStreamAt is set if the endpoint supports receiving RESET_STREAM_AT frames.
+	EnableResetStreamAt bool
 }
 
 // Unmarshal the transport parameters
@@ -140,6 +162,7 @@ func (p *TransportParameters) unmarshal(r *bytes.Reader, sentBy protocol.Perspec
 			initialMaxStreamsUniParameterID,
 			maxAckDelayParameterID,
 			maxDatagramFrameSizeParameterID,
+			minAckDelayParameterID,
 			ackDelayExponentParameterID:
 			if err := p.readNumericTransportParameter(r, paramID, int(paramLen)); err != nil {
 				return err
@@ -156,6 +179,11 @@ func (p *TransportParameters) unmarshal(r *bytes.Reader, sentBy protocol.Perspec
 				return fmt.Errorf("wrong length for disable_active_migration: %d (expected empty)", paramLen)
 			}
 			p.DisableActiveMigration = true
+		case resetStreamAtParameterID:
+			if paramLen != 0 {
+				return fmt.Errorf("wrong length for reset_stream_at: %d (expected empty)", paramLen)
+			}
+			p.EnableResetStreamAt = true
 		case statelessResetTokenParameterID:
 			if sentBy == protocol.PerspectiveClient {
 				return errors.New("client sent a stateless_reset_token")
@@ -181,11 +209,18 @@ func (p *TransportParameters) unmarshal(r *bytes.Reader, sentBy protocol.Perspec
 			}
 			connID, _ := protocol.ReadConnectionID(r, int(paramLen))
 			p.RetrySourceConnectionID = &connID
+		case versionInformationParameterID:
+			if err := p.readVersionInformation(r, int(paramLen)); err != nil {
+				return err
+			}
 		default:
 			r.Seek(int64(paramLen), io.SeekCurrent)
 		}
 	}
 
+	if p.MinAckDelay != nil && *p.MinAckDelay > p.MaxAckDelay {
+		return fmt.Errorf("min_ack_delay (%s) larger than max_ack_delay (%s)", *p.MinAckDelay, p.MaxAckDelay)
+	}
 	if !readActiveConnectionIDLimit {
 		p.ActiveConnectionIDLimit = protocol.DefaultActiveConnectionIDLimit
 	}
@@ -255,6 +290,28 @@ func (p *TransportParameters) readPreferredAddress(r *bytes.Reader, expectedLen
 	return nil
 }
 
+func (p *TransportParameters) readVersionInformation(r *bytes.Reader, length int) error {
+	if length == 0 || length%4 != 0 {
+		return fmt.Errorf("invalid length for version_information: %d", length)
+	}
+	versions := make([]protocol.Version, 0, length/4)
+	for i := 0; i < length/4; i++ {
+		v, err := utils.BigEndian.ReadUint32(r)
+		if err != nil {
+			return err
+		}
+		if v == 0 {
+			return errors.New("version_information contains version 0")
+		}
+		versions = append(versions, protocol.Version(v))
+	}
+	p.VersionInformation = &VersionInformation{
+		ChosenVersion:     versions[0],
+		AvailableVersions: versions[1:],
+	}
+	return nil
+}
+
 func (p *TransportParameters) readNumericTransportParameter(
 	r *bytes.Reader,
 	paramID transportParameterID,
@@ -312,6 +369,12 @@ func (p *TransportParameters) readNumericTransportParameter(
 		p.ActiveConnectionIDLimit = val
 	case maxDatagramFrameSizeParameterID:
 		p.MaxDatagramFrameSize = protocol.ByteCount(val)
+	case minAckDelayParameterID:
+		if val >= 1<<24 {
+			return fmt.Errorf("invalid value for min_ack_delay: %dus (maximum %dus)", val, 1<<24-1)
+		}
+		minAckDelay := time.Duration(val) * time.Microsecond
+		p.MinAckDelay = &minAckDelay
 	default:
 		return fmt.Errorf("TransportParameter BUG: transport parameter %d not found", paramID)
 	}
@@ -407,6 +470,24 @@ func (p *TransportParameters) Marshal(pers protocol.Perspective) []byte {
 	if p.MaxDatagramFrameSize != protocol.InvalidByteCount {
 		b = p.marshalVarintParam(b, maxDatagramFrameSizeParameterID, uint64(p.MaxDatagramFrameSize))
 	}
+	// version_information
+	if p.VersionInformation != nil {
+		b = quicvarint.Append(b, uint64(versionInformationParameterID))
+		b = quicvarint.Append(b, uint64(4+4*len(p.VersionInformation.AvailableVersions)))
+		b = binary.BigEndian.AppendUint32(b, uint32(p.VersionInformation.ChosenVersion))
+		for _, v := range p.VersionInformation.AvailableVersions {
+			b = binary.BigEndian.AppendUint32(b, uint32(v))
+		}
+	}
+	// min_ack_delay
+	if p.MinAckDelay != nil {
+		b = p.marshalVarintParam(b, minAckDelayParameterID, uint64(*p.MinAckDelay/time.Microsecond))
+	}
+	// reset_stream_at
+	if p.EnableResetStreamAt {
+		b = quicvarint.Append(b, uint64(resetStreamAtParameterID))
+		b = quicvarint.Append(b, 0)
+	}
 
 	if pers == protocol.PerspectiveClient && len(AdditionalTransportParametersClient) > 0 {
 		for k, v := range AdditionalTransportParametersClient {
@@ -515,6 +596,17 @@ func (p *TransportParameters) String() string {
 		logString += ", MaxDatagramFrameSize: %d"
 		logParams = append(logParams, p.MaxDatagramFrameSize)
 	}
+	if p.VersionInformation != nil {
+		logString += ", VersionInformation: {ChosenVersion: %s, AvailableVersions: %s}"
+		logParams = append(logParams, p.VersionInformation.ChosenVersion, p.VersionInformation.AvailableVersions)
+	}
+	if p.MinAckDelay != nil {
+		logString += ", MinAckDelay: %s"
+		logParams = append(logParams, *p.MinAckDelay)
+	}
+	if p.EnableResetStreamAt {
+		logString += ", EnableResetStreamAt: true"
+	}
 	logString += "}"
 	return fmt.Sprintf(logString, logParams...)
 }
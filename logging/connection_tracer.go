@@ -24,8 +24,11 @@ type ConnectionTracer struct {
 	UpdatedMetrics                   func(rttStats *RTTStats, cwnd, bytesInFlight ByteCount, packetsInFlight int)
 	AcknowledgedPacket               func(EncryptionLevel, PacketNumber)
 	LostPacket                       func(EncryptionLevel, PacketNumber, PacketLossReason)
+	DetectedSpuriousLoss             func(_ EncryptionLevel, _ PacketNumber, packetReordering uint64, timeReordering time.Duration)
 	UpdatedCongestionState           func(CongestionState)
+	UpdatedECNMarkingFraction        func(fraction, average float64) // fraction of CE-marked bytes in the last round trip, used for L4S
 	UpdatedPTOCount                  func(value uint32)
+	UpdatedMTU                       func(mtu ByteCount, done bool) // done is set when Path MTU Discovery stopped searching
 	UpdatedKeyFromTLS                func(EncryptionLevel, Perspective)
 	UpdatedKey                       func(generation KeyPhase, remote bool)
 	DroppedEncryptionLevel           func(EncryptionLevel)
@@ -34,7 +37,10 @@ type ConnectionTracer struct {
 	LossTimerExpired                 func(TimerType, EncryptionLevel)
 	LossTimerCanceled                func()
 	ECNStateUpdated                  func(state ECNState, trigger ECNStateTrigger)
+	ExpiredStreamData                func(id StreamID, offset, length ByteCount) // data dropped after its delivery deadline
+	UpdatedPeerAddress               func(oldAddr, newAddr net.Addr)             // the server switched to a new client address
 	ChoseALPN                        func(protocol string)
+	DroppedDatagram                  func(size ByteCount, reason DatagramDropReason)
 	// Close is called when the connection is closed.
 	Close func()
 	Debug func(name, msg string)
@@ -171,6 +177,13 @@ func NewMultiplexedConnectionTracer(tracers ...*ConnectionTracer) *ConnectionTra
 				}
 			}
 		},
+		DetectedSpuriousLoss: func(encLevel EncryptionLevel, pn PacketNumber, packetReordering uint64, timeReordering time.Duration) {
+			for _, t := range tracers {
+				if t.DetectedSpuriousLoss != nil {
+					t.DetectedSpuriousLoss(encLevel, pn, packetReordering, timeReordering)
+				}
+			}
+		},
 		UpdatedCongestionState: func(state CongestionState) {
 			for _, t := range tracers {
 				if t.UpdatedCongestionState != nil {
@@ -178,6 +191,13 @@ func NewMultiplexedConnectionTracer(tracers ...*ConnectionTracer) *ConnectionTra
 				}
 			}
 		},
+		UpdatedECNMarkingFraction: func(fraction, average float64) {
+			for _, t := range tracers {
+				if t.UpdatedECNMarkingFraction != nil {
+					t.UpdatedECNMarkingFraction(fraction, average)
+				}
+			}
+		},
 		UpdatedPTOCount: func(value uint32) {
 			for _, t := range tracers {
 				if t.UpdatedPTOCount != nil {
@@ -185,6 +205,13 @@ func NewMultiplexedConnectionTracer(tracers ...*ConnectionTracer) *ConnectionTra
 				}
 			}
 		},
+		UpdatedMTU: func(mtu ByteCount, done bool) {
+			for _, t := range tracers {
+				if t.UpdatedMTU != nil {
+					t.UpdatedMTU(mtu, done)
+				}
+			}
+		},
 		UpdatedKeyFromTLS: func(encLevel EncryptionLevel, perspective Perspective) {
 			for _, t := range tracers {
 				if t.UpdatedKeyFromTLS != nil {
@@ -241,6 +268,27 @@ func NewMultiplexedConnectionTracer(tracers ...*ConnectionTracer) *ConnectionTra
 				}
 			}
 		},
+		ExpiredStreamData: func(id StreamID, offset, length ByteCount) {
+			for _, t := range tracers {
+				if t.ExpiredStreamData != nil {
+					t.ExpiredStreamData(id, offset, length)
+				}
+			}
+		},
+		DroppedDatagram: func(size ByteCount, reason DatagramDropReason) {
+			for _, t := range tracers {
+				if t.DroppedDatagram != nil {
+					t.DroppedDatagram(size, reason)
+				}
+			}
+		},
+		UpdatedPeerAddress: func(oldAddr, newAddr net.Addr) {
+			for _, t := range tracers {
+				if t.UpdatedPeerAddress != nil {
+					t.UpdatedPeerAddress(oldAddr, newAddr)
+				}
+			}
+		},
 		ChoseALPN: func(protocol string) {
 			for _, t := range tracers {
 				if t.ChoseALPN != nil {
@@ -12,12 +12,16 @@ type AckRange = wire.AckRange
 type (
 	// An AckFrame is an ACK frame.
 	AckFrame = wire.AckFrame
+	// An AckFrequencyFrame is an ACK_FREQUENCY frame (ACK frequency extension).
+	AckFrequencyFrame = wire.AckFrequencyFrame
 	// A ConnectionCloseFrame is a CONNECTION_CLOSE frame.
 	ConnectionCloseFrame = wire.ConnectionCloseFrame
 	// A DataBlockedFrame is a DATA_BLOCKED frame.
 	DataBlockedFrame = wire.DataBlockedFrame
 	// A HandshakeDoneFrame is a HANDSHAKE_DONE frame.
 	HandshakeDoneFrame = wire.HandshakeDoneFrame
+	// An ImmediateAckFrame is an IMMEDIATE_ACK frame (ACK frequency extension).
+	ImmediateAckFrame = wire.ImmediateAckFrame
 	// A MaxDataFrame is a MAX_DATA frame.
 	MaxDataFrame = wire.MaxDataFrame
 	// A MaxStreamDataFrame is a MAX_STREAM_DATA frame.
@@ -36,6 +40,8 @@ type (
 	PingFrame = wire.PingFrame
 	// A ResetStreamFrame is a RESET_STREAM frame.
 	ResetStreamFrame = wire.ResetStreamFrame
+	// A ResetStreamAtFrame is a RESET_STREAM_AT frame (reliable stream reset extension).
+	ResetStreamAtFrame = wire.ResetStreamAtFrame
 	// A RetireConnectionIDFrame is a RETIRE_CONNECTION_ID frame.
 	RetireConnectionIDFrame = wire.RetireConnectionIDFrame
 	// A StopSendingFrame is a STOP_SENDING frame.
@@ -64,6 +64,13 @@ var _ = Describe("Tracing", func() {
 				tr2.EXPECT().DroppedPacket(remote, PacketTypeRetry, ByteCount(1024), PacketDropDuplicate)
 				tracer.DroppedPacket(remote, PacketTypeRetry, 1024, PacketDropDuplicate)
 			})
+
+			It("traces the RejectedToken event", func() {
+				remote := &net.UDPAddr{IP: net.IPv4(4, 3, 2, 1)}
+				tr1.EXPECT().RejectedToken(remote, true, TokenRejectionExpired)
+				tr2.EXPECT().RejectedToken(remote, true, TokenRejectionExpired)
+				tracer.RejectedToken(remote, true, TokenRejectionExpired)
+			})
 		})
 	})
 
@@ -195,6 +202,18 @@ var _ = Describe("Tracing", func() {
 			tracer.UpdatedCongestionState(CongestionStateRecovery)
 		})
 
+		It("traces the DetectedSpuriousLoss event", func() {
+			tr1.EXPECT().DetectedSpuriousLoss(EncryptionHandshake, PacketNumber(42), uint64(5), time.Second)
+			tr2.EXPECT().DetectedSpuriousLoss(EncryptionHandshake, PacketNumber(42), uint64(5), time.Second)
+			tracer.DetectedSpuriousLoss(EncryptionHandshake, 42, 5, time.Second)
+		})
+
+		It("traces the UpdatedECNMarkingFraction event", func() {
+			tr1.EXPECT().UpdatedECNMarkingFraction(0.25, 0.5)
+			tr2.EXPECT().UpdatedECNMarkingFraction(0.25, 0.5)
+			tracer.UpdatedECNMarkingFraction(0.25, 0.5)
+		})
+
 		It("traces the UpdatedMetrics event", func() {
 			rttStats := &RTTStats{}
 			rttStats.UpdateRTT(time.Second, 0, time.Now())
@@ -221,6 +240,12 @@ var _ = Describe("Tracing", func() {
 			tracer.UpdatedPTOCount(88)
 		})
 
+		It("traces the UpdatedMTU event", func() {
+			tr1.EXPECT().UpdatedMTU(ByteCount(1337), true)
+			tr2.EXPECT().UpdatedMTU(ByteCount(1337), true)
+			tracer.UpdatedMTU(1337, true)
+		})
+
 		It("traces the UpdatedKeyFromTLS event", func() {
 			tr1.EXPECT().UpdatedKeyFromTLS(EncryptionHandshake, PerspectiveClient)
 			tr2.EXPECT().UpdatedKeyFromTLS(EncryptionHandshake, PerspectiveClient)
@@ -264,6 +289,26 @@ var _ = Describe("Tracing", func() {
 			tracer.LossTimerCanceled()
 		})
 
+		It("traces the UpdatedPeerAddress event", func() {
+			oldAddr := &net.UDPAddr{IP: net.IPv4(1, 2, 3, 4), Port: 1234}
+			newAddr := &net.UDPAddr{IP: net.IPv4(1, 2, 3, 4), Port: 4321}
+			tr1.EXPECT().UpdatedPeerAddress(oldAddr, newAddr)
+			tr2.EXPECT().UpdatedPeerAddress(oldAddr, newAddr)
+			tracer.UpdatedPeerAddress(oldAddr, newAddr)
+		})
+
+		It("traces the ExpiredStreamData event", func() {
+			tr1.EXPECT().ExpiredStreamData(StreamID(4), ByteCount(1000), ByteCount(337))
+			tr2.EXPECT().ExpiredStreamData(StreamID(4), ByteCount(1000), ByteCount(337))
+			tracer.ExpiredStreamData(4, 1000, 337)
+		})
+
+		It("traces the DroppedDatagram event", func() {
+			tr1.EXPECT().DroppedDatagram(ByteCount(1337), DatagramDropExpired)
+			tr2.EXPECT().DroppedDatagram(ByteCount(1337), DatagramDropExpired)
+			tracer.DroppedDatagram(1337, DatagramDropExpired)
+		})
+
 		It("traces the Close event", func() {
 			tr1.EXPECT().Close()
 			tr2.EXPECT().Close()
@@ -7,6 +7,7 @@ type Tracer struct {
 	SentPacket                   func(net.Addr, *Header, ByteCount, []Frame)
 	SentVersionNegotiationPacket func(_ net.Addr, dest, src ArbitraryLenConnectionID, _ []VersionNumber)
 	DroppedPacket                func(net.Addr, PacketType, ByteCount, PacketDropReason)
+	RejectedToken                func(_ net.Addr, isRetryToken bool, _ TokenRejectionReason)
 }
 
 // NewMultiplexedTracer creates a new tracer that multiplexes events to multiple tracers.
@@ -39,5 +40,12 @@ func NewMultiplexedTracer(tracers ...*Tracer) *Tracer {
 				}
 			}
 		},
+		RejectedToken: func(remote net.Addr, isRetryToken bool, reason TokenRejectionReason) {
+			for _, t := range tracers {
+				if t.RejectedToken != nil {
+					t.RejectedToken(remote, isRetryToken, reason)
+				}
+			}
+		},
 	}
 }
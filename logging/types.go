@@ -58,6 +58,32 @@ const (
 	PacketDropDuplicate
 )
 
+// DatagramDropReason is the reason why a DATAGRAM frame was dropped
+type DatagramDropReason uint8
+
+const (
+	// DatagramDropSendQueueFull is used when a datagram is dropped because the send queue is full
+	DatagramDropSendQueueFull DatagramDropReason = iota
+	// DatagramDropReceiveQueueFull is used when a received datagram is dropped because the receive queue is full
+	DatagramDropReceiveQueueFull
+	// DatagramDropExpired is used when a datagram is dropped because it was queued for longer than Config.DatagramMaxAge
+	DatagramDropExpired
+	// DatagramDropTooLarge is used when a datagram is dropped because it doesn't fit into a packet
+	DatagramDropTooLarge
+)
+
+// TokenRejectionReason is the reason why an address validation token was rejected
+type TokenRejectionReason uint8
+
+const (
+	// TokenRejectionInvalid is used when a token couldn't be decrypted or decoded
+	TokenRejectionInvalid TokenRejectionReason = iota
+	// TokenRejectionAddressMismatch is used when a token was issued for a different address
+	TokenRejectionAddressMismatch
+	// TokenRejectionExpired is used when a token is too old
+	TokenRejectionExpired
+)
+
 // TimerType is the type of the loss detection timer
 type TimerType uint8
 
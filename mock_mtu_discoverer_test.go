@@ -117,6 +117,150 @@ func (c *MTUDiscovererGetPingCall) DoAndReturn(f func() (ackhandler.Frame, proto
 	return c
 }
 
+// OnPTO mocks base method.
+func (m *MockMTUDiscoverer) OnPTO(arg0 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnPTO", arg0)
+}
+
+// OnPTO indicates an expected call of OnPTO.
+func (mr *MockMTUDiscovererMockRecorder) OnPTO(arg0 any) *MTUDiscovererOnPTOCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnPTO", reflect.TypeOf((*MockMTUDiscoverer)(nil).OnPTO), arg0)
+	return &MTUDiscovererOnPTOCall{Call: call}
+}
+
+// MTUDiscovererOnPTOCall wrap *gomock.Call
+type MTUDiscovererOnPTOCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MTUDiscovererOnPTOCall) Return() *MTUDiscovererOnPTOCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MTUDiscovererOnPTOCall) Do(f func(protocol.ByteCount)) *MTUDiscovererOnPTOCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MTUDiscovererOnPTOCall) DoAndReturn(f func(protocol.ByteCount)) *MTUDiscovererOnPTOCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// OnPacketAcked mocks base method.
+func (m *MockMTUDiscoverer) OnPacketAcked(arg0 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnPacketAcked", arg0)
+}
+
+// OnPacketAcked indicates an expected call of OnPacketAcked.
+func (mr *MockMTUDiscovererMockRecorder) OnPacketAcked(arg0 any) *MTUDiscovererOnPacketAckedCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnPacketAcked", reflect.TypeOf((*MockMTUDiscoverer)(nil).OnPacketAcked), arg0)
+	return &MTUDiscovererOnPacketAckedCall{Call: call}
+}
+
+// MTUDiscovererOnPacketAckedCall wrap *gomock.Call
+type MTUDiscovererOnPacketAckedCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MTUDiscovererOnPacketAckedCall) Return() *MTUDiscovererOnPacketAckedCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MTUDiscovererOnPacketAckedCall) Do(f func(protocol.ByteCount)) *MTUDiscovererOnPacketAckedCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MTUDiscovererOnPacketAckedCall) DoAndReturn(f func(protocol.ByteCount)) *MTUDiscovererOnPacketAckedCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// OnPacketLost mocks base method.
+func (m *MockMTUDiscoverer) OnPacketLost(arg0 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnPacketLost", arg0)
+}
+
+// OnPacketLost indicates an expected call of OnPacketLost.
+func (mr *MockMTUDiscovererMockRecorder) OnPacketLost(arg0 any) *MTUDiscovererOnPacketLostCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnPacketLost", reflect.TypeOf((*MockMTUDiscoverer)(nil).OnPacketLost), arg0)
+	return &MTUDiscovererOnPacketLostCall{Call: call}
+}
+
+// MTUDiscovererOnPacketLostCall wrap *gomock.Call
+type MTUDiscovererOnPacketLostCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MTUDiscovererOnPacketLostCall) Return() *MTUDiscovererOnPacketLostCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MTUDiscovererOnPacketLostCall) Do(f func(protocol.ByteCount)) *MTUDiscovererOnPacketLostCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MTUDiscovererOnPacketLostCall) DoAndReturn(f func(protocol.ByteCount)) *MTUDiscovererOnPacketLostCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Reset mocks base method.
+func (m *MockMTUDiscoverer) Reset() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Reset")
+}
+
+// Reset indicates an expected call of Reset.
+func (mr *MockMTUDiscovererMockRecorder) Reset() *MTUDiscovererResetCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockMTUDiscoverer)(nil).Reset))
+	return &MTUDiscovererResetCall{Call: call}
+}
+
+// MTUDiscovererResetCall wrap *gomock.Call
+type MTUDiscovererResetCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MTUDiscovererResetCall) Return() *MTUDiscovererResetCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MTUDiscovererResetCall) Do(f func()) *MTUDiscovererResetCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MTUDiscovererResetCall) DoAndReturn(f func()) *MTUDiscovererResetCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // ShouldSendProbe mocks base method.
 func (m *MockMTUDiscoverer) ShouldSendProbe(arg0 time.Time) bool {
 	m.ctrl.T.Helper()
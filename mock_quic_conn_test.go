@@ -347,6 +347,44 @@ func (c *QUICConnLocalAddrCall) DoAndReturn(f func() net.Addr) *QUICConnLocalAdd
 	return c
 }
 
+// Migrate mocks base method.
+func (m *MockQUICConn) Migrate(arg0 net.PacketConn) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Migrate", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Migrate indicates an expected call of Migrate.
+func (mr *MockQUICConnMockRecorder) Migrate(arg0 any) *QUICConnMigrateCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Migrate", reflect.TypeOf((*MockQUICConn)(nil).Migrate), arg0)
+	return &QUICConnMigrateCall{Call: call}
+}
+
+// QUICConnMigrateCall wrap *gomock.Call
+type QUICConnMigrateCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *QUICConnMigrateCall) Return(arg0 error) *QUICConnMigrateCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *QUICConnMigrateCall) Do(f func(net.PacketConn) error) *QUICConnMigrateCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *QUICConnMigrateCall) DoAndReturn(f func(net.PacketConn) error) *QUICConnMigrateCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // NextConnection mocks base method.
 func (m *MockQUICConn) NextConnection() Connection {
 	m.ctrl.T.Helper()
@@ -541,6 +579,44 @@ func (c *QUICConnOpenUniStreamSyncCall) DoAndReturn(f func(context.Context) (Sen
 	return c
 }
 
+// PrioritizeStream mocks base method.
+func (m *MockQUICConn) PrioritizeStream(arg0 protocol.StreamID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PrioritizeStream", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PrioritizeStream indicates an expected call of PrioritizeStream.
+func (mr *MockQUICConnMockRecorder) PrioritizeStream(arg0 any) *QUICConnPrioritizeStreamCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrioritizeStream", reflect.TypeOf((*MockQUICConn)(nil).PrioritizeStream), arg0)
+	return &QUICConnPrioritizeStreamCall{Call: call}
+}
+
+// QUICConnPrioritizeStreamCall wrap *gomock.Call
+type QUICConnPrioritizeStreamCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *QUICConnPrioritizeStreamCall) Return(arg0 error) *QUICConnPrioritizeStreamCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *QUICConnPrioritizeStreamCall) Do(f func(protocol.StreamID) error) *QUICConnPrioritizeStreamCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *QUICConnPrioritizeStreamCall) DoAndReturn(f func(protocol.StreamID) error) *QUICConnPrioritizeStreamCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // ReceiveDatagram mocks base method.
 func (m *MockQUICConn) ReceiveDatagram(arg0 context.Context) ([]byte, error) {
 	m.ctrl.T.Helper()
@@ -580,6 +656,45 @@ func (c *QUICConnReceiveDatagramCall) DoAndReturn(f func(context.Context) ([]byt
 	return c
 }
 
+// ReceiveDatagrams mocks base method.
+func (m *MockQUICConn) ReceiveDatagrams(arg0 context.Context, arg1 [][]byte) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReceiveDatagrams", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReceiveDatagrams indicates an expected call of ReceiveDatagrams.
+func (mr *MockQUICConnMockRecorder) ReceiveDatagrams(arg0, arg1 any) *QUICConnReceiveDatagramsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceiveDatagrams", reflect.TypeOf((*MockQUICConn)(nil).ReceiveDatagrams), arg0, arg1)
+	return &QUICConnReceiveDatagramsCall{Call: call}
+}
+
+// QUICConnReceiveDatagramsCall wrap *gomock.Call
+type QUICConnReceiveDatagramsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *QUICConnReceiveDatagramsCall) Return(arg0 int, arg1 error) *QUICConnReceiveDatagramsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *QUICConnReceiveDatagramsCall) Do(f func(context.Context, [][]byte) (int, error)) *QUICConnReceiveDatagramsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *QUICConnReceiveDatagramsCall) DoAndReturn(f func(context.Context, [][]byte) (int, error)) *QUICConnReceiveDatagramsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // RemoteAddr mocks base method.
 func (m *MockQUICConn) RemoteAddr() net.Addr {
 	m.ctrl.T.Helper()
@@ -656,6 +771,82 @@ func (c *QUICConnSendDatagramCall) DoAndReturn(f func([]byte) error) *QUICConnSe
 	return c
 }
 
+// SendDatagramWithCallback mocks base method.
+func (m *MockQUICConn) SendDatagramWithCallback(arg0 []byte, arg1 func(DatagramState)) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendDatagramWithCallback", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendDatagramWithCallback indicates an expected call of SendDatagramWithCallback.
+func (mr *MockQUICConnMockRecorder) SendDatagramWithCallback(arg0, arg1 any) *QUICConnSendDatagramWithCallbackCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDatagramWithCallback", reflect.TypeOf((*MockQUICConn)(nil).SendDatagramWithCallback), arg0, arg1)
+	return &QUICConnSendDatagramWithCallbackCall{Call: call}
+}
+
+// QUICConnSendDatagramWithCallbackCall wrap *gomock.Call
+type QUICConnSendDatagramWithCallbackCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *QUICConnSendDatagramWithCallbackCall) Return(arg0 error) *QUICConnSendDatagramWithCallbackCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *QUICConnSendDatagramWithCallbackCall) Do(f func([]byte, func(DatagramState)) error) *QUICConnSendDatagramWithCallbackCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *QUICConnSendDatagramWithCallbackCall) DoAndReturn(f func([]byte, func(DatagramState)) error) *QUICConnSendDatagramWithCallbackCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Stats mocks base method.
+func (m *MockQUICConn) Stats() ConnectionStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(ConnectionStats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockQUICConnMockRecorder) Stats() *QUICConnStatsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockQUICConn)(nil).Stats))
+	return &QUICConnStatsCall{Call: call}
+}
+
+// QUICConnStatsCall wrap *gomock.Call
+type QUICConnStatsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *QUICConnStatsCall) Return(arg0 ConnectionStats) *QUICConnStatsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *QUICConnStatsCall) Do(f func() ConnectionStats) *QUICConnStatsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *QUICConnStatsCall) DoAndReturn(f func() ConnectionStats) *QUICConnStatsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // closeWithTransportError mocks base method.
 func (m *MockQUICConn) closeWithTransportError(arg0 qerr.TransportErrorCode) {
 	m.ctrl.T.Helper()
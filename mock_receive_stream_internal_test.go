@@ -154,6 +154,44 @@ func (c *ReceiveStreamISetReadDeadlineCall) DoAndReturn(f func(time.Time) error)
 	return c
 }
 
+// Stats mocks base method.
+func (m *MockReceiveStreamI) Stats() StreamStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(StreamStats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockReceiveStreamIMockRecorder) Stats() *ReceiveStreamIStatsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockReceiveStreamI)(nil).Stats))
+	return &ReceiveStreamIStatsCall{Call: call}
+}
+
+// ReceiveStreamIStatsCall wrap *gomock.Call
+type ReceiveStreamIStatsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *ReceiveStreamIStatsCall) Return(arg0 StreamStats) *ReceiveStreamIStatsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *ReceiveStreamIStatsCall) Do(f func() StreamStats) *ReceiveStreamIStatsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *ReceiveStreamIStatsCall) DoAndReturn(f func() StreamStats) *ReceiveStreamIStatsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // StreamID mocks base method.
 func (m *MockReceiveStreamI) StreamID() protocol.StreamID {
 	m.ctrl.T.Helper()
@@ -266,6 +304,44 @@ func (c *ReceiveStreamIgetWindowUpdateCall) DoAndReturn(f func() protocol.ByteCo
 	return c
 }
 
+// handleResetStreamAtFrame mocks base method.
+func (m *MockReceiveStreamI) handleResetStreamAtFrame(arg0 *wire.ResetStreamAtFrame) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "handleResetStreamAtFrame", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// handleResetStreamAtFrame indicates an expected call of handleResetStreamAtFrame.
+func (mr *MockReceiveStreamIMockRecorder) handleResetStreamAtFrame(arg0 any) *ReceiveStreamIhandleResetStreamAtFrameCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "handleResetStreamAtFrame", reflect.TypeOf((*MockReceiveStreamI)(nil).handleResetStreamAtFrame), arg0)
+	return &ReceiveStreamIhandleResetStreamAtFrameCall{Call: call}
+}
+
+// ReceiveStreamIhandleResetStreamAtFrameCall wrap *gomock.Call
+type ReceiveStreamIhandleResetStreamAtFrameCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *ReceiveStreamIhandleResetStreamAtFrameCall) Return(arg0 error) *ReceiveStreamIhandleResetStreamAtFrameCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *ReceiveStreamIhandleResetStreamAtFrameCall) Do(f func(*wire.ResetStreamAtFrame) error) *ReceiveStreamIhandleResetStreamAtFrameCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *ReceiveStreamIhandleResetStreamAtFrameCall) DoAndReturn(f func(*wire.ResetStreamAtFrame) error) *ReceiveStreamIhandleResetStreamAtFrameCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // handleResetStreamFrame mocks base method.
 func (m *MockReceiveStreamI) handleResetStreamFrame(arg0 *wire.ResetStreamFrame) error {
 	m.ctrl.T.Helper()
@@ -228,3 +228,41 @@ func (c *SendConncapabilitiesCall) DoAndReturn(f func() connCapabilities) *SendC
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// withRemoteAddr mocks base method.
+func (m *MockSendConn) withRemoteAddr(arg0 net.Addr, arg1 packetInfo) sendConn {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "withRemoteAddr", arg0, arg1)
+	ret0, _ := ret[0].(sendConn)
+	return ret0
+}
+
+// withRemoteAddr indicates an expected call of withRemoteAddr.
+func (mr *MockSendConnMockRecorder) withRemoteAddr(arg0, arg1 any) *SendConnwithRemoteAddrCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "withRemoteAddr", reflect.TypeOf((*MockSendConn)(nil).withRemoteAddr), arg0, arg1)
+	return &SendConnwithRemoteAddrCall{Call: call}
+}
+
+// SendConnwithRemoteAddrCall wrap *gomock.Call
+type SendConnwithRemoteAddrCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *SendConnwithRemoteAddrCall) Return(arg0 sendConn) *SendConnwithRemoteAddrCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *SendConnwithRemoteAddrCall) Do(f func(net.Addr, packetInfo) sendConn) *SendConnwithRemoteAddrCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *SendConnwithRemoteAddrCall) DoAndReturn(f func(net.Addr, packetInfo) sendConn) *SendConnwithRemoteAddrCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
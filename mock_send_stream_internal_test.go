@@ -79,6 +79,42 @@ func (c *SendStreamICancelWriteCall) DoAndReturn(f func(qerr.StreamErrorCode)) *
 	return c
 }
 
+// CancelWriteAt mocks base method.
+func (m *MockSendStreamI) CancelWriteAt(arg0 qerr.StreamErrorCode, arg1 uint64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CancelWriteAt", arg0, arg1)
+}
+
+// CancelWriteAt indicates an expected call of CancelWriteAt.
+func (mr *MockSendStreamIMockRecorder) CancelWriteAt(arg0, arg1 any) *SendStreamICancelWriteAtCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelWriteAt", reflect.TypeOf((*MockSendStreamI)(nil).CancelWriteAt), arg0, arg1)
+	return &SendStreamICancelWriteAtCall{Call: call}
+}
+
+// SendStreamICancelWriteAtCall wrap *gomock.Call
+type SendStreamICancelWriteAtCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *SendStreamICancelWriteAtCall) Return() *SendStreamICancelWriteAtCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *SendStreamICancelWriteAtCall) Do(f func(qerr.StreamErrorCode, uint64)) *SendStreamICancelWriteAtCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *SendStreamICancelWriteAtCall) DoAndReturn(f func(qerr.StreamErrorCode, uint64)) *SendStreamICancelWriteAtCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Close mocks base method.
 func (m *MockSendStreamI) Close() error {
 	m.ctrl.T.Helper()
@@ -155,6 +191,42 @@ func (c *SendStreamIContextCall) DoAndReturn(f func() context.Context) *SendStre
 	return c
 }
 
+// SetDeliveryDeadline mocks base method.
+func (m *MockSendStreamI) SetDeliveryDeadline(arg0 time.Duration, arg1 qerr.StreamErrorCode) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetDeliveryDeadline", arg0, arg1)
+}
+
+// SetDeliveryDeadline indicates an expected call of SetDeliveryDeadline.
+func (mr *MockSendStreamIMockRecorder) SetDeliveryDeadline(arg0, arg1 any) *SendStreamISetDeliveryDeadlineCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDeliveryDeadline", reflect.TypeOf((*MockSendStreamI)(nil).SetDeliveryDeadline), arg0, arg1)
+	return &SendStreamISetDeliveryDeadlineCall{Call: call}
+}
+
+// SendStreamISetDeliveryDeadlineCall wrap *gomock.Call
+type SendStreamISetDeliveryDeadlineCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *SendStreamISetDeliveryDeadlineCall) Return() *SendStreamISetDeliveryDeadlineCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *SendStreamISetDeliveryDeadlineCall) Do(f func(time.Duration, qerr.StreamErrorCode)) *SendStreamISetDeliveryDeadlineCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *SendStreamISetDeliveryDeadlineCall) DoAndReturn(f func(time.Duration, qerr.StreamErrorCode)) *SendStreamISetDeliveryDeadlineCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // SetWriteDeadline mocks base method.
 func (m *MockSendStreamI) SetWriteDeadline(arg0 time.Time) error {
 	m.ctrl.T.Helper()
@@ -193,6 +265,44 @@ func (c *SendStreamISetWriteDeadlineCall) DoAndReturn(f func(time.Time) error) *
 	return c
 }
 
+// Stats mocks base method.
+func (m *MockSendStreamI) Stats() StreamStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(StreamStats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockSendStreamIMockRecorder) Stats() *SendStreamIStatsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockSendStreamI)(nil).Stats))
+	return &SendStreamIStatsCall{Call: call}
+}
+
+// SendStreamIStatsCall wrap *gomock.Call
+type SendStreamIStatsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *SendStreamIStatsCall) Return(arg0 StreamStats) *SendStreamIStatsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *SendStreamIStatsCall) Do(f func() StreamStats) *SendStreamIStatsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *SendStreamIStatsCall) DoAndReturn(f func() StreamStats) *SendStreamIStatsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // StreamID mocks base method.
 func (m *MockSendStreamI) StreamID() protocol.StreamID {
 	m.ctrl.T.Helper()
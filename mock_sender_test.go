@@ -186,6 +186,42 @@ func (c *SenderSendCall) DoAndReturn(f func(*packetBuffer, uint16, protocol.ECN)
 	return c
 }
 
+// SetConn mocks base method.
+func (m *MockSender) SetConn(arg0 sendConn) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetConn", arg0)
+}
+
+// SetConn indicates an expected call of SetConn.
+func (mr *MockSenderMockRecorder) SetConn(arg0 any) *SenderSetConnCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetConn", reflect.TypeOf((*MockSender)(nil).SetConn), arg0)
+	return &SenderSetConnCall{Call: call}
+}
+
+// SenderSetConnCall wrap *gomock.Call
+type SenderSetConnCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *SenderSetConnCall) Return() *SenderSetConnCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *SenderSetConnCall) Do(f func(sendConn)) *SenderSetConnCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *SenderSetConnCall) DoAndReturn(f func(sendConn)) *SenderSetConnCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // WouldBlock mocks base method.
 func (m *MockSender) WouldBlock() bool {
 	m.ctrl.T.Helper()
@@ -115,6 +115,42 @@ func (c *StreamICancelWriteCall) DoAndReturn(f func(qerr.StreamErrorCode)) *Stre
 	return c
 }
 
+// CancelWriteAt mocks base method.
+func (m *MockStreamI) CancelWriteAt(arg0 qerr.StreamErrorCode, arg1 uint64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CancelWriteAt", arg0, arg1)
+}
+
+// CancelWriteAt indicates an expected call of CancelWriteAt.
+func (mr *MockStreamIMockRecorder) CancelWriteAt(arg0, arg1 any) *StreamICancelWriteAtCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelWriteAt", reflect.TypeOf((*MockStreamI)(nil).CancelWriteAt), arg0, arg1)
+	return &StreamICancelWriteAtCall{Call: call}
+}
+
+// StreamICancelWriteAtCall wrap *gomock.Call
+type StreamICancelWriteAtCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *StreamICancelWriteAtCall) Return() *StreamICancelWriteAtCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *StreamICancelWriteAtCall) Do(f func(qerr.StreamErrorCode, uint64)) *StreamICancelWriteAtCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *StreamICancelWriteAtCall) DoAndReturn(f func(qerr.StreamErrorCode, uint64)) *StreamICancelWriteAtCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Close mocks base method.
 func (m *MockStreamI) Close() error {
 	m.ctrl.T.Helper()
@@ -268,6 +304,42 @@ func (c *StreamISetDeadlineCall) DoAndReturn(f func(time.Time) error) *StreamISe
 	return c
 }
 
+// SetDeliveryDeadline mocks base method.
+func (m *MockStreamI) SetDeliveryDeadline(arg0 time.Duration, arg1 qerr.StreamErrorCode) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetDeliveryDeadline", arg0, arg1)
+}
+
+// SetDeliveryDeadline indicates an expected call of SetDeliveryDeadline.
+func (mr *MockStreamIMockRecorder) SetDeliveryDeadline(arg0, arg1 any) *StreamISetDeliveryDeadlineCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDeliveryDeadline", reflect.TypeOf((*MockStreamI)(nil).SetDeliveryDeadline), arg0, arg1)
+	return &StreamISetDeliveryDeadlineCall{Call: call}
+}
+
+// StreamISetDeliveryDeadlineCall wrap *gomock.Call
+type StreamISetDeliveryDeadlineCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *StreamISetDeliveryDeadlineCall) Return() *StreamISetDeliveryDeadlineCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *StreamISetDeliveryDeadlineCall) Do(f func(time.Duration, qerr.StreamErrorCode)) *StreamISetDeliveryDeadlineCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *StreamISetDeliveryDeadlineCall) DoAndReturn(f func(time.Duration, qerr.StreamErrorCode)) *StreamISetDeliveryDeadlineCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // SetReadDeadline mocks base method.
 func (m *MockStreamI) SetReadDeadline(arg0 time.Time) error {
 	m.ctrl.T.Helper()
@@ -344,6 +416,44 @@ func (c *StreamISetWriteDeadlineCall) DoAndReturn(f func(time.Time) error) *Stre
 	return c
 }
 
+// Stats mocks base method.
+func (m *MockStreamI) Stats() StreamStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(StreamStats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockStreamIMockRecorder) Stats() *StreamIStatsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockStreamI)(nil).Stats))
+	return &StreamIStatsCall{Call: call}
+}
+
+// StreamIStatsCall wrap *gomock.Call
+type StreamIStatsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *StreamIStatsCall) Return(arg0 StreamStats) *StreamIStatsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *StreamIStatsCall) Do(f func() StreamStats) *StreamIStatsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *StreamIStatsCall) DoAndReturn(f func() StreamStats) *StreamIStatsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // StreamID mocks base method.
 func (m *MockStreamI) StreamID() protocol.StreamID {
 	m.ctrl.T.Helper()
@@ -495,6 +605,44 @@ func (c *StreamIgetWindowUpdateCall) DoAndReturn(f func() protocol.ByteCount) *S
 	return c
 }
 
+// handleResetStreamAtFrame mocks base method.
+func (m *MockStreamI) handleResetStreamAtFrame(arg0 *wire.ResetStreamAtFrame) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "handleResetStreamAtFrame", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// handleResetStreamAtFrame indicates an expected call of handleResetStreamAtFrame.
+func (mr *MockStreamIMockRecorder) handleResetStreamAtFrame(arg0 any) *StreamIhandleResetStreamAtFrameCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "handleResetStreamAtFrame", reflect.TypeOf((*MockStreamI)(nil).handleResetStreamAtFrame), arg0)
+	return &StreamIhandleResetStreamAtFrameCall{Call: call}
+}
+
+// StreamIhandleResetStreamAtFrameCall wrap *gomock.Call
+type StreamIhandleResetStreamAtFrameCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *StreamIhandleResetStreamAtFrameCall) Return(arg0 error) *StreamIhandleResetStreamAtFrameCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *StreamIhandleResetStreamAtFrameCall) Do(f func(*wire.ResetStreamAtFrame) error) *StreamIhandleResetStreamAtFrameCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *StreamIhandleResetStreamAtFrameCall) DoAndReturn(f func(*wire.ResetStreamAtFrame) error) *StreamIhandleResetStreamAtFrameCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // handleResetStreamFrame mocks base method.
 func (m *MockStreamI) handleResetStreamFrame(arg0 *wire.ResetStreamFrame) error {
 	m.ctrl.T.Helper()
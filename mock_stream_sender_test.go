@@ -111,6 +111,80 @@ func (c *StreamSenderonStreamCompletedCall) DoAndReturn(f func(protocol.StreamID
 	return c
 }
 
+// onStreamDataExpired mocks base method.
+func (m *MockStreamSender) onStreamDataExpired(arg0 protocol.StreamID, arg1, arg2 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "onStreamDataExpired", arg0, arg1, arg2)
+}
+
+// onStreamDataExpired indicates an expected call of onStreamDataExpired.
+func (mr *MockStreamSenderMockRecorder) onStreamDataExpired(arg0, arg1, arg2 any) *StreamSenderonStreamDataExpiredCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "onStreamDataExpired", reflect.TypeOf((*MockStreamSender)(nil).onStreamDataExpired), arg0, arg1, arg2)
+	return &StreamSenderonStreamDataExpiredCall{Call: call}
+}
+
+// StreamSenderonStreamDataExpiredCall wrap *gomock.Call
+type StreamSenderonStreamDataExpiredCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *StreamSenderonStreamDataExpiredCall) Return() *StreamSenderonStreamDataExpiredCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *StreamSenderonStreamDataExpiredCall) Do(f func(protocol.StreamID, protocol.ByteCount, protocol.ByteCount)) *StreamSenderonStreamDataExpiredCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *StreamSenderonStreamDataExpiredCall) DoAndReturn(f func(protocol.StreamID, protocol.ByteCount, protocol.ByteCount)) *StreamSenderonStreamDataExpiredCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// peerSupportsResetStreamAt mocks base method.
+func (m *MockStreamSender) peerSupportsResetStreamAt() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "peerSupportsResetStreamAt")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// peerSupportsResetStreamAt indicates an expected call of peerSupportsResetStreamAt.
+func (mr *MockStreamSenderMockRecorder) peerSupportsResetStreamAt() *StreamSenderpeerSupportsResetStreamAtCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "peerSupportsResetStreamAt", reflect.TypeOf((*MockStreamSender)(nil).peerSupportsResetStreamAt))
+	return &StreamSenderpeerSupportsResetStreamAtCall{Call: call}
+}
+
+// StreamSenderpeerSupportsResetStreamAtCall wrap *gomock.Call
+type StreamSenderpeerSupportsResetStreamAtCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *StreamSenderpeerSupportsResetStreamAtCall) Return(arg0 bool) *StreamSenderpeerSupportsResetStreamAtCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *StreamSenderpeerSupportsResetStreamAtCall) Do(f func() bool) *StreamSenderpeerSupportsResetStreamAtCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *StreamSenderpeerSupportsResetStreamAtCall) DoAndReturn(f func() bool) *StreamSenderpeerSupportsResetStreamAtCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // queueControlFrame mocks base method.
 func (m *MockStreamSender) queueControlFrame(arg0 wire.Frame) {
 	m.ctrl.T.Helper()
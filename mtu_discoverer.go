@@ -8,6 +8,7 @@ import (
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/internal/wire"
+	"github.com/quic-go/quic-go/logging"
 )
 
 type mtuDiscoverer interface {
@@ -17,6 +18,11 @@ type mtuDiscoverer interface {
 	ShouldSendProbe(now time.Time) bool
 	CurrentSize() protocol.ByteCount
 	GetPing() (ping ackhandler.Frame, datagramSize protocol.ByteCount)
+	// Reset is called when the connection migrates to a new path.
+	// It falls back to the base size and restarts the search.
+	Reset()
+	// The MTU discoverer observes the fate of 1-RTT packets to detect black holes.
+	ackhandler.PacketSizeObserver
 }
 
 const (
@@ -25,6 +31,16 @@ const (
 	maxMTUDiff = 20
 	// send a probe packet every mtuProbeDelay RTTs
 	mtuProbeDelay = 5
+	// The number of times a probe packet of a given size is lost before concluding that it doesn't fit the path.
+	// This is MAX_PROBES of RFC 8899.
+	maxMTUProbes = 3
+	// Once the search has completed, probe for a larger MTU again after this time.
+	// This is PMTU_RAISE_TIMER of RFC 8899.
+	mtuRaiseInterval = 10 * time.Minute
+	// A black hole is detected if this number of packets larger than the base PLPMTU are lost in a row,
+	// or if the PTO fires this number of times in a row for such packets without any packet being acknowledged.
+	maxLostFullSizePackets = 5
+	maxPTOsWithoutAck      = 2
 )
 
 func getMaxPacketSize(addr net.Addr) protocol.ByteCount {
@@ -43,22 +59,34 @@ func getMaxPacketSize(addr net.Addr) protocol.ByteCount {
 
 type mtuFinder struct {
 	lastProbeTime time.Time
-	mtuIncreased  func(protocol.ByteCount)
+	mtuChanged    func(protocol.ByteCount)
 
 	rttStats *utils.RTTStats
-	inFlight protocol.ByteCount // the size of the probe packet currently in flight. InvalidByteCount if none is in flight
-	current  protocol.ByteCount
-	max      protocol.ByteCount // the maximum value, as advertised by the peer (or our maximum size buffer)
+	tracer   *logging.ConnectionTracer
+
+	inFlight      protocol.ByteCount // the size of the probe packet currently in flight. InvalidByteCount if none is in flight
+	staleProbe    bool               // the probe packet in flight was sent before migrating to a new path, or before detecting a black hole
+	numProbesLost int                // the number of probe packets of the current probe size that were lost
+	base          protocol.ByteCount // the size that is assumed to work on every path (the BASE_PLPMTU)
+	current       protocol.ByteCount
+	max           protocol.ByteCount // the upper bound of the current search
+	maxSize       protocol.ByteCount // the maximum value, as advertised by the peer (or our maximum size buffer)
+
+	// used for black hole detection
+	numLostFullSize int // the number of packets larger than the base size lost since the last such packet was acknowledged
+	numPTOsSinceAck int
 }
 
 var _ mtuDiscoverer = &mtuFinder{}
 
-func newMTUDiscoverer(rttStats *utils.RTTStats, start protocol.ByteCount, mtuIncreased func(protocol.ByteCount)) *mtuFinder {
+func newMTUDiscoverer(rttStats *utils.RTTStats, start protocol.ByteCount, mtuChanged func(protocol.ByteCount), tracer *logging.ConnectionTracer) *mtuFinder {
 	return &mtuFinder{
-		inFlight:     protocol.InvalidByteCount,
-		current:      start,
-		rttStats:     rttStats,
-		mtuIncreased: mtuIncreased,
+		inFlight:   protocol.InvalidByteCount,
+		base:       start,
+		current:    start,
+		rttStats:   rttStats,
+		mtuChanged: mtuChanged,
+		tracer:     tracer,
 	}
 }
 
@@ -69,15 +97,27 @@ func (f *mtuFinder) done() bool {
 func (f *mtuFinder) Start(maxPacketSize protocol.ByteCount) {
 	f.lastProbeTime = time.Now() // makes sure the first probe packet is not sent immediately
 	f.max = maxPacketSize
+	f.maxSize = maxPacketSize
 }
 
 func (f *mtuFinder) ShouldSendProbe(now time.Time) bool {
 	if f.max == 0 || f.lastProbeTime.IsZero() {
 		return false
 	}
-	if f.inFlight != protocol.InvalidByteCount || f.done() {
+	if f.inFlight != protocol.InvalidByteCount {
 		return false
 	}
+	if f.done() {
+		// The path might have changed since the search completed.
+		// Periodically check if a larger MTU is supported.
+		if f.max >= f.maxSize || now.Before(f.lastProbeTime.Add(mtuRaiseInterval)) {
+			return false
+		}
+		f.max = f.maxSize
+		if f.done() {
+			return false
+		}
+	}
 	return !now.Before(f.lastProbeTime.Add(mtuProbeDelay * f.rttStats.SmoothedRTT()))
 }
 
@@ -91,10 +131,80 @@ func (f *mtuFinder) GetPing() (ackhandler.Frame, protocol.ByteCount) {
 	}, size
 }
 
+func (f *mtuFinder) Reset() {
+	if f.max == 0 { // MTU discovery was never started
+		return
+	}
+	f.lastProbeTime = time.Now()
+	f.max = f.maxSize
+	f.numProbesLost = 0
+	f.numLostFullSize = 0
+	f.numPTOsSinceAck = 0
+	// The outcome of a probe packet sent on the old path says nothing about the new path.
+	f.staleProbe = f.inFlight != protocol.InvalidByteCount
+	if f.current != f.base {
+		f.setCurrent(f.base)
+	}
+}
+
 func (f *mtuFinder) CurrentSize() protocol.ByteCount {
 	return f.current
 }
 
+func (f *mtuFinder) OnPacketAcked(size protocol.ByteCount) {
+	f.numPTOsSinceAck = 0
+	if size > f.base {
+		f.numLostFullSize = 0
+	}
+}
+
+func (f *mtuFinder) OnPacketLost(size protocol.ByteCount) {
+	// Packets larger than the current size were sent before a black hole was detected.
+	if size <= f.base || size > f.current {
+		return
+	}
+	f.numLostFullSize++
+	if f.numLostFullSize >= maxLostFullSizePackets {
+		f.onBlackHole()
+	}
+}
+
+func (f *mtuFinder) OnPTO(smallestSize protocol.ByteCount) {
+	// If packets not larger than the base size are lost as well, the PTO is not caused by the packet size.
+	if smallestSize <= f.base || smallestSize > f.current {
+		return
+	}
+	f.numPTOsSinceAck++
+	if f.numPTOsSinceAck >= maxPTOsWithoutAck {
+		f.onBlackHole()
+	}
+}
+
+// onBlackHole falls back to the base size, and restarts the search.
+// The size that was used so far was acknowledged before, so the path must have changed.
+// Instead of capping the search at that size, the search is restarted over the full range.
+// This is the only case where the maximum datagram size decreases. The congestion controller is informed
+// via SetMaxDatagramSize, and caps its congestion window at the maximum window for the smaller size.
+func (f *mtuFinder) onBlackHole() {
+	f.numLostFullSize = 0
+	f.numPTOsSinceAck = 0
+	if f.current <= f.base {
+		return
+	}
+	f.max = f.maxSize
+	f.numProbesLost = 0
+	f.staleProbe = f.inFlight != protocol.InvalidByteCount
+	f.setCurrent(f.base)
+}
+
+func (f *mtuFinder) setCurrent(size protocol.ByteCount) {
+	f.current = size
+	f.mtuChanged(size)
+	if f.tracer != nil && f.tracer.UpdatedMTU != nil {
+		f.tracer.UpdatedMTU(size, f.done())
+	}
+}
+
 type mtuFinderAckHandler mtuFinder
 
 var _ ackhandler.FrameHandler = &mtuFinderAckHandler{}
@@ -105,8 +215,12 @@ func (h *mtuFinderAckHandler) OnAcked(wire.Frame) {
 		panic("OnAcked callback called although there's no MTU probe packet in flight")
 	}
 	h.inFlight = protocol.InvalidByteCount
-	h.current = size
-	h.mtuIncreased(size)
+	if h.staleProbe {
+		h.staleProbe = false
+		return
+	}
+	h.numProbesLost = 0
+	(*mtuFinder)(h).setCurrent(size)
 }
 
 func (h *mtuFinderAckHandler) OnLost(wire.Frame) {
@@ -114,6 +228,17 @@ func (h *mtuFinderAckHandler) OnLost(wire.Frame) {
 	if size == protocol.InvalidByteCount {
 		panic("OnLost callback called although there's no MTU probe packet in flight")
 	}
-	h.max = size
 	h.inFlight = protocol.InvalidByteCount
+	if h.staleProbe {
+		h.staleProbe = false
+		return
+	}
+	h.numProbesLost++
+	if h.numProbesLost < maxMTUProbes {
+		return
+	}
+	h.numProbesLost = 0
+	if size > h.current && size < h.max {
+		h.max = size
+	}
 }
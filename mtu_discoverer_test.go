@@ -4,6 +4,7 @@ import (
 	"math/rand"
 	"time"
 
+	mocklogging "github.com/quic-go/quic-go/internal/mocks/logging"
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/utils"
 
@@ -29,7 +30,7 @@ var _ = Describe("MTU Discoverer", func() {
 		rttStats = &utils.RTTStats{}
 		rttStats.SetInitialRTT(rtt)
 		Expect(rttStats.SmoothedRTT()).To(Equal(rtt))
-		d = newMTUDiscoverer(rttStats, startMTU, func(s protocol.ByteCount) { discoveredMTU = s })
+		d = newMTUDiscoverer(rttStats, startMTU, func(s protocol.ByteCount) { discoveredMTU = s }, nil)
 		d.Start(maxMTU)
 		now = time.Now()
 	})
@@ -47,11 +48,13 @@ var _ = Describe("MTU Discoverer", func() {
 		Expect(d.ShouldSendProbe(now.Add(10 * rtt))).To(BeTrue())
 	})
 
-	It("tries a lower size when a probe is lost", func() {
-		ping, size := d.GetPing()
-		Expect(size).To(Equal(protocol.ByteCount(1500)))
-		ping.Handler.OnLost(ping.Frame)
-		_, size = d.GetPing()
+	It("tries a lower size when a probe is lost repeatedly", func() {
+		for i := 0; i < maxMTUProbes; i++ {
+			ping, size := d.GetPing()
+			Expect(size).To(Equal(protocol.ByteCount(1500)))
+			ping.Handler.OnLost(ping.Frame)
+		}
+		_, size := d.GetPing()
 		Expect(size).To(Equal(protocol.ByteCount(1250)))
 	})
 
@@ -78,7 +81,7 @@ var _ = Describe("MTU Discoverer", func() {
 	})
 
 	It("doesn't do discovery before being started", func() {
-		d := newMTUDiscoverer(rttStats, startMTU, func(s protocol.ByteCount) {})
+		d := newMTUDiscoverer(rttStats, startMTU, func(s protocol.ByteCount) {}, nil)
 		for i := 0; i < 5; i++ {
 			Expect(d.ShouldSendProbe(time.Now())).To(BeFalse())
 		}
@@ -90,14 +93,14 @@ var _ = Describe("MTU Discoverer", func() {
 		for i := 0; i < rep; i++ {
 			maxMTU := protocol.ByteCount(rand.Intn(int(3000-startMTU))) + startMTU + 1
 			currentMTU := startMTU
-			d := newMTUDiscoverer(rttStats, startMTU, func(s protocol.ByteCount) { currentMTU = s })
+			d := newMTUDiscoverer(rttStats, startMTU, func(s protocol.ByteCount) { currentMTU = s }, nil)
 			d.Start(maxMTU)
 			now := time.Now()
 			realMTU := protocol.ByteCount(rand.Intn(int(maxMTU-startMTU))) + startMTU
 			t := now.Add(mtuProbeDelay * rtt)
 			var count int
 			for d.ShouldSendProbe(t) {
-				if count > 25 {
+				if count > 25*maxMTUProbes {
 					Fail("too many iterations")
 				}
 				count++
@@ -116,4 +119,159 @@ var _ = Describe("MTU Discoverer", func() {
 		}
 		Expect(maxDiff).To(BeEquivalentTo(maxMTUDiff))
 	})
+
+	It("traces MTU changes", func() {
+		tr, tracer := mocklogging.NewMockConnectionTracer(mockCtrl)
+		d := newMTUDiscoverer(rttStats, startMTU, func(s protocol.ByteCount) {}, tr)
+		d.Start(1080)
+		ping, size := d.GetPing()
+		Expect(size).To(Equal(protocol.ByteCount(1040)))
+		tracer.EXPECT().UpdatedMTU(protocol.ByteCount(1040), false)
+		ping.Handler.OnAcked(ping.Frame)
+		ping, size = d.GetPing()
+		Expect(size).To(Equal(protocol.ByteCount(1060)))
+		tracer.EXPECT().UpdatedMTU(protocol.ByteCount(1060), true)
+		ping.Handler.OnAcked(ping.Frame)
+		// detect a black hole
+		tracer.EXPECT().UpdatedMTU(startMTU, false)
+		for i := 0; i < maxLostFullSizePackets; i++ {
+			d.OnPacketLost(1060)
+		}
+	})
+
+	It("re-probes for a larger MTU after the search completed", func() {
+		t := now.Add(5 * rtt)
+		for d.ShouldSendProbe(t) {
+			ping, size := d.GetPing()
+			if size <= 1500 {
+				ping.Handler.OnAcked(ping.Frame)
+			} else {
+				ping.Handler.OnLost(ping.Frame)
+			}
+			t = t.Add(5 * rtt)
+		}
+		Expect(d.CurrentSize()).To(BeNumerically(">", 1500-maxMTUDiff))
+		Expect(d.ShouldSendProbe(time.Now().Add(mtuRaiseInterval / 2))).To(BeFalse())
+		Expect(d.ShouldSendProbe(time.Now().Add(mtuRaiseInterval + time.Second))).To(BeTrue())
+		// the search upper bound is reset to the maximum
+		_, size := d.GetPing()
+		Expect(size).To(Equal((d.CurrentSize() + maxMTU) / 2))
+	})
+
+	It("falls back to the base size and restarts the search after migrating to a new path", func() {
+		ping, size := d.GetPing()
+		Expect(size).To(Equal(protocol.ByteCount(1500)))
+		ping.Handler.OnAcked(ping.Frame)
+		Expect(d.CurrentSize()).To(Equal(protocol.ByteCount(1500)))
+		ping, size = d.GetPing()
+		Expect(size).To(Equal(protocol.ByteCount(1750)))
+
+		d.Reset()
+		Expect(d.CurrentSize()).To(Equal(startMTU))
+		Expect(discoveredMTU).To(Equal(startMTU))
+		// the probe packet sent on the old path is ignored
+		ping.Handler.OnAcked(ping.Frame)
+		Expect(d.CurrentSize()).To(Equal(startMTU))
+		Expect(d.ShouldSendProbe(time.Now().Add(5 * rtt))).To(BeTrue())
+		_, size = d.GetPing()
+		Expect(size).To(Equal((startMTU + maxMTU) / 2))
+	})
+
+	It("doesn't reset before being started", func() {
+		d = newMTUDiscoverer(rttStats, startMTU, func(protocol.ByteCount) { Fail("MTU changed") }, nil)
+		d.Reset()
+		Expect(d.CurrentSize()).To(Equal(startMTU))
+		Expect(d.ShouldSendProbe(now.Add(10 * rtt))).To(BeFalse())
+	})
+
+	Context("black hole detection", func() {
+		const blackHoleMTU protocol.ByteCount = 1500
+
+		BeforeEach(func() {
+			ping, size := d.GetPing()
+			Expect(size).To(Equal(blackHoleMTU))
+			ping.Handler.OnAcked(ping.Frame)
+			Expect(d.CurrentSize()).To(Equal(blackHoleMTU))
+		})
+
+		It("falls back to the base size when packets are lost", func() {
+			for i := 0; i < maxLostFullSizePackets-1; i++ {
+				d.OnPacketLost(blackHoleMTU)
+			}
+			Expect(d.CurrentSize()).To(Equal(blackHoleMTU))
+			d.OnPacketLost(blackHoleMTU)
+			Expect(d.CurrentSize()).To(Equal(startMTU))
+			Expect(discoveredMTU).To(Equal(startMTU))
+			// restart the search over the full range, since the size was acknowledged before
+			Expect(d.ShouldSendProbe(time.Now().Add(5 * rtt))).To(BeTrue())
+			_, size := d.GetPing()
+			Expect(size).To(Equal((startMTU + maxMTU) / 2))
+		})
+
+		It("ignores the loss of packets that are not larger than the base size", func() {
+			for i := 0; i < 2*maxLostFullSizePackets; i++ {
+				d.OnPacketLost(startMTU)
+			}
+			Expect(d.CurrentSize()).To(Equal(blackHoleMTU))
+		})
+
+		It("resets the counter when a large packet is acknowledged", func() {
+			for i := 0; i < 2*maxLostFullSizePackets; i++ {
+				d.OnPacketLost(blackHoleMTU)
+				if i%2 == 0 {
+					d.OnPacketAcked(startMTU)
+				} else {
+					d.OnPacketAcked(blackHoleMTU)
+				}
+			}
+			Expect(d.CurrentSize()).To(Equal(blackHoleMTU))
+		})
+
+		It("falls back to the base size after repeated PTOs", func() {
+			d.OnPTO(blackHoleMTU)
+			d.OnPacketAcked(startMTU)
+			d.OnPTO(blackHoleMTU)
+			Expect(d.CurrentSize()).To(Equal(blackHoleMTU))
+			d.OnPTO(blackHoleMTU)
+			Expect(d.CurrentSize()).To(Equal(startMTU))
+		})
+
+		It("ignores PTOs when packets not larger than the base size are in flight", func() {
+			for i := 0; i < 2*maxPTOsWithoutAck; i++ {
+				d.OnPTO(startMTU)
+			}
+			Expect(d.CurrentSize()).To(Equal(blackHoleMTU))
+		})
+
+		It("ignores losses of packets sent before the black hole was detected", func() {
+			for i := 0; i < maxLostFullSizePackets; i++ {
+				d.OnPacketLost(blackHoleMTU)
+			}
+			Expect(d.CurrentSize()).To(Equal(startMTU))
+			for i := 0; i < maxMTUProbes; i++ {
+				ping, size := d.GetPing()
+				Expect(size).To(Equal(blackHoleMTU))
+				ping.Handler.OnLost(ping.Frame)
+			}
+			ping, size := d.GetPing()
+			Expect(size).To(Equal((startMTU + blackHoleMTU) / 2))
+			ping.Handler.OnAcked(ping.Frame)
+			Expect(d.CurrentSize()).To(Equal(size))
+			for i := 0; i < maxLostFullSizePackets; i++ {
+				d.OnPacketLost(blackHoleMTU)
+			}
+			Expect(d.CurrentSize()).To(Equal(size))
+		})
+
+		It("ignores probe packets sent before the black hole was detected", func() {
+			ping, size := d.GetPing()
+			Expect(size).To(BeNumerically(">", blackHoleMTU))
+			for i := 0; i < maxLostFullSizePackets; i++ {
+				d.OnPacketLost(blackHoleMTU)
+			}
+			Expect(d.CurrentSize()).To(Equal(startMTU))
+			ping.Handler.OnAcked(ping.Frame)
+			Expect(d.CurrentSize()).To(Equal(startMTU))
+		})
+	})
 })
@@ -95,7 +95,7 @@ var _ = Describe("Packet packer", func() {
 		ackFramer = NewMockAckFrameSource(mockCtrl)
 		sealingManager = NewMockSealingManager(mockCtrl)
 		pnManager = mockackhandler.NewMockSentPacketHandler(mockCtrl)
-		datagramQueue = newDatagramQueue(func() {}, utils.DefaultLogger)
+		datagramQueue = newDatagramQueue(func() {}, populateConfig(nil), nil, utils.DefaultLogger)
 
 		packer = newPacketPacker(protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8}), func() protocol.ConnectionID { return connID }, initialStream, handshakeStream, pnManager, retransmissionQueue, sealingManager, framer, ackFramer, datagramQueue, protocol.PerspectiveServer)
 	})
@@ -617,6 +617,29 @@ var _ = Describe("Packet packer", func() {
 				Eventually(done).Should(BeClosed())
 			})
 
+			It("sets the ack handler for a DATAGRAM frame with a callback", func() {
+				ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, true)
+				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
+				pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
+				sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
+				f := &wire.DatagramFrame{
+					DataLenPresent: true,
+					Data:           []byte("foobar"),
+				}
+				var state DatagramState
+				Expect(datagramQueue.AddWithCallback(f, func(s DatagramState) { state = s })).To(Succeed())
+
+				framer.EXPECT().HasData()
+				buffer := getPacketBuffer()
+				p, err := packer.AppendPacket(buffer, maxPacketSize, protocol.Version1)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p.Frames).To(HaveLen(1))
+				Expect(p.Frames[0].Frame).To(Equal(f))
+				Expect(p.Frames[0].Handler).ToNot(BeNil())
+				p.Frames[0].Handler.OnAcked(p.Frames[0].Frame)
+				Expect(state).To(Equal(DatagramAcked))
+			})
+
 			It("doesn't pack a DATAGRAM frame if the ACK frame is too large", func() {
 				ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, true).Return(&wire.AckFrame{AckRanges: []wire.AckRange{{Largest: 100}}})
 				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
@@ -674,6 +697,23 @@ var _ = Describe("Packet packer", func() {
 				Eventually(done).Should(BeClosed())
 			})
 
+			It("reports a discarded DATAGRAM frame as dropped", func() {
+				ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, true)
+				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
+				sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
+				f := &wire.DatagramFrame{
+					DataLenPresent: true,
+					Data:           make([]byte, maxPacketSize+10), // won't fit
+				}
+				var state DatagramState
+				Expect(datagramQueue.AddWithCallback(f, func(s DatagramState) { state = s })).To(Succeed())
+
+				framer.EXPECT().HasData()
+				_, err := packer.AppendPacket(getPacketBuffer(), maxPacketSize, protocol.Version1)
+				Expect(err).To(MatchError(errNothingToPack))
+				Expect(state).To(Equal(DatagramDropped))
+			})
+
 			It("accounts for the space consumed by control frames", func() {
 				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
 				sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
@@ -1533,6 +1573,21 @@ var _ = Describe("Packet packer", func() {
 				Expect(buffer.Data).To(HaveLen(int(probePacketSize)))
 				Expect(p.IsPathMTUProbePacket).To(BeTrue())
 			})
+
+			It("packs a path probe packet", func() {
+				sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
+				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x43), protocol.PacketNumberLen2)
+				pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x43))
+				connID := protocol.ParseConnectionID([]byte{1, 3, 3, 7})
+				challenge := ackhandler.Frame{Frame: &wire.PathChallengeFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}}}
+				p, buffer, err := packer.PackPathProbePacket(connID, challenge, protocol.Version1)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p.Length).To(BeEquivalentTo(protocol.MinInitialPacketSize))
+				Expect(p.DestConnID).To(Equal(connID))
+				Expect(p.Frames).To(Equal([]ackhandler.Frame{challenge}))
+				Expect(buffer.Data).To(HaveLen(protocol.MinInitialPacketSize))
+				Expect(p.IsPathMTUProbePacket).To(BeFalse())
+			})
 		})
 	})
 })
@@ -61,7 +61,7 @@ func (u *packetUnpacker) UnpackLongHeader(hdr *wire.Header, rcvTime time.Time, d
 	switch hdr.Type {
 	case protocol.PacketTypeInitial:
 		encLevel = protocol.EncryptionInitial
-		opener, err := u.cs.GetInitialOpener()
+		opener, err := u.cs.GetInitialOpener(v)
 		if err != nil {
 			return nil, err
 		}
@@ -93,7 +93,7 @@ var _ = Describe("Packet Unpacker", func() {
 		hdr, hdrRaw := getLongHeader(extHdr)
 		opener := mocks.NewMockLongHeaderOpener(mockCtrl)
 		gomock.InOrder(
-			cs.EXPECT().GetInitialOpener().Return(opener, nil),
+			cs.EXPECT().GetInitialOpener(protocol.Version1).Return(opener, nil),
 			opener.EXPECT().DecryptHeader(gomock.Any(), gomock.Any(), gomock.Any()),
 			opener.EXPECT().DecodePacketNumber(protocol.PacketNumber(2), protocol.PacketNumberLen3).Return(protocol.PacketNumber(1234)),
 			opener.EXPECT().Open(gomock.Any(), payload, protocol.PacketNumber(1234), hdrRaw).Return([]byte("decrypted"), nil),
@@ -0,0 +1,427 @@
+package quic
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/utils"
+)
+
+// The file-backed caches keep all entries in memory, and write them to the file in the background after every change.
+// Changes that happen while the file is being written are coalesced into the next write.
+// Writes are atomic: the data is written to a temporary file, which is then renamed.
+// A process that crashes while writing therefore never leaves a corrupted cache file behind,
+// but changes that haven't been written yet are lost if the process exits without calling Flush.
+// A cache file that can't be decoded is treated like an empty cache.
+
+type fileTokenStoreToken struct {
+	Data     []byte    `json:"data"`
+	Received time.Time `json:"received"`
+}
+
+type fileTokenStoreOrigin struct {
+	Key    string                `json:"key"`
+	Tokens []fileTokenStoreToken `json:"tokens"` // oldest first
+}
+
+// A FileTokenStore is a TokenStore that persists tokens to a file.
+type FileTokenStore struct {
+	mutex sync.Mutex
+
+	maxOrigins      int
+	tokensPerOrigin int
+	maxAge          time.Duration
+
+	origins []*fileTokenStoreOrigin // most recently used first
+	writer  *cacheFileWriter
+}
+
+var _ TokenStore = &FileTokenStore{}
+
+// NewFileTokenStore creates a TokenStore that persists tokens to a file.
+// This allows a client to skip address validation on the first connection after a restart.
+// Tokens are loaded from the file if it exists. If the file can't be decoded, the token store starts out empty.
+// Changes are written to the file in the background, Flush can be used to wait for them to be written.
+// maxOrigins specifies how many origins this cache is saving tokens for.
+// tokensPerOrigin specifies the maximum number of tokens per origin.
+// Tokens older than maxAge are discarded. If maxAge is 0, it defaults to 24 hours.
+func NewFileTokenStore(filename string, maxOrigins, tokensPerOrigin int, maxAge time.Duration) (*FileTokenStore, error) {
+	if maxOrigins < 1 || tokensPerOrigin < 1 {
+		return nil, errors.New("invalid token store size")
+	}
+	if maxAge == 0 {
+		maxAge = 24 * time.Hour
+	}
+	logger := utils.DefaultLogger.WithPrefix("token store")
+	loaded, err := readCacheFile[*fileTokenStoreOrigin](filename, logger)
+	if err != nil {
+		return nil, err
+	}
+	s := &FileTokenStore{
+		maxOrigins:      maxOrigins,
+		tokensPerOrigin: tokensPerOrigin,
+		maxAge:          maxAge,
+	}
+	s.writer = newCacheFileWriter(filename, s.marshal, logger)
+	now := time.Now()
+	origins := make([]*fileTokenStoreOrigin, 0, len(loaded))
+	for _, o := range loaded {
+		s.removeExpired(o, now)
+		if len(o.Tokens) > s.tokensPerOrigin {
+			o.Tokens = o.Tokens[len(o.Tokens)-s.tokensPerOrigin:]
+		}
+		if len(o.Tokens) > 0 && len(origins) < s.maxOrigins {
+			origins = append(origins, o)
+		}
+	}
+	s.origins = origins
+	return s, nil
+}
+
+func (s *FileTokenStore) Put(key string, token *ClientToken) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	o, i := s.get(key)
+	if o == nil {
+		o = &fileTokenStoreOrigin{Key: key}
+		if len(s.origins) >= s.maxOrigins {
+			s.origins = s.origins[:s.maxOrigins-1]
+		}
+	} else {
+		s.origins = append(s.origins[:i], s.origins[i+1:]...)
+	}
+	s.origins = append([]*fileTokenStoreOrigin{o}, s.origins...)
+	o.Tokens = append(o.Tokens, fileTokenStoreToken{Data: token.data, Received: time.Now()})
+	if len(o.Tokens) > s.tokensPerOrigin {
+		o.Tokens = o.Tokens[len(o.Tokens)-s.tokensPerOrigin:]
+	}
+	s.persist()
+}
+
+func (s *FileTokenStore) Pop(key string) *ClientToken {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	o, i := s.get(key)
+	if o == nil {
+		return nil
+	}
+	s.removeExpired(o, time.Now())
+	var token *ClientToken
+	if len(o.Tokens) > 0 {
+		token = &ClientToken{data: o.Tokens[len(o.Tokens)-1].Data}
+		o.Tokens = o.Tokens[:len(o.Tokens)-1]
+	}
+	s.origins = append(s.origins[:i], s.origins[i+1:]...)
+	if len(o.Tokens) > 0 {
+		s.origins = append([]*fileTokenStoreOrigin{o}, s.origins...)
+	}
+	// Tokens are not supposed to be reused, so we need to persist the removal.
+	s.persist()
+	return token
+}
+
+func (s *FileTokenStore) get(key string) (*fileTokenStoreOrigin, int) {
+	for i, o := range s.origins {
+		if o.Key == key {
+			return o, i
+		}
+	}
+	return nil, -1
+}
+
+func (s *FileTokenStore) removeExpired(o *fileTokenStoreOrigin, now time.Time) {
+	tokens := o.Tokens[:0]
+	for _, t := range o.Tokens {
+		if now.Sub(t.Received) < s.maxAge {
+			tokens = append(tokens, t)
+		}
+	}
+	o.Tokens = tokens
+}
+
+// Flush blocks until all changes made to the token store have been written to the file.
+func (s *FileTokenStore) Flush() {
+	s.writer.Flush()
+}
+
+func (s *FileTokenStore) persist() {
+	s.writer.Schedule()
+}
+
+func (s *FileTokenStore) marshal() ([]byte, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return json.Marshal(s.origins)
+}
+
+type fileSessionCacheEntry struct {
+	Key    string    `json:"key"`
+	Ticket []byte    `json:"ticket"`
+	State  []byte    `json:"state"`
+	Added  time.Time `json:"added"`
+}
+
+// A FileClientSessionCache is a tls.ClientSessionCache that persists session tickets to a file.
+type FileClientSessionCache struct {
+	mutex sync.Mutex
+
+	capacity int
+	maxAge   time.Duration
+
+	entries []*fileSessionCacheEntry // most recently used first
+	logger  utils.Logger
+	writer  *cacheFileWriter
+}
+
+var _ tls.ClientSessionCache = &FileClientSessionCache{}
+
+// NewFileClientSessionCache creates a tls.ClientSessionCache that persists session tickets to a file.
+// quic-go stores the server's transport parameters and the RTT in the session state.
+// Both are persisted, which allows a client to use 0-RTT on the first connection after a restart.
+// Session tickets are loaded from the file if it exists. If the file can't be decoded, the cache starts out empty.
+// Changes are written to the file in the background, Flush can be used to wait for them to be written.
+// capacity specifies the maximum number of session tickets saved.
+// Session tickets older than maxAge are discarded. If maxAge is 0, it defaults to 24 hours.
+// Independent of maxAge, the lifetime of the session ticket sent by the server is respected by crypto/tls.
+//
+// The file contains the resumption secrets. It must be protected accordingly.
+func NewFileClientSessionCache(filename string, capacity int, maxAge time.Duration) (*FileClientSessionCache, error) {
+	if capacity < 1 {
+		return nil, errors.New("invalid session cache capacity")
+	}
+	if maxAge == 0 {
+		maxAge = 24 * time.Hour
+	}
+	logger := utils.DefaultLogger.WithPrefix("session cache")
+	loaded, err := readCacheFile[*fileSessionCacheEntry](filename, logger)
+	if err != nil {
+		return nil, err
+	}
+	c := &FileClientSessionCache{
+		capacity: capacity,
+		maxAge:   maxAge,
+		logger:   logger,
+	}
+	c.writer = newCacheFileWriter(filename, c.marshal, logger)
+	now := time.Now()
+	entries := make([]*fileSessionCacheEntry, 0, len(loaded))
+	for _, e := range loaded {
+		if now.Sub(e.Added) < c.maxAge && len(entries) < c.capacity {
+			entries = append(entries, e)
+		}
+	}
+	c.entries = entries
+	return c, nil
+}
+
+func (c *FileClientSessionCache) Get(key string) (*tls.ClientSessionState, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	e, i := c.get(key)
+	if e == nil {
+		return nil, false
+	}
+	if time.Since(e.Added) >= c.maxAge {
+		c.remove(i)
+		return nil, false
+	}
+	state, err := tls.ParseSessionState(e.State)
+	if err != nil {
+		c.logger.Errorf("Failed to parse session state: %s", err)
+		c.remove(i)
+		return nil, false
+	}
+	cs, err := tls.NewResumptionState(e.Ticket, state)
+	if err != nil {
+		c.logger.Errorf("Failed to restore session state: %s", err)
+		c.remove(i)
+		return nil, false
+	}
+	// Only the order of the entries changed.
+	// There's no need to write the file, the new order will be persisted with the next change.
+	c.entries = append(c.entries[:i], c.entries[i+1:]...)
+	c.entries = append([]*fileSessionCacheEntry{e}, c.entries...)
+	return cs, true
+}
+
+func (c *FileClientSessionCache) Put(key string, cs *tls.ClientSessionState) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	e, i := c.get(key)
+	if e != nil {
+		c.entries = append(c.entries[:i], c.entries[i+1:]...)
+	}
+	// crypto/tls calls Put with a nil session state to remove an entry from the cache.
+	if cs == nil {
+		if e != nil {
+			c.persist()
+		}
+		return
+	}
+	ticket, state, err := cs.ResumptionState()
+	if err != nil || state == nil {
+		return
+	}
+	stateBytes, err := state.Bytes()
+	if err != nil {
+		c.logger.Errorf("Failed to serialize session state: %s", err)
+		return
+	}
+	if len(c.entries) >= c.capacity {
+		c.entries = c.entries[:c.capacity-1]
+	}
+	c.entries = append([]*fileSessionCacheEntry{{
+		Key:    key,
+		Ticket: ticket,
+		State:  stateBytes,
+		Added:  time.Now(),
+	}}, c.entries...)
+	c.persist()
+}
+
+func (c *FileClientSessionCache) get(key string) (*fileSessionCacheEntry, int) {
+	for i, e := range c.entries {
+		if e.Key == key {
+			return e, i
+		}
+	}
+	return nil, -1
+}
+
+func (c *FileClientSessionCache) remove(i int) {
+	c.entries = append(c.entries[:i], c.entries[i+1:]...)
+	c.persist()
+}
+
+// Flush blocks until all changes made to the session cache have been written to the file.
+func (c *FileClientSessionCache) Flush() {
+	c.writer.Flush()
+}
+
+func (c *FileClientSessionCache) persist() {
+	c.writer.Schedule()
+}
+
+func (c *FileClientSessionCache) marshal() ([]byte, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return json.Marshal(c.entries)
+}
+
+// readCacheFile reads and decodes a cache file.
+// It is not an error if the file doesn't exist.
+// If the file can't be decoded, the error is logged, and no entries are returned.
+func readCacheFile[T any](filename string, logger utils.Logger) ([]T, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var entries []T
+	if err := json.Unmarshal(data, &entries); err != nil {
+		logger.Errorf("Ignoring corrupted cache file %s: %s", filename, err)
+		return nil, nil
+	}
+	return entries, nil
+}
+
+// A cacheFileWriter writes a cache file in a background Go routine.
+// The cache is only marshaled when the file is written, so that multiple changes
+// that happen while a write is in progress are coalesced into a single write.
+type cacheFileWriter struct {
+	filename string
+	marshal  func() ([]byte, error)
+	logger   utils.Logger
+
+	mutex   sync.Mutex
+	dirty   bool // set if the cache changed after the last call to marshal
+	running bool // set while the Go routine writing the file is running
+	idle    chan struct{}
+}
+
+func newCacheFileWriter(filename string, marshal func() ([]byte, error), logger utils.Logger) *cacheFileWriter {
+	return &cacheFileWriter{
+		filename: filename,
+		marshal:  marshal,
+		logger:   logger,
+	}
+}
+
+// Schedule schedules a write of the cache file.
+// It doesn't block, and may be called while holding the cache's mutex.
+func (w *cacheFileWriter) Schedule() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.dirty = true
+	if !w.running {
+		w.running = true
+		w.idle = make(chan struct{})
+		go w.run(w.idle)
+	}
+}
+
+func (w *cacheFileWriter) run(idle chan struct{}) {
+	for {
+		w.mutex.Lock()
+		if !w.dirty {
+			w.running = false
+			w.mutex.Unlock()
+			close(idle)
+			return
+		}
+		w.dirty = false
+		w.mutex.Unlock()
+
+		data, err := w.marshal()
+		if err == nil {
+			err = writeCacheFile(w.filename, data)
+		}
+		if err != nil {
+			w.logger.Errorf("Failed to persist cache: %s", err)
+		}
+	}
+}
+
+// Flush blocks until all scheduled writes are completed.
+func (w *cacheFileWriter) Flush() {
+	w.mutex.Lock()
+	idle := w.idle
+	w.mutex.Unlock()
+	if idle != nil {
+		<-idle
+	}
+}
+
+// writeCacheFile atomically replaces the cache file.
+func writeCacheFile(filename string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) // no-op if the rename succeeded
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), filename)
+}
@@ -0,0 +1,332 @@
+package quic
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/testdata"
+	"github.com/quic-go/quic-go/internal/utils"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("File-backed caches", func() {
+	var filename string
+
+	BeforeEach(func() {
+		filename = filepath.Join(GinkgoT().TempDir(), "cache")
+	})
+
+	// The caches are flushed after every test, so that the temporary directory can be removed.
+	newTokenStore := func(filename string, maxOrigins, tokensPerOrigin int, maxAge time.Duration) (*FileTokenStore, error) {
+		s, err := NewFileTokenStore(filename, maxOrigins, tokensPerOrigin, maxAge)
+		if err == nil {
+			DeferCleanup(s.Flush)
+		}
+		return s, err
+	}
+	newSessionCache := func(filename string, capacity int, maxAge time.Duration) (*FileClientSessionCache, error) {
+		c, err := NewFileClientSessionCache(filename, capacity, maxAge)
+		if err == nil {
+			DeferCleanup(c.Flush)
+		}
+		return c, err
+	}
+
+	expectNoTemporaryFiles := func() {
+		entries, err := os.ReadDir(filepath.Dir(filename))
+		ExpectWithOffset(1, err).ToNot(HaveOccurred())
+		ExpectWithOffset(1, entries).To(HaveLen(1))
+		ExpectWithOffset(1, entries[0].Name()).To(Equal("cache"))
+	}
+
+	Context("token store", func() {
+		mockToken := func(num int) *ClientToken {
+			return &ClientToken{data: []byte(fmt.Sprintf("%d", num))}
+		}
+
+		It("rejects invalid sizes", func() {
+			_, err := newTokenStore(filename, 0, 1, 0)
+			Expect(err).To(MatchError("invalid token store size"))
+			_, err = newTokenStore(filename, 1, 0, 0)
+			Expect(err).To(MatchError("invalid token store size"))
+		})
+
+		It("persists tokens", func() {
+			s, err := newTokenStore(filename, 3, 4, 0)
+			Expect(err).ToNot(HaveOccurred())
+			s.Put("foo", mockToken(1))
+			s.Put("foo", mockToken(2))
+			s.Put("bar", mockToken(3))
+			Expect(s.Pop("foo")).To(Equal(mockToken(2)))
+			s.Flush()
+			expectNoTemporaryFiles()
+
+			s, err = newTokenStore(filename, 3, 4, 0)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(s.Pop("foo")).To(Equal(mockToken(1)))
+			Expect(s.Pop("foo")).To(BeNil())
+			Expect(s.Pop("bar")).To(Equal(mockToken(3)))
+			Expect(s.Pop("bar")).To(BeNil())
+		})
+
+		It("limits the number of tokens per origin", func() {
+			s, err := newTokenStore(filename, 3, 2, 0)
+			Expect(err).ToNot(HaveOccurred())
+			s.Put("foo", mockToken(1))
+			s.Put("foo", mockToken(2))
+			s.Put("foo", mockToken(3))
+			Expect(s.Pop("foo")).To(Equal(mockToken(3)))
+			Expect(s.Pop("foo")).To(Equal(mockToken(2)))
+			Expect(s.Pop("foo")).To(BeNil())
+		})
+
+		It("evicts the least recently used origin", func() {
+			s, err := newTokenStore(filename, 2, 2, 0)
+			Expect(err).ToNot(HaveOccurred())
+			s.Put("foo", mockToken(1))
+			s.Put("bar", mockToken(2))
+			s.Put("foo", mockToken(3))
+			s.Put("baz", mockToken(4))
+			Expect(s.Pop("bar")).To(BeNil())
+			Expect(s.Pop("foo")).To(Equal(mockToken(3)))
+			Expect(s.Pop("baz")).To(Equal(mockToken(4)))
+		})
+
+		It("counts popping a token as a use of the origin", func() {
+			s, err := newTokenStore(filename, 2, 2, 0)
+			Expect(err).ToNot(HaveOccurred())
+			s.Put("foo", mockToken(1))
+			s.Put("foo", mockToken(2))
+			s.Put("bar", mockToken(3))
+			Expect(s.Pop("foo")).To(Equal(mockToken(2)))
+			s.Put("baz", mockToken(4))
+			Expect(s.Pop("bar")).To(BeNil())
+			Expect(s.Pop("foo")).To(Equal(mockToken(1)))
+			Expect(s.Pop("baz")).To(Equal(mockToken(4)))
+		})
+
+		It("applies the size limits when loading the file", func() {
+			s, err := newTokenStore(filename, 3, 3, 0)
+			Expect(err).ToNot(HaveOccurred())
+			s.Put("foo", mockToken(1))
+			s.Put("foo", mockToken(2))
+			s.Put("bar", mockToken(3))
+			s.Flush()
+
+			s, err = newTokenStore(filename, 1, 1, 0)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(s.Pop("foo")).To(BeNil())
+			Expect(s.Pop("bar")).To(Equal(mockToken(3)))
+		})
+
+		It("expires tokens", func() {
+			s, err := newTokenStore(filename, 3, 4, 50*time.Millisecond)
+			Expect(err).ToNot(HaveOccurred())
+			s.Put("foo", mockToken(1))
+			time.Sleep(30 * time.Millisecond)
+			s.Put("foo", mockToken(2))
+			s.Put("bar", mockToken(3))
+			s.Flush()
+			time.Sleep(30 * time.Millisecond)
+
+			s, err = newTokenStore(filename, 3, 4, 50*time.Millisecond)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(s.Pop("foo")).To(Equal(mockToken(2)))
+			Expect(s.Pop("foo")).To(BeNil())
+			time.Sleep(30 * time.Millisecond)
+			Expect(s.Pop("bar")).To(BeNil())
+		})
+
+		It("starts out empty when the file is corrupted", func() {
+			Expect(os.WriteFile(filename, []byte("foobar"), 0o600)).To(Succeed())
+			s, err := newTokenStore(filename, 3, 4, 0)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(s.Pop("foo")).To(BeNil())
+			// the corrupted file is replaced on the next change
+			s.Put("foo", mockToken(1))
+			s.Flush()
+			s, err = newTokenStore(filename, 3, 4, 0)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(s.Pop("foo")).To(Equal(mockToken(1)))
+		})
+	})
+
+	Context("session cache", func() {
+		// getSessionState performs a TLS 1.3 handshake and returns the session state received by the client.
+		getSessionState := func() *tls.ClientSessionState {
+			cache := tls.NewLRUClientSessionCache(1)
+			clientConn, serverConn := net.Pipe()
+			defer clientConn.Close()
+			go func() {
+				defer GinkgoRecover()
+				defer serverConn.Close()
+				conn := tls.Server(serverConn, testdata.GetTLSConfig())
+				Expect(conn.Handshake()).To(Succeed())
+				// The session ticket is sent after the handshake.
+				// Write some data, so that the client reads the ticket.
+				_, err := conn.Write([]byte("foobar"))
+				Expect(err).ToNot(HaveOccurred())
+			}()
+			conn := tls.Client(clientConn, &tls.Config{
+				RootCAs:            testdata.GetRootCA(),
+				ServerName:         "localhost",
+				ClientSessionCache: cache,
+			})
+			b := make([]byte, 6)
+			_, err := conn.Read(b)
+			ExpectWithOffset(1, err).ToNot(HaveOccurred())
+			var cs *tls.ClientSessionState
+			EventuallyWithOffset(1, func() bool {
+				var ok bool
+				cs, ok = cache.Get("localhost")
+				return ok
+			}).Should(BeTrue())
+			return cs
+		}
+
+		It("rejects invalid capacities", func() {
+			_, err := newSessionCache(filename, 0, 0)
+			Expect(err).To(MatchError("invalid session cache capacity"))
+		})
+
+		It("persists session tickets", func() {
+			cs := getSessionState()
+			c, err := newSessionCache(filename, 2, 0)
+			Expect(err).ToNot(HaveOccurred())
+			c.Put("localhost", cs)
+			c.Flush()
+			expectNoTemporaryFiles()
+
+			c, err = newSessionCache(filename, 2, 0)
+			Expect(err).ToNot(HaveOccurred())
+			restored, ok := c.Get("localhost")
+			Expect(ok).To(BeTrue())
+			ticket, state, err := restored.ResumptionState()
+			Expect(err).ToNot(HaveOccurred())
+			origTicket, origState, err := cs.ResumptionState()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ticket).To(Equal(origTicket))
+			b, err := state.Bytes()
+			Expect(err).ToNot(HaveOccurred())
+			origB, err := origState.Bytes()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b).To(Equal(origB))
+			_, ok = c.Get("foobar")
+			Expect(ok).To(BeFalse())
+		})
+
+		It("removes session tickets", func() {
+			c, err := newSessionCache(filename, 2, 0)
+			Expect(err).ToNot(HaveOccurred())
+			c.Put("localhost", getSessionState())
+			c.Put("localhost", nil)
+			_, ok := c.Get("localhost")
+			Expect(ok).To(BeFalse())
+			c.Flush()
+
+			c, err = newSessionCache(filename, 2, 0)
+			Expect(err).ToNot(HaveOccurred())
+			_, ok = c.Get("localhost")
+			Expect(ok).To(BeFalse())
+		})
+
+		It("evicts the least recently used session ticket", func() {
+			cs := getSessionState()
+			c, err := newSessionCache(filename, 2, 0)
+			Expect(err).ToNot(HaveOccurred())
+			c.Put("foo", cs)
+			c.Put("bar", cs)
+			c.Put("baz", cs)
+			_, ok := c.Get("foo")
+			Expect(ok).To(BeFalse())
+			_, ok = c.Get("bar")
+			Expect(ok).To(BeTrue())
+			_, ok = c.Get("baz")
+			Expect(ok).To(BeTrue())
+		})
+
+		It("counts retrieving a session ticket as a use", func() {
+			cs := getSessionState()
+			c, err := newSessionCache(filename, 2, 0)
+			Expect(err).ToNot(HaveOccurred())
+			c.Put("foo", cs)
+			c.Put("bar", cs)
+			_, ok := c.Get("foo")
+			Expect(ok).To(BeTrue())
+			c.Put("baz", cs)
+			_, ok = c.Get("bar")
+			Expect(ok).To(BeFalse())
+			_, ok = c.Get("foo")
+			Expect(ok).To(BeTrue())
+			_, ok = c.Get("baz")
+			Expect(ok).To(BeTrue())
+		})
+
+		It("expires session tickets", func() {
+			c, err := newSessionCache(filename, 2, 50*time.Millisecond)
+			Expect(err).ToNot(HaveOccurred())
+			c.Put("localhost", getSessionState())
+			_, ok := c.Get("localhost")
+			Expect(ok).To(BeTrue())
+			time.Sleep(60 * time.Millisecond)
+			_, ok = c.Get("localhost")
+			Expect(ok).To(BeFalse())
+		})
+
+		It("removes session tickets that can't be parsed", func() {
+			Expect(os.WriteFile(filename, []byte(fmt.Sprintf(`[{"key":"localhost","state":"Zm9vYmFy","added":%q}]`, time.Now().Format(time.RFC3339Nano))), 0o600)).To(Succeed())
+			c, err := newSessionCache(filename, 2, 0)
+			Expect(err).ToNot(HaveOccurred())
+			_, ok := c.Get("localhost")
+			Expect(ok).To(BeFalse())
+			c.Flush()
+			data, err := os.ReadFile(filename)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal("[]"))
+		})
+
+		It("starts out empty when the file is corrupted", func() {
+			Expect(os.WriteFile(filename, []byte("foobar"), 0o600)).To(Succeed())
+			c, err := newSessionCache(filename, 2, 0)
+			Expect(err).ToNot(HaveOccurred())
+			_, ok := c.Get("localhost")
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	It("coalesces writes", func() {
+		var counter int
+		unblock := make(chan struct{})
+		w := newCacheFileWriter(filename, func() ([]byte, error) {
+			counter++
+			if counter == 1 {
+				<-unblock
+			}
+			return []byte(fmt.Sprintf("%d", counter)), nil
+		}, utils.DefaultLogger)
+		w.Schedule()
+		// wait for the first write to start
+		Eventually(func() bool {
+			w.mutex.Lock()
+			defer w.mutex.Unlock()
+			return !w.dirty
+		}).Should(BeTrue())
+		// Schedule doesn't block while the file is being written
+		for i := 0; i < 10; i++ {
+			w.Schedule()
+		}
+		close(unblock)
+		w.Flush()
+		Expect(counter).To(Equal(2))
+		data, err := os.ReadFile(filename)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(Equal("2"))
+		expectNoTemporaryFiles()
+	})
+})
@@ -93,12 +93,21 @@ func NewConnectionTracer(w io.WriteCloser, p logging.Perspective, odcid protocol
 		LostPacket: func(encLevel protocol.EncryptionLevel, pn protocol.PacketNumber, lossReason logging.PacketLossReason) {
 			t.LostPacket(encLevel, pn, lossReason)
 		},
+		DetectedSpuriousLoss: func(encLevel protocol.EncryptionLevel, pn protocol.PacketNumber, packetReordering uint64, timeReordering time.Duration) {
+			t.DetectedSpuriousLoss(encLevel, pn, packetReordering, timeReordering)
+		},
 		UpdatedCongestionState: func(state logging.CongestionState) {
 			t.UpdatedCongestionState(state)
 		},
+		UpdatedECNMarkingFraction: func(fraction, average float64) {
+			t.UpdatedECNMarkingFraction(fraction, average)
+		},
 		UpdatedPTOCount: func(value uint32) {
 			t.UpdatedPTOCount(value)
 		},
+		UpdatedMTU: func(mtu logging.ByteCount, done bool) {
+			t.UpdatedMTU(mtu, done)
+		},
 		UpdatedKeyFromTLS: func(encLevel protocol.EncryptionLevel, pers protocol.Perspective) {
 			t.UpdatedKeyFromTLS(encLevel, pers)
 		},
@@ -123,6 +132,15 @@ func NewConnectionTracer(w io.WriteCloser, p logging.Perspective, odcid protocol
 		ECNStateUpdated: func(state logging.ECNState, trigger logging.ECNStateTrigger) {
 			t.ECNStateUpdated(state, trigger)
 		},
+		ExpiredStreamData: func(id logging.StreamID, offset, length logging.ByteCount) {
+			t.ExpiredStreamData(id, offset, length)
+		},
+		DroppedDatagram: func(size logging.ByteCount, reason logging.DatagramDropReason) {
+			t.DroppedDatagram(size, reason)
+		},
+		UpdatedPeerAddress: func(oldAddr, newAddr net.Addr) {
+			t.UpdatedPeerAddress(oldAddr, newAddr)
+		},
 		ChoseALPN: func(protocol string) {
 			t.recordEvent(time.Now(), eventALPNInformation{chosenALPN: protocol})
 		},
@@ -247,6 +265,8 @@ func (t *connectionTracer) toTransportParameters(tp *wire.TransportParameters) *
 		InitialMaxStreamsUni:            int64(tp.MaxUniStreamNum),
 		PreferredAddress:                pa,
 		MaxDatagramFrameSize:            tp.MaxDatagramFrameSize,
+		MinAckDelay:                     tp.MinAckDelay,
+		EnableResetStreamAt:             tp.EnableResetStreamAt,
 	}
 }
 
@@ -390,14 +410,31 @@ func (t *connectionTracer) LostPacket(encLevel protocol.EncryptionLevel, pn prot
 	})
 }
 
+func (t *connectionTracer) DetectedSpuriousLoss(encLevel protocol.EncryptionLevel, pn protocol.PacketNumber, packetReordering uint64, timeReordering time.Duration) {
+	t.recordEvent(time.Now(), &eventSpuriousLossDetected{
+		PacketType:       getPacketTypeFromEncryptionLevel(encLevel),
+		PacketNumber:     pn,
+		PacketReordering: packetReordering,
+		TimeReordering:   timeReordering,
+	})
+}
+
 func (t *connectionTracer) UpdatedCongestionState(state logging.CongestionState) {
 	t.recordEvent(time.Now(), &eventCongestionStateUpdated{state: congestionState(state)})
 }
 
+func (t *connectionTracer) UpdatedECNMarkingFraction(fraction, average float64) {
+	t.recordEvent(time.Now(), &eventECNMarkingFractionUpdated{Fraction: fraction, Average: average})
+}
+
 func (t *connectionTracer) UpdatedPTOCount(value uint32) {
 	t.recordEvent(time.Now(), &eventUpdatedPTO{Value: value})
 }
 
+func (t *connectionTracer) UpdatedMTU(mtu logging.ByteCount, done bool) {
+	t.recordEvent(time.Now(), &eventMTUUpdated{mtu: mtu, done: done})
+}
+
 func (t *connectionTracer) UpdatedKeyFromTLS(encLevel protocol.EncryptionLevel, pers protocol.Perspective) {
 	t.recordEvent(time.Now(), &eventKeyUpdated{
 		Trigger: keyUpdateTLS,
@@ -469,6 +506,28 @@ func (t *connectionTracer) ECNStateUpdated(state logging.ECNState, trigger loggi
 	t.recordEvent(time.Now(), &eventECNStateUpdated{state: state, trigger: trigger})
 }
 
+func (t *connectionTracer) UpdatedPeerAddress(oldAddr, newAddr net.Addr) {
+	t.recordEvent(time.Now(), &eventPeerAddressUpdated{
+		Old: oldAddr.String(),
+		New: newAddr.String(),
+	})
+}
+
+func (t *connectionTracer) ExpiredStreamData(id protocol.StreamID, offset, length protocol.ByteCount) {
+	t.recordEvent(time.Now(), &eventStreamDataExpired{
+		StreamID: id,
+		Offset:   offset,
+		Length:   length,
+	})
+}
+
+func (t *connectionTracer) DroppedDatagram(size protocol.ByteCount, reason logging.DatagramDropReason) {
+	t.recordEvent(time.Now(), &eventDatagramDropped{
+		Length:  size,
+		Trigger: datagramDropReason(reason),
+	})
+}
+
 func (t *connectionTracer) Debug(name, msg string) {
 	t.recordEvent(time.Now(), &eventGeneric{
 		name: name,
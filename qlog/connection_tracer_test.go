@@ -334,6 +334,25 @@ var _ = Describe("Tracing", func() {
 			Expect(ev).To(HaveKeyWithValue("max_datagram_frame_size", float64(1337)))
 		})
 
+		It("records transport parameters that enable the ACK frequency extension", func() {
+			minAckDelay := 2 * time.Millisecond
+			tracer.SentTransportParameters(&logging.TransportParameters{
+				MinAckDelay: &minAckDelay,
+			})
+			entry := exportAndParseSingle()
+			Expect(entry.Name).To(Equal("transport:parameters_set"))
+			Expect(entry.Event).To(HaveKeyWithValue("min_ack_delay", float64(2)))
+		})
+
+		It("records transport parameters that enable the reliable stream reset extension", func() {
+			tracer.SentTransportParameters(&logging.TransportParameters{
+				EnableResetStreamAt: true,
+			})
+			entry := exportAndParseSingle()
+			Expect(entry.Name).To(Equal("transport:parameters_set"))
+			Expect(entry.Event).To(HaveKeyWithValue("reset_stream_at", true))
+		})
+
 		It("records received transport parameters", func() {
 			tracer.ReceivedTransportParameters(&logging.TransportParameters{})
 			entry := exportAndParseSingle()
@@ -697,6 +716,21 @@ var _ = Describe("Tracing", func() {
 			Expect(ev).To(HaveKeyWithValue("trigger", "reordering_threshold"))
 		})
 
+		It("records spurious losses", func() {
+			tracer.DetectedSpuriousLoss(protocol.Encryption1RTT, 42, 5, 12500*time.Microsecond)
+			entry := exportAndParseSingle()
+			Expect(entry.Time).To(BeTemporally("~", time.Now(), scaleDuration(10*time.Millisecond)))
+			Expect(entry.Name).To(Equal("recovery:spurious_loss_detected"))
+			ev := entry.Event
+			Expect(ev).To(HaveKey("header"))
+			hdr := ev["header"].(map[string]interface{})
+			Expect(hdr).To(HaveLen(2))
+			Expect(hdr).To(HaveKeyWithValue("packet_type", "1RTT"))
+			Expect(hdr).To(HaveKeyWithValue("packet_number", float64(42)))
+			Expect(ev).To(HaveKeyWithValue("packet_reordering", float64(5)))
+			Expect(ev).To(HaveKeyWithValue("time_reordering", 12.5))
+		})
+
 		It("records congestion state updates", func() {
 			tracer.UpdatedCongestionState(logging.CongestionStateCongestionAvoidance)
 			entry := exportAndParseSingle()
@@ -706,6 +740,16 @@ var _ = Describe("Tracing", func() {
 			Expect(ev).To(HaveKeyWithValue("new", "congestion_avoidance"))
 		})
 
+		It("records ECN marking fraction updates", func() {
+			tracer.UpdatedECNMarkingFraction(0.25, 0.125)
+			entry := exportAndParseSingle()
+			Expect(entry.Time).To(BeTemporally("~", time.Now(), scaleDuration(10*time.Millisecond)))
+			Expect(entry.Name).To(Equal("recovery:ecn_marking_fraction_updated"))
+			ev := entry.Event
+			Expect(ev).To(HaveKeyWithValue("fraction", 0.25))
+			Expect(ev).To(HaveKeyWithValue("average", 0.125))
+		})
+
 		It("records PTO changes", func() {
 			tracer.UpdatedPTOCount(42)
 			entry := exportAndParseSingle()
@@ -714,6 +758,24 @@ var _ = Describe("Tracing", func() {
 			Expect(entry.Event).To(HaveKeyWithValue("pto_count", float64(42)))
 		})
 
+		It("records MTU updates", func() {
+			tracer.UpdatedMTU(1337, true)
+			entry := exportAndParseSingle()
+			Expect(entry.Time).To(BeTemporally("~", time.Now(), scaleDuration(10*time.Millisecond)))
+			Expect(entry.Name).To(Equal("recovery:mtu_updated"))
+			Expect(entry.Event).To(HaveKeyWithValue("mtu", float64(1337)))
+			Expect(entry.Event).To(HaveKeyWithValue("done", true))
+		})
+
+		It("records dropped datagrams", func() {
+			tracer.DroppedDatagram(1337, logging.DatagramDropExpired)
+			entry := exportAndParseSingle()
+			Expect(entry.Time).To(BeTemporally("~", time.Now(), scaleDuration(10*time.Millisecond)))
+			Expect(entry.Name).To(Equal("transport:datagram_dropped"))
+			Expect(entry.Event).To(HaveKeyWithValue("length", float64(1337)))
+			Expect(entry.Event).To(HaveKeyWithValue("trigger", "expired"))
+		})
+
 		It("records TLS key updates", func() {
 			tracer.UpdatedKeyFromTLS(protocol.EncryptionHandshake, protocol.PerspectiveClient)
 			entry := exportAndParseSingle()
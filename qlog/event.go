@@ -326,6 +326,20 @@ func (e eventUpdatedPTO) MarshalJSONObject(enc *gojay.Encoder) {
 	enc.Uint32Key("pto_count", e.Value)
 }
 
+type eventMTUUpdated struct {
+	mtu  protocol.ByteCount
+	done bool
+}
+
+func (e eventMTUUpdated) Category() category { return categoryRecovery }
+func (e eventMTUUpdated) Name() string       { return "mtu_updated" }
+func (e eventMTUUpdated) IsNil() bool        { return false }
+
+func (e eventMTUUpdated) MarshalJSONObject(enc *gojay.Encoder) {
+	enc.Uint64Key("mtu", uint64(e.mtu))
+	enc.BoolKey("done", e.done)
+}
+
 type eventPacketLost struct {
 	PacketType   logging.PacketType
 	PacketNumber protocol.PacketNumber
@@ -409,6 +423,10 @@ type eventTransportParameters struct {
 	PreferredAddress *preferredAddress
 
 	MaxDatagramFrameSize protocol.ByteCount
+
+	MinAckDelay *time.Duration
+
+	EnableResetStreamAt bool
 }
 
 func (e eventTransportParameters) Category() category { return categoryTransport }
@@ -454,6 +472,10 @@ func (e eventTransportParameters) MarshalJSONObject(enc *gojay.Encoder) {
 	if e.MaxDatagramFrameSize != protocol.InvalidByteCount {
 		enc.Int64Key("max_datagram_frame_size", int64(e.MaxDatagramFrameSize))
 	}
+	if e.MinAckDelay != nil {
+		enc.FloatKey("min_ack_delay", milliseconds(*e.MinAckDelay))
+	}
+	enc.BoolKeyOmitEmpty("reset_stream_at", e.EnableResetStreamAt)
 }
 
 type preferredAddress struct {
@@ -516,6 +538,40 @@ func (e eventLossTimerCanceled) MarshalJSONObject(enc *gojay.Encoder) {
 	enc.StringKey("event_type", "cancelled")
 }
 
+type eventSpuriousLossDetected struct {
+	PacketType       logging.PacketType
+	PacketNumber     protocol.PacketNumber
+	PacketReordering uint64
+	TimeReordering   time.Duration
+}
+
+func (e eventSpuriousLossDetected) Category() category { return categoryRecovery }
+func (e eventSpuriousLossDetected) Name() string       { return "spurious_loss_detected" }
+func (e eventSpuriousLossDetected) IsNil() bool        { return false }
+
+func (e eventSpuriousLossDetected) MarshalJSONObject(enc *gojay.Encoder) {
+	enc.ObjectKey("header", packetHeaderWithTypeAndPacketNumber{
+		PacketType:   e.PacketType,
+		PacketNumber: e.PacketNumber,
+	})
+	enc.Uint64Key("packet_reordering", e.PacketReordering)
+	enc.FloatKey("time_reordering", milliseconds(e.TimeReordering))
+}
+
+type eventECNMarkingFractionUpdated struct {
+	Fraction float64
+	Average  float64
+}
+
+func (e eventECNMarkingFractionUpdated) Category() category { return categoryRecovery }
+func (e eventECNMarkingFractionUpdated) Name() string       { return "ecn_marking_fraction_updated" }
+func (e eventECNMarkingFractionUpdated) IsNil() bool        { return false }
+
+func (e eventECNMarkingFractionUpdated) MarshalJSONObject(enc *gojay.Encoder) {
+	enc.FloatKey("fraction", e.Fraction)
+	enc.FloatKey("average", e.Average)
+}
+
 type eventCongestionStateUpdated struct {
 	state congestionState
 }
@@ -567,6 +623,50 @@ func (e eventALPNInformation) MarshalJSONObject(enc *gojay.Encoder) {
 	enc.StringKey("chosen_alpn", e.chosenALPN)
 }
 
+type eventPeerAddressUpdated struct {
+	Old string
+	New string
+}
+
+func (e eventPeerAddressUpdated) Category() category { return categoryConnectivity }
+func (e eventPeerAddressUpdated) Name() string       { return "peer_address_updated" }
+func (e eventPeerAddressUpdated) IsNil() bool        { return false }
+
+func (e eventPeerAddressUpdated) MarshalJSONObject(enc *gojay.Encoder) {
+	enc.StringKey("old", e.Old)
+	enc.StringKey("new", e.New)
+}
+
+type eventStreamDataExpired struct {
+	StreamID protocol.StreamID
+	Offset   protocol.ByteCount
+	Length   protocol.ByteCount
+}
+
+func (e eventStreamDataExpired) Category() category { return categoryTransport }
+func (e eventStreamDataExpired) Name() string       { return "stream_data_expired" }
+func (e eventStreamDataExpired) IsNil() bool        { return false }
+
+func (e eventStreamDataExpired) MarshalJSONObject(enc *gojay.Encoder) {
+	enc.Int64Key("stream_id", int64(e.StreamID))
+	enc.Int64Key("offset", int64(e.Offset))
+	enc.Int64Key("length", int64(e.Length))
+}
+
+type eventDatagramDropped struct {
+	Length  protocol.ByteCount
+	Trigger datagramDropReason
+}
+
+func (e eventDatagramDropped) Category() category { return categoryTransport }
+func (e eventDatagramDropped) Name() string       { return "datagram_dropped" }
+func (e eventDatagramDropped) IsNil() bool        { return false }
+
+func (e eventDatagramDropped) MarshalJSONObject(enc *gojay.Encoder) {
+	enc.Int64Key("length", int64(e.Length))
+	enc.StringKey("trigger", e.Trigger.String())
+}
+
 type eventNewFrameToRingbuffer struct {
 	streamType     int
 	unidirectional bool
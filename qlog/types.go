@@ -209,6 +209,8 @@ func (e transportError) String() string {
 		return "aead_limit_reached"
 	case qerr.NoViablePathError:
 		return "no_viable_path"
+	case qerr.VersionNegotiationErrorCode:
+		return "version_negotiation_error"
 	default:
 		return ""
 	}
@@ -283,6 +285,23 @@ func (r packetDropReason) String() string {
 	}
 }
 
+type datagramDropReason logging.DatagramDropReason
+
+func (r datagramDropReason) String() string {
+	switch logging.DatagramDropReason(r) {
+	case logging.DatagramDropSendQueueFull:
+		return "send_queue_full"
+	case logging.DatagramDropReceiveQueueFull:
+		return "receive_queue_full"
+	case logging.DatagramDropExpired:
+		return "expired"
+	case logging.DatagramDropTooLarge:
+		return "too_large"
+	default:
+		return "unknown datagram drop reason"
+	}
+}
+
 type timerType logging.TimerType
 
 func (t timerType) String() string {
@@ -53,6 +53,13 @@ var _ = Describe("Types", func() {
 		Expect(packetDropReason(logging.PacketDropUnexpectedVersion).String()).To(Equal("unexpected_version"))
 	})
 
+	It("has a string representation for the datagram drop reason", func() {
+		Expect(datagramDropReason(logging.DatagramDropSendQueueFull).String()).To(Equal("send_queue_full"))
+		Expect(datagramDropReason(logging.DatagramDropReceiveQueueFull).String()).To(Equal("receive_queue_full"))
+		Expect(datagramDropReason(logging.DatagramDropExpired).String()).To(Equal("expired"))
+		Expect(datagramDropReason(logging.DatagramDropTooLarge).String()).To(Equal("too_large"))
+	})
+
 	It("has a string representation for the timer type", func() {
 		Expect(timerType(logging.TimerTypeACK).String()).To(Equal("ack"))
 		Expect(timerType(logging.TimerTypePTO).String()).To(Equal("pto"))
@@ -117,6 +124,7 @@ var _ = Describe("Types", func() {
 			Expect(transportError(qerr.ApplicationErrorErrorCode).String()).To(Equal("application_error"))
 			Expect(transportError(qerr.CryptoBufferExceeded).String()).To(Equal("crypto_buffer_exceeded"))
 			Expect(transportError(qerr.NoViablePathError).String()).To(Equal("no_viable_path"))
+			Expect(transportError(qerr.VersionNegotiationErrorCode).String()).To(Equal("version_negotiation_error"))
 			Expect(transportError(1337).String()).To(BeEmpty())
 		})
 	})
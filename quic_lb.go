@@ -0,0 +1,282 @@
+package quic
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+)
+
+// The connection ID encoding defined by QUIC-LB (draft-ietf-quic-load-balancers)
+// allows stateless load balancers to route packets by connection ID.
+//
+// The first octet of the connection ID contains the config rotation bits in its three most significant bits,
+// and the length of the connection ID minus one in its five least significant bits.
+// It is followed by the server ID and a random nonce, which are encrypted if a key is configured.
+const (
+	quicLBMaxConfigID        = 6
+	quicLBUnroutableConfigID = 7
+	quicLBConfigIDShift      = 5
+	quicLBMinNonceLen        = 4
+	quicLBMaxNonceLen        = 18
+	quicLBMaxServerIDLen     = 15
+	quicLBMaxPlaintextLen    = protocol.MaxConnIDLen - 1
+)
+
+// A LoadBalancerConfig is the configuration shared between a QUIC-LB load balancer and the servers behind it.
+type LoadBalancerConfig struct {
+	// ConfigID are the config rotation bits, encoded in the first octet of the connection ID.
+	// They allow switching between configurations without breaking existing connections.
+	// Valid values are 0 to 6.
+	ConfigID uint8
+	// ServerIDLen is the length of the server ID, between 1 and 15 bytes.
+	ServerIDLen int
+	// NonceLen is the length of the nonce, between 4 and 18 bytes.
+	// The sum of ServerIDLen and NonceLen must not exceed 19 bytes.
+	NonceLen int
+	// Key is the 16 byte AES-128 key used to encrypt the connection ID.
+	// If not set, the server ID is encoded in plaintext.
+	// If the sum of ServerIDLen and NonceLen is 16 bytes, a single AES-ECB pass is used,
+	// otherwise the connection ID is encrypted using four passes of a Feistel network.
+	Key []byte
+}
+
+func (c *LoadBalancerConfig) connIDLen() int {
+	return 1 + c.ServerIDLen + c.NonceLen
+}
+
+func (c *LoadBalancerConfig) validate() error {
+	if c.ConfigID > quicLBMaxConfigID {
+		return fmt.Errorf("quic-lb: invalid config ID %d", c.ConfigID)
+	}
+	if c.ServerIDLen < 1 || c.ServerIDLen > quicLBMaxServerIDLen {
+		return fmt.Errorf("quic-lb: invalid server ID length %d", c.ServerIDLen)
+	}
+	if c.NonceLen < quicLBMinNonceLen || c.NonceLen > quicLBMaxNonceLen {
+		return fmt.Errorf("quic-lb: invalid nonce length %d", c.NonceLen)
+	}
+	if c.ServerIDLen+c.NonceLen > quicLBMaxPlaintextLen {
+		return fmt.Errorf("quic-lb: server ID and nonce too long (%d bytes)", c.ServerIDLen+c.NonceLen)
+	}
+	if c.Key != nil && len(c.Key) != 16 {
+		return fmt.Errorf("quic-lb: invalid key length %d", len(c.Key))
+	}
+	return nil
+}
+
+// quicLBCodec encodes and decodes connection IDs for a single LoadBalancerConfig.
+type quicLBCodec struct {
+	config LoadBalancerConfig
+	block  cipher.Block // nil if connection IDs are not encrypted
+}
+
+func newQUICLBCodec(config LoadBalancerConfig) (*quicLBCodec, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	c := &quicLBCodec{config: config}
+	if config.Key != nil {
+		block, err := aes.NewCipher(config.Key)
+		if err != nil {
+			return nil, err
+		}
+		c.block = block
+	}
+	return c, nil
+}
+
+func (c *quicLBCodec) encode(serverID, nonce []byte) ConnectionID {
+	b := make([]byte, c.config.connIDLen())
+	b[0] = c.config.ConfigID<<quicLBConfigIDShift | uint8(len(b)-1)
+	plaintext := b[1:]
+	copy(plaintext, serverID)
+	copy(plaintext[len(serverID):], nonce)
+	switch {
+	case c.block == nil:
+	case len(plaintext) == aes.BlockSize:
+		c.block.Encrypt(plaintext, plaintext)
+	default:
+		c.fourPassEncrypt(plaintext)
+	}
+	return protocol.ParseConnectionID(b)
+}
+
+func (c *quicLBCodec) decode(b []byte) []byte {
+	plaintext := make([]byte, len(b)-1)
+	copy(plaintext, b[1:])
+	switch {
+	case c.block == nil:
+	case len(plaintext) == aes.BlockSize:
+		c.block.Decrypt(plaintext, plaintext)
+	default:
+		c.fourPassDecrypt(plaintext)
+	}
+	return plaintext[:c.config.ServerIDLen]
+}
+
+// fourPassEncrypt encrypts the plaintext using four passes of a Feistel network.
+// The plaintext is split into two halves. For an odd plaintext length, the halves share the middle byte:
+// The left half contains its most significant, and the right half its least significant four bits.
+func (c *quicLBCodec) fourPassEncrypt(b []byte) {
+	left, right := quicLBSplit(b)
+	c.xorRight(right, left, len(b), 1)
+	c.xorLeft(left, right, len(b), 2)
+	c.xorRight(right, left, len(b), 3)
+	c.xorLeft(left, right, len(b), 4)
+	quicLBCombine(b, left, right)
+}
+
+func (c *quicLBCodec) fourPassDecrypt(b []byte) {
+	left, right := quicLBSplit(b)
+	c.xorLeft(left, right, len(b), 4)
+	c.xorRight(right, left, len(b), 3)
+	c.xorLeft(left, right, len(b), 2)
+	c.xorRight(right, left, len(b), 1)
+	quicLBCombine(b, left, right)
+}
+
+// xorLeft XORs the left half with the first bytes of the AES-ECB encryption of the expanded right half.
+func (c *quicLBCodec) xorLeft(left, right []byte, plaintextLen int, pass uint8) {
+	out := c.expandAndEncrypt(right, plaintextLen, pass)
+	for i := range left {
+		left[i] ^= out[i]
+	}
+	if plaintextLen%2 == 1 {
+		left[len(left)-1] &= 0xf0
+	}
+}
+
+// xorRight XORs the right half with the first bytes of the AES-ECB encryption of the expanded left half.
+func (c *quicLBCodec) xorRight(right, left []byte, plaintextLen int, pass uint8) {
+	out := c.expandAndEncrypt(left, plaintextLen, pass)
+	for i := range right {
+		right[i] ^= out[i]
+	}
+	if plaintextLen%2 == 1 {
+		right[0] &= 0x0f
+	}
+}
+
+// expandAndEncrypt builds an AES block from the plaintext length, the pass number and the input,
+// padded with zeros, and encrypts that block.
+func (c *quicLBCodec) expandAndEncrypt(in []byte, plaintextLen int, pass uint8) []byte {
+	var block [aes.BlockSize]byte
+	block[0] = uint8(plaintextLen)
+	block[1] = pass
+	copy(block[2:], in)
+	c.block.Encrypt(block[:], block[:])
+	return block[:]
+}
+
+func quicLBSplit(b []byte) (left, right []byte) {
+	halfLen := (len(b) + 1) / 2
+	left = make([]byte, halfLen)
+	right = make([]byte, halfLen)
+	copy(left, b[:halfLen])
+	copy(right, b[len(b)-halfLen:])
+	if len(b)%2 == 1 {
+		left[halfLen-1] &= 0xf0
+		right[0] &= 0x0f
+	}
+	return left, right
+}
+
+func quicLBCombine(b, left, right []byte) {
+	copy(b[len(b)-len(right):], right)
+	if len(b)%2 == 1 {
+		b[len(left)-1] = left[len(left)-1] | right[0]
+		copy(b, left[:len(left)-1])
+	} else {
+		copy(b, left)
+	}
+}
+
+type quicLBConnIDGenerator struct {
+	codec    *quicLBCodec
+	serverID []byte
+}
+
+var _ ConnectionIDGenerator = &quicLBConnIDGenerator{}
+
+// NewLoadBalancerConnectionIDGenerator creates a ConnectionIDGenerator that generates connection IDs
+// encoding the server ID, as specified by QUIC-LB (draft-ietf-quic-load-balancers).
+// The load balancer can then route packets to the server using a LoadBalancerDecoder.
+func NewLoadBalancerConnectionIDGenerator(config LoadBalancerConfig, serverID []byte) (ConnectionIDGenerator, error) {
+	codec, err := newQUICLBCodec(config)
+	if err != nil {
+		return nil, err
+	}
+	if len(serverID) != config.ServerIDLen {
+		return nil, fmt.Errorf("quic-lb: expected a %d byte server ID, got %d bytes", config.ServerIDLen, len(serverID))
+	}
+	return &quicLBConnIDGenerator{
+		codec:    codec,
+		serverID: append([]byte(nil), serverID...),
+	}, nil
+}
+
+func (g *quicLBConnIDGenerator) GenerateConnectionID() (ConnectionID, error) {
+	nonce := make([]byte, g.codec.config.NonceLen)
+	if _, err := rand.Read(nonce); err != nil {
+		return ConnectionID{}, err
+	}
+	return g.codec.encode(g.serverID, nonce), nil
+}
+
+func (g *quicLBConnIDGenerator) ConnectionIDLen() int {
+	return g.codec.config.connIDLen()
+}
+
+// ErrUnroutableConnectionID is returned by the LoadBalancerDecoder if the connection ID
+// was not generated using any of the configurations known to the decoder.
+// The load balancer should then route the packet using a fallback algorithm.
+var ErrUnroutableConnectionID = errors.New("quic-lb: unroutable connection ID")
+
+// A LoadBalancerDecoder extracts the server ID from connection IDs generated by a
+// generator created by NewLoadBalancerConnectionIDGenerator.
+// It is safe for concurrent use.
+type LoadBalancerDecoder struct {
+	codecs [quicLBMaxConfigID + 1]*quicLBCodec
+}
+
+// NewLoadBalancerDecoder creates a new LoadBalancerDecoder.
+// Multiple configurations can be used at the same time, as long as they use different config IDs.
+func NewLoadBalancerDecoder(configs ...LoadBalancerConfig) (*LoadBalancerDecoder, error) {
+	d := &LoadBalancerDecoder{}
+	for _, conf := range configs {
+		codec, err := newQUICLBCodec(conf)
+		if err != nil {
+			return nil, err
+		}
+		if d.codecs[conf.ConfigID] != nil {
+			return nil, fmt.Errorf("quic-lb: duplicate config ID %d", conf.ConfigID)
+		}
+		d.codecs[conf.ConfigID] = codec
+	}
+	return d, nil
+}
+
+// ServerID extracts the server ID from a connection ID.
+// The config ID 7 is reserved for connection IDs that aren't routable.
+// It also returns the config ID used to generate the connection ID.
+// If the connection ID is not routable, an ErrUnroutableConnectionID is returned.
+func (d *LoadBalancerDecoder) ServerID(connID ConnectionID) (serverID []byte, configID uint8, _ error) {
+	if connID.Len() == 0 {
+		return nil, 0, ErrUnroutableConnectionID
+	}
+	b := connID.Bytes()
+	configID = b[0] >> quicLBConfigIDShift
+	if configID == quicLBUnroutableConfigID {
+		return nil, 0, ErrUnroutableConnectionID
+	}
+	codec := d.codecs[configID]
+	// The connection ID may be longer than the configured length,
+	// e.g. if the load balancer takes the maximum number of bytes from a short header packet.
+	if codec == nil || len(b) < codec.config.connIDLen() {
+		return nil, 0, ErrUnroutableConnectionID
+	}
+	return codec.decode(b[:codec.config.connIDLen()]), configID, nil
+}
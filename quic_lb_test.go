@@ -0,0 +1,354 @@
+package quic
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// quicLBFourPassReference is a bit-level implementation of the four-pass encryption described in
+// section 5.4.2 of draft-ietf-quic-load-balancers. It treats the halves as integers, and is therefore
+// independent of the byte and nibble handling of the implementation.
+func quicLBFourPassReference(key, plaintext []byte) []byte {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		panic(err)
+	}
+	plaintextLen := len(plaintext)
+	halfLen := (plaintextLen + 1) / 2
+	halfBits := uint(4 * plaintextLen)
+	mask := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), halfBits), big.NewInt(1))
+	p := new(big.Int).SetBytes(plaintext)
+	left := new(big.Int).Rsh(p, halfBits)
+	right := new(big.Int).And(p, mask)
+
+	// The left half is aligned to the most significant bit of its first octet, the right half to the least significant
+	// bit of its last octet. The expanded half is preceded by the plaintext length and the pass number,
+	// and followed by zeros.
+	// Only the first halfLen octets of the encrypted block are used.
+	encrypt := func(half *big.Int, leftAligned bool, pass byte) *big.Int {
+		if leftAligned {
+			half = new(big.Int).Lsh(half, uint(8*halfLen)-halfBits)
+		}
+		var b [aes.BlockSize]byte
+		b[0] = byte(plaintextLen)
+		b[1] = pass
+		half.FillBytes(b[2 : 2+halfLen])
+		block.Encrypt(b[:], b[:])
+		return new(big.Int).SetBytes(b[:halfLen])
+	}
+	// the right half is XORed with the least significant bits, the left half with the most significant bits
+	right.Xor(right, new(big.Int).And(encrypt(left, true, 1), mask))
+	left.Xor(left, new(big.Int).Rsh(encrypt(right, false, 2), uint(8*halfLen)-halfBits))
+	right.Xor(right, new(big.Int).And(encrypt(left, true, 3), mask))
+	left.Xor(left, new(big.Int).Rsh(encrypt(right, false, 4), uint(8*halfLen)-halfBits))
+
+	out := make([]byte, plaintextLen)
+	new(big.Int).Or(new(big.Int).Lsh(left, halfBits), right).FillBytes(out)
+	return out
+}
+
+func mustDecodeHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+var _ = Describe("QUIC-LB", func() {
+	key := []byte{0xfd, 0xf7, 0x26, 0xa9, 0x89, 0x3e, 0xc0, 0x5c, 0x06, 0x32, 0xd3, 0x95, 0x66, 0x80, 0xba, 0xf0}
+
+	It("validates the config", func() {
+		for _, tc := range []struct {
+			config LoadBalancerConfig
+			err    string
+		}{
+			{LoadBalancerConfig{ConfigID: 7, ServerIDLen: 2, NonceLen: 4}, "quic-lb: invalid config ID 7"},
+			{LoadBalancerConfig{ServerIDLen: 0, NonceLen: 4}, "quic-lb: invalid server ID length 0"},
+			{LoadBalancerConfig{ServerIDLen: 16, NonceLen: 4}, "quic-lb: invalid server ID length 16"},
+			{LoadBalancerConfig{ServerIDLen: 2, NonceLen: 3}, "quic-lb: invalid nonce length 3"},
+			{LoadBalancerConfig{ServerIDLen: 2, NonceLen: 19}, "quic-lb: invalid nonce length 19"},
+			{LoadBalancerConfig{ServerIDLen: 10, NonceLen: 10}, "quic-lb: server ID and nonce too long (20 bytes)"},
+			{LoadBalancerConfig{ServerIDLen: 2, NonceLen: 4, Key: make([]byte, 32)}, "quic-lb: invalid key length 32"},
+		} {
+			_, err := NewLoadBalancerConnectionIDGenerator(tc.config, make([]byte, tc.config.ServerIDLen))
+			Expect(err).To(MatchError(tc.err))
+			_, err = NewLoadBalancerDecoder(tc.config)
+			Expect(err).To(MatchError(tc.err))
+		}
+	})
+
+	It("rejects server IDs of the wrong length", func() {
+		_, err := NewLoadBalancerConnectionIDGenerator(LoadBalancerConfig{ServerIDLen: 3, NonceLen: 4}, []byte{1, 2})
+		Expect(err).To(MatchError("quic-lb: expected a 3 byte server ID, got 2 bytes"))
+	})
+
+	It("rejects duplicate config IDs", func() {
+		_, err := NewLoadBalancerDecoder(
+			LoadBalancerConfig{ConfigID: 1, ServerIDLen: 3, NonceLen: 4},
+			LoadBalancerConfig{ConfigID: 1, ServerIDLen: 2, NonceLen: 6},
+		)
+		Expect(err).To(MatchError("quic-lb: duplicate config ID 1"))
+	})
+
+	It("encodes the server ID in plaintext", func() {
+		conf := LoadBalancerConfig{ConfigID: 2, ServerIDLen: 3, NonceLen: 5}
+		g, err := NewLoadBalancerConnectionIDGenerator(conf, []byte{0xde, 0xad, 0xbe})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(g.ConnectionIDLen()).To(Equal(9))
+		connID, err := g.GenerateConnectionID()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(connID.Len()).To(Equal(9))
+		// config rotation bits 0b010, followed by the length minus one
+		Expect(connID.Bytes()[0]).To(Equal(byte(0b010_01000)))
+		Expect(connID.Bytes()[1:4]).To(Equal([]byte{0xde, 0xad, 0xbe}))
+	})
+
+	It("uses a random nonce", func() {
+		g, err := NewLoadBalancerConnectionIDGenerator(LoadBalancerConfig{ServerIDLen: 3, NonceLen: 8, Key: key}, []byte{1, 2, 3})
+		Expect(err).ToNot(HaveOccurred())
+		connIDs := make(map[ConnectionID]struct{})
+		for i := 0; i < 100; i++ {
+			connID, err := g.GenerateConnectionID()
+			Expect(err).ToNot(HaveOccurred())
+			connIDs[connID] = struct{}{}
+		}
+		Expect(connIDs).To(HaveLen(100))
+	})
+
+	It("encrypts the server ID", func() {
+		serverID := []byte{0xde, 0xad, 0xbe, 0xef}
+		for _, nonceLen := range []int{4, 5, 12, 15} {
+			g, err := NewLoadBalancerConnectionIDGenerator(LoadBalancerConfig{ServerIDLen: 4, NonceLen: nonceLen, Key: key}, serverID)
+			Expect(err).ToNot(HaveOccurred())
+			connID, err := g.GenerateConnectionID()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(bytes.Contains(connID.Bytes(), serverID)).To(BeFalse())
+		}
+	})
+
+	for _, enc := range []bool{false, true} {
+		encrypted := enc
+
+		Context(fmt.Sprintf("encrypted: %t", encrypted), func() {
+			It("decodes the server ID for all valid lengths", func() {
+				for serverIDLen := 1; serverIDLen <= quicLBMaxServerIDLen; serverIDLen++ {
+					for nonceLen := quicLBMinNonceLen; serverIDLen+nonceLen <= quicLBMaxPlaintextLen; nonceLen++ {
+						conf := LoadBalancerConfig{
+							ConfigID:    uint8(serverIDLen % (quicLBMaxConfigID + 1)),
+							ServerIDLen: serverIDLen,
+							NonceLen:    nonceLen,
+						}
+						if encrypted {
+							conf.Key = key
+						}
+						serverID := make([]byte, serverIDLen)
+						for i := range serverID {
+							serverID[i] = byte(0xf0 + i)
+						}
+						g, err := NewLoadBalancerConnectionIDGenerator(conf, serverID)
+						Expect(err).ToNot(HaveOccurred())
+						d, err := NewLoadBalancerDecoder(conf)
+						Expect(err).ToNot(HaveOccurred())
+						for i := 0; i < 10; i++ {
+							connID, err := g.GenerateConnectionID()
+							Expect(err).ToNot(HaveOccurred())
+							Expect(connID.Len()).To(Equal(1 + serverIDLen + nonceLen))
+							Expect(int(connID.Bytes()[0]&0x1f) + 1).To(Equal(connID.Len()))
+							id, configID, err := d.ServerID(connID)
+							Expect(err).ToNot(HaveOccurred())
+							Expect(id).To(Equal(serverID))
+							Expect(configID).To(Equal(conf.ConfigID))
+						}
+					}
+				}
+			})
+		})
+	}
+
+	It("decodes connection IDs using multiple configs", func() {
+		conf1 := LoadBalancerConfig{ConfigID: 0, ServerIDLen: 2, NonceLen: 6, Key: key}
+		conf2 := LoadBalancerConfig{ConfigID: 3, ServerIDLen: 4, NonceLen: 8}
+		d, err := NewLoadBalancerDecoder(conf1, conf2)
+		Expect(err).ToNot(HaveOccurred())
+		g1, err := NewLoadBalancerConnectionIDGenerator(conf1, []byte{1, 2})
+		Expect(err).ToNot(HaveOccurred())
+		g2, err := NewLoadBalancerConnectionIDGenerator(conf2, []byte{3, 4, 5, 6})
+		Expect(err).ToNot(HaveOccurred())
+		connID1, err := g1.GenerateConnectionID()
+		Expect(err).ToNot(HaveOccurred())
+		connID2, err := g2.GenerateConnectionID()
+		Expect(err).ToNot(HaveOccurred())
+		serverID, configID, err := d.ServerID(connID1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(serverID).To(Equal([]byte{1, 2}))
+		Expect(configID).To(BeZero())
+		serverID, configID, err = d.ServerID(connID2)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(serverID).To(Equal([]byte{3, 4, 5, 6}))
+		Expect(configID).To(Equal(uint8(3)))
+	})
+
+	It("decodes connection IDs that are followed by more data", func() {
+		conf := LoadBalancerConfig{ServerIDLen: 3, NonceLen: 5, Key: key}
+		g, err := NewLoadBalancerConnectionIDGenerator(conf, []byte{1, 2, 3})
+		Expect(err).ToNot(HaveOccurred())
+		d, err := NewLoadBalancerDecoder(conf)
+		Expect(err).ToNot(HaveOccurred())
+		connID, err := g.GenerateConnectionID()
+		Expect(err).ToNot(HaveOccurred())
+		b := append(connID.Bytes(), make([]byte, protocol.MaxConnIDLen-connID.Len())...)
+		serverID, _, err := d.ServerID(protocol.ParseConnectionID(b))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(serverID).To(Equal([]byte{1, 2, 3}))
+	})
+
+	It("refuses to decode unroutable connection IDs", func() {
+		d, err := NewLoadBalancerDecoder(LoadBalancerConfig{ConfigID: 1, ServerIDLen: 3, NonceLen: 5})
+		Expect(err).ToNot(HaveOccurred())
+		_, _, err = d.ServerID(protocol.ConnectionID{})
+		Expect(err).To(MatchError(ErrUnroutableConnectionID))
+		// reserved config ID
+		_, _, err = d.ServerID(protocol.ParseConnectionID([]byte{0b111_01000, 1, 2, 3, 4, 5, 6, 7, 8}))
+		Expect(err).To(MatchError(ErrUnroutableConnectionID))
+		// unknown config ID
+		_, _, err = d.ServerID(protocol.ParseConnectionID([]byte{0b010_01000, 1, 2, 3, 4, 5, 6, 7, 8}))
+		Expect(err).To(MatchError(ErrUnroutableConnectionID))
+		// too short
+		_, _, err = d.ServerID(protocol.ParseConnectionID([]byte{0b001_01000, 1, 2, 3, 4, 5, 6, 7}))
+		Expect(err).To(MatchError(ErrUnroutableConnectionID))
+		serverID, _, err := d.ServerID(protocol.ParseConnectionID([]byte{0b001_01000, 1, 2, 3, 4, 5, 6, 7, 8}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(serverID).To(Equal([]byte{1, 2, 3}))
+	})
+
+	Context("known answers", func() {
+		key := mustDecodeHex("4d9d0fd25a25e7f321ef464e13f9fa3d")
+
+		check := func(conf LoadBalancerConfig, serverID, nonce []byte, expected string) {
+			codec, err := newQUICLBCodec(conf)
+			ExpectWithOffset(1, err).ToNot(HaveOccurred())
+			connID := codec.encode(serverID, nonce)
+			ExpectWithOffset(1, hex.EncodeToString(connID.Bytes())).To(Equal(expected))
+			d, err := NewLoadBalancerDecoder(conf)
+			ExpectWithOffset(1, err).ToNot(HaveOccurred())
+			id, configID, err := d.ServerID(connID)
+			ExpectWithOffset(1, err).ToNot(HaveOccurred())
+			ExpectWithOffset(1, id).To(Equal(serverID))
+			ExpectWithOffset(1, configID).To(Equal(conf.ConfigID))
+		}
+
+		It("encodes unencrypted connection IDs", func() {
+			for _, tc := range []struct {
+				configID        uint8
+				serverID, nonce string
+				connID          string
+			}{
+				{0, "c4605e", "4504cc4f", "07c4605e4504cc4f"},
+				{1, "350d28b420", "3487d970b0", "2a350d28b4203487d970b0"},
+				{2, "8390", "fc60ee83", "468390fc60ee83"},
+				{6, "da8d2b44040b2f6b29", "2eb99a8e", "cdda8d2b44040b2f6b292eb99a8e"},
+			} {
+				serverID := mustDecodeHex(tc.serverID)
+				nonce := mustDecodeHex(tc.nonce)
+				check(LoadBalancerConfig{ConfigID: tc.configID, ServerIDLen: len(serverID), NonceLen: len(nonce)}, serverID, nonce, tc.connID)
+			}
+		})
+
+		It("encrypts 16 byte plaintexts using a single AES-ECB pass", func() {
+			serverID := mustDecodeHex("ed793a51d49b8f5fab65")
+			nonce := mustDecodeHex("ee080dbf48c0")
+			block, err := aes.NewCipher(key)
+			Expect(err).ToNot(HaveOccurred())
+			ciphertext := make([]byte, aes.BlockSize)
+			block.Encrypt(ciphertext, append(append([]byte{}, serverID...), nonce...))
+			check(
+				LoadBalancerConfig{ConfigID: 2, ServerIDLen: len(serverID), NonceLen: len(nonce), Key: key},
+				serverID, nonce,
+				"50"+hex.EncodeToString(ciphertext),
+			)
+		})
+
+		It("encrypts other plaintext lengths using four Feistel passes", func() {
+			for plaintextLen := 5; plaintextLen <= quicLBMaxPlaintextLen; plaintextLen++ {
+				if plaintextLen == aes.BlockSize {
+					continue
+				}
+				for serverIDLen := 1; serverIDLen <= min(plaintextLen-quicLBMinNonceLen, quicLBMaxServerIDLen); serverIDLen++ {
+					plaintext := make([]byte, plaintextLen)
+					for i := range plaintext {
+						plaintext[i] = byte(0x31*i + 0x17*serverIDLen)
+					}
+					serverID, nonce := plaintext[:serverIDLen], plaintext[serverIDLen:]
+					check(
+						LoadBalancerConfig{ServerIDLen: len(serverID), NonceLen: len(nonce), Key: key},
+						serverID, nonce,
+						hex.EncodeToString(append([]byte{byte(plaintextLen)}, quicLBFourPassReference(key, plaintext)...)),
+					)
+				}
+			}
+		})
+
+		It("encrypts connection IDs", func() {
+			// regression vectors, checked against quicLBFourPassReference
+			for _, tc := range []struct {
+				configID        uint8
+				serverID, nonce string
+				connID          string
+			}{
+				{0, "c4605e", "4504cc4f", "0751cad1860201f9"},
+				{1, "350d28b420", "3487d970b0", "2a774c1d29e36152519eb3"},
+				{2, "8390", "fc60ee83", "46e876cc917ea8"},
+				{0, "9bf7c2e8a0", "56b6e4baf7", "0a36df976230ab8e0e5c81"},
+				{1, "5e", "d60e4c7cb45b74", "28f0e677398f38ff3f"},
+				{2, "da8d2b44040b2f6b29", "2eb99a8e", "4dd484f0c6bcda880bd1b2247abf"},
+			} {
+				serverID := mustDecodeHex(tc.serverID)
+				nonce := mustDecodeHex(tc.nonce)
+				plaintext := append(append([]byte{}, serverID...), nonce...)
+				Expect(hex.EncodeToString(quicLBFourPassReference(key, plaintext))).To(Equal(tc.connID[2:]))
+				check(LoadBalancerConfig{ConfigID: tc.configID, ServerIDLen: len(serverID), NonceLen: len(nonce), Key: key}, serverID, nonce, tc.connID)
+			}
+		})
+
+		It("matches the test vectors published in the draft", func() {
+			// test vectors from appendix B of draft-ietf-quic-load-balancers
+			draftKey := mustDecodeHex("8f95f09245765f80256934e50c66207f")
+			for _, tc := range []struct {
+				configID        uint8
+				serverID, nonce string
+				key             []byte
+				connID          string
+			}{
+				// unencrypted
+				{0, "c4605e", "4504cc4f", nil, "07c4605e4504cc4f"},
+				{1, "350d28b420", "3487d970b0", nil, "2a350d28b4203487d970b0"},
+				{2, "8390", "fc60ee83", nil, "468390fc60ee83"},
+				{6, "da8d2b44040b2f6b29", "2eb99a8e", nil, "cdda8d2b44040b2f6b292eb99a8e"},
+				// four-pass encryption
+				{0, "ed793a", "ee080dbf", draftKey, "074126ee38bf5454"},
+				{2, "ed793a51d49b8f5fab65", "ee080dbf48", draftKey, "4fcd3f572d4eefb046fdb51d164efccc"},
+				// single-pass encryption
+				{4, "ed793a51d49b8f5f", "ee080dbf48c0d1e5", draftKey, "904dd2d05a7b0de9b2b9907afb5ecf8cc3"},
+			} {
+				serverID := mustDecodeHex(tc.serverID)
+				nonce := mustDecodeHex(tc.nonce)
+				if tc.key != nil {
+					plaintext := append(append([]byte{}, serverID...), nonce...)
+					if len(plaintext) != aes.BlockSize {
+						Expect(hex.EncodeToString(quicLBFourPassReference(tc.key, plaintext))).To(Equal(tc.connID[2:]))
+					}
+				}
+				check(LoadBalancerConfig{ConfigID: tc.configID, ServerIDLen: len(serverID), NonceLen: len(nonce), Key: tc.key}, serverID, nonce, tc.connID)
+			}
+		})
+	})
+})
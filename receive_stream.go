@@ -18,6 +18,7 @@ type receiveStreamI interface {
 
 	handleStreamFrame(*wire.StreamFrame) error
 	handleResetStreamFrame(*wire.ResetStreamFrame) error
+	handleResetStreamAtFrame(*wire.ResetStreamAtFrame) error
 	closeForShutdown(error)
 	getWindowUpdate() protocol.ByteCount
 }
@@ -36,11 +37,17 @@ type receiveStream struct {
 	currentFrameDone   func()
 	readPosInFrame     int
 	currentFrameIsLast bool // is the currentFrame the last frame on this stream
+	readOffset         protocol.ByteCount
 
 	finRead             bool // set once we read a frame with a Fin
 	closeForShutdownErr error
 	cancelReadErr       error
 	resetRemotelyErr    *StreamError
+	// set when a RESET_STREAM_AT frame is received, until the data below the reliable size has been read
+	pendingResetErr *StreamError
+	reliableSize    protocol.ByteCount
+	// says if the stream is completed once the reset is surfaced to the application
+	completedOnReset bool
 
 	readChan chan struct{}
 	readOnce chan struct{} // cap: 1, to protect against concurrent use of Read
@@ -84,8 +91,13 @@ func (s *receiveStream) Read(p []byte) (int, error) {
 
 	s.mutex.Lock()
 	completed, n, err := s.readImpl(p)
+	// When completed by a RESET_STREAM_AT frame, the data above the reliable size won't be read.
+	abandon := completed && s.resetRemotelyErr != nil
 	s.mutex.Unlock()
 
+	if abandon {
+		s.flowController.Abandon()
+	}
 	if completed {
 		s.sender.onStreamCompleted(s.streamID)
 	}
@@ -124,8 +136,8 @@ func (s *receiveStream) readImpl(p []byte) (bool /*stream completed */, int, err
 			if s.cancelReadErr != nil {
 				return false, bytesRead, s.cancelReadErr
 			}
-			if s.resetRemotelyErr != nil {
-				return false, bytesRead, s.resetRemotelyErr
+			if completed := s.maybeSurfaceReset(); s.resetRemotelyErr != nil {
+				return completed, bytesRead, s.resetRemotelyErr
 			}
 
 			deadline := s.deadline
@@ -167,8 +179,14 @@ func (s *receiveStream) readImpl(p []byte) (bool /*stream completed */, int, err
 			return false, bytesRead, fmt.Errorf("BUG: readPosInFrame (%d) > frame.DataLen (%d) in stream.Read", s.readPosInFrame, len(s.currentFrame))
 		}
 
-		m := copy(p[bytesRead:], s.currentFrame[s.readPosInFrame:])
+		data := s.currentFrame[s.readPosInFrame:]
+		// after receiving a RESET_STREAM_AT frame, only the data below the reliable size is delivered
+		if s.pendingResetErr != nil {
+			data = data[:min(protocol.ByteCount(len(data)), s.reliableSize-s.readOffset)]
+		}
+		m := copy(p[bytesRead:], data)
 		s.readPosInFrame += m
+		s.readOffset += protocol.ByteCount(m)
 		bytesRead += m
 
 		// when a RESET_STREAM was received, the flow controller was already
@@ -176,6 +194,9 @@ func (s *receiveStream) readImpl(p []byte) (bool /*stream completed */, int, err
 		if s.resetRemotelyErr == nil {
 			s.flowController.AddBytesRead(protocol.ByteCount(m))
 		}
+		if completed := s.maybeSurfaceReset(); s.resetRemotelyErr != nil {
+			return completed, bytesRead, s.resetRemotelyErr
+		}
 
 		if s.readPosInFrame >= len(s.currentFrame) && s.currentFrameIsLast {
 			s.finRead = true
@@ -216,6 +237,11 @@ func (s *receiveStream) cancelReadImpl(errorCode qerr.StreamErrorCode) bool /* c
 		return false
 	}
 	s.cancelReadErr = &StreamError{StreamID: s.streamID, ErrorCode: errorCode, Remote: false}
+	// The data below the reliable size won't be read anymore.
+	if s.pendingResetErr != nil {
+		s.resetRemotelyErr = s.pendingResetErr
+		s.pendingResetErr = nil
+	}
 	s.signalRead()
 	s.sender.queueControlFrame(&wire.StopSendingFrame{
 		StreamID:  s.streamID,
@@ -270,26 +296,66 @@ func (s *receiveStream) handleResetStreamFrame(frame *wire.ResetStreamFrame) err
 }
 
 func (s *receiveStream) handleResetStreamFrameImpl(frame *wire.ResetStreamFrame) (bool /*completed */, error) {
+	// A RESET_STREAM frame is equivalent to a RESET_STREAM_AT frame with a reliable size of 0.
+	return s.handleResetImpl(frame.ErrorCode, frame.FinalSize, 0)
+}
+
+func (s *receiveStream) handleResetStreamAtFrame(frame *wire.ResetStreamAtFrame) error {
+	s.mutex.Lock()
+	completed, err := s.handleResetImpl(frame.ErrorCode, frame.FinalSize, frame.ReliableSize)
+	s.mutex.Unlock()
+
+	if completed {
+		s.flowController.Abandon()
+		s.sender.onStreamCompleted(s.streamID)
+	}
+	return err
+}
+
+func (s *receiveStream) handleResetImpl(errorCode qerr.StreamErrorCode, finalSize, reliableSize protocol.ByteCount) (bool /*completed */, error) {
 	if s.closeForShutdownErr != nil {
 		return false, nil
 	}
-	if err := s.flowController.UpdateHighestReceived(frame.FinalSize, true); err != nil {
+	if err := s.flowController.UpdateHighestReceived(finalSize, true); err != nil {
 		return false, err
 	}
 	newlyRcvdFinalOffset := s.finalOffset == protocol.MaxByteCount
-	s.finalOffset = frame.FinalSize
+	s.finalOffset = finalSize
 
 	// ignore duplicate RESET_STREAM frames for this stream (after checking their final offset)
 	if s.resetRemotelyErr != nil {
 		return false, nil
 	}
-	s.resetRemotelyErr = &StreamError{
-		StreamID:  s.streamID,
-		ErrorCode: frame.ErrorCode,
-		Remote:    true,
+	if s.pendingResetErr == nil {
+		s.pendingResetErr = &StreamError{
+			StreamID:  s.streamID,
+			ErrorCode: errorCode,
+			Remote:    true,
+		}
+		s.reliableSize = reliableSize
+		s.completedOnReset = newlyRcvdFinalOffset
+	} else {
+		// the peer can reduce the reliable size, but it can't increase it
+		s.reliableSize = min(s.reliableSize, reliableSize)
 	}
 	s.signalRead()
-	return newlyRcvdFinalOffset, nil
+	return s.maybeSurfaceReset(), nil
+}
+
+// maybeSurfaceReset makes the reset error returned by Read, once all data below the reliable size was read.
+// It returns true if the stream is completed.
+// It must be called with the mutex locked.
+func (s *receiveStream) maybeSurfaceReset() bool /* completed */ {
+	if s.pendingResetErr == nil {
+		return false
+	}
+	// If reading was canceled, the application isn't interested in the remaining data.
+	if s.readOffset < s.reliableSize && s.cancelReadErr == nil {
+		return false
+	}
+	s.resetRemotelyErr = s.pendingResetErr
+	s.pendingResetErr = nil
+	return s.completedOnReset
 }
 
 func (s *receiveStream) CloseRemote(offset protocol.ByteCount) {
@@ -314,6 +380,16 @@ func (s *receiveStream) closeForShutdown(err error) {
 	s.signalRead()
 }
 
+func (s *receiveStream) Stats() StreamStats {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return StreamStats{
+		FramesReceivedOutOfOrder: s.frameQueue.numOutOfOrder,
+		ReceiveWindow:            uint64(s.flowController.RemainingReceiveWindow()),
+	}
+}
+
 func (s *receiveStream) getWindowUpdate() protocol.ByteCount {
 	return s.flowController.GetWindowUpdate()
 }
@@ -687,6 +687,99 @@ var _ = Describe("Receive Stream", func() {
 				Expect(err).ToNot(HaveOccurred())
 			})
 		})
+		Context("receiving RESET_STREAM_AT frames", func() {
+			rst := &wire.ResetStreamAtFrame{
+				StreamID:     streamID,
+				ErrorCode:    1234,
+				FinalSize:    42,
+				ReliableSize: 6,
+			}
+			streamErr := &StreamError{
+				StreamID:  streamID,
+				ErrorCode: 1234,
+				Remote:    true,
+			}
+
+			It("delivers data up to the reliable size", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(11), false)
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(42), true)
+				Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foobarlorem")})).To(Succeed())
+				Expect(str.handleResetStreamAtFrame(rst)).To(Succeed())
+				gomock.InOrder(
+					mockFC.EXPECT().AddBytesRead(protocol.ByteCount(6)),
+					mockFC.EXPECT().Abandon(),
+					mockSender.EXPECT().onStreamCompleted(streamID),
+				)
+				b := make([]byte, 100)
+				n, err := strWithTimeout.Read(b)
+				Expect(err).To(Equal(streamErr))
+				Expect(b[:n]).To(Equal([]byte("foobar")))
+				_, err = strWithTimeout.Read(b)
+				Expect(err).To(Equal(streamErr))
+			})
+
+			It("unblocks Read once the data below the reliable size was received", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(42), true)
+				Expect(str.handleResetStreamAtFrame(rst)).To(Succeed())
+				done := make(chan struct{})
+				go func() {
+					defer GinkgoRecover()
+					b := make([]byte, 100)
+					n, err := strWithTimeout.Read(b)
+					Expect(err).To(Equal(streamErr))
+					Expect(b[:n]).To(Equal([]byte("foobar")))
+					close(done)
+				}()
+				Consistently(done).ShouldNot(BeClosed())
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(6), false)
+				mockFC.EXPECT().AddBytesRead(protocol.ByteCount(6))
+				mockFC.EXPECT().Abandon()
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foobar")})).To(Succeed())
+				Eventually(done).Should(BeClosed())
+			})
+
+			It("returns the error right away if the data below the reliable size was already read", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(6), false)
+				mockFC.EXPECT().AddBytesRead(protocol.ByteCount(6))
+				Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foobar")})).To(Succeed())
+				b := make([]byte, 6)
+				_, err := strWithTimeout.Read(b)
+				Expect(err).ToNot(HaveOccurred())
+				gomock.InOrder(
+					mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(42), true),
+					mockFC.EXPECT().Abandon(),
+					mockSender.EXPECT().onStreamCompleted(streamID),
+				)
+				Expect(str.handleResetStreamAtFrame(rst)).To(Succeed())
+				_, err = strWithTimeout.Read(b)
+				Expect(err).To(Equal(streamErr))
+			})
+
+			It("handles a RESET_STREAM frame that reduces the reliable size", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(42), true).Times(2)
+				Expect(str.handleResetStreamAtFrame(rst)).To(Succeed())
+				mockFC.EXPECT().Abandon()
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				Expect(str.handleResetStreamFrame(&wire.ResetStreamFrame{
+					StreamID:  streamID,
+					ErrorCode: 1234,
+					FinalSize: 42,
+				})).To(Succeed())
+				_, err := strWithTimeout.Read([]byte{0})
+				Expect(err).To(Equal(streamErr))
+			})
+
+			It("doesn't call onStreamCompleted twice when reading is canceled", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(42), true).Times(2)
+				Expect(str.handleResetStreamAtFrame(rst)).To(Succeed())
+				mockSender.EXPECT().queueControlFrame(gomock.Any())
+				mockFC.EXPECT().Abandon()
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				str.CancelRead(4321)
+				Expect(str.handleResetStreamAtFrame(rst)).To(Succeed())
+			})
+		})
 	})
 
 	Context("flow control", func() {
@@ -706,4 +799,16 @@ var _ = Describe("Receive Stream", func() {
 			Expect(str.getWindowUpdate()).To(Equal(protocol.ByteCount(0x100)))
 		})
 	})
+
+	It("returns statistics", func() {
+		mockFC.EXPECT().UpdateHighestReceived(gomock.Any(), false).AnyTimes()
+		mockSender.EXPECT().onHasStreamData(gomock.Any()).AnyTimes()
+		Expect(str.handleStreamFrame(&wire.StreamFrame{Offset: 6, Data: []byte("bar")})).To(Succeed())
+		Expect(str.handleStreamFrame(&wire.StreamFrame{Offset: 0, Data: []byte("foo")})).To(Succeed())
+		Expect(str.handleStreamFrame(&wire.StreamFrame{Offset: 3, Data: []byte("baz")})).To(Succeed())
+		mockFC.EXPECT().RemainingReceiveWindow().Return(protocol.ByteCount(1337))
+		stats := str.Stats()
+		Expect(stats.FramesReceivedOutOfOrder).To(BeEquivalentTo(1))
+		Expect(stats.ReceiveWindow).To(BeEquivalentTo(1337))
+	})
 })
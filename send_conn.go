@@ -15,6 +15,9 @@ type sendConn interface {
 	RemoteAddr() net.Addr
 
 	capabilities() connCapabilities
+	// withRemoteAddr returns a sendConn that uses the same underlying conn,
+	// but sends to a different remote address.
+	withRemoteAddr(remote net.Addr, info packetInfo) sendConn
 }
 
 type sconn struct {
@@ -99,5 +102,9 @@ func (c *sconn) capabilities() connCapabilities {
 	return capabilities
 }
 
+func (c *sconn) withRemoteAddr(remote net.Addr, info packetInfo) sendConn {
+	return newSendConn(c.rawConn, remote, info, c.logger)
+}
+
 func (c *sconn) RemoteAddr() net.Addr { return c.remoteAddr }
 func (c *sconn) LocalAddr() net.Addr  { return c.localAddr }
@@ -1,12 +1,17 @@
 package quic
 
-import "github.com/quic-go/quic-go/internal/protocol"
+import (
+	"sync"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+)
 
 type sender interface {
 	Send(p *packetBuffer, gsoSize uint16, ecn protocol.ECN)
 	Run() error
 	WouldBlock() bool
 	Available() <-chan struct{}
+	SetConn(sendConn)
 	Close()
 }
 
@@ -21,7 +26,9 @@ type sendQueue struct {
 	closeCalled chan struct{} // runStopped when Close() is called
 	runStopped  chan struct{} // runStopped when the run loop returns
 	available   chan struct{}
-	conn        sendConn
+
+	connMutex sync.Mutex
+	conn      sendConn
 }
 
 var _ sender = &sendQueue{}
@@ -78,7 +85,11 @@ func (h *sendQueue) Run() error {
 			// make sure that all queued packets are actually sent out
 			shouldClose = true
 		case e := <-h.queue:
-			if err := h.conn.Write(e.buf.Data, e.gsoSize, e.ecn); err != nil {
+			// Hold the mutex while writing, so that the previous conn can be closed once SetConn returns.
+			h.connMutex.Lock()
+			err := h.conn.Write(e.buf.Data, e.gsoSize, e.ecn)
+			h.connMutex.Unlock()
+			if err != nil {
 				// This additional check enables:
 				// 1. Checking for "datagram too large" message from the kernel, as such,
 				// 2. Path MTU discovery,and
@@ -96,6 +107,16 @@ func (h *sendQueue) Run() error {
 	}
 }
 
+// SetConn sets the conn used for sending packets.
+// It is used when the connection migrates to a new path.
+// Packets that are still queued are sent on the new conn.
+// It waits for a pending write on the previous conn to complete.
+func (h *sendQueue) SetConn(c sendConn) {
+	h.connMutex.Lock()
+	h.conn = c
+	h.connMutex.Unlock()
+}
+
 func (h *sendQueue) Close() {
 	close(h.closeCalled)
 	// wait until the run loop returned
@@ -44,6 +44,25 @@ var _ = Describe("Send Queue", func() {
 		Eventually(done).Should(BeClosed())
 	})
 
+	It("sends packets on a new conn", func() {
+		c2 := NewMockSendConn(mockCtrl)
+		q.SetConn(c2)
+		q.Send(getPacket([]byte("foobar")), 10, protocol.ECNNon)
+
+		written := make(chan struct{})
+		c2.EXPECT().Write([]byte("foobar"), uint16(10), protocol.ECNNon).Do(func([]byte, uint16, protocol.ECN) error { close(written); return nil })
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			q.Run()
+			close(done)
+		}()
+
+		Eventually(written).Should(BeClosed())
+		q.Close()
+		Eventually(done).Should(BeClosed())
+	})
+
 	It("panics when Send() is called although there's no space in the queue", func() {
 		for i := 0; i < sendQueueCapacity; i++ {
 			Expect(q.WouldBlock()).To(BeFalse())
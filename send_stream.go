@@ -39,6 +39,8 @@ type sendStream struct {
 
 	cancelWriteErr      error
 	closeForShutdownErr error
+	// set when the stream is reset using CancelWriteAt, data below this offset is still delivered
+	reliableSize protocol.ByteCount
 
 	finishedWriting bool // set once Close() is called
 	finSent         bool // set when a STREAM_FRAME with FIN bit has been sent
@@ -51,7 +53,33 @@ type sendStream struct {
 	writeOnce chan struct{}
 	deadline  time.Time
 
+	// delivery deadline, see SetDeliveryDeadline
+	deliveryDeadline  time.Duration
+	deliveryErrorCode StreamErrorCode
+	// expiry time of data that hasn't been sent yet, ordered by offset
+	unsentExpiries []dataExpiry
+	// expiry time of STREAM frames that are either in flight or queued for retransmission
+	frameExpiries      map[*wire.StreamFrame]time.Time
+	deliveryTimer      *time.Timer
+	deliveryTimerAlarm time.Time // zero if the delivery timer is not armed
+
 	flowController flowcontrol.StreamFlowController
+
+	// statistics
+	bytesWritten       uint64
+	bytesAcked         uint64
+	bytesRetransmitted uint64
+	// zero if not blocked by (stream-level / connection-level) flow control
+	streamBlockedSince time.Time
+	connBlockedSince   time.Time
+	streamBlockedTime  time.Duration
+	connBlockedTime    time.Duration
+}
+
+// dataExpiry is the time when the data written starting at offset expires.
+type dataExpiry struct {
+	offset protocol.ByteCount
+	expiry time.Time
 }
 
 var (
@@ -105,6 +133,9 @@ func (s *sendStream) Write(p []byte) (int, error) {
 		return 0, nil
 	}
 
+	if s.deliveryDeadline > 0 {
+		s.trackExpiry(time.Now().Add(s.deliveryDeadline))
+	}
 	s.dataForWriting = p
 
 	var (
@@ -143,6 +174,7 @@ func (s *sendStream) Write(p []byte) (int, error) {
 			if !deadline.IsZero() {
 				if !time.Now().Before(deadline) {
 					s.dataForWriting = nil
+					s.bytesWritten += uint64(bytesWritten)
 					return bytesWritten, errDeadline
 				}
 				if deadlineTimer == nil {
@@ -177,6 +209,7 @@ func (s *sendStream) Write(p []byte) (int, error) {
 		s.mutex.Lock()
 	}
 
+	s.bytesWritten += uint64(bytesWritten)
 	if bytesWritten == len(p) {
 		return bytesWritten, nil
 	}
@@ -200,9 +233,25 @@ func (s *sendStream) canBufferStreamFrame() bool {
 // maxBytes is the maximum length this frame (including frame header) will have.
 func (s *sendStream) popStreamFrame(maxBytes protocol.ByteCount, v protocol.Version) (af ackhandler.StreamFrame, ok, hasMore bool) {
 	s.mutex.Lock()
-	f, hasMoreData := s.popNewOrRetransmittedStreamFrame(maxBytes, v)
+	now := time.Now()
+	if s.nextDataExpired(now) {
+		// Don't send data that already expired. Reset the stream instead.
+		s.mutex.Unlock()
+		s.expireData()
+		return ackhandler.StreamFrame{}, false, false
+	}
+	f, hasMoreData := s.popNewOrRetransmittedStreamFrame(maxBytes, v, now)
 	if f != nil {
 		s.numOutstandingFrames++
+		if s.frameExpiries != nil {
+			// retransmitted frames are already tracked
+			if _, ok := s.frameExpiries[f]; !ok {
+				if expiry := s.expiryAt(f.Offset); !expiry.IsZero() {
+					s.frameExpiries[f] = expiry
+				}
+			}
+			s.pruneUnsentExpiries()
+		}
 	}
 	s.mutex.Unlock()
 
@@ -215,8 +264,8 @@ func (s *sendStream) popStreamFrame(maxBytes protocol.ByteCount, v protocol.Vers
 	}, true, hasMoreData
 }
 
-func (s *sendStream) popNewOrRetransmittedStreamFrame(maxBytes protocol.ByteCount, v protocol.Version) (*wire.StreamFrame, bool /* has more data to send */) {
-	if s.cancelWriteErr != nil || s.closeForShutdownErr != nil {
+func (s *sendStream) popNewOrRetransmittedStreamFrame(maxBytes protocol.ByteCount, v protocol.Version, now time.Time) (*wire.StreamFrame, bool /* has more data to send */) {
+	if s.closeForShutdownErr != nil || (s.cancelWriteErr != nil && s.reliableSize == 0) {
 		return nil, false
 	}
 
@@ -226,13 +275,20 @@ func (s *sendStream) popNewOrRetransmittedStreamFrame(maxBytes protocol.ByteCoun
 			if f == nil {
 				return nil, true
 			}
+			s.bytesRetransmitted += uint64(f.DataLen())
 			// We always claim that we have more data to send.
 			// This might be incorrect, in which case there'll be a spurious call to popStreamFrame in the future.
 			return f, true
 		}
 	}
 
-	if len(s.dataForWriting) == 0 && s.nextFrame == nil {
+	if s.cancelWriteErr != nil {
+		// The stream was reset using CancelWriteAt.
+		// The only new data left to send is the part of the buffered STREAM frame below the reliable size.
+		if s.nextFrame == nil {
+			return nil, false
+		}
+	} else if len(s.dataForWriting) == 0 && s.nextFrame == nil {
 		if s.finishedWriting && !s.finSent {
 			s.finSent = true
 			return &wire.StreamFrame{
@@ -247,6 +303,8 @@ func (s *sendStream) popNewOrRetransmittedStreamFrame(maxBytes protocol.ByteCoun
 
 	sendWindow := s.flowController.SendWindowSize()
 	if sendWindow == 0 {
+		streamBlocked, connBlocked := s.flowController.IsBlocked()
+		s.updateBlockedTime(now, streamBlocked, connBlocked)
 		if isBlocked, offset := s.flowController.IsNewlyBlocked(); isBlocked {
 			s.sender.queueControlFrame(&wire.StreamDataBlockedFrame{
 				StreamID:          s.streamID,
@@ -256,12 +314,16 @@ func (s *sendStream) popNewOrRetransmittedStreamFrame(maxBytes protocol.ByteCoun
 		}
 		return nil, true
 	}
+	s.updateBlockedTime(now, false, false)
 
 	f, hasMoreData := s.popNewStreamFrame(maxBytes, sendWindow, v)
 	if dataLen := f.DataLen(); dataLen > 0 {
 		s.writeOffset += f.DataLen()
 		s.flowController.AddBytesSent(f.DataLen())
 	}
+	if s.cancelWriteErr != nil {
+		return f, s.nextFrame != nil
+	}
 	f.Fin = s.finishedWriting && s.dataForWriting == nil && s.nextFrame == nil && !s.finSent
 	if f.Fin {
 		s.finSent = true
@@ -318,6 +380,9 @@ func (s *sendStream) maybeGetRetransmission(maxBytes protocol.ByteCount, v proto
 	f := s.retransmissionQueue[0]
 	newFrame, needsSplit := f.MaybeSplitOffFrame(maxBytes, v)
 	if needsSplit {
+		if expiry, ok := s.frameExpiries[f]; ok {
+			s.frameExpiries[newFrame] = expiry
+		}
 		return newFrame, true
 	}
 	s.retransmissionQueue = s.retransmissionQueue[1:]
@@ -349,6 +414,10 @@ func (s *sendStream) getDataForWriting(f *wire.StreamFrame, maxBytes protocol.By
 
 func (s *sendStream) isNewlyCompleted() bool {
 	completed := (s.finSent || s.cancelWriteErr != nil) && s.numOutstandingFrames == 0 && len(s.retransmissionQueue) == 0
+	// after CancelWriteAt, the buffered data below the reliable size still needs to be sent
+	if s.reliableSize > 0 && s.nextFrame != nil {
+		completed = false
+	}
 	if completed && !s.completed {
 		s.completed = true
 		return true
@@ -375,11 +444,18 @@ func (s *sendStream) Close() error {
 }
 
 func (s *sendStream) CancelWrite(errorCode StreamErrorCode) {
-	s.cancelWriteImpl(errorCode, false)
+	s.cancelWriteImpl(errorCode, 0, false)
+}
+
+func (s *sendStream) CancelWriteAt(errorCode StreamErrorCode, reliableSize uint64) {
+	if !s.sender.peerSupportsResetStreamAt() {
+		reliableSize = 0
+	}
+	s.cancelWriteImpl(errorCode, protocol.ByteCount(reliableSize), false)
 }
 
 // must be called after locking the mutex
-func (s *sendStream) cancelWriteImpl(errorCode qerr.StreamErrorCode, remote bool) {
+func (s *sendStream) cancelWriteImpl(errorCode qerr.StreamErrorCode, reliableSize protocol.ByteCount, remote bool) {
 	s.mutex.Lock()
 	if s.cancelWriteErr != nil {
 		s.mutex.Unlock()
@@ -387,22 +463,89 @@ func (s *sendStream) cancelWriteImpl(errorCode qerr.StreamErrorCode, remote bool
 	}
 	s.cancelWriteErr = &StreamError{StreamID: s.streamID, ErrorCode: errorCode, Remote: remote}
 	s.ctxCancel(s.cancelWriteErr)
-	s.numOutstandingFrames = 0
-	s.retransmissionQueue = nil
+	s.updateBlockedTime(time.Now(), false, false)
+	s.unsentExpiries = nil
+	if s.frameExpiries != nil {
+		s.frameExpiries = make(map[*wire.StreamFrame]time.Time)
+	}
+	if s.deliveryTimer != nil {
+		s.deliveryTimer.Stop()
+		s.deliveryTimerAlarm = time.Time{}
+	}
+	// Data that was passed to Write, but not yet copied to a STREAM frame, is never sent.
+	maxReliableSize := s.writeOffset
+	if s.nextFrame != nil {
+		maxReliableSize += s.nextFrame.DataLen()
+	}
+	reliableSize = min(reliableSize, maxReliableSize)
+	var resetFrame wire.Frame
+	if reliableSize > 0 {
+		resetFrame = s.resetReliably(errorCode, reliableSize)
+	} else {
+		s.numOutstandingFrames = 0
+		s.retransmissionQueue = nil
+		resetFrame = &wire.ResetStreamFrame{
+			StreamID:  s.streamID,
+			FinalSize: s.writeOffset,
+			ErrorCode: errorCode,
+		}
+	}
+	hasStreamData := s.nextFrame != nil && s.reliableSize > 0
 	newlyCompleted := s.isNewlyCompleted()
 	s.mutex.Unlock()
 
 	s.signalWrite()
-	s.sender.queueControlFrame(&wire.ResetStreamFrame{
-		StreamID:  s.streamID,
-		FinalSize: s.writeOffset,
-		ErrorCode: errorCode,
-	})
+	s.sender.queueControlFrame(resetFrame)
+	if hasStreamData {
+		s.sender.onHasStreamData(s.streamID) // must be called without holding the mutex
+	}
 	if newlyCompleted {
 		s.sender.onStreamCompleted(s.streamID)
 	}
 }
 
+// resetReliably drops all data at and above the reliable size,
+// and returns the RESET_STREAM_AT frame that needs to be sent.
+// It must be called with the mutex locked.
+func (s *sendStream) resetReliably(errorCode qerr.StreamErrorCode, reliableSize protocol.ByteCount) *wire.ResetStreamAtFrame {
+	s.reliableSize = reliableSize
+	if s.nextFrame != nil {
+		if s.writeOffset >= reliableSize {
+			s.nextFrame.PutBack()
+			s.nextFrame = nil
+		} else {
+			s.nextFrame.Data = s.nextFrame.Data[:reliableSize-s.writeOffset]
+		}
+	}
+	retransmissionQueue := s.retransmissionQueue[:0]
+	for _, f := range s.retransmissionQueue {
+		if s.truncateToReliableSize(f) {
+			retransmissionQueue = append(retransmissionQueue, f)
+		}
+	}
+	s.retransmissionQueue = retransmissionQueue
+	return &wire.ResetStreamAtFrame{
+		StreamID:     s.streamID,
+		ErrorCode:    errorCode,
+		FinalSize:    max(s.writeOffset, reliableSize),
+		ReliableSize: reliableSize,
+	}
+}
+
+// truncateToReliableSize cuts off the data at and above the reliable size.
+// It returns false if no data is left, in which case the frame must not be used any more.
+func (s *sendStream) truncateToReliableSize(f *wire.StreamFrame) bool {
+	if f.Offset >= s.reliableSize {
+		f.PutBack()
+		return false
+	}
+	if f.Offset+f.DataLen() > s.reliableSize {
+		f.Data = f.Data[:s.reliableSize-f.Offset]
+	}
+	f.Fin = false
+	return true
+}
+
 func (s *sendStream) updateSendWindow(limit protocol.ByteCount) {
 	s.mutex.Lock()
 	hasStreamData := s.dataForWriting != nil || s.nextFrame != nil
@@ -414,8 +557,53 @@ func (s *sendStream) updateSendWindow(limit protocol.ByteCount) {
 	}
 }
 
+// updateBlockedTime records the time sending was blocked by flow control.
+// It must be called with the mutex locked.
+func (s *sendStream) updateBlockedTime(now time.Time, streamBlocked, connBlocked bool) {
+	if streamBlocked {
+		if s.streamBlockedSince.IsZero() {
+			s.streamBlockedSince = now
+		}
+	} else if !s.streamBlockedSince.IsZero() {
+		s.streamBlockedTime += now.Sub(s.streamBlockedSince)
+		s.streamBlockedSince = time.Time{}
+	}
+	if connBlocked {
+		if s.connBlockedSince.IsZero() {
+			s.connBlockedSince = now
+		}
+	} else if !s.connBlockedSince.IsZero() {
+		s.connBlockedTime += now.Sub(s.connBlockedSince)
+		s.connBlockedSince = time.Time{}
+	}
+}
+
+func (s *sendStream) Stats() StreamStats {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	streamBlockedTime := s.streamBlockedTime
+	if !s.streamBlockedSince.IsZero() {
+		streamBlockedTime += now.Sub(s.streamBlockedSince)
+	}
+	connBlockedTime := s.connBlockedTime
+	if !s.connBlockedSince.IsZero() {
+		connBlockedTime += now.Sub(s.connBlockedSince)
+	}
+	return StreamStats{
+		BytesWritten:                     s.bytesWritten,
+		BytesSent:                        uint64(s.writeOffset),
+		BytesAcked:                       s.bytesAcked,
+		BytesRetransmitted:               s.bytesRetransmitted,
+		StreamFlowControlBlockedTime:     streamBlockedTime,
+		ConnectionFlowControlBlockedTime: connBlockedTime,
+		SendWindow:                       uint64(s.flowController.SendWindowSize()),
+	}
+}
+
 func (s *sendStream) handleStopSendingFrame(frame *wire.StopSendingFrame) {
-	s.cancelWriteImpl(frame.ErrorCode, true)
+	s.cancelWriteImpl(frame.ErrorCode, 0, true)
 }
 
 func (s *sendStream) Context() context.Context {
@@ -430,6 +618,152 @@ func (s *sendStream) SetWriteDeadline(t time.Time) error {
 	return nil
 }
 
+func (s *sendStream) SetDeliveryDeadline(d time.Duration, code StreamErrorCode) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.deliveryDeadline = d
+	s.deliveryErrorCode = code
+	if d > 0 && s.frameExpiries == nil {
+		s.frameExpiries = make(map[*wire.StreamFrame]time.Time)
+	}
+}
+
+// trackExpiry records the expiry time for the data passed to the current Write call.
+// It must be called with the mutex locked.
+func (s *sendStream) trackExpiry(expiry time.Time) {
+	offset := s.writeOffset
+	if s.nextFrame != nil {
+		offset += s.nextFrame.DataLen()
+	}
+	s.unsentExpiries = append(s.unsentExpiries, dataExpiry{offset: offset, expiry: expiry})
+	if s.deliveryTimerAlarm.IsZero() || expiry.Before(s.deliveryTimerAlarm) {
+		s.setDeliveryTimer(expiry)
+	}
+}
+
+// expiryAt returns the expiry time of unsent data at the given offset.
+// It returns the zero value if the data doesn't expire.
+func (s *sendStream) expiryAt(offset protocol.ByteCount) time.Time {
+	for i := len(s.unsentExpiries) - 1; i >= 0; i-- {
+		if s.unsentExpiries[i].offset <= offset {
+			return s.unsentExpiries[i].expiry
+		}
+	}
+	return time.Time{}
+}
+
+// pruneUnsentExpiries removes the expiry times for data that has been sent.
+// The entry covering the current write offset is kept.
+func (s *sendStream) pruneUnsentExpiries() {
+	var i int
+	for i < len(s.unsentExpiries)-1 && s.unsentExpiries[i+1].offset <= s.writeOffset {
+		i++
+	}
+	s.unsentExpiries = s.unsentExpiries[i:]
+}
+
+func (s *sendStream) hasUnsentData() bool {
+	return s.nextFrame != nil || len(s.dataForWriting) > 0
+}
+
+// nextDataExpired says if the data that would be sent next has expired.
+func (s *sendStream) nextDataExpired(now time.Time) bool {
+	if s.frameExpiries == nil || s.cancelWriteErr != nil || s.closeForShutdownErr != nil {
+		return false
+	}
+	var expiry time.Time
+	if len(s.retransmissionQueue) > 0 {
+		expiry = s.frameExpiries[s.retransmissionQueue[0]]
+	} else if s.hasUnsentData() {
+		expiry = s.expiryAt(s.writeOffset)
+	}
+	return !expiry.IsZero() && !now.Before(expiry)
+}
+
+// earliestExpiry returns the earliest expiry time of all data that hasn't been acknowledged yet,
+// as well as the lowest offset of that data.
+func (s *sendStream) earliestExpiry() (time.Time, protocol.ByteCount) {
+	var earliest time.Time
+	offset := protocol.MaxByteCount
+	for f, expiry := range s.frameExpiries {
+		if earliest.IsZero() || expiry.Before(earliest) {
+			earliest = expiry
+		}
+		offset = min(offset, f.Offset)
+	}
+	if s.hasUnsentData() {
+		for _, e := range s.unsentExpiries {
+			if earliest.IsZero() || e.expiry.Before(earliest) {
+				earliest = e.expiry
+			}
+		}
+		offset = min(offset, s.writeOffset)
+	}
+	return earliest, offset
+}
+
+func (s *sendStream) setDeliveryTimer(t time.Time) {
+	s.deliveryTimerAlarm = t
+	if s.deliveryTimer == nil {
+		s.deliveryTimer = time.AfterFunc(time.Until(t), s.onDeliveryTimer)
+		return
+	}
+	s.deliveryTimer.Reset(time.Until(t))
+}
+
+func (s *sendStream) onDeliveryTimer() {
+	s.mutex.Lock()
+	s.deliveryTimerAlarm = time.Time{}
+	if s.cancelWriteErr != nil || s.closeForShutdownErr != nil {
+		s.mutex.Unlock()
+		return
+	}
+	expiry, _ := s.earliestExpiry()
+	if expiry.IsZero() {
+		s.mutex.Unlock()
+		return
+	}
+	if time.Now().Before(expiry) {
+		s.setDeliveryTimer(expiry)
+		s.mutex.Unlock()
+		return
+	}
+	s.mutex.Unlock()
+	s.expireData()
+}
+
+// expireData drops all data that is subject to a delivery deadline and hasn't been acknowledged yet,
+// and resets the stream.
+// If the peer supports RESET_STREAM_AT, the data preceding the expired data is still delivered reliably.
+// It must be called without holding the mutex.
+func (s *sendStream) expireData() {
+	s.mutex.Lock()
+	if s.cancelWriteErr != nil || s.closeForShutdownErr != nil {
+		s.mutex.Unlock()
+		return
+	}
+	_, offset := s.earliestExpiry()
+	end := s.writeOffset
+	if s.nextFrame != nil {
+		end += s.nextFrame.DataLen()
+		s.nextFrame.PutBack()
+		s.nextFrame = nil
+	}
+	end += protocol.ByteCount(len(s.dataForWriting))
+	code := s.deliveryErrorCode
+	s.mutex.Unlock()
+
+	if offset < end {
+		s.sender.onStreamDataExpired(s.streamID, offset, end-offset)
+	}
+	var reliableSize protocol.ByteCount
+	if offset > 0 && s.sender.peerSupportsResetStreamAt() {
+		reliableSize = offset
+	}
+	s.cancelWriteImpl(code, reliableSize, false)
+}
+
 // CloseForShutdown closes a stream abruptly.
 // It makes Write unblock (and return the error) immediately.
 // The peer will NOT be informed about this: the stream is closed without sending a FIN or RST.
@@ -437,6 +771,7 @@ func (s *sendStream) closeForShutdown(err error) {
 	s.mutex.Lock()
 	s.ctxCancel(err)
 	s.closeForShutdownErr = err
+	s.updateBlockedTime(time.Now(), false, false)
 	s.mutex.Unlock()
 	s.signalWrite()
 }
@@ -455,9 +790,11 @@ var _ ackhandler.FrameHandler = &sendStreamAckHandler{}
 
 func (s *sendStreamAckHandler) OnAcked(f wire.Frame) {
 	sf := f.(*wire.StreamFrame)
-	sf.PutBack()
 	s.mutex.Lock()
-	if s.cancelWriteErr != nil {
+	delete(s.frameExpiries, sf)
+	s.bytesAcked += uint64(sf.DataLen())
+	sf.PutBack()
+	if s.cancelWriteErr != nil && s.reliableSize == 0 {
 		s.mutex.Unlock()
 		return
 	}
@@ -476,16 +813,31 @@ func (s *sendStreamAckHandler) OnAcked(f wire.Frame) {
 func (s *sendStreamAckHandler) OnLost(f wire.Frame) {
 	sf := f.(*wire.StreamFrame)
 	s.mutex.Lock()
-	if s.cancelWriteErr != nil {
+	if s.cancelWriteErr != nil && s.reliableSize == 0 {
 		s.mutex.Unlock()
 		return
 	}
-	sf.DataLenPresent = true
-	s.retransmissionQueue = append(s.retransmissionQueue, sf)
 	s.numOutstandingFrames--
 	if s.numOutstandingFrames < 0 {
 		panic("numOutStandingFrames negative")
 	}
+	// After CancelWriteAt, only data below the reliable size is retransmitted.
+	if s.cancelWriteErr != nil && !(*sendStream)(s).truncateToReliableSize(sf) {
+		newlyCompleted := (*sendStream)(s).isNewlyCompleted()
+		s.mutex.Unlock()
+		if newlyCompleted {
+			s.sender.onStreamCompleted(s.streamID)
+		}
+		return
+	}
+	sf.DataLenPresent = true
+	s.retransmissionQueue = append(s.retransmissionQueue, sf)
+	// There's no point in retransmitting data that already expired.
+	if expiry, ok := s.frameExpiries[sf]; ok && !time.Now().Before(expiry) {
+		s.mutex.Unlock()
+		(*sendStream)(s).expireData()
+		return
+	}
 	s.mutex.Unlock()
 
 	s.sender.onHasStreamData(s.streamID)
@@ -325,6 +325,7 @@ var _ = Describe("Send Stream", func() {
 		Context("flow control blocking", func() {
 			It("queues a BLOCKED frame if the stream is flow control blocked", func() {
 				mockFC.EXPECT().SendWindowSize().Return(protocol.ByteCount(0))
+				mockFC.EXPECT().IsBlocked().Return(true, false)
 				mockFC.EXPECT().IsNewlyBlocked().Return(true, protocol.ByteCount(12))
 				mockSender.EXPECT().queueControlFrame(&wire.StreamDataBlockedFrame{
 					StreamID:          streamID,
@@ -368,6 +369,7 @@ var _ = Describe("Send Stream", func() {
 
 				// try to pop again, this time noticing that we're blocked
 				mockFC.EXPECT().SendWindowSize()
+				mockFC.EXPECT().IsBlocked().Return(true, false)
 				// don't use offset 3 here, to make sure the BLOCKED frame contains the number returned by the flow controller
 				mockFC.EXPECT().IsNewlyBlocked().Return(true, protocol.ByteCount(10))
 				mockSender.EXPECT().queueControlFrame(&wire.StreamDataBlockedFrame{
@@ -675,6 +677,89 @@ var _ = Describe("Send Stream", func() {
 		})
 	})
 
+	Context("statistics", func() {
+		It("counts bytes written, sent, acknowledged and retransmitted", func() {
+			mockSender.EXPECT().onHasStreamData(streamID)
+			mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount).AnyTimes()
+			mockFC.EXPECT().AddBytesSent(gomock.Any()).AnyTimes()
+			_, err := str.Write([]byte("foobar"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(str.Stats().BytesWritten).To(BeEquivalentTo(6))
+			Expect(str.Stats().BytesSent).To(BeZero())
+
+			f1, ok, _ := str.popStreamFrame(expectedFrameHeaderLen(0)+4, protocol.Version1)
+			Expect(ok).To(BeTrue())
+			f2, ok, _ := str.popStreamFrame(expectedFrameHeaderLen(4)+2, protocol.Version1)
+			Expect(ok).To(BeTrue())
+			stats := str.Stats()
+			Expect(stats.BytesSent).To(BeEquivalentTo(6))
+			Expect(stats.BytesAcked).To(BeZero())
+
+			f2.Handler.OnAcked(f2.Frame)
+			Expect(str.Stats().BytesAcked).To(BeEquivalentTo(2))
+			mockSender.EXPECT().onHasStreamData(streamID)
+			f1.Handler.OnLost(f1.Frame)
+			Expect(str.Stats().BytesRetransmitted).To(BeZero())
+			f1, ok, _ = str.popStreamFrame(1000, protocol.Version1)
+			Expect(ok).To(BeTrue())
+			stats = str.Stats()
+			Expect(stats.BytesRetransmitted).To(BeEquivalentTo(4))
+			Expect(stats.BytesSent).To(BeEquivalentTo(6))
+			f1.Handler.OnAcked(f1.Frame)
+			Expect(str.Stats().BytesAcked).To(BeEquivalentTo(6))
+		})
+
+		It("tracks the time blocked by stream-level and connection-level flow control", func() {
+			mockSender.EXPECT().onHasStreamData(streamID)
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				defer close(done)
+				_, err := str.Write(make([]byte, 2000))
+				Expect(err).To(MatchError("shutdown"))
+			}()
+			waitForWrite()
+
+			// blocked by connection-level flow control
+			mockFC.EXPECT().SendWindowSize().Return(protocol.ByteCount(0))
+			mockFC.EXPECT().IsBlocked().Return(false, true)
+			mockFC.EXPECT().IsNewlyBlocked()
+			_, ok, _ := str.popStreamFrame(1000, protocol.Version1)
+			Expect(ok).To(BeFalse())
+			time.Sleep(scaleDuration(20 * time.Millisecond))
+			// now blocked by stream-level flow control
+			mockFC.EXPECT().SendWindowSize().Return(protocol.ByteCount(0))
+			mockFC.EXPECT().IsBlocked().Return(true, false)
+			mockFC.EXPECT().IsNewlyBlocked()
+			_, ok, _ = str.popStreamFrame(1000, protocol.Version1)
+			Expect(ok).To(BeFalse())
+			mockFC.EXPECT().SendWindowSize().Return(protocol.ByteCount(0)).Times(2)
+			stats := str.Stats()
+			Expect(stats.ConnectionFlowControlBlockedTime).To(BeNumerically(">=", scaleDuration(20*time.Millisecond)))
+			connBlockedTime := stats.ConnectionFlowControlBlockedTime
+			time.Sleep(scaleDuration(20 * time.Millisecond))
+			// The blocked time includes the ongoing blocked period.
+			Expect(str.Stats().StreamFlowControlBlockedTime).To(BeNumerically(">=", scaleDuration(20*time.Millisecond)))
+			// unblocked
+			mockFC.EXPECT().SendWindowSize().Return(protocol.ByteCount(100))
+			mockFC.EXPECT().AddBytesSent(gomock.Any())
+			_, ok, _ = str.popStreamFrame(1000, protocol.Version1)
+			Expect(ok).To(BeTrue())
+			mockFC.EXPECT().SendWindowSize().Return(protocol.ByteCount(0)).Times(2)
+			stats = str.Stats()
+			Expect(stats.ConnectionFlowControlBlockedTime).To(Equal(connBlockedTime))
+			streamBlockedTime := stats.StreamFlowControlBlockedTime
+			Expect(streamBlockedTime).To(BeNumerically(">=", scaleDuration(20*time.Millisecond)))
+			Expect(stats.SendWindow).To(BeZero())
+			time.Sleep(scaleDuration(5 * time.Millisecond))
+			Expect(str.Stats().StreamFlowControlBlockedTime).To(Equal(streamBlockedTime))
+
+			// make the Write go routine return
+			str.closeForShutdown(errors.New("shutdown"))
+			Eventually(done).Should(BeClosed())
+		})
+	})
+
 	Context("handling MAX_STREAM_DATA frames", func() {
 		It("informs the flow controller", func() {
 			mockFC.EXPECT().UpdateSendWindow(protocol.ByteCount(0x1337))
@@ -884,6 +969,110 @@ var _ = Describe("Send Stream", func() {
 			})
 		})
 
+		Context("canceling writing with a reliable size", func() {
+			It("queues a RESET_STREAM frame if the peer doesn't support RESET_STREAM_AT", func() {
+				mockSender.EXPECT().peerSupportsResetStreamAt().Return(false)
+				gomock.InOrder(
+					mockSender.EXPECT().queueControlFrame(&wire.ResetStreamFrame{
+						StreamID:  streamID,
+						FinalSize: 1234,
+						ErrorCode: 9876,
+					}),
+					mockSender.EXPECT().onStreamCompleted(streamID),
+				)
+				str.writeOffset = 1234
+				str.CancelWriteAt(9876, 100)
+			})
+
+			It("queues a RESET_STREAM frame if no data was written", func() {
+				mockSender.EXPECT().peerSupportsResetStreamAt().Return(true)
+				gomock.InOrder(
+					mockSender.EXPECT().queueControlFrame(&wire.ResetStreamFrame{StreamID: streamID, ErrorCode: 9876}),
+					mockSender.EXPECT().onStreamCompleted(streamID),
+				)
+				str.CancelWriteAt(9876, 100)
+			})
+
+			It("retransmits data below the reliable size", func() {
+				mockSender.EXPECT().onHasStreamData(streamID)
+				mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount).Times(2)
+				mockFC.EXPECT().AddBytesSent(gomock.Any()).Times(2)
+				_, err := strWithTimeout.Write(getData(100))
+				Expect(err).ToNot(HaveOccurred())
+				frame1, ok, _ := str.popStreamFrame(50, protocol.Version1)
+				Expect(ok).To(BeTrue())
+				frame2, ok, _ := str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+				Expect(ok).To(BeTrue())
+				Expect(frame2.Frame.Offset).To(BeNumerically(">", 30))
+
+				mockSender.EXPECT().peerSupportsResetStreamAt().Return(true)
+				mockSender.EXPECT().queueControlFrame(&wire.ResetStreamAtFrame{
+					StreamID:     streamID,
+					ErrorCode:    1234,
+					FinalSize:    100,
+					ReliableSize: 30,
+				})
+				str.CancelWriteAt(1234, 30)
+				_, err = strWithTimeout.Write([]byte("foobar"))
+				Expect(err).To(MatchError(&StreamError{StreamID: streamID, ErrorCode: 1234}))
+
+				// data above the reliable size is not retransmitted
+				frame2.Handler.OnLost(frame2.Frame)
+				mockSender.EXPECT().onHasStreamData(streamID)
+				frame1.Handler.OnLost(frame1.Frame)
+				f, ok, hasMoreData := str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+				Expect(ok).To(BeTrue())
+				Expect(hasMoreData).To(BeTrue()) // that's a spurious hasMoreData
+				Expect(f.Frame.Offset).To(BeZero())
+				Expect(f.Frame.Data).To(Equal(getData(30)))
+				Expect(f.Frame.Fin).To(BeFalse())
+				_, ok, hasMoreData = str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+				Expect(ok).To(BeFalse())
+				Expect(hasMoreData).To(BeFalse())
+
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				f.Handler.OnAcked(f.Frame)
+			})
+
+			It("sends buffered data below the reliable size", func() {
+				mockSender.EXPECT().onHasStreamData(streamID)
+				_, err := strWithTimeout.Write(getData(100))
+				Expect(err).ToNot(HaveOccurred())
+
+				mockSender.EXPECT().peerSupportsResetStreamAt().Return(true)
+				gomock.InOrder(
+					mockSender.EXPECT().queueControlFrame(&wire.ResetStreamAtFrame{
+						StreamID:     streamID,
+						ErrorCode:    1234,
+						FinalSize:    40,
+						ReliableSize: 40,
+					}),
+					mockSender.EXPECT().onHasStreamData(streamID),
+				)
+				str.CancelWriteAt(1234, 40)
+
+				mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount)
+				mockFC.EXPECT().AddBytesSent(protocol.ByteCount(40))
+				f, ok, hasMoreData := str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+				Expect(ok).To(BeTrue())
+				Expect(hasMoreData).To(BeFalse())
+				Expect(f.Frame.Offset).To(BeZero())
+				Expect(f.Frame.Data).To(Equal(getData(40)))
+				Expect(f.Frame.Fin).To(BeFalse())
+
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				f.Handler.OnAcked(f.Frame)
+			})
+
+			It("only cancels once", func() {
+				mockSender.EXPECT().peerSupportsResetStreamAt().Return(true).Times(2)
+				mockSender.EXPECT().queueControlFrame(&wire.ResetStreamFrame{StreamID: streamID, ErrorCode: 1234})
+				mockSender.EXPECT().onStreamCompleted(gomock.Any())
+				str.CancelWriteAt(1234, 0)
+				str.CancelWriteAt(4321, 10)
+			})
+		})
+
 		Context("receiving STOP_SENDING frames", func() {
 			It("queues a RESET_STREAM frames, and copies the error code from the STOP_SENDING frame", func() {
 				mockSender.EXPECT().queueControlFrame(&wire.ResetStreamFrame{
@@ -1051,6 +1240,112 @@ var _ = Describe("Send Stream", func() {
 		})
 	})
 
+	Context("delivery deadlines", func() {
+		It("resets the stream when unsent data expires", func() {
+			str.SetDeliveryDeadline(scaleDuration(20*time.Millisecond), 42)
+			mockSender.EXPECT().onHasStreamData(streamID)
+			_, err := str.Write([]byte("foobar"))
+			Expect(err).ToNot(HaveOccurred())
+			completed := make(chan struct{})
+			gomock.InOrder(
+				mockSender.EXPECT().onStreamDataExpired(streamID, protocol.ByteCount(0), protocol.ByteCount(6)),
+				mockSender.EXPECT().queueControlFrame(&wire.ResetStreamFrame{
+					StreamID:  streamID,
+					FinalSize: 0,
+					ErrorCode: 42,
+				}),
+				mockSender.EXPECT().onStreamCompleted(streamID).Do(func(protocol.StreamID) { close(completed) }),
+			)
+			Eventually(completed).Should(BeClosed())
+			_, ok, hasMoreData := str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+			Expect(ok).To(BeFalse())
+			Expect(hasMoreData).To(BeFalse())
+		})
+
+		It("doesn't retransmit expired data", func() {
+			str.SetDeliveryDeadline(scaleDuration(20*time.Millisecond), 42)
+			mockSender.EXPECT().onHasStreamData(streamID)
+			mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount)
+			mockFC.EXPECT().AddBytesSent(protocol.ByteCount(6))
+			_, err := str.Write([]byte("foobar"))
+			Expect(err).ToNot(HaveOccurred())
+			frame, ok, _ := str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+			Expect(ok).To(BeTrue())
+			completed := make(chan struct{})
+			gomock.InOrder(
+				mockSender.EXPECT().onStreamDataExpired(streamID, protocol.ByteCount(0), protocol.ByteCount(6)),
+				mockSender.EXPECT().queueControlFrame(&wire.ResetStreamFrame{
+					StreamID:  streamID,
+					FinalSize: 6,
+					ErrorCode: 42,
+				}),
+				mockSender.EXPECT().onStreamCompleted(streamID).Do(func(protocol.StreamID) { close(completed) }),
+			)
+			Eventually(completed).Should(BeClosed())
+			// don't EXPECT any calls to onHasStreamData
+			frame.Handler.OnLost(frame.Frame)
+			Expect(str.retransmissionQueue).To(BeEmpty())
+		})
+
+		It("uses RESET_STREAM_AT to deliver the data written before the deadline was set", func() {
+			mockSender.EXPECT().onHasStreamData(streamID).Times(2)
+			mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount)
+			mockFC.EXPECT().AddBytesSent(protocol.ByteCount(3))
+			_, err := str.Write([]byte("foo"))
+			Expect(err).ToNot(HaveOccurred())
+			frame, ok, _ := str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+			Expect(ok).To(BeTrue())
+			str.SetDeliveryDeadline(scaleDuration(20*time.Millisecond), 42)
+			_, err = str.Write([]byte("bar"))
+			Expect(err).ToNot(HaveOccurred())
+			reset := make(chan struct{})
+			gomock.InOrder(
+				mockSender.EXPECT().onStreamDataExpired(streamID, protocol.ByteCount(3), protocol.ByteCount(3)),
+				mockSender.EXPECT().peerSupportsResetStreamAt().Return(true),
+				mockSender.EXPECT().queueControlFrame(&wire.ResetStreamAtFrame{
+					StreamID:     streamID,
+					ErrorCode:    42,
+					FinalSize:    3,
+					ReliableSize: 3,
+				}).Do(func(wire.Frame) { close(reset) }),
+			)
+			Eventually(reset).Should(BeClosed())
+			// the data below the reliable size is retransmitted
+			mockSender.EXPECT().onHasStreamData(streamID)
+			frame.Handler.OnLost(frame.Frame)
+			Expect(str.retransmissionQueue).To(HaveLen(1))
+			Expect(str.retransmissionQueue[0].Data).To(Equal([]byte("foo")))
+		})
+
+		It("doesn't expire acknowledged data", func() {
+			str.SetDeliveryDeadline(scaleDuration(20*time.Millisecond), 42)
+			mockSender.EXPECT().onHasStreamData(streamID)
+			mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount)
+			mockFC.EXPECT().AddBytesSent(protocol.ByteCount(6))
+			_, err := str.Write([]byte("foobar"))
+			Expect(err).ToNot(HaveOccurred())
+			frame, ok, _ := str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+			Expect(ok).To(BeTrue())
+			frame.Handler.OnAcked(frame.Frame)
+			// don't EXPECT any calls to onStreamDataExpired or queueControlFrame
+			time.Sleep(scaleDuration(50 * time.Millisecond))
+			Expect(str.cancelWriteErr).ToNot(HaveOccurred())
+		})
+
+		It("doesn't expire data written before the deadline was set", func() {
+			mockSender.EXPECT().onHasStreamData(streamID)
+			mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount)
+			mockFC.EXPECT().AddBytesSent(protocol.ByteCount(6))
+			_, err := str.Write([]byte("foobar"))
+			Expect(err).ToNot(HaveOccurred())
+			str.SetDeliveryDeadline(scaleDuration(10*time.Millisecond), 42)
+			time.Sleep(scaleDuration(20 * time.Millisecond))
+			frame, ok, _ := str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+			Expect(ok).To(BeTrue())
+			Expect(frame.Frame.Data).To(Equal([]byte("foobar")))
+		})
+	})
+
 	Context("determining when a stream is completed", func() {
 		BeforeEach(func() {
 			mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount).AnyTimes()
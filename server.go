@@ -69,8 +69,8 @@ type baseServer struct {
 
 	conn rawConn
 
-	tokenGenerator *handshake.TokenGenerator
-	maxTokenAge    time.Duration
+	tokenPolicy TokenPolicy
+	ticketKeys  *handshake.SessionTicketKeyRing
 
 	connIDGenerator ConnectionIDGenerator
 	connHandler     packetHandlerManager
@@ -94,7 +94,8 @@ type baseServer struct {
 		protocol.StatelessResetToken,
 		*Config,
 		*tls.Config,
-		*handshake.TokenGenerator,
+		TokenPolicy,
+		*handshake.SessionTicketKeyRing,
 		bool, /* client address validated by an address validation token */
 		*logging.ConnectionTracer,
 		*streamtypebalancer.Balancer,
@@ -115,6 +116,7 @@ type baseServer struct {
 
 	maxNumHandshakesUnvalidated int
 	maxNumHandshakesTotal       int
+	admitConnection             func(*AdmissionInfo) AdmissionDecision
 	numHandshakesUnvalidated    atomic.Int64
 	numHandshakesValidated      atomic.Int64
 
@@ -246,9 +248,10 @@ func newServer(
 	config *Config,
 	tracer *logging.Tracer,
 	onClose func(),
-	tokenGeneratorKey TokenGeneratorKey,
-	maxTokenAge time.Duration,
+	tokenPolicy TokenPolicy,
+	ticketKeys *handshake.SessionTicketKeyRing,
 	maxNumHandshakesUnvalidated, maxNumHandshakesTotal int,
+	admitConnection func(*AdmissionInfo) AdmissionDecision,
 	disableVersionNegotiation bool,
 	acceptEarly bool,
 ) *baseServer {
@@ -256,10 +259,11 @@ func newServer(
 		conn:                        conn,
 		tlsConf:                     tlsConf,
 		config:                      config,
-		tokenGenerator:              handshake.NewTokenGenerator(tokenGeneratorKey),
-		maxTokenAge:                 maxTokenAge,
+		tokenPolicy:                 tokenPolicy,
+		ticketKeys:                  ticketKeys,
 		maxNumHandshakesUnvalidated: maxNumHandshakesUnvalidated,
 		maxNumHandshakesTotal:       maxNumHandshakesTotal,
+		admitConnection:             admitConnection,
 		connIDGenerator:             connIDGenerator,
 		connHandler:                 connHandler,
 		connQueue:                   make(chan quicConn, protocol.MaxAcceptQueueSize),
@@ -530,26 +534,6 @@ func (s *baseServer) cleanupZeroRTTQueues(now time.Time) {
 	s.nextZeroRTTCleanup = nextCleanup
 }
 
-// validateToken returns false if:
-//   - address is invalid
-//   - token is expired
-//   - token is null
-func (s *baseServer) validateToken(token *handshake.Token, addr net.Addr) bool {
-	if token == nil {
-		return false
-	}
-	if !token.ValidateRemoteAddr(addr) {
-		return false
-	}
-	if !token.IsRetryToken && time.Since(token.SentTime) > s.maxTokenAge {
-		return false
-	}
-	if token.IsRetryToken && time.Since(token.SentTime) > s.config.maxRetryTokenAge() {
-		return false
-	}
-	return true
-}
-
 func (s *baseServer) handleInitialImpl(p receivedPacket, hdr *wire.Header) error {
 	if len(hdr.Token) == 0 && hdr.DestConnectionID.Len() < protocol.MinConnectionIDLenInitial {
 		if s.tracer != nil && s.tracer.DroppedPacket != nil {
@@ -568,47 +552,93 @@ func (s *baseServer) handleInitialImpl(p receivedPacket, hdr *wire.Header) error
 	}
 
 	var (
-		token          *handshake.Token
+		token          *AddressToken
 		retrySrcConnID *protocol.ConnectionID
 	)
 	origDestConnID := hdr.DestConnectionID
 	if len(hdr.Token) > 0 {
-		tok, err := s.tokenGenerator.DecodeToken(hdr.Token)
-		if err == nil {
+		tok, err := s.tokenPolicy.ValidateToken(hdr.Token, p.remoteAddr)
+		if err == nil && tok != nil {
 			if tok.IsRetryToken {
 				origDestConnID = tok.OriginalDestConnectionID
 				retrySrcConnID = &tok.RetrySrcConnectionID
 			}
 			token = tok
+		} else if err != nil {
+			tokenErr := &TokenValidationError{Reason: logging.TokenRejectionInvalid}
+			errors.As(err, &tokenErr)
+			s.logger.Debugf("Rejecting token from %s: %s", p.remoteAddr, err)
+			if s.tracer != nil && s.tracer.RejectedToken != nil {
+				s.tracer.RejectedToken(p.remoteAddr, tokenErr.IsRetryToken, tokenErr.Reason)
+			}
+			// For invalid and expired non-retry tokens, we don't send an INVALID_TOKEN error.
+			// We just ignore them, and act as if there was no token on this packet at all.
+			// This also means we might send a Retry later.
+			if tokenErr.IsRetryToken {
+				// For Retry tokens, we send an INVALID_ERROR if
+				// * the token is too old, or
+				// * the token is invalid, in case of a retry token.
+				select {
+				case s.invalidTokenQueue <- rejectedPacket{receivedPacket: p, hdr: hdr}:
+				default:
+					// drop packet if we can't send out the  INVALID_TOKEN packets fast enough
+					p.buffer.Release()
+				}
+				return nil
+			}
 		}
 	}
+	clientAddrValidated := token != nil
 
-	clientAddrValidated := s.validateToken(token, p.remoteAddr)
-	if token != nil && !clientAddrValidated {
-		// For invalid and expired non-retry tokens, we don't send an INVALID_TOKEN error.
-		// We just ignore them, and act as if there was no token on this packet at all.
-		// This also means we might send a Retry later.
-		if !token.IsRetryToken {
-			token = nil
-		} else {
-			// For Retry tokens, we send an INVALID_ERROR if
-			// * the token is too old, or
-			// * the token is invalid, in case of a retry token.
+	// Until the next call to handleInitialImpl, these numbers are guaranteed to not increase.
+	// They might decrease if another connection completes the handshake.
+	numHandshakesUnvalidated := s.numHandshakesUnvalidated.Load()
+	numHandshakesValidated := s.numHandshakesValidated.Load()
+
+	forceRetry := false
+	if s.admitConnection != nil {
+		var tokenData []byte
+		if token != nil {
+			tokenData = token.Data
+		}
+		decision := s.admitConnection(&AdmissionInfo{
+			RemoteAddr:               p.remoteAddr,
+			AddrValidated:            clientAddrValidated,
+			IsRetryToken:             token != nil && token.IsRetryToken,
+			Version:                  hdr.Version,
+			DestConnectionID:         hdr.DestConnectionID,
+			NumHandshakes:            int(numHandshakesUnvalidated + numHandshakesValidated),
+			NumUnvalidatedHandshakes: int(numHandshakesUnvalidated),
+			TokenData:                tokenData,
+		})
+		switch decision {
+		case AdmissionAccept:
+		case AdmissionRetry:
+			forceRetry = !clientAddrValidated
+		case AdmissionDrop:
+			s.logger.Debugf("Dropping Initial packet from %s due to admission control", p.remoteAddr)
+			if s.tracer != nil && s.tracer.DroppedPacket != nil {
+				s.tracer.DroppedPacket(p.remoteAddr, logging.PacketTypeInitial, p.Size(), logging.PacketDropDOSPrevention)
+			}
+			delete(s.zeroRTTQueues, hdr.DestConnectionID)
+			p.buffer.Release()
+			return nil
+		case AdmissionRefuse:
+			s.logger.Debugf("Rejecting new connection from %s due to admission control", p.remoteAddr)
+			delete(s.zeroRTTQueues, hdr.DestConnectionID)
 			select {
-			case s.invalidTokenQueue <- rejectedPacket{receivedPacket: p, hdr: hdr}:
+			case s.connectionRefusedQueue <- rejectedPacket{receivedPacket: p, hdr: hdr}:
 			default:
-				// drop packet if we can't send out the  INVALID_TOKEN packets fast enough
+				// drop packet if we can't send out the CONNECTION_REFUSED fast enough
 				p.buffer.Release()
 			}
 			return nil
+		default:
+			p.buffer.Release()
+			return fmt.Errorf("invalid admission decision: %d", decision)
 		}
 	}
 
-	// Until the next call to handleInitialImpl, these numbers are guaranteed to not increase.
-	// They might decrease if another connection completes the handshake.
-	numHandshakesUnvalidated := s.numHandshakesUnvalidated.Load()
-	numHandshakesValidated := s.numHandshakesValidated.Load()
-
 	// Check the total handshake limit first. It's better to reject than to initiate a retry.
 	if total := numHandshakesUnvalidated + numHandshakesValidated; total >= int64(s.maxNumHandshakesTotal) {
 		s.logger.Debugf("Rejecting new connection. Server currently busy. Currently handshaking: %d (max %d)", total, s.maxNumHandshakesTotal)
@@ -621,7 +651,7 @@ func (s *baseServer) handleInitialImpl(p receivedPacket, hdr *wire.Header) error
 		}
 		return nil
 	}
-	if token == nil && numHandshakesUnvalidated >= int64(s.maxNumHandshakesUnvalidated) {
+	if token == nil && (forceRetry || numHandshakesUnvalidated >= int64(s.maxNumHandshakesUnvalidated)) {
 		// Retry invalidates all 0-RTT packets sent.
 		delete(s.zeroRTTQueues, hdr.DestConnectionID)
 		select {
@@ -680,7 +710,8 @@ func (s *baseServer) handleInitialImpl(p receivedPacket, hdr *wire.Header) error
 			s.connHandler.GetStatelessResetToken(connID),
 			config,
 			s.tlsConf,
-			s.tokenGenerator,
+			s.tokenPolicy,
+			s.ticketKeys,
 			clientAddrValidated,
 			tracer,
 			balancer, //make this Capital? or again interface problems?
@@ -775,7 +806,7 @@ func (s *baseServer) sendRetryPacket(p rejectedPacket) error {
 	if err != nil {
 		return err
 	}
-	token, err := s.tokenGenerator.NewRetryToken(p.remoteAddr, hdr.DestConnectionID, srcConnID)
+	token, err := s.tokenPolicy.NewRetryToken(p.remoteAddr, hdr.DestConnectionID, srcConnID)
 	if err != nil {
 		return err
 	}
@@ -18,6 +18,7 @@ import (
 	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/internal/wire"
 	"github.com/quic-go/quic-go/logging"
+	"github.com/quic-go/quic-go/streamtypebalancer"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -260,7 +261,7 @@ var _ = Describe("Server", func() {
 			It("creates a connection when the token is accepted", func() {
 				serv.maxNumHandshakesUnvalidated = 0
 				raddr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1337}
-				retryToken, err := serv.tokenGenerator.NewRetryToken(
+				retryToken, err := serv.tokenPolicy.NewRetryToken(
 					raddr,
 					protocol.ParseConnectionID([]byte{0xde, 0xad, 0xc0, 0xde}),
 					protocol.ParseConnectionID([]byte{0xde, 0xca, 0xfb, 0xad}),
@@ -305,9 +306,11 @@ var _ = Describe("Server", func() {
 					tokenP protocol.StatelessResetToken,
 					_ *Config,
 					_ *tls.Config,
-					_ *handshake.TokenGenerator,
+					_ TokenPolicy,
+					_ *handshake.SessionTicketKeyRing,
 					_ bool,
 					_ *logging.ConnectionTracer,
+					_ *streamtypebalancer.Balancer,
 					_ uint64,
 					_ utils.Logger,
 					_ protocol.Version,
@@ -508,9 +511,11 @@ var _ = Describe("Server", func() {
 					tokenP protocol.StatelessResetToken,
 					_ *Config,
 					_ *tls.Config,
-					_ *handshake.TokenGenerator,
+					_ TokenPolicy,
+					_ *handshake.SessionTicketKeyRing,
 					_ bool,
 					_ *logging.ConnectionTracer,
+					_ *streamtypebalancer.Balancer,
 					_ uint64,
 					_ utils.Logger,
 					_ protocol.Version,
@@ -572,9 +577,11 @@ var _ = Describe("Server", func() {
 					_ protocol.StatelessResetToken,
 					_ *Config,
 					_ *tls.Config,
-					_ *handshake.TokenGenerator,
+					_ TokenPolicy,
+					_ *handshake.SessionTicketKeyRing,
 					_ bool,
 					_ *logging.ConnectionTracer,
+					_ *streamtypebalancer.Balancer,
 					_ uint64,
 					_ utils.Logger,
 					_ protocol.Version,
@@ -627,9 +634,11 @@ var _ = Describe("Server", func() {
 					_ protocol.StatelessResetToken,
 					_ *Config,
 					_ *tls.Config,
-					_ *handshake.TokenGenerator,
+					_ TokenPolicy,
+					_ *handshake.SessionTicketKeyRing,
 					_ bool,
 					_ *logging.ConnectionTracer,
+					_ *streamtypebalancer.Balancer,
 					_ uint64,
 					_ utils.Logger,
 					_ protocol.Version,
@@ -678,9 +687,11 @@ var _ = Describe("Server", func() {
 					_ protocol.StatelessResetToken,
 					_ *Config,
 					_ *tls.Config,
-					_ *handshake.TokenGenerator,
+					_ TokenPolicy,
+					_ *handshake.SessionTicketKeyRing,
 					_ bool,
 					_ *logging.ConnectionTracer,
+					_ *streamtypebalancer.Balancer,
 					_ uint64,
 					_ utils.Logger,
 					_ protocol.Version,
@@ -758,9 +769,11 @@ var _ = Describe("Server", func() {
 					_ protocol.StatelessResetToken,
 					_ *Config,
 					_ *tls.Config,
-					_ *handshake.TokenGenerator,
+					_ TokenPolicy,
+					_ *handshake.SessionTicketKeyRing,
 					_ bool,
 					_ *logging.ConnectionTracer,
+					_ *streamtypebalancer.Balancer,
 					_ uint64,
 					_ utils.Logger,
 					_ protocol.Version,
@@ -827,7 +840,7 @@ var _ = Describe("Server", func() {
 		Context("token validation", func() {
 			It("decodes the token from the token field", func() {
 				raddr := &net.UDPAddr{IP: net.IPv4(192, 168, 13, 37), Port: 1337}
-				token, err := serv.tokenGenerator.NewRetryToken(raddr, protocol.ConnectionID{}, protocol.ConnectionID{})
+				token, err := serv.tokenPolicy.NewRetryToken(raddr, protocol.ConnectionID{}, protocol.ConnectionID{})
 				Expect(err).ToNot(HaveOccurred())
 				packet := getPacket(&wire.Header{
 					Type:    protocol.PacketTypeInitial,
@@ -850,7 +863,7 @@ var _ = Describe("Server", func() {
 
 			It("sends an INVALID_TOKEN error, if an invalid retry token is received", func() {
 				serv.maxNumHandshakesUnvalidated = 0
-				token, err := serv.tokenGenerator.NewRetryToken(&net.UDPAddr{}, protocol.ConnectionID{}, protocol.ConnectionID{})
+				token, err := serv.tokenPolicy.NewRetryToken(&net.UDPAddr{}, protocol.ConnectionID{}, protocol.ConnectionID{})
 				Expect(err).ToNot(HaveOccurred())
 				hdr := &wire.Header{
 					Type:             protocol.PacketTypeInitial,
@@ -863,6 +876,7 @@ var _ = Describe("Server", func() {
 				packet.data = append(packet.data, []byte("coalesced packet")...) // add some garbage to simulate a coalesced packet
 				raddr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1337}
 				packet.remoteAddr = raddr
+				tracer.EXPECT().RejectedToken(raddr, true, logging.TokenRejectionAddressMismatch)
 				tracer.EXPECT().SentPacket(packet.remoteAddr, gomock.Any(), gomock.Any(), gomock.Any()).Do(func(_ net.Addr, replyHdr *logging.Header, _ logging.ByteCount, frames []logging.Frame) {
 					Expect(replyHdr.Type).To(Equal(protocol.PacketTypeInitial))
 					Expect(replyHdr.SrcConnectionID).To(Equal(hdr.DestConnectionID))
@@ -886,10 +900,9 @@ var _ = Describe("Server", func() {
 
 			It("sends an INVALID_TOKEN error, if an expired retry token is received", func() {
 				serv.maxNumHandshakesUnvalidated = 0
-				serv.config.HandshakeIdleTimeout = time.Millisecond / 2 // the maximum retry token age is equivalent to the handshake timeout
-				Expect(serv.config.maxRetryTokenAge()).To(Equal(time.Millisecond))
+				serv.tokenPolicy.(*tokenPolicy).maxRetryTokenAge = time.Millisecond
 				raddr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1337}
-				token, err := serv.tokenGenerator.NewRetryToken(raddr, protocol.ConnectionID{}, protocol.ConnectionID{})
+				token, err := serv.tokenPolicy.NewRetryToken(raddr, protocol.ConnectionID{}, protocol.ConnectionID{})
 				Expect(err).ToNot(HaveOccurred())
 				time.Sleep(2 * time.Millisecond) // make sure the token is expired
 				hdr := &wire.Header{
@@ -901,6 +914,7 @@ var _ = Describe("Server", func() {
 				}
 				packet := getPacket(hdr, make([]byte, protocol.MinInitialPacketSize))
 				packet.remoteAddr = raddr
+				tracer.EXPECT().RejectedToken(raddr, true, logging.TokenRejectionExpired)
 				tracer.EXPECT().SentPacket(packet.remoteAddr, gomock.Any(), gomock.Any(), gomock.Any()).Do(func(_ net.Addr, replyHdr *logging.Header, _ logging.ByteCount, frames []logging.Frame) {
 					Expect(replyHdr.Type).To(Equal(protocol.PacketTypeInitial))
 					Expect(replyHdr.SrcConnectionID).To(Equal(hdr.DestConnectionID))
@@ -924,7 +938,7 @@ var _ = Describe("Server", func() {
 
 			It("doesn't send an INVALID_TOKEN error, if an invalid non-retry token is received", func() {
 				serv.maxNumHandshakesUnvalidated = 0
-				token, err := serv.tokenGenerator.NewToken(&net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1337})
+				token, err := serv.tokenPolicy.NewToken(&net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1337})
 				Expect(err).ToNot(HaveOccurred())
 				hdr := &wire.Header{
 					Type:             protocol.PacketTypeInitial,
@@ -937,6 +951,7 @@ var _ = Describe("Server", func() {
 				packet.data[len(packet.data)-10] ^= 0xff // corrupt the packet
 				raddr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1337}
 				packet.remoteAddr = raddr
+				tracer.EXPECT().RejectedToken(raddr, false, logging.TokenRejectionAddressMismatch)
 				tracer.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).MaxTimes(1)
 				done := make(chan struct{})
 				conn.EXPECT().WriteTo(gomock.Any(), raddr).DoAndReturn(func(b []byte, _ net.Addr) (int, error) {
@@ -953,9 +968,9 @@ var _ = Describe("Server", func() {
 
 			It("sends an INVALID_TOKEN error, if an expired non-retry token is received", func() {
 				serv.maxNumHandshakesUnvalidated = 0
-				serv.maxTokenAge = time.Millisecond
+				serv.tokenPolicy.(*tokenPolicy).maxTokenAge = time.Millisecond
 				raddr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1337}
-				token, err := serv.tokenGenerator.NewToken(raddr)
+				token, err := serv.tokenPolicy.NewToken(raddr)
 				Expect(err).ToNot(HaveOccurred())
 				time.Sleep(2 * time.Millisecond) // make sure the token is expired
 				hdr := &wire.Header{
@@ -967,6 +982,7 @@ var _ = Describe("Server", func() {
 				}
 				packet := getPacket(hdr, make([]byte, protocol.MinInitialPacketSize))
 				packet.remoteAddr = raddr
+				tracer.EXPECT().RejectedToken(raddr, false, logging.TokenRejectionExpired)
 				tracer.EXPECT().SentPacket(packet.remoteAddr, gomock.Any(), gomock.Any(), gomock.Any()).Do(func(_ net.Addr, replyHdr *logging.Header, _ logging.ByteCount, frames []logging.Frame) {
 					Expect(replyHdr.Type).To(Equal(protocol.PacketTypeRetry))
 				})
@@ -982,7 +998,7 @@ var _ = Describe("Server", func() {
 
 			It("doesn't send an INVALID_TOKEN error, if the packet is corrupted", func() {
 				serv.maxNumHandshakesUnvalidated = 0
-				token, err := serv.tokenGenerator.NewRetryToken(&net.UDPAddr{}, protocol.ConnectionID{}, protocol.ConnectionID{})
+				token, err := serv.tokenPolicy.NewRetryToken(&net.UDPAddr{}, protocol.ConnectionID{}, protocol.ConnectionID{})
 				Expect(err).ToNot(HaveOccurred())
 				hdr := &wire.Header{
 					Type:             protocol.PacketTypeInitial,
@@ -995,6 +1011,7 @@ var _ = Describe("Server", func() {
 				packet.data[len(packet.data)-10] ^= 0xff // corrupt the packet
 				packet.remoteAddr = &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1337}
 				done := make(chan struct{})
+				tracer.EXPECT().RejectedToken(packet.remoteAddr, true, logging.TokenRejectionAddressMismatch)
 				tracer.EXPECT().DroppedPacket(packet.remoteAddr, logging.PacketTypeInitial, packet.Size(), logging.PacketDropPayloadDecryptError).Do(func(net.Addr, logging.PacketType, protocol.ByteCount, logging.PacketDropReason) { close(done) })
 				phm.EXPECT().Get(gomock.Any())
 				serv.handlePacket(packet)
@@ -1042,9 +1059,11 @@ var _ = Describe("Server", func() {
 					_ protocol.StatelessResetToken,
 					conf *Config,
 					_ *tls.Config,
-					_ *handshake.TokenGenerator,
+					_ TokenPolicy,
+					_ *handshake.SessionTicketKeyRing,
 					_ bool,
 					_ *logging.ConnectionTracer,
+					_ *streamtypebalancer.Balancer,
 					_ uint64,
 					_ utils.Logger,
 					_ protocol.Version,
@@ -1112,9 +1131,11 @@ var _ = Describe("Server", func() {
 					_ protocol.StatelessResetToken,
 					conf *Config,
 					_ *tls.Config,
-					_ *handshake.TokenGenerator,
+					_ TokenPolicy,
+					_ *handshake.SessionTicketKeyRing,
 					_ bool,
 					_ *logging.ConnectionTracer,
+					_ *streamtypebalancer.Balancer,
 					_ uint64,
 					_ utils.Logger,
 					_ protocol.Version,
@@ -1189,9 +1210,11 @@ var _ = Describe("Server", func() {
 					_ protocol.StatelessResetToken,
 					_ *Config,
 					_ *tls.Config,
-					_ *handshake.TokenGenerator,
+					_ TokenPolicy,
+					_ *handshake.SessionTicketKeyRing,
 					_ bool,
 					_ *logging.ConnectionTracer,
+					_ *streamtypebalancer.Balancer,
 					_ uint64,
 					_ utils.Logger,
 					_ protocol.Version,
@@ -1262,9 +1285,11 @@ var _ = Describe("Server", func() {
 				_ protocol.StatelessResetToken,
 				_ *Config,
 				_ *tls.Config,
-				_ *handshake.TokenGenerator,
+				_ TokenPolicy,
+				_ *handshake.SessionTicketKeyRing,
 				_ bool,
 				_ *logging.ConnectionTracer,
+				_ *streamtypebalancer.Balancer,
 				_ uint64,
 				_ utils.Logger,
 				_ protocol.Version,
@@ -1306,9 +1331,11 @@ var _ = Describe("Server", func() {
 				_ protocol.StatelessResetToken,
 				_ *Config,
 				_ *tls.Config,
-				_ *handshake.TokenGenerator,
+				_ TokenPolicy,
+				_ *handshake.SessionTicketKeyRing,
 				_ bool,
 				_ *logging.ConnectionTracer,
+				_ *streamtypebalancer.Balancer,
 				_ uint64,
 				_ utils.Logger,
 				_ protocol.Version,
@@ -1367,9 +1394,11 @@ var _ = Describe("Server", func() {
 				_ protocol.StatelessResetToken,
 				_ *Config,
 				_ *tls.Config,
-				_ *handshake.TokenGenerator,
+				_ TokenPolicy,
+				_ *handshake.SessionTicketKeyRing,
 				_ bool,
 				_ *logging.ConnectionTracer,
+				_ *streamtypebalancer.Balancer,
 				_ uint64,
 				_ utils.Logger,
 				_ protocol.Version,
@@ -1490,9 +1519,11 @@ var _ = Describe("Server", func() {
 				_ protocol.StatelessResetToken,
 				_ *Config,
 				_ *tls.Config,
-				_ *handshake.TokenGenerator,
+				_ TokenPolicy,
+				_ *handshake.SessionTicketKeyRing,
 				_ bool,
 				_ *logging.ConnectionTracer,
+				_ *streamtypebalancer.Balancer,
 				_ uint64,
 				_ utils.Logger,
 				_ protocol.Version,
@@ -0,0 +1,159 @@
+package quic
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/handshake"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// SessionTicketKey is a key used to encrypt session tickets.
+type SessionTicketKey = handshake.SessionTicketKey
+
+// SessionTicketKeyConfig configures the SessionTicketKeyManager returned by NewSessionTicketKeyManager.
+type SessionTicketKeyConfig struct {
+	// Keys returns the key ring used to encrypt session tickets.
+	// New session tickets are encrypted using the first key,
+	// and session tickets encrypted using any of the keys are accepted.
+	// Keys can be rotated by adding a new key at the front, and removing old keys.
+	// It must not be set if Secret is set.
+	Keys func() []SessionTicketKey
+	// Secret is used to derive a new key at the beginning of every RotationInterval.
+	// Servers sharing the same secret (and having synchronized clocks) derive the same keys,
+	// without any further coordination.
+	// It must be at least 32 bytes long.
+	// If neither Keys nor Secret is set, a random secret is generated.
+	Secret []byte
+	// RotationInterval is the interval at which new keys are derived from the Secret.
+	// If unset, it defaults to 24 hours.
+	RotationInterval time.Duration
+	// MaxKeyAge is the maximum age of a key. Session tickets encrypted using older keys are rejected.
+	// If unset, it defaults to 7 days, the maximum lifetime of a session ticket in TLS 1.3.
+	MaxKeyAge time.Duration
+	// Max0RTTKeyAge is the maximum age of a key for which 0-RTT is accepted.
+	// Session tickets encrypted using older keys can still be used for session resumption,
+	// but the client's 0-RTT data is rejected.
+	// Keys returned by the Keys callback without a creation time are never used for 0-RTT.
+	// If unset, it defaults to the RotationInterval.
+	Max0RTTKeyAge time.Duration
+	// ReplayFilterCapacity is the expected number of 0-RTT connection attempts during Max0RTTKeyAge.
+	// The replay filter rejects 0-RTT if the same session ticket and ClientHello were seen before.
+	// Exceeding the capacity doesn't weaken the protection against replays,
+	// but increases the number of legitimate 0-RTT connection attempts that are rejected.
+	// If unset, it defaults to 65536. If negative, no replay filter is used.
+	ReplayFilterCapacity int
+}
+
+// A SessionTicketKeyManager manages the keys used to encrypt TLS session tickets.
+// It replaces the session ticket keys of the tls.Config, and allows a fleet of servers
+// to share and rotate session ticket keys, while limiting the acceptance of 0-RTT to recent keys.
+//
+// The replay filter is kept in memory. It only detects replays to the same server,
+// and it is lost when the server is restarted.
+// Applications must still be prepared for 0-RTT data to be replayed, see section 8 of RFC 8446.
+type SessionTicketKeyManager struct {
+	ring *handshake.SessionTicketKeyRing
+
+	secret           []byte
+	rotationInterval time.Duration
+	maxKeyAge        time.Duration
+
+	mutex      sync.Mutex
+	epoch      int64
+	cachedKeys []SessionTicketKey
+}
+
+// NewSessionTicketKeyManager creates a new SessionTicketKeyManager.
+// It is used by setting Transport.SessionTicketKeys.
+func NewSessionTicketKeyManager(conf *SessionTicketKeyConfig) (*SessionTicketKeyManager, error) {
+	if conf == nil {
+		conf = &SessionTicketKeyConfig{}
+	}
+	if conf.Keys != nil && conf.Secret != nil {
+		return nil, errors.New("only one of Keys and Secret can be set")
+	}
+	m := &SessionTicketKeyManager{
+		secret:           conf.Secret,
+		rotationInterval: conf.RotationInterval,
+		maxKeyAge:        conf.MaxKeyAge,
+	}
+	if m.rotationInterval == 0 {
+		m.rotationInterval = 24 * time.Hour
+	}
+	if m.maxKeyAge == 0 {
+		m.maxKeyAge = 7 * 24 * time.Hour
+	}
+	if m.rotationInterval < 0 || m.maxKeyAge < 0 || conf.Max0RTTKeyAge < 0 {
+		return nil, errors.New("invalid session ticket key lifetime")
+	}
+	max0RTTKeyAge := conf.Max0RTTKeyAge
+	if max0RTTKeyAge == 0 {
+		max0RTTKeyAge = m.rotationInterval
+	}
+	replayFilterCapacity := conf.ReplayFilterCapacity
+	if replayFilterCapacity == 0 {
+		replayFilterCapacity = 1 << 16
+	}
+	keys := conf.Keys
+	if keys == nil {
+		if m.secret == nil {
+			m.secret = make([]byte, 32)
+			if _, err := rand.Read(m.secret); err != nil {
+				return nil, err
+			}
+		}
+		if len(m.secret) < 32 {
+			return nil, errors.New("session ticket secret too short")
+		}
+		m.epoch = -1
+		keys = func() []SessionTicketKey { return m.derivedKeys(time.Now()) }
+	}
+	m.ring = handshake.NewSessionTicketKeyRing(keys, m.maxKeyAge, max0RTTKeyAge, replayFilterCapacity)
+	return m, nil
+}
+
+// derivedKeys returns the keys derived from the secret that are valid at the given time, newest first.
+func (m *SessionTicketKeyManager) derivedKeys(now time.Time) []SessionTicketKey {
+	epoch := now.UnixNano() / int64(m.rotationInterval)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if epoch == m.epoch {
+		return m.cachedKeys
+	}
+	var keys []SessionTicketKey
+	for e := epoch; e >= 0; e-- {
+		created := time.Unix(0, e*int64(m.rotationInterval))
+		if now.Sub(created) >= m.maxKeyAge {
+			break
+		}
+		keys = append(keys, SessionTicketKey{Key: m.deriveKey(e), Created: created})
+	}
+	m.epoch = epoch
+	m.cachedKeys = keys
+	return keys
+}
+
+func (m *SessionTicketKeyManager) deriveKey(epoch int64) [32]byte {
+	info := binary.BigEndian.AppendUint64([]byte("quic-go session ticket key"), uint64(epoch))
+	var key [32]byte
+	if _, err := io.ReadFull(hkdf.New(sha256.New, m.secret, nil, info), key[:]); err != nil {
+		panic(err) // only fails if more than 255 * 32 bytes are read
+	}
+	return key
+}
+
+func (m *SessionTicketKeyManager) keyRing() *handshake.SessionTicketKeyRing {
+	if m == nil {
+		return nil
+	}
+	return m.ring
+}
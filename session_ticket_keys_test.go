@@ -0,0 +1,84 @@
+package quic
+
+import (
+	"bytes"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Session Ticket Key Manager", func() {
+	secret := bytes.Repeat([]byte{42}, 32)
+
+	It("validates the config", func() {
+		_, err := NewSessionTicketKeyManager(&SessionTicketKeyConfig{
+			Secret: secret,
+			Keys:   func() []SessionTicketKey { return nil },
+		})
+		Expect(err).To(MatchError("only one of Keys and Secret can be set"))
+		_, err = NewSessionTicketKeyManager(&SessionTicketKeyConfig{Secret: []byte("foobar")})
+		Expect(err).To(MatchError("session ticket secret too short"))
+		_, err = NewSessionTicketKeyManager(&SessionTicketKeyConfig{Max0RTTKeyAge: -time.Second})
+		Expect(err).To(MatchError("invalid session ticket key lifetime"))
+	})
+
+	It("derives keys from the secret", func() {
+		m, err := NewSessionTicketKeyManager(&SessionTicketKeyConfig{
+			Secret:           secret,
+			RotationInterval: time.Hour,
+			MaxKeyAge:        3 * time.Hour,
+		})
+		Expect(err).ToNot(HaveOccurred())
+		now := time.Date(2024, 1, 1, 12, 30, 0, 0, time.UTC)
+		keys := m.derivedKeys(now)
+		Expect(keys).To(HaveLen(3))
+		Expect(keys[0].Created).To(BeTemporally("==", time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)))
+		Expect(keys[1].Created).To(BeTemporally("==", time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC)))
+		Expect(keys[2].Created).To(BeTemporally("==", time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)))
+		Expect(keys[0].Key).ToNot(Equal(keys[1].Key))
+
+		// after the rotation
+		rotated := m.derivedKeys(now.Add(time.Hour))
+		Expect(rotated).To(HaveLen(3))
+		Expect(rotated[0].Created).To(BeTemporally("==", time.Date(2024, 1, 1, 13, 0, 0, 0, time.UTC)))
+		Expect(rotated[1:]).To(Equal(keys[:2]))
+	})
+
+	It("derives the same keys on different servers", func() {
+		m1, err := NewSessionTicketKeyManager(&SessionTicketKeyConfig{Secret: secret})
+		Expect(err).ToNot(HaveOccurred())
+		m2, err := NewSessionTicketKeyManager(&SessionTicketKeyConfig{Secret: secret})
+		Expect(err).ToNot(HaveOccurred())
+		m3, err := NewSessionTicketKeyManager(nil)
+		Expect(err).ToNot(HaveOccurred())
+		now := time.Now()
+		Expect(m1.derivedKeys(now)).To(Equal(m2.derivedKeys(now)))
+		Expect(m1.derivedKeys(now)).ToNot(Equal(m3.derivedKeys(now)))
+
+		ticket, err := m1.keyRing().Seal([]byte("foobar"))
+		Expect(err).ToNot(HaveOccurred())
+		state, allow0RTT, err := m2.keyRing().Open(ticket)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state).To(Equal([]byte("foobar")))
+		Expect(allow0RTT).To(BeTrue())
+		_, _, err = m3.keyRing().Open(ticket)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("uses the keys provided by the application", func() {
+		key := SessionTicketKey{Key: [32]byte{1, 2, 3}, Created: time.Now()}
+		m, err := NewSessionTicketKeyManager(&SessionTicketKeyConfig{
+			Keys: func() []SessionTicketKey { return []SessionTicketKey{key} },
+		})
+		Expect(err).ToNot(HaveOccurred())
+		ticket, err := m.keyRing().Seal([]byte("foobar"))
+		Expect(err).ToNot(HaveOccurred())
+		m2, err := NewSessionTicketKeyManager(&SessionTicketKeyConfig{
+			Keys: func() []SessionTicketKey { return []SessionTicketKey{key} },
+		})
+		Expect(err).ToNot(HaveOccurred())
+		_, _, err = m2.keyRing().Open(ticket)
+		Expect(err).ToNot(HaveOccurred())
+	})
+})
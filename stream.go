@@ -27,6 +27,10 @@ type streamSender interface {
 	onHasStreamData(protocol.StreamID)
 	// must be called without holding the mutex that is acquired by closeForShutdown
 	onStreamCompleted(protocol.StreamID)
+	// called when unacknowledged data is dropped because its delivery deadline expired
+	onStreamDataExpired(id protocol.StreamID, offset, length protocol.ByteCount)
+	// says if the peer supports RESET_STREAM_AT frames
+	peerSupportsResetStreamAt() bool
 }
 
 // Each of the both stream halves gets its own uniStreamSender.
@@ -56,6 +60,7 @@ type streamI interface {
 	// for receiving
 	handleStreamFrame(*wire.StreamFrame) error
 	handleResetStreamFrame(*wire.ResetStreamFrame) error
+	handleResetStreamAtFrame(*wire.ResetStreamAtFrame) error
 	getWindowUpdate() protocol.ByteCount
 	// for sending
 	hasData() bool
@@ -123,6 +128,15 @@ func (s *stream) Close() error {
 	return s.sendStream.Close()
 }
 
+// need to define Stats() here, since both receiveStream and sendStream have a Stats()
+func (s *stream) Stats() StreamStats {
+	stats := s.sendStream.Stats()
+	rcvStats := s.receiveStream.Stats()
+	stats.FramesReceivedOutOfOrder = rcvStats.FramesReceivedOutOfOrder
+	stats.ReceiveWindow = rcvStats.ReceiveWindow
+	return stats
+}
+
 func (s *stream) SetDeadline(t time.Time) error {
 	_ = s.SetReadDeadline(t)  // SetReadDeadline never errors
 	_ = s.SetWriteDeadline(t) // SetWriteDeadline never errors
@@ -57,6 +57,17 @@ var _ = Describe("Stream", func() {
 		Expect(str.StreamID()).To(Equal(protocol.StreamID(1337)))
 	})
 
+	It("returns statistics for both directions", func() {
+		mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(6), false)
+		Expect(str.handleStreamFrame(&wire.StreamFrame{Offset: 3, Data: []byte("foo")})).To(Succeed())
+		mockFC.EXPECT().SendWindowSize().Return(protocol.ByteCount(42))
+		mockFC.EXPECT().RemainingReceiveWindow().Return(protocol.ByteCount(1337))
+		stats := str.Stats()
+		Expect(stats.SendWindow).To(BeEquivalentTo(42))
+		Expect(stats.FramesReceivedOutOfOrder).To(BeEquivalentTo(1))
+		Expect(stats.ReceiveWindow).To(BeEquivalentTo(1337))
+	})
+
 	Context("deadlines", func() {
 		It("sets a write deadline, when SetDeadline is called", func() {
 			str.SetDeadline(time.Now().Add(-time.Second))
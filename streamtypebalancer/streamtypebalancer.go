@@ -195,6 +195,12 @@ func NewBalancerAndTracer(w io.WriteCloser, p logging.Perspective, odcid protoco
 		ECNStateUpdated: func(state logging.ECNState, trigger logging.ECNStateTrigger) {
 			t.ECNStateUpdated(state, trigger)
 		},
+		ExpiredStreamData: func(id logging.StreamID, offset, length logging.ByteCount) {
+			t.ExpiredStreamData(id, offset, length)
+		},
+		UpdatedPeerAddress: func(oldAddr, newAddr net.Addr) {
+			t.UpdatedPeerAddress(oldAddr, newAddr)
+		},
 		ChoseALPN: func(protocol string) {
 			//t.recordEvent(time.Now(), eventALPNInformation{chosenALPN: protocol})
 		},
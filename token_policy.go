@@ -0,0 +1,183 @@
+package quic
+
+import (
+	"crypto/rand"
+	"net"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/handshake"
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/logging"
+)
+
+// A TokenPolicy generates and validates the tokens used for address validation,
+// see section 8.1 of RFC 9000 for details.
+// Tokens are used in Retry packets, and in NEW_TOKEN frames sent after completion of the handshake.
+// The client presents these tokens in the Initial packets of the (next) connection.
+type TokenPolicy interface {
+	// NewRetryToken returns a token to be sent in a Retry packet.
+	// The original Destination Connection ID and the Retry Source Connection ID
+	// need to be returned by ValidateToken.
+	NewRetryToken(remoteAddr net.Addr, origDestConnID, retrySrcConnID ConnectionID) ([]byte, error)
+	// NewToken returns a token to be sent in a NEW_TOKEN frame after completion of the handshake.
+	// If it returns an empty token, no NEW_TOKEN frame is sent.
+	NewToken(remoteAddr net.Addr) ([]byte, error)
+	// ValidateToken validates a token received in an Initial packet.
+	// If the token is invalid, it should return a *TokenValidationError.
+	// If it returns neither a token nor an error, the token is ignored,
+	// and the connection attempt is handled as if no token was presented.
+	ValidateToken(token []byte, remoteAddr net.Addr) (*AddressToken, error)
+}
+
+// An AddressToken is a valid token, as returned by TokenPolicy.ValidateToken.
+type AddressToken struct {
+	IsRetryToken bool
+	// Only set for Retry tokens.
+	OriginalDestConnectionID ConnectionID
+	RetrySrcConnectionID     ConnectionID
+	// Application data embedded into the token.
+	// Only set for tokens sent in NEW_TOKEN frames.
+	// It is passed to the Transport.AdmitConnection callback in AdmissionInfo.TokenData.
+	Data []byte
+}
+
+// A TokenValidationError is returned by TokenPolicy.ValidateToken if a token is invalid.
+// If the invalid token is a Retry token, the server closes the connection attempt with an INVALID_TOKEN error.
+// Otherwise, the token is ignored, and the connection attempt is handled as if no token was presented.
+type TokenValidationError struct {
+	IsRetryToken bool
+	Reason       logging.TokenRejectionReason
+}
+
+func (e *TokenValidationError) Error() string {
+	switch e.Reason {
+	case logging.TokenRejectionInvalid:
+		return "invalid token"
+	case logging.TokenRejectionAddressMismatch:
+		return "token issued for a different address"
+	case logging.TokenRejectionExpired:
+		return "token expired"
+	default:
+		return "token rejected"
+	}
+}
+
+// TokenPolicyConfig configures the TokenPolicy returned by NewTokenPolicy.
+type TokenPolicyConfig struct {
+	// Keys returns the keys used to protect tokens.
+	// New tokens are protected using the first key, and tokens protected using any of the keys are accepted.
+	// When running multiple servers that are authoritative for the same domain, they should use the same keys.
+	// Keys can be rotated by adding a new key at the front, and removing old keys once all tokens
+	// protected using this key have expired.
+	// If unset, a random key is generated.
+	Keys func() []TokenGeneratorKey
+	// MaxTokenAge is the maximum age of tokens sent in NEW_TOKEN frames.
+	// If unset, it defaults to 24 hours.
+	MaxTokenAge time.Duration
+	// MaxRetryTokenAge is the maximum age of tokens sent in Retry packets.
+	// If unset, it defaults to 10 seconds.
+	MaxRetryTokenAge time.Duration
+	// IPv4PrefixLen and IPv6PrefixLen bind tokens sent in NEW_TOKEN frames to the IP prefix
+	// of the respective length, instead of the exact IP address of the client.
+	// This allows clients to keep their validated address across NAT rebindings,
+	// at the cost of accepting tokens from all addresses in the prefix.
+	// Retry tokens are always bound to the exact IP address.
+	IPv4PrefixLen int
+	IPv6PrefixLen int
+	// NewTokenData is called after completion of the handshake.
+	// The data returned is embedded into the token sent in the NEW_TOKEN frame.
+	// When the client presents this token on a later connection, it is passed to the
+	// Transport.AdmitConnection callback in AdmissionInfo.TokenData.
+	// If it returns false, no NEW_TOKEN frame is sent.
+	// If unset, a NEW_TOKEN frame without any application data is sent.
+	NewTokenData func(remoteAddr net.Addr) ([]byte, bool)
+}
+
+// NewTokenPolicy creates a new TokenPolicy.
+func NewTokenPolicy(conf *TokenPolicyConfig) (TokenPolicy, error) {
+	if conf == nil {
+		conf = &TokenPolicyConfig{}
+	}
+	keys := conf.Keys
+	if keys == nil {
+		var key TokenGeneratorKey
+		if _, err := rand.Read(key[:]); err != nil {
+			return nil, err
+		}
+		keys = func() []TokenGeneratorKey { return []TokenGeneratorKey{key} }
+	}
+	maxRetryTokenAge := conf.MaxRetryTokenAge
+	if maxRetryTokenAge == 0 {
+		maxRetryTokenAge = 2 * protocol.DefaultHandshakeIdleTimeout
+	}
+	return newTokenPolicy(
+		handshake.NewRotatingTokenGenerator(keys, conf.IPv4PrefixLen, conf.IPv6PrefixLen),
+		conf.MaxTokenAge,
+		maxRetryTokenAge,
+		conf.NewTokenData,
+	), nil
+}
+
+type tokenPolicy struct {
+	tokenGenerator   *handshake.TokenGenerator
+	maxTokenAge      time.Duration
+	maxRetryTokenAge time.Duration
+	newTokenData     func(net.Addr) ([]byte, bool)
+}
+
+var _ TokenPolicy = &tokenPolicy{}
+
+func newTokenPolicy(
+	tokenGenerator *handshake.TokenGenerator,
+	maxTokenAge, maxRetryTokenAge time.Duration,
+	newTokenData func(net.Addr) ([]byte, bool),
+) *tokenPolicy {
+	if maxTokenAge == 0 {
+		maxTokenAge = protocol.TokenValidity
+	}
+	return &tokenPolicy{
+		tokenGenerator:   tokenGenerator,
+		maxTokenAge:      maxTokenAge,
+		maxRetryTokenAge: maxRetryTokenAge,
+		newTokenData:     newTokenData,
+	}
+}
+
+func (p *tokenPolicy) NewRetryToken(remoteAddr net.Addr, origDestConnID, retrySrcConnID ConnectionID) ([]byte, error) {
+	return p.tokenGenerator.NewRetryToken(remoteAddr, origDestConnID, retrySrcConnID)
+}
+
+func (p *tokenPolicy) NewToken(remoteAddr net.Addr) ([]byte, error) {
+	var data []byte
+	if p.newTokenData != nil {
+		var ok bool
+		data, ok = p.newTokenData(remoteAddr)
+		if !ok {
+			return nil, nil
+		}
+	}
+	return p.tokenGenerator.NewToken(remoteAddr, data)
+}
+
+func (p *tokenPolicy) ValidateToken(b []byte, remoteAddr net.Addr) (*AddressToken, error) {
+	token, err := p.tokenGenerator.DecodeToken(b)
+	if err != nil || token == nil {
+		return nil, &TokenValidationError{Reason: logging.TokenRejectionInvalid}
+	}
+	if !token.ValidateRemoteAddr(remoteAddr) {
+		return nil, &TokenValidationError{IsRetryToken: token.IsRetryToken, Reason: logging.TokenRejectionAddressMismatch}
+	}
+	maxAge := p.maxTokenAge
+	if token.IsRetryToken {
+		maxAge = p.maxRetryTokenAge
+	}
+	if time.Since(token.SentTime) > maxAge {
+		return nil, &TokenValidationError{IsRetryToken: token.IsRetryToken, Reason: logging.TokenRejectionExpired}
+	}
+	return &AddressToken{
+		IsRetryToken:             token.IsRetryToken,
+		OriginalDestConnectionID: token.OriginalDestConnectionID,
+		RetrySrcConnectionID:     token.RetrySrcConnectionID,
+		Data:                     token.Data,
+	}, nil
+}
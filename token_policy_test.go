@@ -0,0 +1,131 @@
+package quic
+
+import (
+	"crypto/rand"
+	"net"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/logging"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Token Policy", func() {
+	addr := &net.UDPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 1234}
+
+	newKey := func() TokenGeneratorKey {
+		var key TokenGeneratorKey
+		rand.Read(key[:])
+		return key
+	}
+
+	It("generates and validates Retry tokens", func() {
+		p, err := NewTokenPolicy(nil)
+		Expect(err).ToNot(HaveOccurred())
+		origDestConnID := protocol.ParseConnectionID([]byte{1, 2, 3, 4})
+		retrySrcConnID := protocol.ParseConnectionID([]byte{5, 6, 7, 8})
+		token, err := p.NewRetryToken(addr, origDestConnID, retrySrcConnID)
+		Expect(err).ToNot(HaveOccurred())
+		t, err := p.ValidateToken(token, addr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(t.IsRetryToken).To(BeTrue())
+		Expect(t.OriginalDestConnectionID).To(Equal(origDestConnID))
+		Expect(t.RetrySrcConnectionID).To(Equal(retrySrcConnID))
+
+		_, err = p.ValidateToken(token, &net.UDPAddr{IP: net.IPv4(192, 0, 2, 2), Port: 1234})
+		Expect(err).To(Equal(&TokenValidationError{IsRetryToken: true, Reason: logging.TokenRejectionAddressMismatch}))
+	})
+
+	It("rejects invalid tokens", func() {
+		p, err := NewTokenPolicy(nil)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = p.ValidateToken([]byte("foobar"), addr)
+		Expect(err).To(Equal(&TokenValidationError{Reason: logging.TokenRejectionInvalid}))
+		Expect(err).To(MatchError("invalid token"))
+	})
+
+	It("rejects expired tokens", func() {
+		p, err := NewTokenPolicy(&TokenPolicyConfig{
+			MaxTokenAge:      time.Millisecond,
+			MaxRetryTokenAge: time.Millisecond,
+		})
+		Expect(err).ToNot(HaveOccurred())
+		token, err := p.NewToken(addr)
+		Expect(err).ToNot(HaveOccurred())
+		retryToken, err := p.NewRetryToken(addr, protocol.ConnectionID{}, protocol.ConnectionID{})
+		Expect(err).ToNot(HaveOccurred())
+		time.Sleep(2 * time.Millisecond)
+		_, err = p.ValidateToken(token, addr)
+		Expect(err).To(Equal(&TokenValidationError{Reason: logging.TokenRejectionExpired}))
+		_, err = p.ValidateToken(retryToken, addr)
+		Expect(err).To(Equal(&TokenValidationError{IsRetryToken: true, Reason: logging.TokenRejectionExpired}))
+	})
+
+	It("embeds application data", func() {
+		p, err := NewTokenPolicy(&TokenPolicyConfig{
+			NewTokenData: func(remoteAddr net.Addr) ([]byte, bool) {
+				Expect(remoteAddr).To(Equal(addr))
+				return []byte("foobar"), true
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		token, err := p.NewToken(addr)
+		Expect(err).ToNot(HaveOccurred())
+		t, err := p.ValidateToken(token, addr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(t.IsRetryToken).To(BeFalse())
+		Expect(t.Data).To(Equal([]byte("foobar")))
+	})
+
+	It("doesn't issue tokens if told so", func() {
+		p, err := NewTokenPolicy(&TokenPolicyConfig{
+			NewTokenData: func(net.Addr) ([]byte, bool) { return nil, false },
+		})
+		Expect(err).ToNot(HaveOccurred())
+		token, err := p.NewToken(addr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(token).To(BeEmpty())
+	})
+
+	It("binds tokens to an IP prefix", func() {
+		p, err := NewTokenPolicy(&TokenPolicyConfig{IPv4PrefixLen: 24})
+		Expect(err).ToNot(HaveOccurred())
+		token, err := p.NewToken(addr)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = p.ValidateToken(token, &net.UDPAddr{IP: net.IPv4(192, 0, 2, 42), Port: 4321})
+		Expect(err).ToNot(HaveOccurred())
+		_, err = p.ValidateToken(token, &net.UDPAddr{IP: net.IPv4(192, 0, 3, 1), Port: 1234})
+		Expect(err).To(Equal(&TokenValidationError{Reason: logging.TokenRejectionAddressMismatch}))
+	})
+
+	It("rotates keys", func() {
+		key1 := newKey()
+		key2 := newKey()
+		keys := []TokenGeneratorKey{key1}
+		p1, err := NewTokenPolicy(&TokenPolicyConfig{Keys: func() []TokenGeneratorKey { return keys }})
+		Expect(err).ToNot(HaveOccurred())
+		// a second server, sharing the keys
+		p2, err := NewTokenPolicy(&TokenPolicyConfig{Keys: func() []TokenGeneratorKey { return keys }})
+		Expect(err).ToNot(HaveOccurred())
+		token1, err := p1.NewToken(addr)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = p2.ValidateToken(token1, addr)
+		Expect(err).ToNot(HaveOccurred())
+
+		keys = []TokenGeneratorKey{key2, key1}
+		token2, err := p1.NewToken(addr)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = p2.ValidateToken(token1, addr)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = p2.ValidateToken(token2, addr)
+		Expect(err).ToNot(HaveOccurred())
+
+		keys = []TokenGeneratorKey{key2}
+		_, err = p2.ValidateToken(token1, addr)
+		Expect(err).To(Equal(&TokenValidationError{Reason: logging.TokenRejectionInvalid}))
+		_, err = p2.ValidateToken(token2, addr)
+		Expect(err).ToNot(HaveOccurred())
+	})
+})
@@ -11,6 +11,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/quic-go/quic-go/internal/handshake"
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/internal/wire"
@@ -26,7 +27,7 @@ const (
 	// It's not clear how to choose a reasonable value that works for all use cases.
 	// In production, implementations should:
 	// 1. Choose a lower value.
-	// 2. Implement some kind of IP-address based filtering using the Config.GetConfigForClient
+	// 2. Implement some kind of IP-address based filtering using the Transport.AdmitConnection
 	//    callback in order to prevent flooding attacks from a single / small number of IP addresses.
 	defaultMaxNumHandshakes = math.MaxInt32
 )
@@ -86,6 +87,18 @@ type Transport struct {
 	// See section 8.1.3 of RFC 9000 for details.
 	MaxTokenAge time.Duration
 
+	// TokenPolicy controls the generation and validation of address validation tokens.
+	// It can be created using NewTokenPolicy, which allows binding tokens to an IP prefix,
+	// embedding application data into tokens, and rotating the keys used to protect tokens.
+	// If set, TokenGeneratorKey and MaxTokenAge are ignored.
+	TokenPolicy TokenPolicy
+
+	// SessionTicketKeys manages the keys used to encrypt TLS session tickets.
+	// It can be created using NewSessionTicketKeyManager, which allows sharing and rotating keys
+	// across multiple servers, limiting 0-RTT to recent keys, and detecting replayed 0-RTT connection attempts.
+	// If set, the session ticket keys of the tls.Config (and the WrapSession and UnwrapSession callbacks) are not used.
+	SessionTicketKeys *SessionTicketKeyManager
+
 	// DisableVersionNegotiationPackets disables the sending of Version Negotiation packets.
 	// This can be useful if version information is exchanged out-of-band.
 	// It has no effect for clients.
@@ -105,11 +118,22 @@ type Transport struct {
 	// and unvalidated source addresses.
 	// If unset, the number of concurrent handshakes will not be limited.
 	// Applications should choose a reasonable value based on their thread model, and consider
-	// implementing IP-based rate limiting using Config.GetConfigForClient.
+	// implementing IP-based rate limiting using AdmitConnection.
 	// If the number of handshakes reaches this number, new connection attempts will be rejected by
 	// terminating the connection attempt using a CONNECTION_REFUSED error.
 	MaxHandshakes int
 
+	// AdmitConnection is called for every Initial packet that would create a new connection,
+	// before any cryptographic handshake work is done for the connection attempt.
+	// It allows the application to accept the connection attempt, to require address validation
+	// using a Retry, to silently drop the packet, or to refuse the connection attempt.
+	// NewHandshakeRateLimiter provides an implementation that limits the rate of connection attempts
+	// per source IP address and per subnet.
+	// Connection attempts accepted by this callback are still subject to MaxUnvalidatedHandshakes and MaxHandshakes.
+	// The callback is called from the server's run loop, so it must not block.
+	// It has no effect for clients.
+	AdmitConnection func(*AdmissionInfo) AdmissionDecision
+
 	// A Tracer traces events that don't belong to a single QUIC connection.
 	Tracer *logging.Tracer
 
@@ -192,6 +216,10 @@ func (t *Transport) createServer(tlsConf *tls.Config, conf *Config, allow0RTT bo
 	if maxHandshakes == 0 {
 		maxHandshakes = defaultMaxNumHandshakes
 	}
+	tokenPolicy := t.TokenPolicy
+	if tokenPolicy == nil {
+		tokenPolicy = newTokenPolicy(handshake.NewTokenGenerator(*t.TokenGeneratorKey), t.MaxTokenAge, conf.maxRetryTokenAge(), nil)
+	}
 	s := newServer(
 		t.conn,
 		t.handlerMap,
@@ -200,10 +228,11 @@ func (t *Transport) createServer(tlsConf *tls.Config, conf *Config, allow0RTT bo
 		conf,
 		t.Tracer,
 		t.closeServer,
-		*t.TokenGeneratorKey,
-		t.MaxTokenAge,
+		tokenPolicy,
+		t.SessionTicketKeys.keyRing(),
 		maxUnvalidatedHandshakes,
 		maxHandshakes,
+		t.AdmitConnection,
 		t.DisableVersionNegotiationPackets,
 		allow0RTT,
 	)